@@ -33,19 +33,25 @@ type Argument struct {
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the JSON representation of an abi argument. Tuple
+// arguments describe their fields as nested components.
+type ArgumentMarshaling struct {
+	Name         string
+	Type         string
+	InternalType string
+	Components   []ArgumentMarshaling
+	Indexed      bool
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (argument *Argument) UnmarshalJSON(data []byte) error {
-	var extarg struct {
-		Name    string
-		Type    string
-		Indexed bool
-	}
+	var extarg ArgumentMarshaling
 	err := json.Unmarshal(data, &extarg)
 	if err != nil {
 		return fmt.Errorf("argument json err: %v", err)
 	}
 
-	argument.Type, err = NewType(extarg.Type)
+	argument.Type, err = NewType(extarg.Type, extarg.InternalType, extarg.Components)
 	if err != nil {
 		return err
 	}
@@ -83,6 +89,15 @@ func (arguments Arguments) isTuple() bool {
 	return len(arguments) > 1
 }
 
+// names returns the names of all the arguments.
+func (arguments Arguments) names() []string {
+	names := make([]string, len(arguments))
+	for i, arg := range arguments {
+		names[i] = arg.Name
+	}
+	return names
+}
+
 // Unpack performs the operation hexdata -> Go format
 func (arguments Arguments) Unpack(v interface{}, data []byte) error {
 
@@ -117,7 +132,7 @@ func (arguments Arguments) unpackTuple(v interface{}, marshalledValues []interfa
 	var abi2struct map[string]string
 	if kind == reflect.Struct {
 		var err error
-		abi2struct, err = mapAbiToStructFields(arguments, value)
+		abi2struct, err = mapArgNamesToStructFields(arguments.names(), value)
 		if err != nil {
 			return err
 		}
@@ -164,11 +179,17 @@ func (arguments Arguments) unpackAtomic(v interface{}, marshalledValues []interf
 
 	var abi2struct map[string]string
 	if kind == reflect.Struct {
+		arg := arguments.NonIndexed()[0]
+
+		// A single tuple is unpacked directly into a struct of its fields,
+		// unless the struct wraps it into a field named after the argument
+		if arg.Type.T == TupleTy && !elem.FieldByName(ToCamelCase(arg.Name)).IsValid() {
+			return set(elem, reflectValue, arg)
+		}
 		var err error
-		if abi2struct, err = mapAbiToStructFields(arguments, elem); err != nil {
+		if abi2struct, err = mapArgNamesToStructFields([]string{arg.Name}, elem); err != nil {
 			return err
 		}
-		arg := arguments.NonIndexed()[0]
 		if structField, ok := abi2struct[arg.Name]; ok {
 			return set(elem.FieldByName(structField), reflectValue, arg)
 		}
@@ -179,21 +200,6 @@ func (arguments Arguments) unpackAtomic(v interface{}, marshalledValues []interf
 
 }
 
-// Computes the full size of an array;
-// i.e. counting nested arrays, which count towards size for unpacking.
-func getArraySize(arr *Type) int {
-	size := arr.Size
-	// Arrays can be nested, with each element being the same size
-	arr = arr.Elem
-	for arr.T == ArrayTy {
-		// Keep multiplying by elem.Size while the elem is an array.
-		size *= arr.Size
-		arr = arr.Elem
-	}
-	// Now we have the full array size, including its children.
-	return size
-}
-
 // UnpackValues can be used to unpack ABI-encoded hexdata according to the ABI-specification,
 // without supplying a struct to unpack into. Instead, this method returns a list containing the
 // values. An atomic argument will be a list with one element.
@@ -202,18 +208,18 @@ func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
 	virtualArgs := 0
 	for index, arg := range arguments.NonIndexed() {
 		marshalledValue, err := toGoType((index+virtualArgs)*32, arg.Type, data)
-		if arg.Type.T == ArrayTy {
-			// If we have a static array, like [3]uint256, these are coded as
-			// just like uint256,uint256,uint256.
+		if !isDynamicType(arg.Type) {
+			// If we have a static array or tuple, like [3]uint256, these are
+			// coded just like uint256,uint256,uint256.
 			// This means that we need to add two 'virtual' arguments when
 			// we count the index from now on.
 			//
-			// Array values nested multiple levels deep are also encoded inline:
+			// Values nested multiple levels deep are also encoded inline:
 			// [2][3]uint256: uint256,uint256,uint256,uint256,uint256,uint256
 			//
-			// Calculate the full array size to get the correct offset for the next argument.
+			// Calculate the full size to get the correct offset for the next argument.
 			// Decrement it by 1, as the normal index increment is still applied.
-			virtualArgs += getArraySize(&arg.Type) - 1
+			virtualArgs += getTypeSize(arg.Type)/32 - 1
 		}
 		if err != nil {
 			return nil, err
@@ -243,11 +249,7 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	// input offset is the bytes offset for packed output
 	inputOffset := 0
 	for _, abiArg := range abiArgs {
-		if abiArg.Type.T == ArrayTy {
-			inputOffset += 32 * abiArg.Type.Size
-		} else {
-			inputOffset += 32
-		}
+		inputOffset += getTypeSize(abiArg.Type)
 	}
 	var ret []byte
 	for i, a := range args {
@@ -257,8 +259,8 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		// check for a slice type (string, bytes, slice)
-		if input.Type.requiresLengthPrefix() {
+		// check for a dynamic type (string, bytes, slice, dynamic array or tuple)
+		if isDynamicType(input.Type) {
 			// calculate the offset
 			offset := inputOffset + len(variableInput)
 			// set the offset
@@ -277,14 +279,14 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	return ret, nil
 }
 
-// capitalise makes the first character of a string upper case, also removing any
-// prefixing underscores from the variable names.
-func capitalise(input string) string {
-	for len(input) > 0 && input[0] == '_' {
-		input = input[1:]
-	}
-	if len(input) == 0 {
-		return ""
+// ToCamelCase converts an under-score string to a camel-case string, also
+// removing any prefixing underscores from the variable names.
+func ToCamelCase(input string) string {
+	parts := strings.Split(input, "_")
+	for i, s := range parts {
+		if len(s) > 0 {
+			parts[i] = strings.ToUpper(s[:1]) + s[1:]
+		}
 	}
-	return strings.ToUpper(input[:1]) + input[1:]
+	return strings.Join(parts, "")
 }
@@ -22,13 +22,15 @@ package bind
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
-	"unicode"
 
 	"github.com/dsplinz2019/dsplinz/accounts/abi"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
 	"golang.org/x/tools/imports"
 )
 
@@ -47,7 +49,10 @@ const (
 // manually maintain hard coded strings that break on runtime.
 func Bind(types []string, abis []string, bytecodes []string, pkg string, lang Lang) (string, error) {
 	// Process each individual contract requested binding
-	contracts := make(map[string]*tmplContract)
+	var (
+		contracts = make(map[string]*tmplContract)
+		structs   = make(map[string]*tmplStruct)
+	)
 
 	for i := 0; i < len(types); i++ {
 		// Parse the actual ABI to generate the binding for
@@ -55,13 +60,12 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 		if err != nil {
 			return "", err
 		}
-		// Strip any whitespace from the JSON ABI
-		strippedABI := strings.Map(func(r rune) rune {
-			if unicode.IsSpace(r) {
-				return -1
-			}
-			return r
-		}, abis[i])
+		// Strip any insignificant whitespace from the JSON ABI
+		stripped := new(bytes.Buffer)
+		if err := json.Compact(stripped, []byte(abis[i])); err != nil {
+			return "", err
+		}
+		strippedABI := stripped.String()
 
 		// Extract the call and transact methods; events; and sort them alphabetically
 		var (
@@ -117,29 +121,36 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		// Collect the tuples used by the contract to generate structs for
+		collectStructs(evmABI, structs)
 		contracts[types[i]] = &tmplContract{
 			Type:        capitalise(types[i]),
 			InputABI:    strings.Replace(strippedABI, "\"", "\\\"", -1),
-			InputBin:    strings.TrimSpace(bytecodes[i]),
+			InputBin:    hexutil.CPToHex(strings.TrimSpace(bytecodes[i])),
 			Constructor: evmABI.Constructor,
 			Calls:       calls,
 			Transacts:   transacts,
 			Events:      events,
 		}
 	}
+	if len(structs) > 0 && lang != LangGo {
+		return "", fmt.Errorf("tuple types are only supported in Go bindings")
+	}
 	// Generate the contract template data content and render it
 	data := &tmplData{
 		Package:   pkg,
 		Contracts: contracts,
+		Structs:   structs,
 	}
 	buffer := new(bytes.Buffer)
 
 	funcs := map[string]interface{}{
-		"bindtype":      bindType[lang],
-		"bindtopictype": bindTopicType[lang],
-		"namedtype":     namedType[lang],
-		"capitalise":    capitalise,
-		"decapitalise":  decapitalise,
+		"bindtype":       func(kind abi.Type) string { return bindType[lang](kind, structs) },
+		"bindtopictype":  func(kind abi.Type) string { return bindTopicType[lang](kind, structs) },
+		"bindfiltertype": func(kind abi.Type) string { return bindFilterTypeGo(kind, structs) },
+		"namedtype":      namedType[lang],
+		"capitalise":     capitalise,
+		"decapitalise":   decapitalise,
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
@@ -157,9 +168,75 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 	return buffer.String(), nil
 }
 
+// collectStructs gathers all the tuple types used by the methods and events of
+// a contract, naming them in a deterministic order. Tuples carrying their
+// Solidity source name are named after it, all others are numbered.
+func collectStructs(evmABI abi.ABI, structs map[string]*tmplStruct) {
+	var args []abi.Argument
+
+	args = append(args, evmABI.Constructor.Inputs...)
+	methods := make([]string, 0, len(evmABI.Methods))
+	for name := range evmABI.Methods {
+		methods = append(methods, name)
+	}
+	sort.Strings(methods)
+	for _, name := range methods {
+		args = append(args, evmABI.Methods[name].Inputs...)
+		args = append(args, evmABI.Methods[name].Outputs...)
+	}
+	events := make([]string, 0, len(evmABI.Events))
+	for name := range evmABI.Events {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	for _, name := range events {
+		args = append(args, evmABI.Events[name].Inputs...)
+	}
+	for _, arg := range args {
+		collectStruct(arg.Type, structs)
+	}
+}
+
+// collectStruct registers the given tuple type and all the tuples nested in it.
+func collectStruct(kind abi.Type, structs map[string]*tmplStruct) {
+	switch kind.T {
+	case abi.ArrayTy, abi.SliceTy:
+		collectStruct(*kind.Elem, structs)
+	case abi.TupleTy:
+		key := structKey(kind)
+		if _, exist := structs[key]; exist {
+			return
+		}
+		// Register inner tuples first, the fields need their names
+		for _, elem := range kind.TupleElems {
+			collectStruct(*elem, structs)
+		}
+		var fields []*tmplField
+		for i, elem := range kind.TupleElems {
+			fields = append(fields, &tmplField{Type: bindTypeGo(*elem, structs), Name: capitalise(kind.TupleRawNames[i]), SolKind: *elem})
+		}
+		name := capitalise(kind.TupleRawName)
+		if name == "" {
+			anonymous := 0
+			for _, s := range structs {
+				if s.Anonymous {
+					anonymous++
+				}
+			}
+			name = fmt.Sprintf("Struct%d", anonymous)
+		}
+		structs[key] = &tmplStruct{Name: name, Fields: fields, Anonymous: kind.TupleRawName == ""}
+	}
+}
+
+// structKey returns the identifier under which a tuple type is collected.
+func structKey(kind abi.Type) string {
+	return kind.TupleRawName + kind.String()
+}
+
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
-var bindType = map[Lang]func(kind abi.Type) string{
+var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindTypeGo,
 	LangJava: bindTypeJava,
 }
@@ -184,25 +261,22 @@ func wrapArray(stringKind string, innerLen int, innerMapping string) (string, []
 	return innerMapping, parts
 }
 
-// Translates the array sizes to a Go-lang declaration of a (nested) array of the inner type.
-// Simply returns the inner type if arraySizes is empty.
-func arrayBindingGo(inner string, arraySizes []string) string {
-	out := ""
-	//prepend all array sizes, from outer (end arraySizes) to inner (start arraySizes)
-	for i := len(arraySizes) - 1; i >= 0; i-- {
-		out += "[" + arraySizes[i] + "]"
-	}
-	out += inner
-	return out
-}
-
 // bindTypeGo converts a Solidity type to a Go one. Since there is no clear mapping
 // from all Solidity types to Go ones (e.g. uint17), those that cannot be exactly
-// mapped will use an upscaled type (e.g. *big.Int).
-func bindTypeGo(kind abi.Type) string {
-	stringKind := kind.String()
-	innerLen, innerMapping := bindUnnestedTypeGo(stringKind)
-	return arrayBindingGo(wrapArray(stringKind, innerLen, innerMapping))
+// mapped will use an upscaled type (e.g. *big.Int). Tuples are mapped to the
+// generated structs.
+func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[structKey(kind)].Name
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]", kind.Size) + bindTypeGo(*kind.Elem, structs)
+	case abi.SliceTy:
+		return "[]" + bindTypeGo(*kind.Elem, structs)
+	default:
+		_, bound := bindUnnestedTypeGo(kind.String())
+		return bound
+	}
 }
 
 // The inner function of bindTypeGo, this finds the inner type of stringKind.
@@ -247,7 +321,7 @@ func arrayBindingJava(inner string, arraySizes []string) string {
 // bindTypeJava converts a Solidity type to a Java one. Since there is no clear mapping
 // from all Solidity types to Java ones (e.g. uint17), those that cannot be exactly
 // mapped will use an upscaled type (e.g. BigDecimal).
-func bindTypeJava(kind abi.Type) string {
+func bindTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	stringKind := kind.String()
 	innerLen, innerMapping := bindUnnestedTypeJava(stringKind)
 	return arrayBindingJava(wrapArray(stringKind, innerLen, innerMapping))
@@ -310,25 +384,36 @@ func bindUnnestedTypeJava(stringKind string) (int, string) {
 
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
-var bindTopicType = map[Lang]func(kind abi.Type) string{
+var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindTopicTypeGo,
 	LangJava: bindTopicTypeJava,
 }
 
 // bindTypeGo converts a Solidity topic type to a Go one. It is almost the same
 // funcionality as for simple types, but dynamic types get converted to hashes.
-func bindTopicTypeGo(kind abi.Type) string {
-	bound := bindTypeGo(kind)
+func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	bound := bindFilterTypeGo(kind, structs)
 	if bound == "string" || bound == "[]byte" {
 		bound = "common.Hash"
 	}
 	return bound
 }
 
+// bindFilterTypeGo converts a Solidity indexed type to the Go type used to filter
+// for it. Strings and bytes are hashed into topics by the filterer, arrays and
+// tuples however have to be filtered for by the hash of their encoding.
+func bindFilterTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.ArrayTy, abi.SliceTy, abi.TupleTy:
+		return "common.Hash"
+	}
+	return bindTypeGo(kind, structs)
+}
+
 // bindTypeGo converts a Solidity topic type to a Java one. It is almost the same
 // funcionality as for simple types, but dynamic types get converted to hashes.
-func bindTopicTypeJava(kind abi.Type) string {
-	bound := bindTypeJava(kind)
+func bindTopicTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
+	bound := bindTypeJava(kind, structs)
 	if bound == "String" || bound == "Bytes" {
 		bound = "Hash"
 	}
@@ -387,48 +472,16 @@ var methodNormalizer = map[Lang]func(string) string{
 
 // capitalise makes a camel-case string which starts with an upper case character.
 func capitalise(input string) string {
-	for len(input) > 0 && input[0] == '_' {
-		input = input[1:]
-	}
-	if len(input) == 0 {
-		return ""
-	}
-	return toCamelCase(strings.ToUpper(input[:1]) + input[1:])
+	return abi.ToCamelCase(input)
 }
 
 // decapitalise makes a camel-case string which starts with a lower case character.
 func decapitalise(input string) string {
-	for len(input) > 0 && input[0] == '_' {
-		input = input[1:]
-	}
-	if len(input) == 0 {
+	goForm := abi.ToCamelCase(input)
+	if len(goForm) == 0 {
 		return ""
 	}
-	return toCamelCase(strings.ToLower(input[:1]) + input[1:])
-}
-
-// toCamelCase converts an under-score string to a camel-case string
-func toCamelCase(input string) string {
-	toupper := false
-
-	result := ""
-	for k, v := range input {
-		switch {
-		case k == 0:
-			result = strings.ToUpper(string(input[0]))
-
-		case toupper:
-			result += strings.ToUpper(string(v))
-			toupper = false
-
-		case v == '_':
-			toupper = true
-
-		default:
-			result += string(v)
-		}
-	}
-	return result
+	return strings.ToLower(goForm[:1]) + goForm[1:]
 }
 
 // structured checks whether a list of ABI data types has enough information to
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+)
+
+// Tests that prefixed bytecode ends up "0x" prefixed in the generated bindings,
+// so that it decodes back to the original code whatever the display prefix.
+func TestBindBytecodePrefix(t *testing.T) {
+	var (
+		abi  = `[{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
+		code = "6060604052600a8060106000396000f360606040526008565b00"
+	)
+	for _, prefix := range []string{"", "0x", "t0", "T0"} {
+		bind, err := Bind([]string{"Token"}, []string{abi}, []string{prefix + code + "\n"}, "bindtest", LangGo)
+		if err != nil {
+			t.Fatalf("prefix %q: failed to generate binding: %v", prefix, err)
+		}
+		match := regexp.MustCompile("const TokenBin = `([^`]*)`").FindStringSubmatch(bind)
+		if match == nil {
+			t.Fatalf("prefix %q: bytecode constant missing from binding", prefix)
+		}
+		if prefix != "" && !strings.HasPrefix(match[1], "0x") {
+			t.Errorf("prefix %q: bytecode not 0x prefixed: %s", prefix, match[1])
+		}
+		if !bytes.Equal(common.FromHex(match[1]), common.FromHex(code)) {
+			t.Errorf("prefix %q: bytecode mismatch: have %s, want %s", prefix, match[1], code)
+		}
+	}
+}
+
+// Tests that tuples are bound to generated structs, named after their Solidity
+// source where available, and that indexed tuples are filtered by hash.
+func TestBindTuples(t *testing.T) {
+	abi := `[
+		{"constant": true, "inputs": [{"name": "id", "type": "uint256"}], "name": "get", "outputs": [{"name": "p", "type": "tuple", "internalType": "struct Store.Person", "components": [{"name": "name", "type": "string"}, {"name": "home_addr", "type": "tuple", "components": [{"name": "city", "type": "string"}]}]}], "type": "function"},
+		{"constant": false, "inputs": [{"name": "points", "type": "tuple[2][]", "components": [{"name": "x", "type": "int64"}, {"name": "y", "type": "int64"}]}], "name": "draw", "outputs": [], "type": "function"},
+		{"anonymous": false, "inputs": [{"indexed": true, "name": "from", "type": "tuple", "components": [{"name": "x", "type": "int64"}, {"name": "y", "type": "int64"}]}, {"indexed": false, "name": "to", "type": "tuple", "components": [{"name": "x", "type": "int64"}, {"name": "y", "type": "int64"}]}], "name": "Moved", "type": "event"}
+	]`
+	bind, err := Bind([]string{"Store"}, []string{abi}, []string{""}, "bindtest", LangGo)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, want := range []string{
+		`type Struct0 struct {\s+X int64\s+Y int64\s+}`,
+		`type Struct1 struct {\s+City string\s+}`,
+		`type StorePerson struct {\s+Name\s+string\s+HomeAddr Struct1\s+}`,
+		`Get\(opts \*bind.CallOpts, id \*big.Int\) \(StorePerson, error\)`,
+		`Draw\(opts \*bind.TransactOpts, points \[\]\[2\]Struct0\)`,
+		`type StoreMoved struct {\s+From common.Hash\s+To\s+Struct0`,
+		`FilterMoved\(opts \*bind.FilterOpts, from \[\]common.Hash\)`,
+		`internalType\\":\\"struct Store.Person`,
+	} {
+		if !regexp.MustCompile(want).MatchString(bind) {
+			t.Errorf("binding missing %s", want)
+		}
+	}
+	if _, err := Bind([]string{"Store"}, []string{abi}, []string{""}, "bindtest", LangJava); err == nil {
+		t.Errorf("java binding of tuples succeeded")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
 )
 
 func TestPack(t *testing.T) {
@@ -325,7 +326,7 @@ func TestPack(t *testing.T) {
 			common.Hex2Bytes("0000000000000000000000000000000000000000000000000000000000000006666f6f6261720000000000000000000000000000000000000000000000000000"),
 		},
 	} {
-		typ, err := NewType(test.typ)
+		typ, err := NewType(test.typ, "", nil)
 		if err != nil {
 			t.Fatalf("%v failed. Unexpected parse error: %v", i, err)
 		}
@@ -441,3 +442,127 @@ func TestPackNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestPackHexAddress(t *testing.T) {
+	typ, err := NewType("address", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.LeftPadBytes(common.FromHex("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), 32)
+	for _, input := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"t05aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+	} {
+		packed, err := typ.pack(reflect.ValueOf(input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", input, err)
+			continue
+		}
+		if !bytes.Equal(packed, want) {
+			t.Errorf("%s: pack mismatch: have %x, want %x", input, packed, want)
+		}
+	}
+	for _, input := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+	} {
+		if _, err := typ.pack(reflect.ValueOf(input)); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+	// The ambiguous prefixes are only rejected in strict mode
+	ambiguous := "t15aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	if _, err := typ.pack(reflect.ValueOf(ambiguous)); err != nil {
+		t.Errorf("%s: unexpected error: %v", ambiguous, err)
+	}
+	hexutil.SetStrictPrefix(true)
+	defer hexutil.SetStrictPrefix(false)
+	if _, err := typ.pack(reflect.ValueOf(ambiguous)); err == nil {
+		t.Errorf("%s: expected error in strict mode", ambiguous)
+	}
+}
+
+const tupleABI = `[
+	{"name": "dynamic", "inputs": [{"name": "s", "type": "tuple", "components": [{"name": "a", "type": "uint256"}, {"name": "b", "type": "string"}]}, {"name": "c", "type": "uint256"}]},
+	{"name": "static", "inputs": [{"name": "t", "type": "tuple[2]", "components": [{"name": "a", "type": "uint256"}, {"name": "flag", "type": "bool"}]}, {"name": "c", "type": "uint256"}]},
+	{"name": "slice", "inputs": [{"name": "s", "type": "tuple[]", "components": [{"name": "a", "type": "uint256"}, {"name": "b", "type": "string"}]}]},
+	{"name": "nested", "inputs": [{"name": "n", "type": "tuple", "components": [{"name": "a", "type": "uint256"}, {"name": "inner_values", "type": "tuple[]", "components": [{"name": "flag", "type": "bool"}, {"name": "data", "type": "bytes"}]}]}]}
+]`
+
+type (
+	tupleDynamic struct {
+		A *big.Int
+		B string
+	}
+	tupleStatic struct {
+		A    *big.Int
+		Flag bool
+	}
+	tupleInner struct {
+		Flag bool
+		Data []byte
+	}
+	tupleNested struct {
+		A           *big.Int
+		InnerValues []tupleInner
+	}
+)
+
+// word returns the 32 byte big endian encoding of a number.
+func word(n int64) string {
+	return common.Bytes2Hex(common.LeftPadBytes(big.NewInt(n).Bytes(), 32))
+}
+
+func TestPackTuple(t *testing.T) {
+	abi, err := JSON(strings.NewReader(tupleABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo := common.Bytes2Hex(common.RightPadBytes([]byte("foo"), 32))
+	bar := common.Bytes2Hex(common.RightPadBytes([]byte("bar"), 32))
+
+	for i, test := range []struct {
+		method string
+		args   []interface{}
+		want   string
+		sig    string
+	}{
+		{
+			"dynamic",
+			[]interface{}{tupleDynamic{big.NewInt(1), "foo"}, big.NewInt(2)},
+			word(0x40) + word(2) + word(1) + word(0x40) + word(3) + foo,
+			"dynamic((uint256,string),uint256)",
+		},
+		{
+			"static",
+			[]interface{}{[2]tupleStatic{{big.NewInt(1), true}, {big.NewInt(2), false}}, big.NewInt(3)},
+			word(1) + word(1) + word(2) + word(0) + word(3),
+			"static((uint256,bool)[2],uint256)",
+		},
+		{
+			"slice",
+			[]interface{}{[]tupleDynamic{{big.NewInt(1), "foo"}, {big.NewInt(2), "bar"}}},
+			word(0x20) + word(2) + word(0x40) + word(0xc0) + word(1) + word(0x40) + word(3) + foo + word(2) + word(0x40) + word(3) + bar,
+			"slice((uint256,string)[])",
+		},
+		{
+			"nested",
+			[]interface{}{&tupleNested{big.NewInt(1), []tupleInner{{true, []byte("foo")}}}},
+			word(0x20) + word(1) + word(0x40) + word(1) + word(0x20) + word(1) + word(0x40) + word(3) + foo,
+			"nested((uint256,(bool,bytes)[]))",
+		},
+	} {
+		method := abi.Methods[test.method]
+		if sig := method.Sig(); sig != test.sig {
+			t.Errorf("test %d: signature mismatch: have %s, want %s", i, sig, test.sig)
+		}
+		packed, err := method.Inputs.Pack(test.args...)
+		if err != nil {
+			t.Errorf("test %d: failed to pack: %v", i, err)
+			continue
+		}
+		if have := common.Bytes2Hex(packed); have != test.want {
+			t.Errorf("test %d: pack mismatch:\nhave %s\nwant %s", i, have, test.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/dsplinz2019/dsplinz/common"
 )
 
 // Type enumerator
@@ -38,6 +40,7 @@ const (
 	HashTy
 	FixedPointTy
 	FunctionTy
+	TupleTy
 )
 
 // Type is the reflection of the supported argument type
@@ -50,6 +53,11 @@ type Type struct {
 	T    byte // Our own type checking
 
 	stringKind string // holds the unparsed string for deriving signatures
+
+	// Tuple relative fields
+	TupleRawName  string   // Raw struct name defined in source code, may be empty
+	TupleElems    []*Type  // Type information of all tuple fields
+	TupleRawNames []string // Raw field name of all tuple fields
 }
 
 var (
@@ -57,8 +65,10 @@ var (
 	typeRegex = regexp.MustCompile("([a-zA-Z]+)(([0-9]+)(x([0-9]+))?)?")
 )
 
-// NewType creates a new reflection type of abi type given in t.
-func NewType(t string) (typ Type, err error) {
+// NewType creates a new reflection type of abi type given in t. The components
+// describe the fields of tuple types and are ignored for all other types, the
+// internal type optionally carries the Solidity source name of tuples.
+func NewType(t string, internalType string, components []ArgumentMarshaling) (typ Type, err error) {
 	// check that array brackets are equal if they exist
 	if strings.Count(t, "[") != strings.Count(t, "]") {
 		return Type{}, fmt.Errorf("invalid arg type in abi")
@@ -71,7 +81,11 @@ func NewType(t string) (typ Type, err error) {
 	if strings.Count(t, "[") != 0 {
 		i := strings.LastIndex(t, "[")
 		// recursively embed the type
-		embeddedType, err := NewType(t[:i])
+		subInternal := internalType
+		if j := strings.LastIndex(internalType, "["); j != -1 {
+			subInternal = internalType[:j]
+		}
+		embeddedType, err := NewType(t[:i], subInternal, components)
 		if err != nil {
 			return Type{}, err
 		}
@@ -100,6 +114,8 @@ func NewType(t string) (typ Type, err error) {
 		} else {
 			return Type{}, fmt.Errorf("invalid formatting of array type")
 		}
+		// tuples are expanded in signatures, so derive the kind from the element
+		typ.stringKind = embeddedType.stringKind + sliced
 		return typ, err
 	}
 	// parse the type and size of the abi-type.
@@ -158,6 +174,8 @@ func NewType(t string) (typ Type, err error) {
 		typ.T = FunctionTy
 		typ.Size = 24
 		typ.Type = reflect.ArrayOf(24, reflect.TypeOf(byte(0)))
+	case "tuple":
+		return newTupleType(internalType, components)
 	default:
 		return Type{}, fmt.Errorf("unsupported arg type: %s", t)
 	}
@@ -165,6 +183,56 @@ func NewType(t string) (typ Type, err error) {
 	return
 }
 
+// newTupleType creates the reflection type of a tuple from the components of
+// its abi definition. Tuples are represented by Go structs with a field for
+// every component, named after the camel-cased component name.
+func newTupleType(internalType string, components []ArgumentMarshaling) (Type, error) {
+	var (
+		fields []reflect.StructField
+		elems  []*Type
+		names  []string
+		kinds  []string
+		used   = make(map[string]bool)
+	)
+	for _, c := range components {
+		elem, err := NewType(c.Type, c.InternalType, c.Components)
+		if err != nil {
+			return Type{}, err
+		}
+		name := ToCamelCase(c.Name)
+		if name == "" {
+			return Type{}, fmt.Errorf("abi: purely anonymous or underscored tuple field is not supported")
+		}
+		if used[name] {
+			return Type{}, fmt.Errorf("abi: tuple field %q is not unique", c.Name)
+		}
+		used[name] = true
+
+		fields = append(fields, reflect.StructField{
+			Name: name,
+			Type: elem.Type,
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", c.Name)),
+		})
+		elems = append(elems, &elem)
+		names = append(names, c.Name)
+		kinds = append(kinds, elem.stringKind)
+	}
+	typ := Type{
+		Kind:          reflect.Struct,
+		Type:          reflect.StructOf(fields),
+		T:             TupleTy,
+		stringKind:    "(" + strings.Join(kinds, ",") + ")",
+		TupleElems:    elems,
+		TupleRawNames: names,
+	}
+	// Solidity reports the source name of structs, e.g. "struct Lib.Person",
+	// which is flattened into a valid Go identifier.
+	if strings.HasPrefix(internalType, "struct ") {
+		typ.TupleRawName = strings.Replace(strings.TrimPrefix(internalType, "struct "), ".", "", -1)
+	}
+	return typ, nil
+}
+
 // String implements Stringer
 func (t Type) String() (out string) {
 	return t.stringKind
@@ -174,25 +242,76 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 	// dereference pointer first if it's a pointer
 	v = indirect(v)
 
+	// addresses may be given in hex, make sure they are not mistyped
+	if t.T == AddressTy && v.Kind() == reflect.String {
+		addr, err := common.ParseHexAddress(v.String())
+		if err != nil {
+			return nil, fmt.Errorf("abi: cannot use %q as address argument: %v", v.String(), err)
+		}
+		v = reflect.ValueOf(addr)
+	}
 	if err := typeCheck(t, v); err != nil {
 		return nil, err
 	}
 
-	if t.T == SliceTy || t.T == ArrayTy {
-		var packed []byte
+	switch t.T {
+	case SliceTy, ArrayTy:
+		var ret []byte
 
+		if t.requiresLengthPrefix() {
+			ret = append(ret, packNum(reflect.ValueOf(v.Len()))...)
+		}
+		// dynamic elements are referenced by offsets relative to the first element
+		offset := 0
+		offsetReq := isDynamicType(*t.Elem)
+		if offsetReq {
+			offset = getTypeSize(*t.Elem) * v.Len()
+		}
+		var tail []byte
 		for i := 0; i < v.Len(); i++ {
 			val, err := t.Elem.pack(v.Index(i))
 			if err != nil {
 				return nil, err
 			}
-			packed = append(packed, val...)
+			if !offsetReq {
+				ret = append(ret, val...)
+				continue
+			}
+			ret = append(ret, packNum(reflect.ValueOf(offset))...)
+			offset += len(val)
+			tail = append(tail, val...)
 		}
-		if t.T == SliceTy {
-			return packBytesSlice(packed, v.Len()), nil
-		} else if t.T == ArrayTy {
-			return packed, nil
+		return append(ret, tail...), nil
+
+	case TupleTy:
+		fields, err := mapArgNamesToStructFields(t.TupleRawNames, v)
+		if err != nil {
+			return nil, err
+		}
+		// dynamic fields are referenced by offsets relative to the tuple start
+		offset := 0
+		for _, elem := range t.TupleElems {
+			offset += getTypeSize(*elem)
+		}
+		var ret, tail []byte
+		for i, elem := range t.TupleElems {
+			field := v.FieldByName(fields[t.TupleRawNames[i]])
+			if !field.IsValid() {
+				return nil, fmt.Errorf("abi: field %s for tuple not found in the given struct", t.TupleRawNames[i])
+			}
+			val, err := elem.pack(field)
+			if err != nil {
+				return nil, err
+			}
+			if isDynamicType(*elem) {
+				ret = append(ret, packNum(reflect.ValueOf(offset))...)
+				tail = append(tail, val...)
+				offset += len(val)
+			} else {
+				ret = append(ret, val...)
+			}
 		}
+		return append(ret, tail...), nil
 	}
 	return packElement(t, v), nil
 }
@@ -202,3 +321,42 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 func (t Type) requiresLengthPrefix() bool {
 	return t.T == StringTy || t.T == BytesTy || t.T == SliceTy
 }
+
+// isDynamicType returns whether the type is encoded out of place, referenced
+// by an offset. Dynamic types are bytes, string, slices, arrays of dynamic
+// types and tuples with at least one dynamic field.
+func isDynamicType(t Type) bool {
+	switch t.T {
+	case StringTy, BytesTy, SliceTy:
+		return true
+	case ArrayTy:
+		return isDynamicType(*t.Elem)
+	case TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getTypeSize returns the number of bytes the type occupies in the head of an
+// encoding. Static types are encoded in place, dynamic types only take up the
+// 32 bytes of the offset pointing to their actual content.
+func getTypeSize(t Type) int {
+	if isDynamicType(t) {
+		return 32
+	}
+	switch t.T {
+	case ArrayTy:
+		return t.Size * getTypeSize(*t.Elem)
+	case TupleTy:
+		total := 0
+		for _, elem := range t.TupleElems {
+			total += getTypeSize(*elem)
+		}
+		return total
+	}
+	return 32
+}
@@ -97,7 +97,7 @@ func TestTypeRegexp(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		typ, err := NewType(tt.blob)
+		typ, err := NewType(tt.blob, "", nil)
 		if err != nil {
 			t.Errorf("type %q: failed to parse type string: %v", tt.blob, err)
 		}
@@ -256,7 +256,7 @@ func TestTypeCheck(t *testing.T) {
 		{"invalidType", "", "unsupported arg type: invalidType"},
 		{"invalidSlice[]", "", "unsupported arg type: invalidSlice"},
 	} {
-		typ, err := NewType(test.typ)
+		typ, err := NewType(test.typ, "", nil)
 		if err != nil && len(test.err) == 0 {
 			t.Fatal("unexpected parse error:", err)
 		} else if err != nil && len(test.err) != 0 {
@@ -281,3 +281,40 @@ func TestTypeCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTupleType(t *testing.T) {
+	components := []ArgumentMarshaling{
+		{Name: "id", Type: "uint256"},
+		{Name: "owner_address", Type: "address"},
+		{Name: "tags", Type: "tuple[]", InternalType: "struct Lib.Tag[]", Components: []ArgumentMarshaling{{Name: "name", Type: "string"}}},
+	}
+	typ, err := NewType("tuple[2]", "struct Lib.Item[2]", components)
+	if err != nil {
+		t.Fatalf("failed to parse tuple: %v", err)
+	}
+	if typ.String() != "(uint256,address,(string)[])[2]" {
+		t.Errorf("signature mismatch: have %s", typ)
+	}
+	elem := typ.Elem
+	if elem.T != TupleTy || elem.TupleRawName != "LibItem" || elem.TupleElems[2].Elem.TupleRawName != "LibTag" {
+		t.Errorf("tuple name mismatch: %q, %q", elem.TupleRawName, elem.TupleElems[2].Elem.TupleRawName)
+	}
+	if field, ok := elem.Type.FieldByName("OwnerAddress"); !ok || field.Type != reflect.TypeOf(common.Address{}) {
+		t.Errorf("tuple field mismatch: %v", elem.Type)
+	}
+	if !isDynamicType(typ) || getTypeSize(typ) != 32 {
+		t.Errorf("dynamic tuple array misreported as static")
+	}
+	static, err := NewType("tuple[3]", "", components[:2])
+	if err != nil {
+		t.Fatalf("failed to parse tuple: %v", err)
+	}
+	if isDynamicType(static) || getTypeSize(static) != 6*32 {
+		t.Errorf("static tuple array size mismatch: have %d, want %d", getTypeSize(static), 6*32)
+	}
+	for _, name := range []string{"", "_"} {
+		if _, err := NewType("tuple", "", []ArgumentMarshaling{{Name: name, Type: "uint256"}}); err == nil {
+			t.Errorf("anonymous tuple field %q accepted", name)
+		}
+	}
+}
@@ -168,7 +168,7 @@ var unpackTests = []unpackTest{
 	// multi dimensional, if these pass, all types that don't require length prefix should pass
 	{
 		def:  `[{"type": "uint8[][]"}]`,
-		enc:  "00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000E0000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002",
+		enc:  "00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002",
 		want: [][]uint8{{1, 2}, {1, 2}},
 	},
 	{
@@ -178,7 +178,7 @@ var unpackTests = []unpackTest{
 	},
 	{
 		def:  `[{"type": "uint8[][2]"}]`,
-		enc:  "000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000800000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001",
+		enc:  "0000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000800000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001",
 		want: [2][]uint8{{1}, {1}},
 	},
 	{
@@ -815,3 +815,62 @@ func TestOOMMaliciousInput(t *testing.T) {
 		}
 	}
 }
+
+func TestUnpackTuple(t *testing.T) {
+	abi, err := JSON(strings.NewReader(tupleABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Multiple arguments unpacked into a struct
+	packed, err := abi.Methods["dynamic"].Inputs.Pack(tupleDynamic{big.NewInt(1), "foo"}, big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dynamic struct {
+		S tupleDynamic
+		C *big.Int
+	}
+	if err := abi.Methods["dynamic"].Inputs.Unpack(&dynamic, packed); err != nil {
+		t.Fatalf("failed to unpack dynamic tuple: %v", err)
+	}
+	if dynamic.S.A.Cmp(big.NewInt(1)) != 0 || dynamic.S.B != "foo" || dynamic.C.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("dynamic tuple mismatch: %+v", dynamic)
+	}
+	// Static tuple arrays are inlined, the following argument must be found
+	packed, err = abi.Methods["static"].Inputs.Pack([2]tupleStatic{{big.NewInt(1), true}, {big.NewInt(2), false}}, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := abi.Methods["static"].Inputs.UnpackValues(packed)
+	if err != nil {
+		t.Fatalf("failed to unpack static tuples: %v", err)
+	}
+	if len(values) != 2 || values[1].(*big.Int).Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("static tuple trailing argument mismatch: %v", values)
+	}
+	// A single tuple argument unpacked directly into a user struct
+	want := []tupleDynamic{{big.NewInt(1), "foo"}, {big.NewInt(2), "bar"}}
+	packed, err = abi.Methods["slice"].Inputs.Pack(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slice []tupleDynamic
+	if err := abi.Methods["slice"].Inputs.Unpack(&slice, packed); err != nil {
+		t.Fatalf("failed to unpack tuple slice: %v", err)
+	}
+	if !reflect.DeepEqual(slice, want) {
+		t.Errorf("tuple slice mismatch: have %+v, want %+v", slice, want)
+	}
+	nested := tupleNested{big.NewInt(1), []tupleInner{{true, []byte("foo")}, {false, []byte("bar")}}}
+	packed, err = abi.Methods["nested"].Inputs.Pack(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unpacked tupleNested
+	if err := abi.Methods["nested"].Inputs.Unpack(&unpacked, packed); err != nil {
+		t.Fatalf("failed to unpack nested tuple: %v", err)
+	}
+	if !reflect.DeepEqual(unpacked, nested) {
+		t.Errorf("nested tuple mismatch: have %+v, want %+v", unpacked, nested)
+	}
+}
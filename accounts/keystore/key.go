@@ -153,7 +153,7 @@ func NewKeyForDirectICAP(rand io.Reader) *Key {
 		panic("key generation: ecdsa.GenerateKey failed: " + err.Error())
 	}
 	key := newKeyFromECDSA(privateKeyECDSA)
-	if !strings.HasPrefix(key.Address.Hex(), hexutil.CustomHexPrefix+"00") {
+	if !strings.HasPrefix(key.Address.Hex(), hexutil.HexPrefix()+"00") {
 		return NewKeyForDirectICAP(rand)
 	}
 	return key
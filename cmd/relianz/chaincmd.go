@@ -20,23 +20,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
 	"github.com/dsplinz2019/dsplinz/cmd/utils"
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/console"
 	"github.com/dsplinz2019/dsplinz/core"
+	"github.com/dsplinz2019/dsplinz/core/rawdb"
 	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/core/state/pruner"
 	"github.com/dsplinz2019/dsplinz/core/types"
 	"github.com/dsplinz2019/dsplinz/dsp/downloader"
 	"github.com/dsplinz2019/dsplinz/ethdb"
 	"github.com/dsplinz2019/dsplinz/event"
 	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/params"
 	"github.com/dsplinz2019/dsplinz/trie"
-	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -65,9 +69,11 @@ It expects the genesis file as argument.`,
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 			utils.GCModeFlag,
+			utils.GCRetainFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
 		},
@@ -86,6 +92,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -103,6 +110,7 @@ if already existing.`,
 		ArgsUsage: "<datafile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -117,6 +125,7 @@ if already existing.`,
 		ArgsUsage: "<dumpfile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -131,6 +140,9 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		ArgsUsage: "<sourceChaindataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
+			utils.DBRawCopyFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
@@ -139,7 +151,10 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
-The first argument must be the directory containing the blockchain to download from`,
+The first argument must be the directory containing the blockchain to download from.
+The source database is opened with whatever engine it was created with, while the
+local one uses --db.engine, so copydb can be used to migrate a chain between database
+engines. With --db.raw the source is copied entry by entry, retaining all of its data.`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -148,6 +163,7 @@ The first argument must be the directory containing the blockchain to download f
 		ArgsUsage: " ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
@@ -161,6 +177,7 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -169,6 +186,67 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "dsplinz dump 0" to dump the genesis block.`,
 	}
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Delete the state data not referenced by recent blocks",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.LightModeFlag,
+			utils.GCRetainFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command deletes all trie nodes and contract codes from the chain
+database that are not reachable from the state of the genesis block or of one of
+the most recent blocks (--gcmode.retain). Only states that were persisted to disk
+are retained, so older states have to be regenerated by re-executing blocks.
+
+The node must not be running while pruning.`,
+	}
+	freezerCommand = cli.Command{
+		Name:     "freezer",
+		Usage:    "Inspect the ancient block store",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Canonical blocks older than --ancient.depth are moved out of the chain database
+into an append-only ancient store (--datadir.ancient). These commands report on
+and verify the integrity of the ancient store.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "info",
+				Usage:     "Report the contents of the ancient store",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(freezerInfo),
+				Category:  "BLOCKCHAIN COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+				},
+				Description: `
+Prints the number of frozen blocks, along with the item count and the disk usage
+of every ancient table.`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Verify the integrity of the ancient store",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(freezerVerify),
+				Category:  "BLOCKCHAIN COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+				},
+				Description: `
+Checks that the ancient table indices are consistent with their data files, that
+the frozen blocks form a chain leading into the chain database, and that every
+frozen body and receipt list matches the roots in its header.`,
+			},
+		},
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -189,6 +267,12 @@ func initGenesis(ctx *cli.Context) error {
 	if err := json.NewDecoder(file).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	if genesis.Config != nil {
+		if err := genesis.Config.CheckConfigForkOrder(); err != nil {
+			utils.Fatalf("Invalid fork schedule: %v", err)
+		}
+		printForkSchedule(genesis.Config)
+	}
 	// Open an initialise both full and light databases
 	stack := makeFullNode(ctx)
 	for _, name := range []string{"chaindata", "lightchaindata"} {
@@ -205,6 +289,21 @@ func initGenesis(ctx *cli.Context) error {
 	return nil
 }
 
+// printForkSchedule writes the activation blocks of all protocol and consensus
+// engine forks of a chain configuration as a table to stdout.
+func printForkSchedule(config *params.ChainConfig) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "Fork\tBlock")
+	for _, fork := range config.ForkSchedule() {
+		block := "-"
+		if fork.Block != nil {
+			block = fork.Block.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\n", fork.Name, block)
+	}
+	w.Flush()
+}
+
 func importChain(ctx *cli.Context) error {
 	if len(ctx.Args()) < 1 {
 		utils.Fatalf("This command requires an argument.")
@@ -246,19 +345,7 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*ethdb.LDBDatabase)
-
-	stats, err := db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
-
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
-	}
-	fmt.Println(ioStats)
+	showDatabaseStats(chainDb)
 
 	fmt.Printf("Trie cache misses:  %d\n", trie.CacheMisses())
 	fmt.Printf("Trie cache unloads: %d\n\n", trie.CacheUnloads())
@@ -279,24 +366,27 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err := chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
+	showDatabaseStats(chainDb)
+	return nil
+}
+
+// showDatabaseStats prints the internal statistics of the database, along with
+// the io statistics if the database engine tracks them.
+func showDatabaseStats(db ethdb.Database) {
+	stats, err := db.Stat("")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
+	if ioStats, err := db.Stat("iostats"); err == nil {
+		fmt.Println(ioStats)
 	}
-	fmt.Println(ioStats)
-
-	return nil
 }
 
 func exportChain(ctx *cli.Context) error {
@@ -337,7 +427,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -353,7 +443,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -368,18 +458,42 @@ func copyDb(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		utils.Fatalf("Source chaindata directory path argument missing")
 	}
-	// Initialize a new chain for the running node to sync into
+	// Open the source database with the engine it was created with, along with
+	// its ancient store if it has one
+	source := ctx.Args().First()
+	db, err := ethdb.NewDatabase("", source, ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	if err != nil {
+		return err
+	}
+	if ancient := filepath.Join(source, "ancient"); common.FileExist(ancient) {
+		frdb, err := rawdb.NewDatabaseWithFreezer(db, ancient, "", 0)
+		if err != nil {
+			db.Close()
+			return err
+		}
+		db = frdb
+	}
+	defer db.Close()
+
 	stack := makeFullNode(ctx)
+	if ctx.GlobalBool(utils.DBRawCopyFlag.Name) {
+		chainDb := utils.MakeChainDatabase(ctx, stack)
+		defer chainDb.Close()
+
+		start := time.Now()
+		if err := utils.CopyDatabase(chainDb, db); err != nil {
+			utils.Fatalf("Database copy failed: %v", err)
+		}
+		fmt.Printf("Database copy done in %v\n", time.Since(start))
+		return nil
+	}
+	// Initialize a new chain for the running node to sync into
 	chain, chainDb := utils.MakeChain(ctx, stack)
 
 	syncmode := *utils.GlobalTextMarshaler(ctx, utils.SyncModeFlag.Name).(*downloader.SyncMode)
 	dl := downloader.New(syncmode, chainDb, new(event.TypeMux), chain, nil, nil)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := ethdb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
-	if err != nil {
-		return err
-	}
 	hc, err := core.NewHeaderChain(db, chain.Config(), chain.Engine(), func() bool { return false })
 	if err != nil {
 		return err
@@ -403,7 +517,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*ethdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -414,7 +528,11 @@ func copyDb(ctx *cli.Context) error {
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
-	for _, name := range []string{"chaindata", "lightchaindata"} {
+	names := []string{"chaindata", "lightchaindata"}
+	if ancient := ctx.GlobalString(utils.AncientFlag.Name); ancient != "" {
+		names = append(names, ancient)
+	}
+	for _, name := range names {
 		// Ensure the database exists in the first place
 		logger := log.New("database", name)
 
@@ -466,8 +584,121 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneState deletes the state data of the chain database which is not
+// referenced by the genesis block or by any of the recent blocks.
+func pruneState(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	// Gather the persisted states of the recent blocks to retain
+	retain := ctx.GlobalUint64(utils.GCRetainFlag.Name)
+	if retain == 0 {
+		utils.Fatalf("At least one recent state must be retained")
+	}
+	var (
+		head  = chain.CurrentBlock()
+		roots []common.Hash
+		seen  = make(map[common.Hash]bool)
+	)
+	for number := head.NumberU64(); ; number-- {
+		if block := chain.GetBlockByNumber(number); block != nil && !seen[block.Root()] {
+			if ok, _ := chainDb.Has(block.Root().Bytes()); ok {
+				roots = append(roots, block.Root())
+				seen[block.Root()] = true
+			}
+		}
+		if number == 0 || head.NumberU64()-number+1 >= retain {
+			break
+		}
+	}
+	if genesis := chain.Genesis(); !seen[genesis.Root()] {
+		if ok, _ := chainDb.Has(genesis.Root().Bytes()); ok {
+			roots = append(roots, genesis.Root())
+		}
+	}
+	if len(roots) == 0 {
+		utils.Fatalf("No recent state found on disk, refusing to prune")
+	}
+	log.Info("Pruning state database", "head", head.NumberU64(), "retained", len(roots))
+
+	start := time.Now()
+	stats, err := pruner.Prune(chainDb, roots)
+	if err != nil {
+		utils.Fatalf("Pruning failed: %v", err)
+	}
+	fmt.Printf("Pruning done in %v, deleted %d entries (%v).\n", time.Since(start), stats.Deleted, stats.Size)
+
+	// Compact the database to actually release the disk space
+	start = time.Now()
+	fmt.Println("Compacting entire database...")
+	if err := chainDb.Compact(nil, nil); err != nil {
+		utils.Fatalf("Compaction failed: %v", err)
+	}
+	fmt.Printf("Compaction done in %v.\n", time.Since(start))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
 	return err != nil
 }
+
+// openAncientDatabase opens the chain database along with its ancient store,
+// without moving any further blocks into the latter.
+func openAncientDatabase(ctx *cli.Context) ethdb.Database {
+	stack, _ := makeConfigNode(ctx)
+
+	db, err := stack.OpenDatabaseWithFreezer("chaindata", 0, 0, ctx.GlobalString(utils.AncientFlag.Name), 0, "")
+	if err != nil {
+		utils.Fatalf("Could not open database: %v", err)
+	}
+	return db
+}
+
+// freezerInfo reports the contents of the ancient store.
+func freezerInfo(ctx *cli.Context) error {
+	db := openAncientDatabase(ctx)
+	defer db.Close()
+
+	stats, err := rawdb.InspectAncients(db)
+	if err != nil {
+		utils.Fatalf("Failed to inspect ancient store: %v", err)
+	}
+	frozen, _ := db.(rawdb.AncientReader).Ancients()
+	fmt.Printf("Frozen blocks: %d\n", frozen)
+	if frozen > 0 {
+		fmt.Printf("Last frozen:   #%d [%x]\n", frozen-1, rawdb.ReadCanonicalHash(db, frozen-1))
+	}
+	if hash := rawdb.ReadHeadBlockHash(db); hash != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
+			fmt.Printf("Head block:    #%d [%x]\n", *number, hash)
+		}
+	}
+	fmt.Println()
+
+	var total common.StorageSize
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tITEMS\tSIZE\tCOMPRESSED")
+	for _, table := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%v\t%v\n", table.Name, table.Items, table.Size, table.Compressed)
+		total += table.Size
+	}
+	fmt.Fprintf(w, "total\t\t%v\t\n", total)
+	return w.Flush()
+}
+
+// freezerVerify checks the integrity of the ancient store.
+func freezerVerify(ctx *cli.Context) error {
+	db := openAncientDatabase(ctx)
+	defer db.Close()
+
+	start := time.Now()
+	if err := rawdb.VerifyAncients(db); err != nil {
+		utils.Fatalf("Ancient store verification failed: %v", err)
+	}
+	frozen, _ := db.(rawdb.AncientReader).Ancients()
+	fmt.Printf("Verified %d ancient blocks in %v.\n", frozen, time.Since(start))
+	return nil
+}
@@ -171,6 +171,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Serve GraphQL queries on the HTTP-RPC server if requested.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		utils.RegisterGraphQLService(stack)
+	}
 	// Add the Dsplinz Stats daemon if requested.
 	if cfg.Rlzstats.URL != "" {
 		utils.RegisterRlzStatsService(stack, cfg.Rlzstats.URL)
@@ -60,6 +60,9 @@ var (
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
+		utils.DBEngineFlag,
+		utils.AncientFlag,
+		utils.AncientDepthFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
 		utils.DashboardEnabledFlag,
@@ -72,9 +75,12 @@ var (
 		utils.RlzashDatasetDirFlag,
 		utils.RlzashDatasetsInMemoryFlag,
 		utils.RlzashDatasetsOnDiskFlag,
+		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolSnapshotFlag,
+		utils.TxPoolResnapshotFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -82,10 +88,15 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolHistoryFlag,
+		utils.TxPoolScheduledFlag,
+		utils.TxPoolPolicyFlag,
+		utils.TxPoolSenderCapFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.GCRetainFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
@@ -122,6 +133,7 @@ var (
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.ExtraDataFlag,
+		utils.SCAMainChainFlag,
 		configFileFlag,
 	}
 
@@ -135,6 +147,24 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GRPCEnabledFlag,
+		utils.GRPCListenAddrFlag,
+		utils.GRPCPortFlag,
+		utils.GRPCTLSCertFlag,
+		utils.GRPCTLSKeyFlag,
+		utils.GraphQLEnabledFlag,
+		utils.HexPrefixFlag,
+		utils.StrictHexPrefixFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
+		utils.RPCBatchLimitFlag,
+		utils.RPCResponseLimitFlag,
+		utils.RPCAuditLogFlag,
+		utils.RPCAuditMethodsFlag,
+		utils.RPCAuditExcludeFlag,
+		utils.RPCAuditPayloadsFlag,
+		utils.RPCAuditMaxSizeFlag,
+		utils.RPCAuditMaxFilesFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -161,6 +191,8 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		pruneStateCommand,
+		freezerCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -178,6 +210,8 @@ func init() {
 		licenseCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See replaycmd.go:
+		replayCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -0,0 +1,236 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of go-dsplinz.
+//
+// go-dsplinz is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-dsplinz is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-dsplinz. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dsplinz2019/dsplinz/cmd/utils"
+	"github.com/dsplinz2019/dsplinz/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	replayTargetFlag = cli.StringFlag{
+		Name:  "target",
+		Usage: "API endpoint of the node to replay the requests against (default = local IPC)",
+	}
+	replayMethodsFlag = cli.StringFlag{
+		Name:  "methods",
+		Usage: "Comma separated modules and methods to replay (default = all read-only ones)",
+	}
+	replayCommand = cli.Command{
+		Action:    utils.MigrateFlags(replay),
+		Name:      "rpc-replay",
+		Usage:     "Replay the read-only requests of an RPC audit log against a node",
+		ArgsUsage: "<auditlog> [<auditlog> ...]",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Flags: []cli.Flag{
+			replayTargetFlag,
+			replayMethodsFlag,
+		},
+		Description: `
+The rpc-replay command re-issues the read-only requests recorded in RPC audit logs
+(--rpc.auditlog) against the node at --target (the local node by default), and prints the differences between
+the recorded and the replayed responses. Only requests recorded with their
+parameters (--rpc.auditpayloads) can be replayed.
+
+Note, results depending on the chain head (e.g. the latest block) differ if the
+chain progressed in the meantime.`,
+	}
+)
+
+// replayPrefixes are the method name prefixes of the read-only requests, the ones
+// that are safe to replay.
+var replayPrefixes = []string{
+	"get", "call", "estimateGas", "blockNumber", "chainId", "gasPrice", "protocolVersion",
+	"syncing", "version", "listening", "peerCount", "clientVersion", "sha3",
+	"content", "inspect", "status", "scheduled",
+}
+
+// replayExcluded are the read-only methods depending on node local state, which
+// can't be replayed against a different node.
+var replayExcluded = map[string]bool{
+	"getFilterChanges": true,
+	"getFilterLogs":    true,
+	"getWork":          true,
+}
+
+// replayable reports whether a method is read-only, thus safe to replay.
+func replayable(method string) bool {
+	parts := strings.SplitN(method, "_", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	switch parts[0] {
+	case "dsp", "eth", "net", "web3", "txpool", "alien":
+	default:
+		return false
+	}
+	if replayExcluded[parts[1]] {
+		return false
+	}
+	for _, prefix := range replayPrefixes {
+		if strings.HasPrefix(parts[1], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// replay re-issues the requests recorded in the audit logs against a node and
+// reports the responses differing from the recorded ones.
+func replay(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("This command requires an audit log argument.")
+	}
+	client, err := dialRPC(ctx.String(replayTargetFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to node: %v", err)
+	}
+	defer client.Close()
+
+	var methods map[string]bool
+	if ctx.IsSet(replayMethodsFlag.Name) {
+		methods = make(map[string]bool)
+		for _, name := range strings.Split(ctx.String(replayMethodsFlag.Name), ",") {
+			methods[strings.TrimSpace(name)] = true
+		}
+	}
+	var replayed, skipped, differing int
+	for _, path := range ctx.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			utils.Fatalf("Failed to open audit log: %v", err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, 64*1024*1024)
+
+		for line := 1; scanner.Scan(); line++ {
+			record := new(rpc.AuditRecord)
+			if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+				utils.Fatalf("Invalid audit record at %s:%d: %v", path, line, err)
+			}
+			module := strings.SplitN(record.Method, "_", 2)[0]
+			if !replayable(record.Method) || (methods != nil && !methods[module] && !methods[record.Method]) {
+				continue
+			}
+			// Requests without parameters have the hash of an empty payload
+			if record.Params == nil && record.ParamsHash != rpc.AuditHash(nil) {
+				skipped++
+				continue
+			}
+			replayed++
+			if diff := replayRecord(client, record); diff != "" {
+				differing++
+				fmt.Printf("%s:%d %s (id %s, %s):\n%s\n", path, line, record.Method, record.ID, record.Time.Format("2006-01-02 15:04:05"), diff)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			utils.Fatalf("Failed to read audit log: %v", err)
+		}
+	}
+	fmt.Printf("Replayed %d requests, %d differing, %d skipped without recorded parameters\n", replayed, differing, skipped)
+	if differing > 0 {
+		return fmt.Errorf("%d of %d replayed responses differ", differing, replayed)
+	}
+	return nil
+}
+
+// replayRecord re-issues a recorded request, returning the difference between the
+// recorded and the replayed response, empty if they match.
+func replayRecord(client *rpc.Client, record *rpc.AuditRecord) string {
+	var params []json.RawMessage
+	if len(record.Params) > 0 {
+		if err := json.Unmarshal(record.Params, &params); err != nil {
+			return fmt.Sprintf("  invalid recorded parameters: %v", err)
+		}
+	}
+	args := make([]interface{}, len(params))
+	for i, param := range params {
+		args[i] = param
+	}
+	var result json.RawMessage
+	err := client.Call(&result, record.Method, args...)
+
+	// Compare the errors if either of the calls failed
+	if err != nil || record.Error != "" {
+		have := "<nil>"
+		if err != nil {
+			have = err.Error()
+		}
+		if have == record.Error {
+			return ""
+		}
+		want := record.Error
+		if want == "" {
+			want = "<nil>"
+		}
+		return fmt.Sprintf("- error: %s\n+ error: %s", want, have)
+	}
+	// Compare the results in full if recorded, by their hash otherwise
+	if record.Result == nil {
+		compact := new(bytes.Buffer)
+		json.Compact(compact, result)
+		if rpc.AuditHash(compact.Bytes()) == record.ResultHash {
+			return ""
+		}
+		return fmt.Sprintf("- result hash: %s\n+ result hash: %s", record.ResultHash, rpc.AuditHash(compact.Bytes()))
+	}
+	return diffJSON(record.Result, result)
+}
+
+// diffJSON returns the differing lines of two JSON values, empty if they match.
+// The values are normalized first, so formatting and key order don't matter.
+func diffJSON(want, have json.RawMessage) string {
+	normalize := func(blob []byte) []string {
+		var value interface{}
+		if err := json.Unmarshal(blob, &value); err != nil {
+			return []string{string(blob)}
+		}
+		blob, _ = json.MarshalIndent(value, "", "  ")
+		return strings.Split(string(blob), "\n")
+	}
+	a, b := normalize(want), normalize(have)
+
+	// Trim the common prefix and suffix, leaving the differing hunk
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		start++
+	}
+	if start == len(a) && start == len(b) {
+		return ""
+	}
+	end := 0
+	for end < len(a)-start && end < len(b)-start && a[len(a)-1-end] == b[len(b)-1-end] {
+		end++
+	}
+	var diff []string
+	for _, line := range a[start : len(a)-end] {
+		diff = append(diff, "- "+line)
+	}
+	for _, line := range b[start : len(b)-end] {
+		diff = append(diff, "+ "+line)
+	}
+	return strings.Join(diff, "\n")
+}
@@ -65,6 +65,9 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			configFileFlag,
 			utils.DataDirFlag,
+			utils.DBEngineFlag,
+			utils.AncientFlag,
+			utils.AncientDepthFlag,
 			utils.KeyStoreDirFlag,
 			//	utils.NoUSBFlag,
 			utils.NetworkIdFlag,
@@ -72,6 +75,7 @@ var AppHelpFlagGroups = []flagGroup{
 			//utils.RinkebyFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.GCRetainFlag,
 			utils.RlzStatsURLFlag,
 			utils.IdentityFlag,
 			//utils.LightServFlag,
@@ -109,9 +113,12 @@ var AppHelpFlagGroups = []flagGroup{
 	{
 		Name: "TRANSACTION POOL",
 		Flags: []cli.Flag{
+			utils.TxPoolLocalsFlag,
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolSnapshotFlag,
+			utils.TxPoolResnapshotFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
@@ -119,6 +126,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolHistoryFlag,
+			utils.TxPoolScheduledFlag,
+			utils.TxPoolPolicyFlag,
+			utils.TxPoolSenderCapFlag,
 		},
 	},
 	{
@@ -149,6 +160,24 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.GRPCEnabledFlag,
+			utils.GRPCListenAddrFlag,
+			utils.GRPCPortFlag,
+			utils.GRPCTLSCertFlag,
+			utils.GRPCTLSKeyFlag,
+			utils.GraphQLEnabledFlag,
+			utils.HexPrefixFlag,
+			utils.StrictHexPrefixFlag,
+			utils.RPCRateLimitFlag,
+			utils.RPCRateBurstFlag,
+			utils.RPCBatchLimitFlag,
+			utils.RPCResponseLimitFlag,
+			utils.RPCAuditLogFlag,
+			utils.RPCAuditMethodsFlag,
+			utils.RPCAuditExcludeFlag,
+			utils.RPCAuditPayloadsFlag,
+			utils.RPCAuditMaxSizeFlag,
+			utils.RPCAuditMaxFilesFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -184,6 +213,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TargetGasLimitFlag,
 			utils.GasPriceFlag,
 			utils.ExtraDataFlag,
+			utils.SCAMainChainFlag,
 		},
 	},
 	{
@@ -26,6 +26,7 @@ import (
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/core"
@@ -238,7 +239,7 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 }
 
 // ImportPreimages imports a batch of exported hash preimages into the database.
-func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ImportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Importing preimages", "file", fn)
 
 	// Open the file handle and potentially unwrap the gzip stream
@@ -285,7 +286,7 @@ func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
 
 // ExportPreimages exports all known hash preimages into the specified file,
 // truncating any data already present in the file.
-func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ExportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Exporting preimages", "file", fn)
 
 	// Open the file handle and potentially wrap with a gzip stream
@@ -310,3 +311,52 @@ func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
 	log.Info("Exported preimages", "file", fn)
 	return nil
 }
+
+// CopyDatabase copies every entry of the source database into the destination
+// one, including the frozen blocks of its ancient store. The two databases may
+// use different engines.
+func CopyDatabase(dst, src ethdb.Database) error {
+	// Copy the ancients first, before the destination has a chain head that its
+	// own freezer could start moving blocks from
+	if err := rawdb.CopyAncients(dst, src); err != nil {
+		return err
+	}
+	log.Info("Copying database")
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		count  int
+		size   common.StorageSize
+		batch  = dst.NewBatch()
+		it     = src.NewIteratorWithPrefix(nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if err := batch.Put(common.CopyBytes(it.Key()), common.CopyBytes(it.Value())); err != nil {
+			return err
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying database", "entries", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Copied database", "entries", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
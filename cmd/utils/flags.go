@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -32,6 +33,7 @@ import (
 	"github.com/dsplinz2019/dsplinz/accounts/keystore"
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/common/fdlimit"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
 	"github.com/dsplinz2019/dsplinz/consensus"
 	"github.com/dsplinz2019/dsplinz/consensus/alien"
 	"github.com/dsplinz2019/dsplinz/consensus/clique"
@@ -46,6 +48,7 @@ import (
 	"github.com/dsplinz2019/dsplinz/dsp/gasprice"
 	"github.com/dsplinz2019/dsplinz/ethdb"
 	"github.com/dsplinz2019/dsplinz/ethstats"
+	"github.com/dsplinz2019/dsplinz/graphql"
 	"github.com/dsplinz2019/dsplinz/les"
 	"github.com/dsplinz2019/dsplinz/log"
 	"github.com/dsplinz2019/dsplinz/metrics"
@@ -56,6 +59,7 @@ import (
 	"github.com/dsplinz2019/dsplinz/p2p/nat"
 	"github.com/dsplinz2019/dsplinz/p2p/netutil"
 	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rpc"
 	whisper "github.com/dsplinz2019/dsplinz/whisper/whisperv6"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -119,6 +123,19 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: `Database engine for new databases ("leveldb", "bolt"), existing ones keep their own`,
+	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
+	AncientDepthFlag = cli.Uint64Flag{
+		Name:  "ancient.depth",
+		Usage: "Number of recent blocks kept in the database before moving them to the ancient store (0 = never move)",
+		Value: dsp.DefaultConfig.DatabaseFreezerDepth,
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -176,6 +193,11 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	GCRetainFlag = cli.Uint64Flag{
+		Name:  "gcmode.retain",
+		Usage: "Number of recent block states kept by the full garbage collection mode",
+		Value: 128,
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -241,6 +263,10 @@ var (
 		Value: dsp.DefaultConfig.Rlzash.DatasetsOnDisk,
 	}
 	// Transaction pool settings
+	TxPoolLocalsFlag = cli.StringFlag{
+		Name:  "txpool.locals",
+		Usage: "Comma separated accounts to treat as locals (no price limit, no flush)",
+	}
 	TxPoolNoLocalsFlag = cli.BoolFlag{
 		Name:  "txpool.nolocals",
 		Usage: "Disables price exemptions for locally submitted transactions",
@@ -255,6 +281,15 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolSnapshotFlag = cli.StringFlag{
+		Name:  "txpool.snapshot",
+		Usage: "Disk snapshot of all pooled transactions to survive node restarts (empty = disabled)",
+	}
+	TxPoolResnapshotFlag = cli.DurationFlag{
+		Name:  "txpool.resnapshot",
+		Usage: "Time interval to regenerate the transaction pool snapshot",
+		Value: core.DefaultTxPoolConfig.Resnapshot,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -290,6 +325,26 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: dsp.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolHistoryFlag = cli.Uint64Flag{
+		Name:  "txpool.history",
+		Usage: "Number of recent transaction status transitions to retain",
+		Value: dsp.DefaultConfig.TxPool.History,
+	}
+	TxPoolScheduledFlag = cli.Uint64Flag{
+		Name:  "txpool.scheduled",
+		Usage: "Maximum number of scheduled transactions waiting for their schedule",
+		Value: dsp.DefaultConfig.TxPool.Scheduled,
+	}
+	TxPoolPolicyFlag = cli.StringFlag{
+		Name:  "txpool.policy",
+		Usage: `Ordering and eviction policy of equally priced transactions ("price" or "fifo")`,
+		Value: dsp.DefaultConfig.TxPool.Policy,
+	}
+	TxPoolSenderCapFlag = cli.Uint64Flag{
+		Name:  "txpool.sendercap",
+		Usage: "Maximum number of transactions included per sender and block (0 = unlimited)",
+		Value: dsp.DefaultConfig.TxPool.SenderCap,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -373,6 +428,10 @@ var (
 		Name:  "nocompaction",
 		Usage: "Disables db compaction after import",
 	}
+	DBRawCopyFlag = cli.BoolFlag{
+		Name:  "db.raw",
+		Usage: "Copy the source database entry by entry instead of syncing from it (copydb)",
+	}
 	// RPC settings
 	RPCEnabledFlag = cli.BoolFlag{
 		Name:  "rpc",
@@ -435,6 +494,84 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GRPCEnabledFlag = cli.BoolFlag{
+		Name:  "grpc",
+		Usage: "Enable the gRPC server serving the chain data as protocol buffers",
+	}
+	GRPCListenAddrFlag = cli.StringFlag{
+		Name:  "grpcaddr",
+		Usage: "gRPC server listening interface",
+		Value: node.DefaultGRPCHost,
+	}
+	GRPCPortFlag = cli.IntFlag{
+		Name:  "grpcport",
+		Usage: "gRPC server listening port",
+		Value: node.DefaultGRPCPort,
+	}
+	GRPCTLSCertFlag = cli.StringFlag{
+		Name:  "grpccert",
+		Usage: "TLS certificate file of the gRPC server",
+	}
+	GRPCTLSKeyFlag = cli.StringFlag{
+		Name:  "grpckey",
+		Usage: "TLS key file of the gRPC server",
+	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable GraphQL queries of the chain data on the HTTP-RPC server (at /graphql)",
+	}
+	HexPrefixFlag = cli.StringFlag{
+		Name:  "hexprefix",
+		Usage: `Prefix addresses and hashes are displayed with ("0x" or "t0")`,
+		Value: hexutil.DefaultHexPrefix,
+	}
+	StrictHexPrefixFlag = cli.BoolFlag{
+		Name:  "hexprefix.strict",
+		Usage: "Reject hex input with the ambiguous t1..t9 prefixes",
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpc.ratelimit",
+		Usage: "Request cost each HTTP/WS-RPC client is granted per second (0 = unlimited)",
+	}
+	RPCRateBurstFlag = cli.Uint64Flag{
+		Name:  "rpc.rateburst",
+		Usage: "Request cost each HTTP/WS-RPC client may spend at once",
+		Value: 100,
+	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpc.batchlimit",
+		Usage: "Maximum number of requests in an HTTP/WS-RPC batch (0 = unlimited)",
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpc.responselimit",
+		Usage: "Maximum size of an HTTP/WS-RPC response in bytes (0 = unlimited)",
+	}
+	RPCAuditLogFlag = cli.StringFlag{
+		Name:  "rpc.auditlog",
+		Usage: "File to record the IPC/HTTP/WS-RPC requests served into (relative paths are within the datadir)",
+	}
+	RPCAuditMethodsFlag = cli.StringFlag{
+		Name:  "rpc.auditmethods",
+		Usage: "Comma separated modules and methods to record in the RPC audit log (default = all)",
+	}
+	RPCAuditExcludeFlag = cli.StringFlag{
+		Name:  "rpc.auditexclude",
+		Usage: "Comma separated modules and methods never recorded in the RPC audit log",
+	}
+	RPCAuditPayloadsFlag = cli.BoolFlag{
+		Name:  "rpc.auditpayloads",
+		Usage: "Record request parameters and results in the RPC audit log, not just their hashes (needed by rpc-replay)",
+	}
+	RPCAuditMaxSizeFlag = cli.IntFlag{
+		Name:  "rpc.auditmaxsize",
+		Usage: "Size in megabytes above which the RPC audit log is rotated (0 = never)",
+		Value: 100,
+	}
+	RPCAuditMaxFilesFlag = cli.IntFlag{
+		Name:  "rpc.auditmaxfiles",
+		Usage: "Number of rotated RPC audit logs to keep",
+		Value: 10,
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -537,7 +674,7 @@ var (
 	// PBFT settings
 	PBFTEnableFlag = cli.BoolFlag{
 		Name:  "pbft",
-		Usage: "PBFT miner coinbase send confirm transaction",
+		Usage: "Sign and gossip PBFT confirmations of accepted blocks",
 	}
 
 	// Data side chain settings
@@ -560,6 +697,10 @@ var (
 		Usage: "Period of each side chain block",
 		Value: 1,
 	}
+	SCAMainChainFlag = cli.StringFlag{
+		Name:  "sca.mainchain",
+		Usage: "RPC endpoint of the main chain a side chain anchors its blocks to (with the alien API enabled)",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -744,6 +885,96 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGRPC creates the gRPC listener interface string from the set command line
+// flags, returning empty if the gRPC endpoint is disabled.
+func setGRPC(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GRPCEnabledFlag.Name) && cfg.GRPCHost == "" {
+		cfg.GRPCHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GRPCListenAddrFlag.Name) {
+			cfg.GRPCHost = ctx.GlobalString(GRPCListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GRPCPortFlag.Name) {
+		cfg.GRPCPort = ctx.GlobalInt(GRPCPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GRPCTLSCertFlag.Name) {
+		cfg.GRPCTLSCert = ctx.GlobalString(GRPCTLSCertFlag.Name)
+	}
+	if ctx.GlobalIsSet(GRPCTLSKeyFlag.Name) {
+		cfg.GRPCTLSKey = ctx.GlobalString(GRPCTLSKeyFlag.Name)
+	}
+}
+
+// setHexPrefix configures how addresses and hashes are displayed and parsed
+// from the set command line flags. Strict parsing applies to the whole process,
+// the display prefix only to the RPC servers of the node.
+func setHexPrefix(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(HexPrefixFlag.Name) {
+		prefix := ctx.GlobalString(HexPrefixFlag.Name)
+		if !hexutil.IsValidHexPrefix(prefix) {
+			Fatalf("Invalid --%s value %q, want \"0x\" or %q", HexPrefixFlag.Name, prefix, hexutil.DefaultHexPrefix)
+		}
+		cfg.HexPrefix = prefix
+	}
+	if ctx.GlobalIsSet(StrictHexPrefixFlag.Name) {
+		hexutil.SetStrictPrefix(ctx.GlobalBool(StrictHexPrefixFlag.Name))
+	}
+}
+
+// setRPCLimits configures the request limits of the HTTP and WebSocket RPC
+// endpoints from the set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if !ctx.GlobalIsSet(RPCRateLimitFlag.Name) && !ctx.GlobalIsSet(RPCRateBurstFlag.Name) &&
+		!ctx.GlobalIsSet(RPCBatchLimitFlag.Name) && !ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		return
+	}
+	if cfg.RPCLimits == nil {
+		cfg.RPCLimits = &rpc.LimitConfig{Burst: RPCRateBurstFlag.Value}
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCLimits.Rate = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateBurstFlag.Name) {
+		cfg.RPCLimits.Burst = ctx.GlobalUint64(RPCRateBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCLimits.BatchLimit = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCLimits.ResponseLimit = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+}
+
+// setRPCAudit configures the audit log of the RPC endpoints from the set command
+// line flags.
+func setRPCAudit(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCAuditLogFlag.Name) {
+		cfg.RPCAudit = &rpc.AuditConfig{
+			Path:     ctx.GlobalString(RPCAuditLogFlag.Name),
+			MaxSize:  int64(RPCAuditMaxSizeFlag.Value) * 1024 * 1024,
+			MaxFiles: RPCAuditMaxFilesFlag.Value,
+		}
+	}
+	if cfg.RPCAudit == nil {
+		return
+	}
+	if ctx.GlobalIsSet(RPCAuditMethodsFlag.Name) {
+		cfg.RPCAudit.Methods = splitAndTrim(ctx.GlobalString(RPCAuditMethodsFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCAuditExcludeFlag.Name) {
+		cfg.RPCAudit.Exclude = splitAndTrim(ctx.GlobalString(RPCAuditExcludeFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCAuditPayloadsFlag.Name) {
+		cfg.RPCAudit.Payloads = ctx.GlobalBool(RPCAuditPayloadsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCAuditMaxSizeFlag.Name) {
+		cfg.RPCAudit.MaxSize = int64(ctx.GlobalInt(RPCAuditMaxSizeFlag.Name)) * 1024 * 1024
+	}
+	if ctx.GlobalIsSet(RPCAuditMaxFilesFlag.Name) {
+		cfg.RPCAudit.MaxFiles = ctx.GlobalInt(RPCAuditMaxFilesFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -902,6 +1133,10 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGRPC(ctx, cfg)
+	setHexPrefix(ctx, cfg)
+	setRPCLimits(ctx, cfg)
+	setRPCAudit(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -915,6 +1150,12 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "rinkeby")
 	}
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		cfg.DBEngine = ctx.GlobalString(DBEngineFlag.Name)
+		if !validDBEngine(cfg.DBEngine) {
+			Fatalf("--%s must be one of %s", DBEngineFlag.Name, strings.Join(ethdb.Engines, ", "))
+		}
+	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -926,6 +1167,16 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// validDBEngine reports whether the named database engine is supported.
+func validDBEngine(engine string) bool {
+	for _, e := range ethdb.Engines {
+		if e == engine {
+			return true
+		}
+	}
+	return false
+}
+
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.GlobalIsSet(GpoBlocksFlag.Name) {
 		cfg.Blocks = ctx.GlobalInt(GpoBlocksFlag.Name)
@@ -936,6 +1187,16 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 }
 
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
+	if ctx.GlobalIsSet(TxPoolLocalsFlag.Name) {
+		locals := strings.Split(ctx.GlobalString(TxPoolLocalsFlag.Name), ",")
+		for _, account := range locals {
+			account = strings.TrimSpace(account)
+			if !common.IsHexAddress(account) {
+				Fatalf("Invalid account in --txpool.locals: %s", account)
+			}
+			cfg.Locals = append(cfg.Locals, common.HexToAddress(account))
+		}
+	}
 	if ctx.GlobalIsSet(TxPoolNoLocalsFlag.Name) {
 		cfg.NoLocals = ctx.GlobalBool(TxPoolNoLocalsFlag.Name)
 	}
@@ -945,6 +1206,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolSnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalString(TxPoolSnapshotFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolResnapshotFlag.Name) {
+		cfg.Resnapshot = ctx.GlobalDuration(TxPoolResnapshotFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -966,6 +1233,18 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolHistoryFlag.Name) {
+		cfg.History = ctx.GlobalUint64(TxPoolHistoryFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolScheduledFlag.Name) {
+		cfg.Scheduled = ctx.GlobalUint64(TxPoolScheduledFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolPolicyFlag.Name) {
+		cfg.Policy = ctx.GlobalString(TxPoolPolicyFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolSenderCapFlag.Name) {
+		cfg.SenderCap = ctx.GlobalUint64(TxPoolSenderCapFlag.Name)
+	}
 }
 
 func setRlzash(ctx *cli.Context, cfg *dsp.Config) {
@@ -1076,11 +1355,20 @@ func SetRlzConfig(ctx *cli.Context, stack *node.Node, cfg *dsp.Config) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
+	if ctx.GlobalIsSet(AncientDepthFlag.Name) {
+		cfg.DatabaseFreezerDepth = ctx.GlobalUint64(AncientDepthFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	if ctx.GlobalIsSet(GCRetainFlag.Name) {
+		cfg.TrieRetention = ctx.GlobalUint64(GCRetainFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
@@ -1101,6 +1389,9 @@ func SetRlzConfig(ctx *cli.Context, stack *node.Node, cfg *dsp.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(SCAMainChainFlag.Name) {
+		cfg.MainChainEndpoint = ctx.GlobalString(SCAMainChainFlag.Name)
+	}
 
 	// Override any default configs for hard coded networks.
 	switch {
@@ -1207,23 +1498,48 @@ func RegisterRlzStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds the GraphQL endpoint of the Dsplinz client to the
+// HTTP-RPC server of the given node.
+func RegisterGraphQLService(stack *node.Node) {
+	if stack.HTTPEndpoint() == "" {
+		log.Warn("GraphQL enabled without the HTTP-RPC server, queries won't be served")
+	}
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Serve the queries with the backend of either the full or the light client
+		var ethServ *dsp.Rlzereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.APIBackend)
+		}
+		var lesServ *les.LightDsplinz
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend)
+		}
+		return nil, errors.New("no Dsplinz service to serve GraphQL queries of")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
 	params.TargetGasLimit = ctx.GlobalUint64(TargetGasLimitFlag.Name)
 }
 
-// MakeChainDatabase open an LevelDB using the flags passed to the client and will hard crash if it fails.
+// MakeChainDatabase open the chain database using the flags passed to the client and will hard crash if it fails.
 func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	var (
 		cache   = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 		handles = makeDatabaseHandles()
+
+		chainDb ethdb.Database
+		err     error
 	)
-	name := "chaindata"
 	if ctx.GlobalBool(LightModeFlag.Name) {
-		name = "lightchaindata"
+		chainDb, err = stack.OpenDatabase("lightchaindata", cache, handles)
+	} else {
+		chainDb, err = stack.OpenDatabaseWithFreezer("chaindata", cache, handles, ctx.GlobalString(AncientFlag.Name), ctx.GlobalUint64(AncientDepthFlag.Name), "")
 	}
-	chainDb, err := stack.OpenDatabase(name, cache, handles)
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
@@ -1278,6 +1594,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		Disabled:      ctx.GlobalString(GCModeFlag.Name) == "archive",
 		TrieNodeLimit: dsp.DefaultConfig.TrieCache,
 		TrieTimeLimit: dsp.DefaultConfig.TrieTimeout,
+		TriesInMemory: ctx.GlobalUint64(GCRetainFlag.Name),
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieNodeLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
@@ -14,13 +14,25 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the dsp library. If not, see <http://www.gnu.org/licenses/>.
 
-// PM want to change the prefix of hex from 0x to anything they want, @#$%@#$%@#%^ ;-)
-// Just set the CustomHashPrefix to "0x" , everything will back to normal.
+// Addresses and hashes are displayed with a configurable prefix instead of the
+// usual "0x". Call SetHexPrefix("0x") and everything will back to normal.
 
 package hexutil
 
-const CustomHexPrefix = "t0"
+import (
+	"fmt"
+	"sync/atomic"
+)
 
+// DefaultHexPrefix is the prefix addresses and hashes are displayed with unless
+// configured otherwise.
+const DefaultHexPrefix = "t0"
+
+// ErrAmbiguousPrefix is returned in strict mode for hex strings starting with
+// one of the legacy t1..t9 or T1..T9 prefixes.
+var ErrAmbiguousPrefix = &decError{"hex string with ambiguous prefix"}
+
+// PossibleCustomHexPrefixMap holds all the prefixes accepted on input.
 var PossibleCustomHexPrefixMap = map[string]bool{
 	"0x": true,
 	"0X": true,
@@ -46,20 +58,98 @@ var PossibleCustomHexPrefixMap = map[string]bool{
 	"T9": true,
 }
 
-func CPToHex(s string) string {
-	if len(s) > len(CustomHexPrefix) {
-		if _, ok := PossibleCustomHexPrefixMap[s[:2]]; ok {
-			return "0x" + s[2:]
-		}
+// strictHexPrefixMap holds the prefixes accepted on input in strict mode.
+var strictHexPrefixMap = map[string]bool{
+	"0x": true,
+	"0X": true,
+	"t0": true,
+	"T0": true,
+}
+
+var (
+	hexPrefix    atomic.Value // Display prefix of addresses and hashes
+	strictPrefix int32        // Whether to reject the ambiguous prefixes (1) or not (0)
+)
+
+func init() {
+	hexPrefix.Store(DefaultHexPrefix)
+}
+
+// HexPrefix returns the prefix addresses and hashes are currently displayed with.
+func HexPrefix() string {
+	return hexPrefix.Load().(string)
+}
+
+// SetHexPrefix sets the prefix addresses and hashes are displayed with. Only
+// "0x" and "t0" are allowed.
+func SetHexPrefix(prefix string) error {
+	if !IsValidHexPrefix(prefix) {
+		return fmt.Errorf("invalid hex prefix %q, want \"0x\" or %q", prefix, DefaultHexPrefix)
 	}
-	return s
+	hexPrefix.Store(prefix)
+	return nil
+}
+
+// IsValidHexPrefix reports whether prefix can be used as display prefix.
+func IsValidHexPrefix(prefix string) bool {
+	return prefix == "0x" || prefix == DefaultHexPrefix
 }
 
+// StrictPrefix reports whether the ambiguous t1..T9 prefixes are rejected.
+func StrictPrefix() bool {
+	return atomic.LoadInt32(&strictPrefix) == 1
+}
+
+// SetStrictPrefix enables or disables rejecting the ambiguous t1..T9 prefixes.
+func SetStrictPrefix(strict bool) {
+	if strict {
+		atomic.StoreInt32(&strictPrefix, 1)
+	} else {
+		atomic.StoreInt32(&strictPrefix, 0)
+	}
+}
+
+// HasCustomPrefix reports whether s starts with a prefix accepted on input. In
+// strict mode the ambiguous t1..T9 prefixes are not accepted.
+func HasCustomPrefix(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	if StrictPrefix() {
+		return strictHexPrefixMap[s[:2]]
+	}
+	return PossibleCustomHexPrefixMap[s[:2]]
+}
+
+// CheckPrefix returns ErrAmbiguousPrefix if s starts with a prefix that is not
+// accepted in strict mode.
+func CheckPrefix(s string) error {
+	if len(s) >= 2 && StrictPrefix() && PossibleCustomHexPrefixMap[s[:2]] && !strictHexPrefixMap[s[:2]] {
+		return ErrAmbiguousPrefix
+	}
+	return nil
+}
+
+// CPToHex replaces an accepted prefix of s with "0x".
+func CPToHex(s string) string {
+	return ToPrefix(s, "0x")
+}
+
+// HexToCP replaces the "0x" prefix of s with the configured display prefix.
 func HexToCP(s string) string {
 	if len(s) > 2 {
 		if s[:2] == "0x" || s[:2] == "0X" {
-			return CustomHexPrefix + s[2:]
+			return HexPrefix() + s[2:]
 		}
 	}
 	return s
 }
+
+// ToPrefix replaces an accepted prefix of s with the given one. Strings without
+// an accepted prefix are returned unchanged.
+func ToPrefix(s string, prefix string) string {
+	if len(s) > 2 && HasCustomPrefix(s) {
+		return prefix + s[2:]
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+// withPrefix runs fn with the given display prefix and strict mode, restoring
+// the defaults afterwards.
+func withPrefix(t *testing.T, prefix string, strict bool, fn func()) {
+	if err := SetHexPrefix(prefix); err != nil {
+		t.Fatalf("failed to set prefix %q: %v", prefix, err)
+	}
+	SetStrictPrefix(strict)
+	defer func() {
+		SetHexPrefix(DefaultHexPrefix)
+		SetStrictPrefix(false)
+	}()
+	fn()
+}
+
+func TestSetHexPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "0X", "t1", "T0", "0x0"} {
+		if err := SetHexPrefix(prefix); err == nil {
+			t.Errorf("prefix %q: expected error", prefix)
+		}
+	}
+	if prefix := HexPrefix(); prefix != DefaultHexPrefix {
+		t.Errorf("prefix mismatch after invalid updates: have %q, want %q", prefix, DefaultHexPrefix)
+	}
+}
+
+func TestHexPrefixRoundTrip(t *testing.T) {
+	blob := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+
+	for _, prefix := range []string{"0x", "t0"} {
+		withPrefix(t, prefix, false, func() {
+			enc := HexToCP(Encode(blob))
+			if want := prefix + "0123456789abcdef"; enc != want {
+				t.Fatalf("prefix %q: encoding mismatch: have %s, want %s", prefix, enc, want)
+			}
+			dec, err := Decode(CPToHex(enc))
+			if err != nil {
+				t.Fatalf("prefix %q: failed to decode %s: %v", prefix, enc, err)
+			}
+			if !bytes.Equal(dec, blob) {
+				t.Fatalf("prefix %q: decoding mismatch: have %x, want %x", prefix, dec, blob)
+			}
+			var b Bytes
+			if err := b.UnmarshalJSON([]byte(`"` + enc + `"`)); err != nil {
+				t.Fatalf("prefix %q: failed to unmarshal %s: %v", prefix, enc, err)
+			}
+			if !bytes.Equal(b, blob) {
+				t.Fatalf("prefix %q: unmarshal mismatch: have %x, want %x", prefix, []byte(b), blob)
+			}
+		})
+	}
+}
+
+func TestStrictPrefix(t *testing.T) {
+	tests := []struct {
+		input  string
+		lax    error
+		strict error
+	}{
+		{input: "0x0102"},
+		{input: "0X0102"},
+		{input: "t00102"},
+		{input: "T00102"},
+		{input: "t10102", strict: ErrAmbiguousPrefix},
+		{input: "T90102", strict: ErrAmbiguousPrefix},
+		{input: "0102", lax: ErrMissingPrefix, strict: ErrMissingPrefix},
+	}
+	for _, test := range tests {
+		var b Bytes
+		if err := b.UnmarshalText([]byte(test.input)); err != test.lax {
+			t.Errorf("input %s: lax error mismatch: have %v, want %v", test.input, err, test.lax)
+		}
+		withPrefix(t, DefaultHexPrefix, true, func() {
+			if err := b.UnmarshalText([]byte(test.input)); err != test.strict {
+				t.Errorf("input %s: strict error mismatch: have %v, want %v", test.input, err, test.strict)
+			}
+		})
+	}
+	withPrefix(t, DefaultHexPrefix, true, func() {
+		if s := CPToHex("t10102"); s != "t10102" {
+			t.Errorf("ambiguous prefix converted in strict mode: %s", s)
+		}
+	})
+}
@@ -72,6 +72,17 @@ func (b Bytes) String() string {
 	return Encode(b)
 }
 
+// ImplementsGraphQLType returns true if Bytes implements the specified GraphQL type.
+func (b Bytes) ImplementsGraphQLType(name string) bool { return name == "Bytes" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Bytes) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return b.UnmarshalText([]byte(input))
+	}
+	return fmt.Errorf("unexpected type %T for Bytes", input)
+}
+
 // UnmarshalFixedJSON decodes the input as a string with 0x prefix. The length of out
 // determines the required input length. This function is commonly used to implement the
 // UnmarshalJSON method for fixed-size types.
@@ -187,6 +198,31 @@ func (b *Big) String() string {
 	return EncodeBig(b.ToInt())
 }
 
+// ImplementsGraphQLType returns true if Big implements the specified GraphQL type.
+func (b Big) ImplementsGraphQLType(name string) bool { return name == "BigInt" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data, either a hex string
+// or a non-negative integer.
+func (b *Big) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	case int32:
+		if input >= 0 {
+			*b = Big(*new(big.Int).SetInt64(int64(input)))
+			return nil
+		}
+	case float64:
+		if input >= 0 && input == float64(int64(input)) {
+			*b = Big(*new(big.Int).SetInt64(int64(input)))
+			return nil
+		}
+	default:
+		return fmt.Errorf("unexpected type %T for BigInt", input)
+	}
+	return fmt.Errorf("invalid BigInt %v", input)
+}
+
 // Uint64 marshals/unmarshals as a JSON string with 0x prefix.
 // The zero value marshals as "0x0".
 type Uint64 uint64
@@ -234,6 +270,31 @@ func (b Uint64) String() string {
 	return EncodeUint64(uint64(b))
 }
 
+// ImplementsGraphQLType returns true if Uint64 implements the specified GraphQL type.
+func (b Uint64) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data, either a hex string
+// or a non-negative integer.
+func (b *Uint64) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	case int32:
+		if input >= 0 {
+			*b = Uint64(input)
+			return nil
+		}
+	case float64:
+		if input >= 0 && input < 1<<64 && input == float64(uint64(input)) {
+			*b = Uint64(input)
+			return nil
+		}
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return fmt.Errorf("invalid Long %v", input)
+}
+
 // Uint marshals/unmarshals as a JSON string with 0x prefix.
 // The zero value marshals as "0x0".
 type Uint uint
@@ -274,22 +335,16 @@ func isString(input []byte) bool {
 }
 
 func bytesHave0xPrefix(input []byte) bool {
-
-	//return len(input) >= 2 && input[0] == '0' && (input[1] == 'x' || input[1] == 'X')
-
-	if len(input) >= 2 {
-		if _, ok := PossibleCustomHexPrefixMap[string(input[0:2])]; ok {
-			return true
-		}
-	}
-	return false
-
+	return HasCustomPrefix(string(input))
 }
 
 func checkText(input []byte, wantPrefix bool) ([]byte, error) {
 	if len(input) == 0 {
 		return nil, nil // empty strings are allowed
 	}
+	if err := CheckPrefix(string(input)); err != nil {
+		return nil, err
+	}
 	if bytesHave0xPrefix(input) {
 		input = input[2:]
 	} else if wantPrefix {
@@ -19,6 +19,7 @@ package common
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -98,11 +99,9 @@ func (h Hash) MarshalText() ([]byte, error) {
 	return []byte(s), nil
 }
 
+// MarshalJSON marshals the hash with the configured display prefix.
 func (h *Hash) MarshalJSON() ([]byte, error) {
-	if strings.HasPrefix(h.String(), "0x") || strings.HasPrefix(h.String(), "0X") || strings.HasPrefix(h.String(), hexutil.CustomHexPrefix) || strings.HasPrefix(h.String(), strings.ToUpper(hexutil.CustomHexPrefix)) {
-		return json.Marshal(hexutil.CustomHexPrefix + h.String()[2:])
-	}
-	return json.Marshal(h.String())
+	return json.Marshal(hexutil.ToPrefix(h.String(), hexutil.HexPrefix()))
 }
 
 // Sets the hash to the value of b. If b is larger than len(h), 'b' will be cropped (from the left).
@@ -124,6 +123,17 @@ func (h *Hash) Set(other Hash) {
 	}
 }
 
+// ImplementsGraphQLType returns true if Hash implements the specified GraphQL type.
+func (h Hash) ImplementsGraphQLType(name string) bool { return name == "Bytes32" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (h *Hash) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return h.UnmarshalText([]byte(input))
+	}
+	return fmt.Errorf("unexpected type %T for Bytes32", input)
+}
+
 // Generate implements testing/quick.Generator.
 func (h Hash) Generate(rand *rand.Rand, size int) reflect.Value {
 	m := rand.Intn(len(h))
@@ -166,14 +176,74 @@ func HexToAddress(s string) Address {
 	return BytesToAddress(FromHex(s))
 }
 
+// Errors returned when validating hex-encoded addresses.
+var (
+	ErrAddressPrefix = errors.New("invalid address prefix, want 0x or t0")
+	ErrAddressLength = errors.New("invalid address length, want 40 hex digits")
+	ErrAddressSyntax = errors.New("invalid hex digit in address")
+)
+
+// AddressChecksumError is returned for mixed-case addresses whose case does not
+// match the EIP55 checksum.
+type AddressChecksumError struct {
+	Have string // Address as given
+	Want string // Correctly checksummed address
+}
+
+func (err *AddressChecksumError) Error() string {
+	return fmt.Sprintf("invalid address checksum: have %s, want %s", err.Have, err.Want)
+}
+
 // IsHexAddress verifies whether a string can represent a valid hex-encoded
-// Dsplinz address or not.
+// Dsplinz address or not. Mixed-case addresses must have a valid checksum.
 func IsHexAddress(s string) bool {
-	s = hexutil.CPToHex(s)
-	if hasHexPrefix(s) {
+	return ValidateHexAddress(s) == nil
+}
+
+// ValidateHexAddress checks that s is a hex-encoded address, optionally prefixed
+// with 0x or t0. All lower or all upper case addresses are accepted as is, mixed
+// case addresses must match the EIP55 checksum.
+func ValidateHexAddress(s string) error {
+	digits, err := stripAddressPrefix(s)
+	if err != nil {
+		return err
+	}
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	addr := BytesToAddress(FromHex(digits))
+	if want := addr.Hex(); digits != want[2:] {
+		return &AddressChecksumError{Have: s, Want: want}
+	}
+	return nil
+}
+
+// ParseHexAddress decodes a hex-encoded address, verifying its prefix and
+// checksum like ValidateHexAddress.
+func ParseHexAddress(s string) (Address, error) {
+	if err := ValidateHexAddress(s); err != nil {
+		return Address{}, err
+	}
+	return HexToAddress(s), nil
+}
+
+// stripAddressPrefix returns the hex digits of a possibly prefixed address,
+// rejecting malformed digits and, in strict mode (see hexutil.StrictPrefix),
+// the ambiguous t1..t9 prefixes.
+func stripAddressPrefix(s string) (string, error) {
+	if len(s) >= 2 && hexutil.PossibleCustomHexPrefixMap[s[:2]] {
+		if !hexutil.HasCustomPrefix(s) {
+			return "", ErrAddressPrefix
+		}
 		s = s[2:]
 	}
-	return len(s) == 2*AddressLength && isHex(s)
+	if len(s) != 2*AddressLength {
+		return "", ErrAddressLength
+	}
+	if !isHex(s) {
+		return "", ErrAddressSyntax
+	}
+	return s, nil
 }
 
 // Get the string representation of the underlying address
@@ -255,12 +325,20 @@ func (a *Address) UnmarshalJSON(input []byte) error {
 	return hexutil.UnmarshalFixedJSON(addressT, input, a[:])
 }
 
-// MarshalJSON marshals the original value
+// MarshalJSON marshals the address with the configured display prefix.
 func (a *Address) MarshalJSON() ([]byte, error) {
-	if strings.HasPrefix(a.String(), "0x") || strings.HasPrefix(a.String(), "0X") || strings.HasPrefix(a.String(), hexutil.CustomHexPrefix) || strings.HasPrefix(a.String(), strings.ToUpper(hexutil.CustomHexPrefix)) {
-		return json.Marshal(hexutil.CustomHexPrefix + a.String()[2:])
+	return json.Marshal(hexutil.ToPrefix(a.String(), hexutil.HexPrefix()))
+}
+
+// ImplementsGraphQLType returns true if Address implements the specified GraphQL type.
+func (a Address) ImplementsGraphQLType(name string) bool { return name == "Address" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (a *Address) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return a.UnmarshalText([]byte(input))
 	}
-	return json.Marshal(a.String())
+	return fmt.Errorf("unexpected type %T for Address", input)
 }
 
 // UnprefixedHash allows marshaling an Address without 0x prefix.
@@ -290,10 +368,10 @@ func NewMixedcaseAddress(addr Address) MixedcaseAddress {
 
 // NewMixedcaseAddressFromString is mainly meant for unit-testing
 func NewMixedcaseAddressFromString(hexaddr string) (*MixedcaseAddress, error) {
-	hexaddr = hexutil.CPToHex(hexaddr)
-	if !IsHexAddress(hexaddr) {
-		return nil, fmt.Errorf("Invalid address")
+	if _, err := stripAddressPrefix(hexaddr); err != nil {
+		return nil, err
 	}
+	hexaddr = hexutil.CPToHex(hexaddr)
 	a := FromHex(hexaddr)
 	return &MixedcaseAddress{addr: BytesToAddress(a), original: hexaddr}, nil
 }
@@ -308,10 +386,10 @@ func (ma *MixedcaseAddress) UnmarshalJSON(input []byte) error {
 
 // MarshalJSON marshals the original value
 func (ma MixedcaseAddress) MarshalJSON() ([]byte, error) {
-	if strings.HasPrefix(ma.original, "0x") || strings.HasPrefix(ma.original, "0X") || strings.HasPrefix(ma.original, hexutil.CustomHexPrefix) || strings.HasPrefix(ma.original, strings.ToUpper(hexutil.CustomHexPrefix)) {
-		return json.Marshal(fmt.Sprintf("%s%s", hexutil.CustomHexPrefix, ma.original[2:]))
+	if hexutil.HasCustomPrefix(ma.original) {
+		return json.Marshal(fmt.Sprintf("%s%s", hexutil.HexPrefix(), ma.original[2:]))
 	}
-	return json.Marshal(fmt.Sprintf("%s%s", hexutil.CustomHexPrefix, ma.original))
+	return json.Marshal(fmt.Sprintf("%s%s", hexutil.HexPrefix(), ma.original))
 }
 
 // Address returns the address
@@ -329,7 +407,12 @@ func (ma *MixedcaseAddress) String() string {
 
 // ValidChecksum returns true if the address has valid checksum
 func (ma *MixedcaseAddress) ValidChecksum() bool {
-	return ma.original == hexutil.CPToHex(ma.addr.Hex())
+	// EIP-55 rejects an uppercase 0X, the custom prefixes stand in for 0x
+	original := ma.original
+	if len(original) > 2 && original[:2] != "0X" && hexutil.HasCustomPrefix(original) {
+		original = "0x" + original[2:]
+	}
+	return original == hexutil.CPToHex(ma.addr.Hex())
 }
 
 // Original returns the mixed-case input string
@@ -51,6 +51,9 @@ func TestIsHexAddress(t *testing.T) {
 		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beae", false},
 		{"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed11", false},
 		{"0xxaaeb6053f3e94c9b9a09f33669435e7ef1beaed", false},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false},
+		{"t15aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
 	}
 
 	for _, test := range tests {
@@ -196,3 +199,93 @@ func TestMixedcaseAccount_Address(t *testing.T) {
 	}
 
 }
+
+func TestAddressHexPrefixRoundTrip(t *testing.T) {
+	defer hexutil.SetHexPrefix(hexutil.DefaultHexPrefix)
+
+	addr := HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	for _, prefix := range []string{"0x", "t0"} {
+		if err := hexutil.SetHexPrefix(prefix); err != nil {
+			t.Fatal(err)
+		}
+		if want := prefix + "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"; addr.Hex() != want {
+			t.Errorf("prefix %q: hex mismatch: have %s, want %s", prefix, addr.Hex(), want)
+		}
+		if back := HexToAddress(addr.Hex()); back != addr {
+			t.Errorf("prefix %q: hex round trip mismatch: have %x, want %x", prefix, back, addr)
+		}
+		blob, err := json.Marshal(&addr)
+		if err != nil {
+			t.Fatalf("prefix %q: failed to marshal: %v", prefix, err)
+		}
+		if want := `"` + addr.Hex() + `"`; string(blob) != want {
+			t.Errorf("prefix %q: json mismatch: have %s, want %s", prefix, blob, want)
+		}
+		var back Address
+		if err := json.Unmarshal(blob, &back); err != nil {
+			t.Fatalf("prefix %q: failed to unmarshal %s: %v", prefix, blob, err)
+		}
+		if back != addr {
+			t.Errorf("prefix %q: json round trip mismatch: have %x, want %x", prefix, back, addr)
+		}
+		mixed, err := NewMixedcaseAddressFromString(addr.Hex())
+		if err != nil {
+			t.Fatalf("prefix %q: failed to parse mixed case address: %v", prefix, err)
+		}
+		if !mixed.ValidChecksum() {
+			t.Errorf("prefix %q: checksum of %s invalid", prefix, addr.Hex())
+		}
+	}
+}
+
+func TestIsHexAddressStrictPrefix(t *testing.T) {
+	defer hexutil.SetStrictPrefix(false)
+
+	ambiguous := "t15aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	if !IsHexAddress(ambiguous) {
+		t.Errorf("address %s rejected in lax mode", ambiguous)
+	}
+	hexutil.SetStrictPrefix(true)
+	if IsHexAddress(ambiguous) {
+		t.Errorf("address %s accepted in strict mode", ambiguous)
+	}
+	if err := ValidateHexAddress(ambiguous); err != ErrAddressPrefix {
+		t.Errorf("ValidateHexAddress(%s) == %v; expected %v", ambiguous, err, ErrAddressPrefix)
+	}
+	if !IsHexAddress("t05aaeb6053f3e94c9b9a09f33669435e7ef1beaed") {
+		t.Errorf("t0 address rejected in strict mode")
+	}
+}
+
+func TestValidateHexAddress(t *testing.T) {
+	tests := []struct {
+		str string
+		err error
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"t05aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"T05aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil},
+		{"5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", nil},
+		{"t15aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil},
+		{"T95aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", ErrAddressLength},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beagg", ErrAddressSyntax},
+	}
+	for _, test := range tests {
+		if err := ValidateHexAddress(test.str); err != test.err {
+			t.Errorf("ValidateHexAddress(%s) == %v; expected %v", test.str, err, test.err)
+		}
+	}
+	// Mixed case input with a wrong checksum must report the correct one
+	err := ValidateHexAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD")
+	cerr, ok := err.(*AddressChecksumError)
+	if !ok {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+	if want := HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed").Hex(); cerr.Want != want {
+		t.Errorf("checksum suggestion mismatch: have %s, want %s", cerr.Want, want)
+	}
+	if _, err := ParseHexAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"); err == nil {
+		t.Errorf("expected error parsing badly checksummed address")
+	}
+}
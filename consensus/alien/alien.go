@@ -0,0 +1,663 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package alien implements the delegated-proof-of-stake consensus engine.
+package alien
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/accounts"
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/crypto/sha3"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rlp"
+	"github.com/dsplinz2019/dsplinz/rpc"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+	checkpointInterval = 360  // Number of blocks after which to save the vote snapshot to the database
+
+	extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for signer seal
+
+	defaultPeriod         = 3      // Default minimum difference between two consecutive block's timestamps
+	defaultEpoch          = 201600 // Default number of blocks after which a vote expires
+	defaultMaxSignerCount = 21     // Default max count of signers in one loop
+)
+
+var (
+	// SignerBlockReward is the reward in wei credited to the signer of each block.
+	SignerBlockReward = big.NewInt(5e+18)
+
+	// defaultMinVoterBalance is used when the config does not specify a minimum.
+	defaultMinVoterBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e+18))
+
+	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
+
+	diffInTurn = big.NewInt(1) // Block difficulty, only the in-turn signer is ever allowed to seal
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of signers is requested for a block
+	// that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errMissingVanity is returned if a block's extra-data section is shorter than
+	// 32 bytes, which is required to store the signer vanity.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errMissingSignature is returned if a block's extra-data section doesn't seem
+	// to contain a 65 byte secp256k1 signature.
+	errMissingSignature = errors.New("extra-data 65 byte suffix signature missing")
+
+	// errInvalidMixDigest is returned if a block's mix digest is non-zero.
+	errInvalidMixDigest = errors.New("non-zero mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not 1.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// errInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	errInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errUnalignedTimestamp is returned if the timestamp of a block does not fall
+	// on a slot boundary of the current loop.
+	errUnalignedTimestamp = errors.New("timestamp not aligned to signer slot")
+
+	// errInvalidCoinbase is returned if the coinbase of a block differs from its signer.
+	errInvalidCoinbase = errors.New("coinbase does not match signer")
+
+	// errInvalidHeaderExtra is returned if the consensus section of a block's
+	// extra-data differs from the one derived locally.
+	errInvalidHeaderExtra = errors.New("invalid header extra-data")
+
+	// errUnauthorizedSigner is returned if a header is signed by a non authorized entity.
+	errUnauthorizedSigner = errors.New("unauthorized signer")
+
+	// errNotInTurn is returned if a header is signed by an authorized signer
+	// outside of its slot.
+	errNotInTurn = errors.New("signer not in turn")
+
+	// errGenesisNotAligned is returned if the genesis loop start time is later
+	// than the first block.
+	errGenesisNotAligned = errors.New("block timestamp before genesis loop start")
+
+	// errInvalidVotingChain is returned if an attempt is made to apply a batch of
+	// headers which are not contiguous.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errPBFTDisabled is returned if a confirmation is received while the PBFT
+	// confirmations are not enabled in the config.
+	errPBFTDisabled = errors.New("pbft confirmations disabled")
+
+	// errMismatchedConfirmation is returned if a confirmation references a block
+	// number that does not match the block hash.
+	errMismatchedConfirmation = errors.New("confirmation number does not match block")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// SignTxFn is a signTx callback function to request a transaction to be signed
+// by a backing account.
+type SignTxFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error)
+
+// sigHash returns the hash which is used as input for the delegated-proof-of-stake
+// signing. It is the hash of the entire header apart from the 65 byte signature
+// contained at the end of the extra data.
+//
+// Note, the method requires the extra data to be at least 65 bytes, otherwise it
+// panics. This is done to avoid accidentally using both forms (signature present
+// or not), which could be abused to produce different hashes for the same header.
+func sigHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+
+	rlp.Encode(hasher, []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-extraSeal], // Yes, this will panic if extra is too short
+		header.MixDigest,
+		header.Nonce,
+	})
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// ecrecover extracts the Dsplinz account address from a signed header.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// If the signature's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Retrieve the signature from the header extra-data
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	// Recover the public key and the Dsplinz address
+	pubkey, err := crypto.Ecrecover(sigHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// Alien is the delegated-proof-of-stake consensus engine. Signers are elected
+// by balance weighted votes and take turns sealing blocks in fixed time slots.
+type Alien struct {
+	config     *params.AlienConfig // Consensus engine configuration parameters
+	db         ethdb.Database      // Database to store and retrieve snapshot checkpoints
+	recents    *lru.ARCCache       // Snapshots for recent block to speed up reorgs
+	signatures *lru.ARCCache       // Signatures of recent blocks to speed up mining
+
+	signer common.Address // Dsplinz address of the signing key
+	signFn SignerFn       // Signer function to authorize hashes with
+	signTx SignTxFn       // Sign transaction function to sign tx
+
+	mainChain     MainChainBackend // Main chain a side chain anchors its blocks to
+	anchors       *lru.ARCCache    // Confirmed main chain block hashes to verify anchors against
+	anchorLookups map[uint64]bool  // Main chain blocks being looked up in the background
+
+	lock sync.RWMutex // Protects the signer and main chain fields
+}
+
+// New creates an Alien delegated-proof-of-stake consensus engine with the initial
+// signers set to the ones provided by the user.
+func New(config *params.AlienConfig, db ethdb.Database) *Alien {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Period == 0 {
+		conf.Period = defaultPeriod
+	}
+	if conf.Epoch == 0 {
+		conf.Epoch = defaultEpoch
+	}
+	if conf.MaxSignerCount == 0 {
+		conf.MaxSignerCount = defaultMaxSignerCount
+	}
+	if conf.MinVoterBalance == nil {
+		conf.MinVoterBalance = new(big.Int).Set(defaultMinVoterBalance)
+	}
+	// Allocate the snapshot caches and create the engine
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	anchors, _ := lru.NewARC(inmemoryAnchors)
+
+	return &Alien{
+		config:        &conf,
+		db:            db,
+		recents:       recents,
+		signatures:    signatures,
+		anchors:       anchors,
+		anchorLookups: make(map[uint64]bool),
+	}
+}
+
+// Author implements consensus.Engine, returning the Dsplinz address recovered
+// from the signature in the header's extra-data section.
+func (a *Alien) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, a.signatures)
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (a *Alien) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return a.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (a *Alien) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := a.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules.The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database. This is useful for concurrently verifying
+// a batch of new headers.
+func (a *Alien) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	// Don't waste time checking blocks from the future
+	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Check that the extra-data contains both the vanity and signature
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	// Ensure that the mix digest is zero as we don't have fork protection currently
+	if header.MixDigest != (common.Hash{}) {
+		return errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in DPoS
+	if header.UncleHash != uncleHash {
+		return errInvalidUncleHash
+	}
+	// All headers past the genesis must carry the fixed in-turn difficulty
+	if header.Number.Uint64() > 0 && (header.Difficulty == nil || header.Difficulty.Cmp(diffInTurn) != 0) {
+		return errInvalidDifficulty
+	}
+	// All basic checks passed, verify cascading fields
+	return a.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields verifies all the header fields that are not standalone,
+// rather depend on a batch of previous headers. The caller may optionally pass
+// in a batch of parents (ascending order) to avoid looking those up from the
+// database. This is useful for concurrently verifying a batch of new headers.
+func (a *Alien) verifyCascadingFields(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	// Ensure that the block's timestamp isn't too close to it's parent
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time.Uint64()+a.config.Period > header.Time.Uint64() {
+		return errInvalidTimestamp
+	}
+	// All basic checks passed, verify the seal and return
+	return a.verifySeal(chain, header, parents)
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (a *Alien) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the signature contained
+// in the header satisfies the consensus protocol requirements.
+func (a *Alien) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return a.verifySeal(chain, header, nil)
+}
+
+// verifySeal checks whether the signature contained in the header satisfies the
+// consensus protocol requirements. The method accepts an optional list of parent
+// headers that aren't yet part of the local blockchain to generate the snapshots
+// from.
+func (a *Alien) verifySeal(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// Resolve the authorization key and check against the signer queue
+	signer, err := ecrecover(header, a.signatures)
+	if err != nil {
+		return err
+	}
+	if signer != header.Coinbase {
+		return errInvalidCoinbase
+	}
+	return snap.verifyInturn(signer, header.Time.Uint64())
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (a *Alien) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	number := header.Number.Uint64()
+
+	// Nonce and mix digest are unused in DPoS
+	header.Nonce = types.BlockNonce{}
+	header.MixDigest = common.Hash{}
+	header.Difficulty = new(big.Int).Set(diffInTurn)
+
+	// Ensure the extra data has all it's components, the consensus section is
+	// filled in by Finalize once the transactions are known
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = header.Extra[:extraVanity]
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+
+	// Move the timestamp onto the next free slot of the current loop
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	earliest := parent.Time.Uint64() + a.config.Period
+	if now := uint64(time.Now().Unix()); now > earliest {
+		earliest = now
+	}
+	header.Time = new(big.Int).SetUint64(snap.nextSlot(earliest))
+	return nil
+}
+
+// Finalize implements consensus.Engine, crediting the block reward to the signer,
+// tallying the votes of the block and rotating the signer queue
+// at the end of each loop. The resulting consensus data is stored in the header's
+// extra-data. If the header already carries the section (i.e. the block is being
+// verified rather than sealed), it must match the locally derived one.
+func (a *Alien) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	number := header.Number.Uint64()
+
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	headerExtra, err := a.processCustomTx(chain, header, snap, state, txs)
+	if err != nil {
+		return nil, err
+	}
+	if err := snap.processLoop(header, headerExtra); err != nil {
+		return nil, err
+	}
+	// Keep the main chain anchor the sealer recorded unless it conflicts with the main chain
+	existing := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+	if len(existing) > 0 {
+		var recorded HeaderExtra
+		if err := rlp.DecodeBytes(existing, &recorded); err != nil {
+			return nil, errInvalidHeaderExtra
+		}
+		if err := a.verifyMainChainAnchor(recorded.MainChainNumber, recorded.MainChainHash); err != nil {
+			return nil, err
+		}
+		headerExtra.MainChainNumber, headerExtra.MainChainHash = recorded.MainChainNumber, recorded.MainChainHash
+	} else if a.config.SideChain {
+		headerExtra.MainChainNumber, headerExtra.MainChainHash = a.mainChainAnchor()
+	}
+	encoded, err := rlp.EncodeToBytes(headerExtra)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		if !bytes.Equal(existing, encoded) {
+			return nil, errInvalidHeaderExtra
+		}
+	} else {
+		seal := header.Extra[len(header.Extra)-extraSeal:]
+		header.Extra = append(append(header.Extra[:extraVanity:extraVanity], encoded...), seal...)
+	}
+	// Accumulate the block reward and commit the final state root
+	accumulateRewards(a.config, state, header)
+
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	// Assemble and return the final block for sealing
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// accumulateRewards credits the signer of the given block with the block reward.
+func accumulateRewards(config *params.AlienConfig, state *state.StateDB, header *types.Header) {
+	state.AddBalance(header.Coinbase, new(big.Int).Set(SignerBlockReward))
+}
+
+// Authorize injects a private key into the consensus engine to mint new blocks
+// with.
+func (a *Alien) Authorize(signer common.Address, signFn SignerFn, signTxFn SignTxFn) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.signer = signer
+	a.signFn = signFn
+	a.signTx = signTxFn
+}
+
+// Seal implements consensus.Engine, attempting to create a sealed block using
+// the local signing credentials. Signers that are not in turn for the block's
+// slot silently give up.
+func (a *Alien) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	// Don't hold the signer fields for the entire sealing procedure
+	a.lock.RLock()
+	signer, signFn := a.signer, a.signFn
+	a.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errUnauthorizedSigner
+	}
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := snap.verifyInturn(signer, header.Time.Uint64()); err != nil {
+		log.Trace("Not in turn to seal block", "number", number, "signer", signer, "err", err)
+		return nil, nil
+	}
+	if header.Coinbase != signer {
+		return nil, errInvalidCoinbase
+	}
+	// Sweet, the protocol permits us to sign the block, wait for our time
+	delay := time.Unix(header.Time.Int64(), 0).Sub(time.Now()) // nolint: gosimple
+	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	// Sign all the things!
+	sighash, err := signFn(accounts.Account{Address: signer}, sigHash(header).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	// Anchor the side chain on the main chain every now and then
+	if a.checkpointDue(number) {
+		go func(header *types.Header) {
+			if err := a.submitCheckpoint(chain, header); err != nil {
+				log.Warn("Failed to submit main chain checkpoint", "number", header.Number, "err", err)
+			}
+		}(types.CopyHeader(header))
+	}
+	return block.WithSeal(header), nil
+}
+
+// Confirm implements consensus.Confirmer, signing a confirmation of the given
+// block with the local signing credentials if the local signer was part of the
+// signer queue the block was sealed under.
+func (a *Alien) Confirm(chain consensus.ChainReader, header *types.Header) (*types.Confirmation, error) {
+	if !a.config.PBFTEnable {
+		return nil, nil
+	}
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	a.lock.RLock()
+	signer, signFn := a.signer, a.signFn
+	a.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errUnauthorizedSigner
+	}
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.isSigner(signer) {
+		return nil, nil
+	}
+	confirmation := types.NewConfirmation(number, header.Hash())
+	sig, err := signFn(accounts.Account{Address: signer}, confirmation.SigHash().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return confirmation.WithSignature(sig)
+}
+
+// VerifyConfirmation implements consensus.Confirmer, checking that the signer of
+// the confirmation was part of the signer queue the confirmed block was sealed
+// under.
+func (a *Alien) VerifyConfirmation(chain consensus.ChainReader, confirmation *types.Confirmation) (common.Address, error) {
+	if !a.config.PBFTEnable {
+		return common.Address{}, errPBFTDisabled
+	}
+	header := chain.GetHeaderByHash(confirmation.BlockHash)
+	if header == nil {
+		return common.Address{}, errUnknownBlock
+	}
+	if header.Number.Uint64() != confirmation.Number || confirmation.Number == 0 {
+		return common.Address{}, errMismatchedConfirmation
+	}
+	signer, err := confirmation.Signer()
+	if err != nil {
+		return common.Address{}, err
+	}
+	snap, err := a.snapshot(chain, confirmation.Number-1, header.ParentHash, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if !snap.isSigner(signer) {
+		return common.Address{}, errUnauthorizedSigner
+	}
+	return signer, nil
+}
+
+// ConfirmationSigners implements consensus.Confirmer, returning the size of the
+// signer queue the block was sealed under.
+func (a *Alien) ConfirmationSigners(chain consensus.ChainReader, header *types.Header) (int, error) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return 0, nil
+	}
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(snap.Signers), nil
+}
+
+// ConfirmationQuorum implements consensus.Confirmer, requiring more than two
+// thirds of the signer queue the block was sealed under to confirm it.
+func (a *Alien) ConfirmationQuorum(chain consensus.ChainReader, header *types.Header) (int, error) {
+	signers, err := a.ConfirmationSigners(chain, header)
+	if err != nil || signers == 0 {
+		return 0, err
+	}
+	return 2*signers/3 + 1, nil
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. Only the in-turn signer
+// may seal a block, so the difficulty is constant.
+func (a *Alien) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(diffInTurn)
+}
+
+// ApplyGenesis makes sure the genesis snapshot is available in the database, so
+// light clients don't need to derive it on their first header verification. The
+// stakes of the self voted signers are resolved by genesisStakes.
+func (a *Alien) ApplyGenesis(chain consensus.ChainReader) error {
+	genesis := chain.GetHeaderByNumber(0)
+	if genesis == nil {
+		return errUnknownBlock
+	}
+	if _, err := a.snapshot(chain, 0, genesis.Hash(), nil); err != nil {
+		log.Error("Failed to apply alien genesis", "hash", genesis.Hash(), "err", err)
+		return err
+	}
+	return nil
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to query
+// the signer snapshots.
+func (a *Alien) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "alien",
+		Version:   "1.0",
+		Service:   &API{chain: chain, alien: a},
+		Public:    false,
+	}}
+}
@@ -0,0 +1,761 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/accounts"
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
+	"github.com/dsplinz2019/dsplinz/core"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/core/vm"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rlp"
+	"github.com/dsplinz2019/dsplinz/rpc"
+)
+
+// testPeriod matches the fixed 10 second block spacing of core.GenerateChain.
+const (
+	testPeriod    = 10
+	testGenesisTs = 1554004800
+)
+
+var ether = big.NewInt(1e18)
+
+// testerAccountPool is a pool to maintain deterministic test accounts, keyed by
+// a textual name.
+type testerAccountPool struct {
+	accounts map[string]*ecdsa.PrivateKey
+	owners   map[common.Address]*ecdsa.PrivateKey
+}
+
+func newTesterAccountPool() *testerAccountPool {
+	return &testerAccountPool{
+		accounts: make(map[string]*ecdsa.PrivateKey),
+		owners:   make(map[common.Address]*ecdsa.PrivateKey),
+	}
+}
+
+func (ap *testerAccountPool) address(account string) common.Address {
+	if ap.accounts[account] == nil {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte("alien-test-" + account)))
+		if err != nil {
+			panic(err)
+		}
+		ap.accounts[account] = key
+		ap.owners[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	return crypto.PubkeyToAddress(ap.accounts[account].PublicKey)
+}
+
+// signFn signs a hash with the key of the owning test account.
+func (ap *testerAccountPool) signFn(account accounts.Account, hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, ap.owners[account.Address])
+}
+
+// testerChain is a blockchain driven by the alien engine whose blocks are
+// produced through core.GenerateChain and signed by the in-turn test signer.
+type testerChain struct {
+	t        *testing.T
+	accounts *testerAccountPool
+	config   *params.ChainConfig
+	db       ethdb.Database
+	engine   *Alien
+	chain    *core.BlockChain
+}
+
+func newTesterChain(t *testing.T, accounts *testerAccountPool, alienConfig *params.AlienConfig, alloc core.GenesisAlloc) *testerChain {
+	config := *params.AllAlienProtocolChanges
+	config.Alien = alienConfig
+
+	db := ethdb.NewMemDatabase()
+	genesis := &core.Genesis{
+		Config:     &config,
+		Timestamp:  testGenesisTs,
+		ExtraData:  make([]byte, extraVanity+extraSeal),
+		GasLimit:   params.GenesisGasLimit,
+		Difficulty: big.NewInt(1),
+		Alloc:      alloc,
+	}
+	genesis.MustCommit(db)
+
+	engine := New(alienConfig, db)
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	return &testerChain{t: t, accounts: accounts, config: &config, db: db, engine: engine, chain: chain}
+}
+
+// makeBlock generates the next block, skipping the given number of slots, and
+// signs it with the key of the in-turn signer.
+func (tc *testerChain) makeBlock(skip int, gen func(*core.BlockGen)) *types.Block {
+	return tc.makeBlockOn(tc.chain.CurrentBlock(), skip, gen)
+}
+
+// makeBlockOn generates a block on top of the given parent, skipping the given
+// number of slots, and signs it with the key of the in-turn signer.
+func (tc *testerChain) makeBlockOn(parent *types.Block, skip int, gen func(*core.BlockGen)) *types.Block {
+	snap, err := tc.engine.snapshot(tc.chain, parent.NumberU64(), parent.Hash(), nil)
+	if err != nil {
+		tc.t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	signer, err := snap.inturn(parent.Time().Uint64() + uint64(testPeriod*(skip+1)))
+	if err != nil {
+		tc.t.Fatalf("failed to resolve in-turn signer: %v", err)
+	}
+	blocks, _ := core.GenerateChain(tc.config, parent, tc.engine, tc.db, 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(signer)
+		b.SetExtra(make([]byte, extraVanity+extraSeal))
+		if skip > 0 {
+			b.OffsetTime(int64(testPeriod * skip))
+		}
+		if gen != nil {
+			gen(b)
+		}
+	})
+	if blocks[0] == nil {
+		tc.t.Fatalf("failed to finalize block %d", parent.NumberU64()+1)
+	}
+	return tc.sign(blocks[0].Header(), blocks[0], signer)
+}
+
+// sign seals the header with the key of the given signer.
+func (tc *testerChain) sign(header *types.Header, block *types.Block, signer common.Address) *types.Block {
+	sig, err := crypto.Sign(sigHash(header).Bytes(), tc.accounts.owners[signer])
+	if err != nil {
+		tc.t.Fatalf("failed to sign block: %v", err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return block.WithSeal(header)
+}
+
+// extend generates, signs and imports the next block.
+func (tc *testerChain) extend(skip int, gen func(*core.BlockGen)) *types.Block {
+	block := tc.makeBlock(skip, gen)
+	if _, err := tc.chain.InsertChain(types.Blocks{block}); err != nil {
+		tc.t.Fatalf("failed to insert block %d: %v", block.NumberU64(), err)
+	}
+	return block
+}
+
+// head returns the snapshot at the current head.
+func (tc *testerChain) head() *Snapshot {
+	header := tc.chain.CurrentHeader()
+	snap, err := tc.engine.snapshot(tc.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		tc.t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	return snap
+}
+
+// voteTx creates a signed vote of the account for the candidate.
+func (tc *testerChain) voteTx(b *core.BlockGen, voter, candidate string) *types.Transaction {
+	from := tc.accounts.address(voter)
+	tx := types.NewTransaction(b.TxNonce(from), tc.accounts.address(candidate), new(big.Int), 100000, big.NewInt(1), []byte("ufo:1:event:vote"))
+	tx, err := types.SignTx(tx, types.NewEIP155Signer(tc.config.ChainId), tc.accounts.accounts[voter])
+	if err != nil {
+		tc.t.Fatalf("failed to sign vote: %v", err)
+	}
+	return tx
+}
+
+func testAlienConfig(accounts *testerAccountPool, signers ...string) *params.AlienConfig {
+	config := &params.AlienConfig{
+		Period:           testPeriod,
+		Epoch:            30000,
+		MaxSignerCount:   uint64(len(signers)),
+		MinVoterBalance:  new(big.Int).Set(ether),
+		GenesisTimestamp: testGenesisTs + testPeriod,
+	}
+	for _, signer := range signers {
+		config.SelfVoteSigners = append(config.SelfVoteSigners, common.UnprefixedAddress(accounts.address(signer)))
+	}
+	return config
+}
+
+func fund(accounts *testerAccountPool, balances map[string]int64) core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc)
+	for account, balance := range balances {
+		alloc[accounts.address(account)] = core.GenesisAccount{Balance: new(big.Int).Mul(big.NewInt(balance), ether)}
+	}
+	return alloc
+}
+
+// Tests that the self vote signers take turns in slot order and that a new loop
+// starts with a freshly elected queue once the last slot is filled.
+func TestSignerRotation(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	genesis := tc.head()
+	if genesis.LoopStartTime != config.GenesisTimestamp {
+		t.Fatalf("genesis loop start mismatch: have %d, want %d", genesis.LoopStartTime, config.GenesisTimestamp)
+	}
+	if want := new(big.Int).Mul(big.NewInt(20), ether); genesis.Tally[accounts.address("B")].Cmp(want) != 0 {
+		t.Fatalf("genesis stake mismatch: have %v, want %v", genesis.Tally[accounts.address("B")], want)
+	}
+	for i := 0; i < 3; i++ {
+		block := tc.extend(0, nil)
+		if block.Coinbase() != genesis.Signers[i] {
+			t.Fatalf("block %d: signer mismatch: have %x, want %x", block.NumberU64(), block.Coinbase(), genesis.Signers[i])
+		}
+	}
+	snap := tc.head()
+	if want := config.GenesisTimestamp + 3*testPeriod; snap.LoopStartTime != want {
+		t.Fatalf("loop start mismatch: have %d, want %d", snap.LoopStartTime, want)
+	}
+	if len(snap.Signers) != 3 {
+		t.Fatalf("signer count mismatch: have %d, want %d", len(snap.Signers), 3)
+	}
+	for _, signer := range []string{"A", "B", "C"} {
+		if !snap.isSigner(accounts.address(signer)) {
+			t.Errorf("signer %s missing from rotated queue", signer)
+		}
+	}
+	// A block sealed outside of the signer's slot must be rejected
+	block := tc.makeBlock(0, nil)
+	header := block.Header()
+	for _, signer := range snap.Signers {
+		if signer != header.Coinbase {
+			header.Coinbase = signer
+			break
+		}
+	}
+	forged := tc.sign(header, block, header.Coinbase)
+	if _, err := tc.chain.InsertChain(types.Blocks{forged}); err != errNotInTurn {
+		t.Fatalf("out of turn block error mismatch: have %v, want %v", err, errNotInTurn)
+	}
+}
+
+// Tests that the signer queue still rotates if the signer of the last slot of the
+// loop is absent, the next loop starting right after the late block.
+func TestSignerRotationMissedLastSlot(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.TrantorBlock = big.NewInt(0)
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	genesis := tc.head()
+	tc.extend(0, nil)
+	tc.extend(0, nil)
+	block := tc.extend(1, nil) // Skips the last slot, lands in the first one of the next loop
+
+	snap := tc.head()
+	if want := config.GenesisTimestamp + 4*testPeriod; snap.LoopStartTime != want {
+		t.Fatalf("loop start mismatch: have %d, want %d", snap.LoopStartTime, want)
+	}
+	if want := block.Time().Uint64() + testPeriod; snap.LoopStartTime != want {
+		t.Fatalf("loop start not following the late block: have %d, want %d", snap.LoopStartTime, want)
+	}
+	var headerExtra HeaderExtra
+	if err := rlp.DecodeBytes(block.Extra()[extraVanity:len(block.Extra())-extraSeal], &headerExtra); err != nil {
+		t.Fatalf("failed to decode header extra: %v", err)
+	}
+	if len(headerExtra.SignerQueue) != 3 {
+		t.Fatalf("signer queue not elected: have %d signers, want %d", len(headerExtra.SignerQueue), 3)
+	}
+	if len(headerExtra.SignerMissing) != 1 || headerExtra.SignerMissing[0] != genesis.Signers[2] {
+		t.Fatalf("missed signers mismatch: have %x, want [%x]", headerExtra.SignerMissing, genesis.Signers[2])
+	}
+	// The chain must keep going with the newly elected queue
+	if block := tc.extend(0, nil); block.Coinbase() != snap.Signers[0] {
+		t.Fatalf("signer mismatch: have %x, want %x", block.Coinbase(), snap.Signers[0])
+	}
+}
+
+// Tests that balance weighted votes elect a new candidate into the next loop
+// and that voters below MinVoterBalance are ignored.
+func TestVoteElection(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B")
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "V": 100}))
+	defer tc.chain.Stop()
+
+	// Fund a poor voter during the chain, it must not be able to vote
+	tc.extend(0, func(b *core.BlockGen) {
+		b.AddTx(tc.voteTx(b, "V", "D"))
+		tx := types.NewTransaction(b.TxNonce(accounts.address("V")), accounts.address("P"), big.NewInt(1e17), 21000, big.NewInt(1), nil)
+		tx, _ = types.SignTx(tx, types.NewEIP155Signer(tc.config.ChainId), accounts.accounts["V"])
+		b.AddTx(tx)
+	})
+	tc.extend(0, func(b *core.BlockGen) {
+		b.AddTx(tc.voteTx(b, "P", "A"))
+	})
+	snap := tc.head()
+	if _, ok := snap.Votes[accounts.address("P")]; ok {
+		t.Fatalf("vote below minimum voter balance was counted")
+	}
+	if vote, ok := snap.Votes[accounts.address("V")]; !ok || vote.Candidate != accounts.address("D") {
+		t.Fatalf("vote for candidate not recorded: %v", vote)
+	}
+	// The second block filled the loop, D outweighs A and replaces it
+	if !snap.isSigner(accounts.address("D")) || snap.isSigner(accounts.address("A")) {
+		t.Fatalf("elected queue mismatch: %x", snap.Signers)
+	}
+	// The newly elected signer must be able to seal its slots
+	for i := 0; i < 2; i++ {
+		tc.extend(0, nil)
+	}
+}
+
+// Tests that the explorer API reports the signer queue, tallies and votes of
+// the election in TestVoteElection.
+func TestExplorerAPI(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B")
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "V": 100}))
+	defer tc.chain.Stop()
+
+	tc.extend(0, func(b *core.BlockGen) {
+		b.AddTx(tc.voteTx(b, "V", "D"))
+		tx := types.NewTransaction(b.TxNonce(accounts.address("V")), accounts.address("P"), big.NewInt(1e17), 21000, big.NewInt(1), nil)
+		tx, _ = types.SignTx(tx, types.NewEIP155Signer(tc.config.ChainId), accounts.accounts["V"])
+		b.AddTx(tx)
+	})
+	tc.extend(0, nil)
+
+	api := NewPublicExplorerAPI(tc.chain, tc.engine)
+	queue, err := api.SignerQueue(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve signer queue: %v", err)
+	}
+	if want := queue.LoopStartTime + 2*testPeriod; queue.NextRotation != want {
+		t.Errorf("next rotation mismatch: have %d, want %d", queue.NextRotation, want)
+	}
+	if !sameSigners(queue.Signers, tc.head().Signers) {
+		t.Errorf("signer queue mismatch: have %x, want %x", queue.Signers, tc.head().Signers)
+	}
+	candidates, err := api.Candidates(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve candidates: %v", err)
+	}
+	top := candidates[0]
+	if top.Address != accounts.address("D") || top.Voters != 1 || !top.Signer || !top.Eligible {
+		t.Errorf("top candidate mismatch: %+v", top)
+	}
+	votes, err := api.Votes(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve votes: %v", err)
+	}
+	var found bool
+	for _, vote := range votes {
+		if vote.Voter == accounts.address("V") && vote.Candidate == accounts.address("D") && vote.Number == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("vote of V missing: %v", votes)
+	}
+	poor, err := api.VoterStatus(accounts.address("P"), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve voter status: %v", err)
+	}
+	if poor.Eligible || poor.Vote != nil {
+		t.Errorf("poor voter status mismatch: %+v", poor)
+	}
+	rich, err := api.VoterStatus(accounts.address("V"), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve voter status: %v", err)
+	}
+	if !rich.Eligible || rich.Vote == nil || rich.Vote.Candidate != accounts.address("D") {
+		t.Errorf("voter status mismatch: %+v", rich)
+	}
+	number := rpc.BlockNumber(1000)
+	if _, err := api.SignerQueue(&number); err != errUnknownBlock {
+		t.Errorf("unknown block error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
+
+// Tests that side chain sealers record the main chain head in their blocks and
+// anchor signed checkpoints on the main chain.
+func TestSideChainAnchoring(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B")
+	config.SideChain = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20}))
+	defer tc.chain.Stop()
+
+	sideChains := []params.AlienSideChain{{
+		Genesis: tc.chain.Genesis().Hash(),
+		Signers: []common.Address{accounts.address("A"), accounts.address("B")},
+	}}
+	mainchain := NewFakeMainChain(sideChains...)
+	mainchain.Advance()
+	number, hash := mainchain.Advance()
+	tc.engine.SetMainChain(mainchain)
+
+	block := tc.extend(0, nil)
+	extra := block.Extra()
+	var headerExtra HeaderExtra
+	if err := rlp.DecodeBytes(extra[extraVanity:len(extra)-extraSeal], &headerExtra); err != nil {
+		t.Fatalf("failed to decode header extra: %v", err)
+	}
+	if headerExtra.MainChainNumber != number || headerExtra.MainChainHash != hash {
+		t.Fatalf("anchor mismatch: have %d/%x, want %d/%x", headerExtra.MainChainNumber, headerExtra.MainChainHash, number, hash)
+	}
+	if tc.engine.checkpointDue(1) || !tc.engine.checkpointDue(defaultCheckpointInterval) {
+		t.Fatalf("checkpoint interval mismatch")
+	}
+	// Checkpoints are signed by the local signer and verified by the main chain
+	tc.engine.Authorize(block.Coinbase(), accounts.signFn, nil)
+	if err := tc.engine.submitCheckpoint(tc.chain, block.Header()); err != nil {
+		t.Fatalf("failed to submit checkpoint: %v", err)
+	}
+	checkpoints := mainchain.Checkpoints()
+	if len(checkpoints) != 1 {
+		t.Fatalf("checkpoint count mismatch: have %d, want %d", len(checkpoints), 1)
+	}
+	checkpoint := checkpoints[0]
+	if checkpoint.Signer != block.Coinbase() || checkpoint.Hash != block.Hash() || checkpoint.SideChain != tc.chain.Genesis().Hash() {
+		t.Fatalf("checkpoint mismatch: %+v", checkpoint)
+	}
+	api := NewPrivateMainChainAPI(ethdb.NewMemDatabase(), sideChains)
+	if err := api.SubmitCheckpoint(*checkpoint); err != nil {
+		t.Fatalf("failed to record checkpoint: %v", err)
+	}
+	stored, err := api.GetCheckpoint(checkpoint.SideChain, hexutil.Uint64(checkpoint.Number))
+	if err != nil || stored.Hash != checkpoint.Hash {
+		t.Fatalf("stored checkpoint mismatch: %v, %v", stored, err)
+	}
+	forged := *checkpoint
+	forged.Number++
+	if err := api.SubmitCheckpoint(forged); err != errInvalidCheckpoint {
+		t.Fatalf("forged checkpoint error mismatch: have %v, want %v", err, errInvalidCheckpoint)
+	}
+	// Recorded checkpoints can't be replaced, not even by an authorized signer
+	replaced := signCheckpoint(t, accounts, "B", Checkpoint{SideChain: checkpoint.SideChain, Number: checkpoint.Number, Hash: common.Hash{0x01}})
+	if err := api.SubmitCheckpoint(replaced); err != errCheckpointExists {
+		t.Fatalf("replaced checkpoint error mismatch: have %v, want %v", err, errCheckpointExists)
+	}
+	// Only the signers of configured side chains are accepted
+	unauthorized := signCheckpoint(t, accounts, "X", Checkpoint{SideChain: checkpoint.SideChain, Number: checkpoint.Number + 1, Hash: common.Hash{0x02}})
+	if err := api.SubmitCheckpoint(unauthorized); err != errUnauthorizedCheckpoint {
+		t.Fatalf("unauthorized checkpoint error mismatch: have %v, want %v", err, errUnauthorizedCheckpoint)
+	}
+	unknown := signCheckpoint(t, accounts, "A", Checkpoint{SideChain: common.Hash{0x03}, Number: checkpoint.Number})
+	if err := api.SubmitCheckpoint(unknown); err != errUnknownSideChain {
+		t.Fatalf("unknown side chain error mismatch: have %v, want %v", err, errUnknownSideChain)
+	}
+}
+
+// forkedMainChain is a main chain whose head is on a fork of the fake main chain.
+type forkedMainChain struct {
+	*FakeMainChain
+}
+
+func (f forkedMainChain) CurrentHead(ctx context.Context) (uint64, common.Hash, error) {
+	number, _, err := f.FakeMainChain.CurrentHead(ctx)
+	return number, common.Hash{0xff}, err
+}
+
+// Tests that side chain blocks are only rejected if their anchor conflicts with a
+// confirmed main chain block, not if the main chain can't be reached.
+func TestSideChainInvalidAnchor(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B")
+	config.SideChain = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20}))
+	defer tc.chain.Stop()
+
+	mainchain := NewFakeMainChain()
+	mainchain.Advance()
+
+	// Seal a block anchored to a fork of the main chain
+	tc.engine.SetMainChain(forkedMainChain{mainchain})
+	forked := tc.makeBlock(0, nil)
+
+	// Unconfirmed main chain blocks aren't trusted to reject anchors with
+	tc.engine.fetchMainChainAnchor(mainchain, 1, common.Hash{0xff})
+	if _, ok := tc.engine.anchors.Get(uint64(1)); ok {
+		t.Fatalf("unconfirmed main chain block cached")
+	}
+	for i := 0; i < mainChainConfirmations; i++ {
+		mainchain.Advance()
+	}
+	tc.engine.fetchMainChainAnchor(mainchain, 1, common.Hash{0xff})
+
+	tc.engine.SetMainChain(mainchain)
+	if _, err := tc.chain.InsertChain(types.Blocks{forked}); err != errInvalidMainChainAnchor {
+		t.Fatalf("anchor error mismatch: have %v, want %v", err, errInvalidMainChainAnchor)
+	}
+	// Anchors that can't be verified are accepted, even without a main chain
+	ahead := NewFakeMainChain()
+	for i := 0; i < 2*mainChainConfirmations; i++ {
+		ahead.Advance()
+	}
+	tc.engine.SetMainChain(ahead)
+	block := tc.makeBlock(0, nil)
+
+	tc.engine.SetMainChain(nil)
+	if _, err := tc.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
+// signCheckpoint signs the checkpoint with the key of the test account.
+func signCheckpoint(t *testing.T, accounts *testerAccountPool, signer string, checkpoint Checkpoint) Checkpoint {
+	checkpoint.Signer = accounts.address(signer)
+	sig, err := crypto.Sign(checkpoint.SigHash().Bytes(), accounts.accounts[signer])
+	if err != nil {
+		t.Fatalf("failed to sign checkpoint: %v", err)
+	}
+	checkpoint.Signature = sig
+	return checkpoint
+}
+
+// Tests that missed slots are recorded and that, from Trantor on, repeatedly
+// absent signers are no longer elected.
+func TestMissedSlotsPunished(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.TrantorBlock = big.NewInt(0)
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	missed := tc.head().Signers[0]
+	tc.extend(1, nil)
+
+	snap := tc.head()
+	if snap.Punished[missed] != 1 {
+		t.Fatalf("missed slot count mismatch: have %d, want %d", snap.Punished[missed], 1)
+	}
+	snap.Punished[missed] = punishThreshold
+	for _, item := range snap.candidates(big.NewInt(1)) {
+		if item.addr == missed {
+			t.Fatalf("punished signer %x still eligible", missed)
+		}
+	}
+}
+
+// Tests that from the Terminus fork the stake of a vote follows the voter's balance.
+func TestTerminusStakeRefresh(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.TerminusBlock = big.NewInt(0)
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30, "V": 100}))
+	defer tc.chain.Stop()
+
+	tc.extend(0, func(b *core.BlockGen) {
+		b.AddTx(tc.voteTx(b, "V", "A"))
+	})
+	before := new(big.Int).Set(tc.head().Tally[accounts.address("A")])
+
+	tc.extend(0, func(b *core.BlockGen) {
+		tx := types.NewTransaction(b.TxNonce(accounts.address("V")), accounts.address("X"), new(big.Int).Mul(big.NewInt(50), ether), 21000, big.NewInt(1), nil)
+		tx, _ = types.SignTx(tx, types.NewEIP155Signer(tc.config.ChainId), accounts.accounts["V"])
+		b.AddTx(tx)
+	})
+	after := tc.head().Tally[accounts.address("A")]
+	if diff := new(big.Int).Sub(before, after); diff.Cmp(new(big.Int).Mul(big.NewInt(50), ether)) < 0 {
+		t.Fatalf("stake not refreshed: before %v, after %v", before, after)
+	}
+}
+
+// Tests that confirmations are only accepted from the signers of the queue the
+// confirmed block was sealed under.
+func TestVerifyConfirmation(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.PBFTEnable = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	block := tc.extend(0, nil)
+
+	confirm := func(signer string, number uint64, hash common.Hash) *types.Confirmation {
+		confirmation := types.NewConfirmation(number, hash)
+		sig, err := crypto.Sign(confirmation.SigHash().Bytes(), accounts.accounts[signer])
+		if err != nil {
+			t.Fatalf("failed to sign confirmation: %v", err)
+		}
+		confirmation, _ = confirmation.WithSignature(sig)
+		return confirmation
+	}
+	accounts.address("X")
+	if signer, err := tc.engine.VerifyConfirmation(tc.chain, confirm("B", 1, block.Hash())); err != nil || signer != accounts.address("B") {
+		t.Fatalf("valid confirmation rejected: signer %x, err %v", signer, err)
+	}
+	if _, err := tc.engine.VerifyConfirmation(tc.chain, confirm("X", 1, block.Hash())); err != errUnauthorizedSigner {
+		t.Fatalf("outsider confirmation error mismatch: have %v, want %v", err, errUnauthorizedSigner)
+	}
+	if _, err := tc.engine.VerifyConfirmation(tc.chain, confirm("B", 2, block.Hash())); err != errMismatchedConfirmation {
+		t.Fatalf("mismatched confirmation error mismatch: have %v, want %v", err, errMismatchedConfirmation)
+	}
+	if _, err := tc.engine.VerifyConfirmation(tc.chain, confirm("B", 2, common.Hash{0x01})); err != errUnknownBlock {
+		t.Fatalf("unknown block confirmation error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+	if quorum, err := tc.engine.ConfirmationQuorum(tc.chain, block.Header()); err != nil || quorum != 3 {
+		t.Fatalf("quorum mismatch: have %d, want %d (err %v)", quorum, 3, err)
+	}
+	// Confirmations created by the local signer pass verification
+	tc.engine.Authorize(accounts.address("C"), accounts.signFn, nil)
+
+	confirmation, err := tc.engine.Confirm(tc.chain, block.Header())
+	if err != nil || confirmation == nil {
+		t.Fatalf("failed to confirm block: confirmation %v, err %v", confirmation, err)
+	}
+	if signer, err := tc.engine.VerifyConfirmation(tc.chain, confirmation); err != nil || signer != accounts.address("C") {
+		t.Fatalf("local confirmation rejected: signer %x, err %v", signer, err)
+	}
+}
+
+// Tests that the confirmation pool advances the safe and finalized blocks of the
+// chain as signer confirmations arrive.
+func TestConfirmationFinality(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.PBFTEnable = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	block := tc.extend(0, nil)
+	tc.extend(0, nil)
+
+	pool := core.NewConfirmationPool(tc.chain, tc.db)
+	defer pool.Stop()
+
+	finalized := make(chan core.FinalizedEvent, 1)
+	sub := tc.chain.SubscribeFinalizedEvent(finalized)
+	defer sub.Unsubscribe()
+
+	for i, signer := range []string{"A", "B", "C"} {
+		confirmation := types.NewConfirmation(block.NumberU64(), block.Hash())
+		sig, _ := crypto.Sign(confirmation.SigHash().Bytes(), accounts.accounts[signer])
+		confirmation, _ = confirmation.WithSignature(sig)
+
+		if err := pool.Add(confirmation); err != nil {
+			t.Fatalf("failed to add confirmation of %s: %v", signer, err)
+		}
+		if err := pool.Add(confirmation); err != core.ErrKnownConfirmation {
+			t.Fatalf("duplicate confirmation error mismatch: have %v, want %v", err, core.ErrKnownConfirmation)
+		}
+		if count := pool.Count(block.NumberU64()); count != i+1 {
+			t.Fatalf("confirmation count mismatch: have %d, want %d", count, i+1)
+		}
+		if i == 1 {
+			if safe := tc.chain.CurrentSafeBlock(); safe.Hash() != block.Hash() {
+				t.Fatalf("safe block mismatch: have #%d, want #%d", safe.NumberU64(), block.NumberU64())
+			}
+			if number := tc.chain.CurrentFinalizedBlock().NumberU64(); number != 0 {
+				t.Fatalf("block finalized without quorum: have #%d", number)
+			}
+		}
+	}
+	select {
+	case ev := <-finalized:
+		if ev.Block.Hash() != block.Hash() {
+			t.Fatalf("finalized event mismatch: have #%d, want #%d", ev.Block.NumberU64(), block.NumberU64())
+		}
+	default:
+		t.Fatalf("no finalized event posted")
+	}
+	if final := tc.chain.CurrentFinalizedBlock(); final.Hash() != block.Hash() {
+		t.Fatalf("finalized block mismatch: have #%d, want #%d", final.NumberU64(), block.NumberU64())
+	}
+}
+
+// Tests that forks branching off below the finalized block never become canonical,
+// even if they are heavier.
+func TestReorgBelowFinalized(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	config.PBFTEnable = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	genesis := tc.chain.CurrentBlock()
+	block := tc.extend(0, nil)
+	head := tc.extend(0, nil)
+
+	pool := core.NewConfirmationPool(tc.chain, tc.db)
+	defer pool.Stop()
+
+	for _, signer := range []string{"A", "B", "C"} {
+		confirmation := types.NewConfirmation(block.NumberU64(), block.Hash())
+		sig, _ := crypto.Sign(confirmation.SigHash().Bytes(), accounts.accounts[signer])
+		confirmation, _ = confirmation.WithSignature(sig)
+		if err := pool.Add(confirmation); err != nil {
+			t.Fatalf("failed to add confirmation of %s: %v", signer, err)
+		}
+	}
+	if final := tc.chain.CurrentFinalizedBlock(); final.Hash() != block.Hash() {
+		t.Fatalf("finalized block mismatch: have #%d, want #%d", final.NumberU64(), block.NumberU64())
+	}
+	// Import a longer fork off the genesis block, skipping the first slot so it
+	// differs from the canonical chain
+	parent := genesis
+	for i := 0; i < 3; i++ {
+		skip := 0
+		if i == 0 {
+			skip = 1
+		}
+		fork := tc.makeBlockOn(parent, skip, nil)
+		if _, err := tc.chain.InsertChain(types.Blocks{fork}); err != nil {
+			t.Fatalf("failed to import fork block %d: %v", fork.NumberU64(), err)
+		}
+		parent = fork
+	}
+	if current := tc.chain.CurrentBlock(); current.Hash() != head.Hash() {
+		t.Fatalf("head mismatch: have #%d %x, want #%d %x", current.NumberU64(), current.Hash(), head.NumberU64(), head.Hash())
+	}
+	if final := tc.chain.CurrentFinalizedBlock(); final.Hash() != block.Hash() {
+		t.Fatalf("finalized block moved: have #%d, want #%d", final.NumberU64(), block.NumberU64())
+	}
+}
+
+// Tests that blocks whose consensus extra-data was tampered with are rejected.
+func TestTamperedHeaderExtra(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B", "C")
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20, "C": 30}))
+	defer tc.chain.Stop()
+
+	block := tc.makeBlock(0, nil)
+	header := block.Header()
+
+	var headerExtra HeaderExtra
+	if err := rlp.DecodeBytes(header.Extra[extraVanity:len(header.Extra)-extraSeal], &headerExtra); err != nil {
+		t.Fatalf("failed to decode header extra: %v", err)
+	}
+	headerExtra.CurrentBlockVotes = append(headerExtra.CurrentBlockVotes, &Vote{Voter: accounts.address("X"), Candidate: accounts.address("X"), Stake: new(big.Int).Mul(big.NewInt(1000), ether)})
+	encoded, _ := rlp.EncodeToBytes(headerExtra)
+	header.Extra = append(append(append([]byte{}, header.Extra[:extraVanity]...), encoded...), make([]byte, extraSeal)...)
+
+	forged := tc.sign(header, block, header.Coinbase)
+	if _, err := tc.chain.InsertChain(types.Blocks{forged}); err != errInvalidHeaderExtra {
+		t.Fatalf("tampered block error mismatch: have %v, want %v", err, errInvalidHeaderExtra)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/core/types"
+)
+
+// Custom transactions are plain value transfers whose data starts with the
+// prefix, version and category below, e.g. "ufo:1:event:vote".
+const (
+	ufoPrefix        = "ufo"
+	ufoVersion       = "1"
+	ufoCategoryEvent = "event"
+	ufoEventVote     = "vote"
+	ufoMinSplitLen   = 3
+	posPrefix        = 0
+	posVersion       = 1
+	posCategory      = 2
+	posEvent         = 3
+)
+
+// Vote is a balance weighted vote of a voter for a candidate.
+type Vote struct {
+	Voter     common.Address `json:"voter"`
+	Candidate common.Address `json:"candidate"`
+	Stake     *big.Int       `json:"stake"`
+}
+
+// HeaderExtra is the consensus data carried in a block's extra-data, between
+// the vanity prefix and the seal.
+type HeaderExtra struct {
+	CurrentBlockVotes      []*Vote
+	ModifyPredecessorVotes []*Vote
+	SignerMissing          []common.Address
+	LoopStartTime          uint64
+	SignerQueue            []common.Address
+	MainChainNumber        uint64
+	MainChainHash          common.Hash
+}
+
+// processCustomTx collects the votes, stake updates and missed slots of a block
+// from its transactions and the state after executing them.
+func (a *Alien) processCustomTx(chain consensus.ChainReader, header *types.Header, snap *Snapshot, state *state.StateDB, txs []*types.Transaction) (*HeaderExtra, error) {
+	var (
+		headerExtra = new(HeaderExtra)
+		signer      = types.MakeSigner(chain.Config(), header.Number)
+		voted       = make(map[common.Address]bool)
+		touched     []common.Address
+	)
+	for _, tx := range txs {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		touched = append(touched, sender)
+		if tx.To() != nil {
+			touched = append(touched, *tx.To())
+		}
+		if tx.To() == nil || len(tx.Data()) < len(ufoPrefix) {
+			continue
+		}
+		txData := string(tx.Data())
+		txDataInfo := strings.Split(txData, ":")
+		if len(txDataInfo) <= ufoMinSplitLen || txDataInfo[posPrefix] != ufoPrefix || txDataInfo[posVersion] != ufoVersion || txDataInfo[posCategory] != ufoCategoryEvent {
+			continue
+		}
+		switch txDataInfo[posEvent] {
+		case ufoEventVote:
+			// Side chains have no stake of their own, their signers are fixed
+			if a.config.SideChain {
+				continue
+			}
+			stake := state.GetBalance(sender)
+			if stake.Cmp(a.config.MinVoterBalance) < 0 {
+				continue
+			}
+			for i, vote := range headerExtra.CurrentBlockVotes {
+				if vote.Voter == sender {
+					headerExtra.CurrentBlockVotes = append(headerExtra.CurrentBlockVotes[:i], headerExtra.CurrentBlockVotes[i+1:]...)
+					break
+				}
+			}
+			headerExtra.CurrentBlockVotes = append(headerExtra.CurrentBlockVotes, &Vote{Voter: sender, Candidate: *tx.To(), Stake: new(big.Int).Set(stake)})
+			voted[sender] = true
+		}
+	}
+	// From the Terminus fork the stakes of existing votes follow the voter's balance
+	if a.config.IsTerminus(header.Number) {
+		modified := make(map[common.Address]bool)
+		for _, address := range touched {
+			if voted[address] || modified[address] {
+				continue
+			}
+			if vote, ok := snap.Votes[address]; ok {
+				headerExtra.ModifyPredecessorVotes = append(headerExtra.ModifyPredecessorVotes, &Vote{Voter: address, Candidate: vote.Candidate, Stake: new(big.Int).Set(state.GetBalance(address))})
+				modified[address] = true
+			}
+		}
+	}
+	// Missed slots are always recorded, they only affect elections from Trantor
+	if parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); parent != nil {
+		headerExtra.SignerMissing = snap.missingSigners(parent.Time.Uint64(), header.Time.Uint64())
+	}
+	return headerExtra, nil
+}
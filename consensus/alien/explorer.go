@@ -0,0 +1,307 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/event"
+	"github.com/dsplinz2019/dsplinz/rpc"
+)
+
+// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+const chainHeadChanSize = 10
+
+// ExplorerBackend is the chain access needed by the explorer API.
+type ExplorerBackend interface {
+	consensus.ChainReader
+	CurrentFinalizedBlock() *types.Block
+	CurrentSafeBlock() *types.Block
+	StateAt(root common.Hash) (*state.StateDB, error)
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// SignerQueue is the signer queue of a loop and its timing.
+type SignerQueue struct {
+	Number        uint64           `json:"number"`        // Block number the queue was retrieved at
+	Hash          common.Hash      `json:"hash"`          // Block hash the queue was retrieved at
+	Signers       []common.Address `json:"signers"`       // Signers of the loop, one slot each
+	LoopStartTime uint64           `json:"loopStartTime"` // Timestamp of the first slot of the loop
+	NextRotation  uint64           `json:"nextRotation"`  // Timestamp of the first slot of the next loop
+	Period        uint64           `json:"period"`        // Seconds between two slots
+}
+
+// CandidateInfo is the balance weighted tally of a candidate.
+type CandidateInfo struct {
+	Address  common.Address `json:"address"`
+	Tally    *hexutil.Big   `json:"tally"`    // Sum of the stakes voting for the candidate
+	Voters   int            `json:"voters"`   // Number of voters voting for the candidate
+	Missed   uint64         `json:"missed"`   // Decaying count of missed slots
+	Signer   bool           `json:"signer"`   // Whether the candidate is in the current signer queue
+	Eligible bool           `json:"eligible"` // Whether the candidate may be elected into the next queue
+}
+
+// VoteInfo is the active vote of a voter.
+type VoteInfo struct {
+	Voter     common.Address `json:"voter"`
+	Candidate common.Address `json:"candidate"`
+	Stake     *hexutil.Big   `json:"stake"`
+	Number    uint64         `json:"number"` // Block number the vote was cast or renewed at
+}
+
+// VoterStatus tells whether an account may vote at a given block.
+type VoterStatus struct {
+	Address         common.Address `json:"address"`
+	Balance         *hexutil.Big   `json:"balance"`
+	MinVoterBalance *hexutil.Big   `json:"minVoterBalance"`
+	Eligible        bool           `json:"eligible"` // Whether the balance reaches MinVoterBalance
+	Vote            *VoteInfo      `json:"vote"`     // Active vote of the account, if any
+}
+
+// PublicExplorerAPI exposes the signers, votes and candidates of the delegated-
+// proof-of-stake scheme at any block.
+type PublicExplorerAPI struct {
+	chain ExplorerBackend
+	alien *Alien
+}
+
+// NewPublicExplorerAPI creates a new explorer API for the given engine.
+func NewPublicExplorerAPI(chain ExplorerBackend, alien *Alien) *PublicExplorerAPI {
+	return &PublicExplorerAPI{chain: chain, alien: alien}
+}
+
+// header resolves a block number, including the block tags, to its header.
+func (api *PublicExplorerAPI) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil {
+		return api.chain.CurrentHeader()
+	}
+	switch *number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return api.chain.CurrentHeader()
+	case rpc.FinalizedBlockNumber:
+		if block := api.chain.CurrentFinalizedBlock(); block != nil {
+			return block.Header()
+		}
+		return nil
+	case rpc.SafeBlockNumber:
+		if block := api.chain.CurrentSafeBlock(); block != nil {
+			return block.Header()
+		}
+		return nil
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// snapshot retrieves the snapshot and the header at the given block.
+func (api *PublicExplorerAPI) snapshot(number *rpc.BlockNumber) (*Snapshot, *types.Header, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, nil, errUnknownBlock
+	}
+	snap, err := api.alien.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snap, header, nil
+}
+
+// signerQueue assembles the signer queue summary of a snapshot.
+func (api *PublicExplorerAPI) signerQueue(snap *Snapshot) *SignerQueue {
+	period := api.alien.config.Period
+	return &SignerQueue{
+		Number:        snap.Number,
+		Hash:          snap.Hash,
+		Signers:       append([]common.Address(nil), snap.Signers...),
+		LoopStartTime: snap.LoopStartTime,
+		NextRotation:  snap.LoopStartTime + uint64(len(snap.Signers))*period,
+		Period:        period,
+	}
+}
+
+// SignerQueue returns the signer queue and the next rotation time at the given block.
+func (api *PublicExplorerAPI) SignerQueue(number *rpc.BlockNumber) (*SignerQueue, error) {
+	snap, _, err := api.snapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.signerQueue(snap), nil
+}
+
+// Candidates returns the candidates at the given block, sorted by their balance
+// weighted tally.
+func (api *PublicExplorerAPI) Candidates(number *rpc.BlockNumber) ([]*CandidateInfo, error) {
+	snap, header, err := api.snapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	voters := make(map[common.Address]int)
+	for _, vote := range snap.Votes {
+		voters[vote.Candidate]++
+	}
+	eligible := make(map[common.Address]bool)
+	for _, item := range snap.candidates(header.Number) {
+		eligible[item.addr] = true
+	}
+	candidates := make([]*CandidateInfo, 0, len(snap.Tally))
+	for candidate, tally := range snap.Tally {
+		candidates = append(candidates, &CandidateInfo{
+			Address:  candidate,
+			Tally:    (*hexutil.Big)(new(big.Int).Set(tally)),
+			Voters:   voters[candidate],
+			Missed:   snap.Punished[candidate],
+			Signer:   snap.isSigner(candidate),
+			Eligible: eligible[candidate],
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if cmp := candidates[i].Tally.ToInt().Cmp(candidates[j].Tally.ToInt()); cmp != 0 {
+			return cmp > 0
+		}
+		return candidates[i].Address.Big().Cmp(candidates[j].Address.Big()) < 0
+	})
+	return candidates, nil
+}
+
+// Votes returns the active votes at the given block.
+func (api *PublicExplorerAPI) Votes(number *rpc.BlockNumber) ([]*VoteInfo, error) {
+	snap, _, err := api.snapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]*VoteInfo, 0, len(snap.Votes))
+	for voter, vote := range snap.Votes {
+		votes = append(votes, newVoteInfo(vote, snap.Voters[voter]))
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Voter.Big().Cmp(votes[j].Voter.Big()) < 0
+	})
+	return votes, nil
+}
+
+// MissedSlots returns the decaying count of missed slots per signer at the given block.
+func (api *PublicExplorerAPI) MissedSlots(number *rpc.BlockNumber) (map[common.Address]uint64, error) {
+	snap, _, err := api.snapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	missed := make(map[common.Address]uint64, len(snap.Punished))
+	for signer, count := range snap.Punished {
+		missed[signer] = count
+	}
+	return missed, nil
+}
+
+// VoterStatus returns whether the account's balance at the given block reaches
+// MinVoterBalance, together with its active vote.
+func (api *PublicExplorerAPI) VoterStatus(address common.Address, number *rpc.BlockNumber) (*VoterStatus, error) {
+	snap, header, err := api.snapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		balance = statedb.GetBalance(address)
+		min     = api.alien.config.MinVoterBalance
+	)
+	status := &VoterStatus{
+		Address:         address,
+		Balance:         (*hexutil.Big)(balance),
+		MinVoterBalance: (*hexutil.Big)(new(big.Int).Set(min)),
+		Eligible:        balance.Cmp(min) >= 0,
+	}
+	if vote, ok := snap.Votes[address]; ok {
+		status.Vote = newVoteInfo(vote, snap.Voters[address])
+	}
+	return status, nil
+}
+
+// SignersChanged creates a subscription that fires with the new signer queue
+// each time the signer set changes.
+func (api *PublicExplorerAPI) SignersChanged(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+		headSub := api.chain.SubscribeChainHeadEvent(heads)
+		defer headSub.Unsubscribe()
+
+		var last []common.Address
+		if snap, _, err := api.snapshot(nil); err == nil {
+			last = snap.Signers
+		}
+		for {
+			select {
+			case ev := <-heads:
+				header := ev.Block.Header()
+				snap, err := api.alien.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+				if err != nil || sameSigners(last, snap.Signers) {
+					continue
+				}
+				last = snap.Signers
+				notifier.Notify(rpcSub.ID, api.signerQueue(snap))
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// newVoteInfo converts a snapshot vote for the API.
+func newVoteInfo(vote *Vote, number uint64) *VoteInfo {
+	return &VoteInfo{
+		Voter:     vote.Voter,
+		Candidate: vote.Candidate,
+		Stake:     (*hexutil.Big)(new(big.Int).Set(vote.Stake)),
+		Number:    number,
+	}
+}
+
+// sameSigners reports whether two signer queues contain the same signers,
+// regardless of their order.
+func sameSigners(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[common.Address]int, len(a))
+	for _, signer := range a {
+		set[signer]++
+	}
+	for _, signer := range b {
+		if set[signer]--; set[signer] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/p2p"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rpc"
+)
+
+// FakeMainChain is an in-process MainChainBackend for testing side chains. It is
+// also a node.Service serving the main chain RPC methods side chains use, so it
+// can run as main chain node of a p2p/simulations network. Its head only moves
+// when advanced explicitly.
+type FakeMainChain struct {
+	number      uint64
+	hash        common.Hash
+	api         *PrivateMainChainAPI
+	checkpoints []*Checkpoint
+	lock        sync.RWMutex
+}
+
+// NewFakeMainChain creates a fake main chain with its head at block zero,
+// accepting the checkpoints of the given side chains.
+func NewFakeMainChain(sideChains ...params.AlienSideChain) *FakeMainChain {
+	return &FakeMainChain{
+		hash: fakeMainChainHash(0),
+		api:  NewPrivateMainChainAPI(ethdb.NewMemDatabase(), sideChains),
+	}
+}
+
+// fakeMainChainHash derives a deterministic block hash from the block number.
+func fakeMainChainHash(number uint64) common.Hash {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return crypto.Keccak256Hash([]byte("fake main chain"), enc)
+}
+
+// Advance moves the head of the fake main chain forward by one block and
+// returns the new head.
+func (f *FakeMainChain) Advance() (uint64, common.Hash) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.number++
+	f.hash = fakeMainChainHash(f.number)
+	return f.number, f.hash
+}
+
+// CurrentHead implements MainChainBackend.
+func (f *FakeMainChain) CurrentHead(ctx context.Context) (uint64, common.Hash, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.number, f.hash, nil
+}
+
+// BlockHash implements MainChainBackend.
+func (f *FakeMainChain) BlockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if number > f.number {
+		return common.Hash{}, errUnknownBlock
+	}
+	return fakeMainChainHash(number), nil
+}
+
+// SubmitCheckpoint implements MainChainBackend, recording the checkpoint if the
+// main chain API accepts it.
+func (f *FakeMainChain) SubmitCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	if err := f.api.SubmitCheckpoint(*checkpoint); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	cpy := *checkpoint
+	f.checkpoints = append(f.checkpoints, &cpy)
+	return nil
+}
+
+// Checkpoints returns the checkpoints submitted so far, in submission order.
+func (f *FakeMainChain) Checkpoints() []*Checkpoint {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return append([]*Checkpoint(nil), f.checkpoints...)
+}
+
+// Protocols implements node.Service, the fake main chain runs no protocols.
+func (f *FakeMainChain) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning the RPC methods side chains call on
+// their main chain.
+func (f *FakeMainChain) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "dsp",
+			Version:   "1.0",
+			Service:   &fakeMainChainAPI{f},
+			Public:    true,
+		}, {
+			Namespace: "alien",
+			Version:   "1.0",
+			Service:   &fakeCheckpointAPI{f},
+		},
+	}
+}
+
+// Start implements node.Service.
+func (f *FakeMainChain) Start(server *p2p.Server) error { return nil }
+
+// Stop implements node.Service.
+func (f *FakeMainChain) Stop() error { return nil }
+
+// fakeMainChainAPI serves the blocks of the fake main chain.
+type fakeMainChainAPI struct {
+	chain *FakeMainChain
+}
+
+// GetBlockByNumber returns the number and hash of a main chain block, or nil if
+// the block does not exist yet.
+func (api *fakeMainChainAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	head, _, _ := api.chain.CurrentHead(ctx)
+	if number >= 0 {
+		head = uint64(number)
+	}
+	hash, err := api.chain.BlockHash(ctx, head)
+	if err != nil {
+		return nil, nil
+	}
+	return map[string]interface{}{"number": (*hexutil.Big)(new(big.Int).SetUint64(head)), "hash": hash}, nil
+}
+
+// fakeCheckpointAPI accepts checkpoints into the fake main chain.
+type fakeCheckpointAPI struct {
+	chain *FakeMainChain
+}
+
+// SubmitCheckpoint records a checkpoint, see PrivateMainChainAPI.
+func (api *fakeCheckpointAPI) SubmitCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	return api.chain.SubmitCheckpoint(ctx, &checkpoint)
+}
+
+// GetCheckpoint retrieves a checkpoint, see PrivateMainChainAPI.
+func (api *fakeCheckpointAPI) GetCheckpoint(sideChain common.Hash, number hexutil.Uint64) (*Checkpoint, error) {
+	return api.chain.api.GetCheckpoint(sideChain, number)
+}
@@ -0,0 +1,405 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/accounts"
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/crypto/sha3"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rlp"
+	"github.com/dsplinz2019/dsplinz/rpc"
+)
+
+const (
+	// mainChainTimeout bounds the time a side chain sealer waits for the main chain.
+	mainChainTimeout = 2 * time.Second
+
+	// mainChainConfirmations is the number of main chain blocks built on top of an
+	// anchored block before its hash is trusted to verify side chain blocks with.
+	mainChainConfirmations = 12
+
+	// inmemoryAnchors is the number of confirmed main chain block hashes to keep
+	// in memory for verifying the anchors of side chain blocks.
+	inmemoryAnchors = 1024
+
+	// maxAnchorLookups is the maximum number of main chain blocks looked up in the
+	// background at once.
+	maxAnchorLookups = 16
+
+	// defaultCheckpointInterval is the number of side chain blocks after which a
+	// checkpoint is submitted to the main chain, unless configured otherwise.
+	defaultCheckpointInterval = 64
+)
+
+var (
+	// errInvalidCheckpoint is returned if the signature of a checkpoint does not
+	// match the signer it claims.
+	errInvalidCheckpoint = errors.New("invalid checkpoint signature")
+
+	// errUnknownCheckpoint is returned if no checkpoint was recorded for the
+	// requested side chain block.
+	errUnknownCheckpoint = errors.New("unknown checkpoint")
+
+	// errUnknownSideChain is returned if a checkpoint is submitted for a side
+	// chain the main chain is not configured to anchor.
+	errUnknownSideChain = errors.New("unknown side chain")
+
+	// errUnauthorizedCheckpoint is returned if a checkpoint is signed by someone
+	// not among the authorized signers of its side chain.
+	errUnauthorizedCheckpoint = errors.New("unauthorized checkpoint signer")
+
+	// errCheckpointExists is returned if a checkpoint was already recorded for
+	// the side chain block.
+	errCheckpointExists = errors.New("checkpoint already recorded")
+
+	// errInvalidMainChainAnchor is returned if the main chain block recorded in
+	// a side chain block conflicts with a confirmed main chain block, or if a
+	// block not on a side chain records one.
+	errInvalidMainChainAnchor = errors.New("invalid main chain anchor")
+
+	// checkpointPrefix prefixes the main chain database keys of side chain checkpoints.
+	checkpointPrefix = []byte("alien-checkpoint-")
+)
+
+// MainChainBackend is the connection of a side chain to its main chain.
+type MainChainBackend interface {
+	// CurrentHead returns the number and hash of the main chain head, which side
+	// chain sealers record in their blocks.
+	CurrentHead(ctx context.Context) (uint64, common.Hash, error)
+
+	// BlockHash returns the hash of the main chain block with the given number,
+	// which side chain nodes verify the recorded anchors against.
+	BlockHash(ctx context.Context, number uint64) (common.Hash, error)
+
+	// SubmitCheckpoint records a signed side chain header on the main chain.
+	SubmitCheckpoint(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+// Checkpoint is a side chain header anchored on the main chain, signed by the
+// side chain signer that sealed it.
+type Checkpoint struct {
+	SideChain common.Hash    `json:"sideChain"` // Genesis hash identifying the side chain
+	Number    uint64         `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// SigHash returns the hash to be signed by the side chain signer.
+func (c *Checkpoint) SigHash() (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+	rlp.Encode(hasher, []interface{}{c.SideChain, c.Number, c.Hash})
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// Verify checks that the checkpoint was signed by its signer.
+func (c *Checkpoint) Verify() error {
+	if len(c.Signature) != extraSeal {
+		return errInvalidCheckpoint
+	}
+	pubkey, err := crypto.Ecrecover(c.SigHash().Bytes(), c.Signature)
+	if err != nil {
+		return errInvalidCheckpoint
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	if signer != c.Signer {
+		return errInvalidCheckpoint
+	}
+	return nil
+}
+
+// SetMainChain sets the main chain a side chain anchors its blocks to. It has no
+// effect unless the engine is configured as side chain.
+func (a *Alien) SetMainChain(backend MainChainBackend) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.mainChain = backend
+}
+
+// mainChainBackend returns the main chain backend, if any.
+func (a *Alien) mainChainBackend() MainChainBackend {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.mainChain
+}
+
+// mainChainAnchor returns the number and hash of the main chain head, which side
+// chain sealers record in their blocks. Without a main chain backend, or if the
+// main chain is unreachable, an empty anchor is returned.
+func (a *Alien) mainChainAnchor() (uint64, common.Hash) {
+	backend := a.mainChainBackend()
+	if backend == nil {
+		return 0, common.Hash{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mainChainTimeout)
+	defer cancel()
+
+	number, hash, err := backend.CurrentHead(ctx)
+	if err != nil {
+		log.Warn("Failed to retrieve main chain head", "err", err)
+		return 0, common.Hash{}
+	}
+	return number, hash
+}
+
+// verifyMainChainAnchor checks the main chain block recorded in a side chain
+// block against the confirmed main chain blocks known locally. Anchors not known
+// yet are looked up in the background and accepted, a block is only rejected if
+// its anchor conflicts with a confirmed main chain block. Blocks without an
+// anchor are accepted, their sealer could not reach the main chain.
+func (a *Alien) verifyMainChainAnchor(number uint64, hash common.Hash) error {
+	if number == 0 && hash == (common.Hash{}) {
+		return nil
+	}
+	if !a.config.SideChain {
+		return errInvalidMainChainAnchor
+	}
+	if canonical, ok := a.anchors.Get(number); ok {
+		if canonical.(common.Hash) != hash {
+			return errInvalidMainChainAnchor
+		}
+		return nil
+	}
+	a.lookupMainChainAnchor(number, hash)
+	return nil
+}
+
+// lookupMainChainAnchor retrieves the main chain block of an anchor in the
+// background, unless no main chain is connected or too many lookups are pending.
+func (a *Alien) lookupMainChainAnchor(number uint64, hash common.Hash) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.mainChain == nil || a.anchorLookups[number] || len(a.anchorLookups) >= maxAnchorLookups {
+		return
+	}
+	a.anchorLookups[number] = true
+
+	go func(backend MainChainBackend) {
+		a.fetchMainChainAnchor(backend, number, hash)
+
+		a.lock.Lock()
+		delete(a.anchorLookups, number)
+		a.lock.Unlock()
+	}(a.mainChain)
+}
+
+// fetchMainChainAnchor retrieves the hash of a main chain block and caches it
+// once enough blocks were built on top of it not to expect a reorg any more.
+func (a *Alien) fetchMainChainAnchor(backend MainChainBackend, number uint64, hash common.Hash) {
+	ctx, cancel := context.WithTimeout(context.Background(), mainChainTimeout)
+	defer cancel()
+
+	canonical, err := backend.BlockHash(ctx, number)
+	if err != nil {
+		log.Debug("Failed to retrieve main chain block", "number", number, "err", err)
+		return
+	}
+	head, _, err := backend.CurrentHead(ctx)
+	if err != nil || head < number+mainChainConfirmations {
+		return
+	}
+	a.anchors.Add(number, canonical)
+
+	if canonical != hash {
+		log.Warn("Side chain block anchored to non-canonical main chain block", "number", number, "anchor", hash, "canonical", canonical)
+	}
+}
+
+// checkpointDue reports whether the sealed side chain block should be anchored
+// on the main chain.
+func (a *Alien) checkpointDue(number uint64) bool {
+	if !a.config.SideChain || a.mainChainBackend() == nil {
+		return false
+	}
+	interval := a.config.CheckpointInterval
+	if interval == 0 {
+		interval = defaultCheckpointInterval
+	}
+	return number%interval == 0
+}
+
+// submitCheckpoint signs the sealed side chain header with the local signing
+// credentials and submits it to the main chain.
+func (a *Alien) submitCheckpoint(chain consensus.ChainReader, header *types.Header) error {
+	backend := a.mainChainBackend()
+	if backend == nil {
+		return nil
+	}
+	genesis := chain.GetHeaderByNumber(0)
+	if genesis == nil {
+		return errUnknownBlock
+	}
+	a.lock.RLock()
+	signer, signFn := a.signer, a.signFn
+	a.lock.RUnlock()
+
+	if signFn == nil {
+		return errUnauthorizedSigner
+	}
+	checkpoint := &Checkpoint{
+		SideChain: genesis.Hash(),
+		Number:    header.Number.Uint64(),
+		Hash:      header.Hash(),
+		Signer:    signer,
+	}
+	sig, err := signFn(accounts.Account{Address: signer}, checkpoint.SigHash().Bytes())
+	if err != nil {
+		return err
+	}
+	checkpoint.Signature = sig
+
+	ctx, cancel := context.WithTimeout(context.Background(), mainChainTimeout)
+	defer cancel()
+
+	return backend.SubmitCheckpoint(ctx, checkpoint)
+}
+
+// checkpointKey = checkpointPrefix + side chain + number (uint64 big endian)
+func checkpointKey(sideChain common.Hash, number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return append(append(append([]byte{}, checkpointPrefix...), sideChain[:]...), enc...)
+}
+
+// rpcMainChain is a MainChainBackend talking to a main chain node over RPC.
+type rpcMainChain struct {
+	client *rpc.Client
+}
+
+// NewRPCMainChain creates a main chain backend using the given RPC client.
+func NewRPCMainChain(client *rpc.Client) MainChainBackend {
+	return &rpcMainChain{client: client}
+}
+
+// CurrentHead implements MainChainBackend.
+func (m *rpcMainChain) CurrentHead(ctx context.Context) (uint64, common.Hash, error) {
+	var head struct {
+		Number *hexutil.Big `json:"number"`
+		Hash   common.Hash  `json:"hash"`
+	}
+	if err := m.client.CallContext(ctx, &head, "dsp_getBlockByNumber", "latest", false); err != nil {
+		return 0, common.Hash{}, err
+	}
+	if head.Number == nil {
+		return 0, common.Hash{}, errUnknownBlock
+	}
+	return head.Number.ToInt().Uint64(), head.Hash, nil
+}
+
+// BlockHash implements MainChainBackend.
+func (m *rpcMainChain) BlockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	var block struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	if err := m.client.CallContext(ctx, &block, "dsp_getBlockByNumber", hexutil.Uint64(number), false); err != nil {
+		return common.Hash{}, err
+	}
+	if block.Hash == nil {
+		return common.Hash{}, errUnknownBlock
+	}
+	return *block.Hash, nil
+}
+
+// SubmitCheckpoint implements MainChainBackend.
+func (m *rpcMainChain) SubmitCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	return m.client.CallContext(ctx, nil, "alien_submitCheckpoint", checkpoint)
+}
+
+// PrivateMainChainAPI is the main chain side of the side chain anchoring,
+// recording the checkpoints submitted by the authorized side chain signers. It
+// is not public, side chains need the "alien" namespace enabled explicitly.
+type PrivateMainChainAPI struct {
+	db      ethdb.Database
+	signers map[common.Hash]map[common.Address]bool // Authorized checkpoint signers per side chain
+	lock    sync.Mutex                              // Protects the check and write of checkpoints
+}
+
+// NewPrivateMainChainAPI creates a new API storing the checkpoints of the given
+// side chains in db.
+func NewPrivateMainChainAPI(db ethdb.Database, sideChains []params.AlienSideChain) *PrivateMainChainAPI {
+	signers := make(map[common.Hash]map[common.Address]bool)
+	for _, sideChain := range sideChains {
+		if signers[sideChain.Genesis] == nil {
+			signers[sideChain.Genesis] = make(map[common.Address]bool)
+		}
+		for _, signer := range sideChain.Signers {
+			signers[sideChain.Genesis][signer] = true
+		}
+	}
+	return &PrivateMainChainAPI{db: db, signers: signers}
+}
+
+// SubmitCheckpoint records a checkpoint signed by an authorized signer of its
+// side chain. Recorded checkpoints are never overwritten.
+func (api *PrivateMainChainAPI) SubmitCheckpoint(checkpoint Checkpoint) error {
+	signers, ok := api.signers[checkpoint.SideChain]
+	if !ok {
+		return errUnknownSideChain
+	}
+	if !signers[checkpoint.Signer] {
+		return errUnauthorizedCheckpoint
+	}
+	if err := checkpoint.Verify(); err != nil {
+		return err
+	}
+	blob, err := rlp.EncodeToBytes(&checkpoint)
+	if err != nil {
+		return err
+	}
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	key := checkpointKey(checkpoint.SideChain, checkpoint.Number)
+	if has, err := api.db.Has(key); err != nil {
+		return err
+	} else if has {
+		return errCheckpointExists
+	}
+	return api.db.Put(key, blob)
+}
+
+// GetCheckpoint retrieves the checkpoint of a side chain block.
+func (api *PrivateMainChainAPI) GetCheckpoint(sideChain common.Hash, number hexutil.Uint64) (*Checkpoint, error) {
+	blob, err := api.db.Get(checkpointKey(sideChain, uint64(number)))
+	if err != nil || len(blob) == 0 {
+		return nil, errUnknownCheckpoint
+	}
+	checkpoint := new(Checkpoint)
+	if err := rlp.DecodeBytes(blob, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
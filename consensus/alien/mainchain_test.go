@@ -0,0 +1,97 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
+	"github.com/dsplinz2019/dsplinz/node"
+	"github.com/dsplinz2019/dsplinz/p2p/simulations"
+	"github.com/dsplinz2019/dsplinz/p2p/simulations/adapters"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rlp"
+)
+
+// Tests that a side chain anchors its blocks over RPC to the fake main chain
+// running as node of a p2p/simulations network.
+func TestSideChainSimulation(t *testing.T) {
+	accounts := newTesterAccountPool()
+	config := testAlienConfig(accounts, "A", "B")
+	config.SideChain = true
+
+	tc := newTesterChain(t, accounts, config, fund(accounts, map[string]int64{"A": 10, "B": 20}))
+	defer tc.chain.Stop()
+
+	// Boot the main chain as simulated node and connect the side chain to it
+	mainchain := NewFakeMainChain(params.AlienSideChain{
+		Genesis: tc.chain.Genesis().Hash(),
+		Signers: []common.Address{accounts.address("A"), accounts.address("B")},
+	})
+	adapter := adapters.NewSimAdapter(adapters.Services{
+		"mainchain": func(ctx *adapters.ServiceContext) (node.Service, error) {
+			return mainchain, nil
+		},
+	})
+	network := simulations.NewNetwork(adapter, &simulations.NetworkConfig{DefaultService: "mainchain"})
+	defer network.Shutdown()
+
+	sim, err := network.NewNode()
+	if err != nil {
+		t.Fatalf("failed to create main chain node: %v", err)
+	}
+	if err := network.Start(sim.ID()); err != nil {
+		t.Fatalf("failed to start main chain node: %v", err)
+	}
+	client, err := sim.Client()
+	if err != nil {
+		t.Fatalf("failed to connect to main chain node: %v", err)
+	}
+	tc.engine.SetMainChain(NewRPCMainChain(client))
+
+	// Sealed blocks record the main chain head, importing verifies it again
+	number, hash := mainchain.Advance()
+	block := tc.extend(0, nil)
+
+	extra := block.Extra()
+	var headerExtra HeaderExtra
+	if err := rlp.DecodeBytes(extra[extraVanity:len(extra)-extraSeal], &headerExtra); err != nil {
+		t.Fatalf("failed to decode header extra: %v", err)
+	}
+	if headerExtra.MainChainNumber != number || headerExtra.MainChainHash != hash {
+		t.Fatalf("anchor mismatch: have %d/%x, want %d/%x", headerExtra.MainChainNumber, headerExtra.MainChainHash, number, hash)
+	}
+	// Checkpoints are recorded by the main chain node exactly once
+	tc.engine.Authorize(block.Coinbase(), accounts.signFn, nil)
+	if err := tc.engine.submitCheckpoint(tc.chain, block.Header()); err != nil {
+		t.Fatalf("failed to submit checkpoint: %v", err)
+	}
+	var stored *Checkpoint
+	if err := client.Call(&stored, "alien_getCheckpoint", tc.chain.Genesis().Hash(), hexutil.Uint64(block.NumberU64())); err != nil {
+		t.Fatalf("failed to retrieve checkpoint: %v", err)
+	}
+	if stored == nil || stored.Hash != block.Hash() || stored.Signer != block.Coinbase() {
+		t.Fatalf("stored checkpoint mismatch: %+v", stored)
+	}
+	if err := tc.engine.submitCheckpoint(tc.chain, block.Header()); err == nil || err.Error() != errCheckpointExists.Error() {
+		t.Fatalf("resubmitted checkpoint error mismatch: have %v, want %v", err, errCheckpointExists)
+	}
+	if checkpoints := mainchain.Checkpoints(); len(checkpoints) != 1 {
+		t.Fatalf("checkpoint count mismatch: have %d, want %d", len(checkpoints), 1)
+	}
+}
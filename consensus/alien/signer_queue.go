@@ -0,0 +1,91 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/crypto"
+)
+
+type tallyItem struct {
+	addr  common.Address
+	stake *big.Int
+}
+
+type tallySlice []tallyItem
+
+func (s tallySlice) Len() int      { return len(s) }
+func (s tallySlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s tallySlice) Less(i, j int) bool {
+	if cmp := s[i].stake.Cmp(s[j].stake); cmp != 0 {
+		return cmp > 0
+	}
+	return bytes.Compare(s[i].addr[:], s[j].addr[:]) < 0
+}
+
+// candidates returns the candidates eligible for the next signer queue, sorted
+// by their balance weighted tally.
+func (s *Snapshot) candidates(number *big.Int) tallySlice {
+	var tally tallySlice
+	for candidate, stake := range s.Tally {
+		if stake.Sign() <= 0 {
+			continue
+		}
+		if s.config.IsTrantor(number) && s.Punished[candidate] >= punishThreshold {
+			continue
+		}
+		tally = append(tally, tallyItem{candidate, stake})
+	}
+	sort.Sort(tally)
+	return tally
+}
+
+// createSignerQueue elects the signers of the next loop: the candidates with the
+// highest tallies, up to MaxSignerCount, shuffled by the given seed. Side chains
+// and chains without any eligible candidate fall back to the self vote signers.
+func (s *Snapshot) createSignerQueue(number *big.Int, seed common.Hash) []common.Address {
+	var queue []common.Address
+	if !s.config.SideChain {
+		for _, item := range s.candidates(number) {
+			if uint64(len(queue)) >= s.config.MaxSignerCount {
+				break
+			}
+			queue = append(queue, item.addr)
+		}
+	}
+	if len(queue) == 0 {
+		for _, signer := range s.config.SelfVoteSigners {
+			if uint64(len(queue)) >= s.config.MaxSignerCount {
+				break
+			}
+			queue = append(queue, common.Address(signer))
+		}
+	}
+	// Fisher-Yates shuffle driven by a hash chain, independent of math/rand
+	for i := len(queue) - 1; i > 0; i-- {
+		var index [8]byte
+		binary.BigEndian.PutUint64(index[:], uint64(i))
+		j := binary.BigEndian.Uint64(crypto.Keccak256(seed[:], index[:])[:8]) % uint64(i+1)
+		queue[i], queue[j] = queue[j], queue[i]
+	}
+	return queue
+}
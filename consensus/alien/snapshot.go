@@ -0,0 +1,461 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/params"
+	"github.com/dsplinz2019/dsplinz/rlp"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	// punishThreshold is the number of missed slots after which a candidate is
+	// no longer elected into the signer queue (active from the Trantor fork).
+	punishThreshold = 10
+)
+
+// Snapshot is the state of the voting and the signer queue at a given point in time.
+type Snapshot struct {
+	config   *params.AlienConfig // Consensus engine parameters to fine tune behavior
+	sigcache *lru.ARCCache       // Cache of recent block signatures to speed up ecrecover
+
+	Number        uint64                      `json:"number"`        // Block number where the snapshot was created
+	Hash          common.Hash                 `json:"hash"`          // Block hash where the snapshot was created
+	LoopStartTime uint64                      `json:"loopStartTime"` // Timestamp of the first slot of the current loop
+	Signers       []common.Address            `json:"signers"`       // Signer queue of the current loop, one slot each
+	Votes         map[common.Address]*Vote    `json:"votes"`         // Active vote of each voter
+	Voters        map[common.Address]uint64   `json:"voters"`        // Block number each voter last voted at
+	Tally         map[common.Address]*big.Int `json:"tally"`         // Balance weighted votes of each candidate
+	Punished      map[common.Address]uint64   `json:"punished"`      // Decaying count of missed slots per signer
+	HistoryHash   []common.Hash               `json:"historyHash"`   // Hashes of the most recent blocks
+}
+
+// newGenesisSnapshot creates the snapshot of the genesis block, in which every
+// self vote signer votes for itself with the given stake.
+func newGenesisSnapshot(config *params.AlienConfig, sigcache *lru.ARCCache, genesis *types.Header, stakes map[common.Address]*big.Int) *Snapshot {
+	snap := &Snapshot{
+		config:        config,
+		sigcache:      sigcache,
+		Number:        0,
+		Hash:          genesis.Hash(),
+		LoopStartTime: config.GenesisTimestamp,
+		Votes:         make(map[common.Address]*Vote),
+		Voters:        make(map[common.Address]uint64),
+		Tally:         make(map[common.Address]*big.Int),
+		Punished:      make(map[common.Address]uint64),
+		HistoryHash:   []common.Hash{genesis.Hash()},
+	}
+	if snap.LoopStartTime == 0 {
+		snap.LoopStartTime = genesis.Time.Uint64() + config.Period
+	}
+	for _, unprefixed := range config.SelfVoteSigners {
+		signer := common.Address(unprefixed)
+		stake, ok := stakes[signer]
+		if !ok || stake == nil {
+			stake = new(big.Int).Set(config.MinVoterBalance)
+		}
+		if uint64(len(snap.Signers)) < config.MaxSignerCount {
+			snap.Signers = append(snap.Signers, signer)
+		}
+		snap.Votes[signer] = &Vote{Voter: signer, Candidate: signer, Stake: new(big.Int).Set(stake)}
+		snap.Voters[signer] = 0
+		snap.Tally[signer] = new(big.Int).Set(stake)
+	}
+	return snap
+}
+
+// loadSnapshot loads an existing snapshot from the database.
+func loadSnapshot(config *params.AlienConfig, sigcache *lru.ARCCache, db ethdb.Database, hash common.Hash) (*Snapshot, error) {
+	blob, err := db.Get(append([]byte("alien-"), hash[:]...))
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	snap.config = config
+	snap.sigcache = sigcache
+
+	return snap, nil
+}
+
+// store inserts the snapshot into the database.
+func (s *Snapshot) store(db ethdb.Database) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(append([]byte("alien-"), s.Hash[:]...), blob)
+}
+
+// copy creates a deep copy of the snapshot.
+func (s *Snapshot) copy() *Snapshot {
+	cpy := &Snapshot{
+		config:        s.config,
+		sigcache:      s.sigcache,
+		Number:        s.Number,
+		Hash:          s.Hash,
+		LoopStartTime: s.LoopStartTime,
+		Signers:       make([]common.Address, len(s.Signers)),
+		Votes:         make(map[common.Address]*Vote),
+		Voters:        make(map[common.Address]uint64),
+		Tally:         make(map[common.Address]*big.Int),
+		Punished:      make(map[common.Address]uint64),
+		HistoryHash:   make([]common.Hash, len(s.HistoryHash)),
+	}
+	copy(cpy.Signers, s.Signers)
+	copy(cpy.HistoryHash, s.HistoryHash)
+
+	for voter, vote := range s.Votes {
+		cpy.Votes[voter] = &Vote{Voter: vote.Voter, Candidate: vote.Candidate, Stake: new(big.Int).Set(vote.Stake)}
+	}
+	for voter, number := range s.Voters {
+		cpy.Voters[voter] = number
+	}
+	for candidate, tally := range s.Tally {
+		cpy.Tally[candidate] = new(big.Int).Set(tally)
+	}
+	for signer, count := range s.Punished {
+		cpy.Punished[signer] = count
+	}
+	return cpy
+}
+
+// apply creates a new authorization snapshot by applying the given headers to
+// the original one.
+func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+	// Allow passing in no headers for cleaner code
+	if len(headers) == 0 {
+		return s, nil
+	}
+	// Sanity check that the headers can be applied
+	for i := 0; i < len(headers)-1; i++ {
+		if headers[i+1].Number.Uint64() != headers[i].Number.Uint64()+1 {
+			return nil, errInvalidVotingChain
+		}
+	}
+	if headers[0].Number.Uint64() != s.Number+1 {
+		return nil, errInvalidVotingChain
+	}
+	// Iterate through the headers and create a new snapshot
+	snap := s.copy()
+
+	for _, header := range headers {
+		if len(header.Extra) < extraVanity+extraSeal {
+			return nil, errMissingSignature
+		}
+		var headerExtra HeaderExtra
+		if err := rlp.DecodeBytes(header.Extra[extraVanity:len(header.Extra)-extraSeal], &headerExtra); err != nil {
+			return nil, errInvalidHeaderExtra
+		}
+		snap.applyHeaderExtra(header.Number, &headerExtra)
+		if len(headerExtra.SignerQueue) > 0 {
+			snap.rotate(&headerExtra)
+		}
+		snap.LoopStartTime = headerExtra.LoopStartTime
+
+		snap.Number = header.Number.Uint64()
+		snap.Hash = header.Hash()
+		snap.HistoryHash = append(snap.HistoryHash, snap.Hash)
+		if limit := int(2 * s.config.MaxSignerCount); len(snap.HistoryHash) > limit {
+			snap.HistoryHash = snap.HistoryHash[len(snap.HistoryHash)-limit:]
+		}
+	}
+	return snap, nil
+}
+
+// applyHeaderExtra updates the votes, stakes and missed slots of
+// the snapshot with the consensus data of a single block. The signer queue is
+// left untouched.
+func (s *Snapshot) applyHeaderExtra(number *big.Int, headerExtra *HeaderExtra) {
+	// Drop the votes that have not been renewed within an epoch
+	for voter, voted := range s.Voters {
+		if number.Uint64()-voted > s.config.Epoch {
+			s.removeVote(voter)
+		}
+	}
+	for _, vote := range headerExtra.CurrentBlockVotes {
+		s.removeVote(vote.Voter)
+		s.addVote(number.Uint64(), vote)
+	}
+	for _, vote := range headerExtra.ModifyPredecessorVotes {
+		if old, ok := s.Votes[vote.Voter]; ok {
+			voted := s.Voters[vote.Voter]
+			s.removeVote(vote.Voter)
+			s.addVote(voted, &Vote{Voter: vote.Voter, Candidate: old.Candidate, Stake: vote.Stake})
+		}
+	}
+	for _, signer := range headerExtra.SignerMissing {
+		s.Punished[signer]++
+	}
+}
+
+// rotate switches the snapshot over to the signer queue of a new loop.
+func (s *Snapshot) rotate(headerExtra *HeaderExtra) {
+	s.Signers = append([]common.Address(nil), headerExtra.SignerQueue...)
+	for signer, count := range s.Punished {
+		if count /= 2; count == 0 {
+			delete(s.Punished, signer)
+		} else {
+			s.Punished[signer] = count
+		}
+	}
+}
+
+// addVote records a vote, ignoring it if the stake is below the minimum voter balance.
+func (s *Snapshot) addVote(number uint64, vote *Vote) {
+	if vote.Stake == nil || vote.Stake.Cmp(s.config.MinVoterBalance) < 0 {
+		return
+	}
+	s.Votes[vote.Voter] = &Vote{Voter: vote.Voter, Candidate: vote.Candidate, Stake: new(big.Int).Set(vote.Stake)}
+	s.Voters[vote.Voter] = number
+
+	if tally, ok := s.Tally[vote.Candidate]; ok {
+		tally.Add(tally, vote.Stake)
+	} else {
+		s.Tally[vote.Candidate] = new(big.Int).Set(vote.Stake)
+	}
+}
+
+// removeVote withdraws the active vote of a voter, if any.
+func (s *Snapshot) removeVote(voter common.Address) {
+	vote, ok := s.Votes[voter]
+	if !ok {
+		return
+	}
+	if tally, ok := s.Tally[vote.Candidate]; ok {
+		tally.Sub(tally, vote.Stake)
+		if tally.Sign() <= 0 {
+			delete(s.Tally, vote.Candidate)
+		}
+	}
+	delete(s.Votes, voter)
+	delete(s.Voters, voter)
+}
+
+// isSigner checks whether the address is in the current signer queue.
+func (s *Snapshot) isSigner(address common.Address) bool {
+	for _, signer := range s.Signers {
+		if signer == address {
+			return true
+		}
+	}
+	return false
+}
+
+// slot returns the index of the slot the given timestamp falls into, counted
+// from the start of the current loop.
+func (s *Snapshot) slot(timestamp uint64) (uint64, error) {
+	if timestamp < s.LoopStartTime {
+		return 0, errGenesisNotAligned
+	}
+	if (timestamp-s.LoopStartTime)%s.config.Period != 0 {
+		return 0, errUnalignedTimestamp
+	}
+	return (timestamp - s.LoopStartTime) / s.config.Period, nil
+}
+
+// inturn returns the signer owning the slot of the given timestamp.
+func (s *Snapshot) inturn(timestamp uint64) (common.Address, error) {
+	if len(s.Signers) == 0 {
+		return common.Address{}, errUnauthorizedSigner
+	}
+	slot, err := s.slot(timestamp)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return s.Signers[slot%uint64(len(s.Signers))], nil
+}
+
+// verifyInturn checks that the signer owns the slot of the given timestamp.
+func (s *Snapshot) verifyInturn(signer common.Address, timestamp uint64) error {
+	if !s.isSigner(signer) {
+		return errUnauthorizedSigner
+	}
+	expected, err := s.inturn(timestamp)
+	if err != nil {
+		return err
+	}
+	if expected != signer {
+		return errNotInTurn
+	}
+	return nil
+}
+
+// nextSlot returns the timestamp of the first slot not earlier than the given time.
+func (s *Snapshot) nextSlot(earliest uint64) uint64 {
+	if earliest <= s.LoopStartTime {
+		return s.LoopStartTime
+	}
+	offset := earliest - s.LoopStartTime
+	if rem := offset % s.config.Period; rem != 0 {
+		offset += s.config.Period - rem
+	}
+	return s.LoopStartTime + offset
+}
+
+// missingSigners returns the signers whose slots passed without a block between
+// the parent and the given timestamp.
+func (s *Snapshot) missingSigners(parentTime, timestamp uint64) []common.Address {
+	if len(s.Signers) == 0 {
+		return nil
+	}
+	last, err := s.slot(timestamp)
+	if err != nil {
+		return nil
+	}
+	var first uint64
+	if parent, err := s.slot(parentTime); err == nil {
+		first = parent + 1
+	}
+	// Cap the report to a single loop, a stalled chain should not punish forever
+	if count := uint64(len(s.Signers)); last > first+count {
+		first = last - count
+	}
+	var missing []common.Address
+	for i := first; i < last; i++ {
+		missing = append(missing, s.Signers[i%uint64(len(s.Signers))])
+	}
+	return missing
+}
+
+// processLoop completes the consensus data of a block being finalized. If the
+// block fills the last slot of the loop, or any later one because the signers
+// of the last slots were absent, the signer queue of the next loop is elected
+// from the current tallies. The next loop starts at the slot following the block.
+func (s *Snapshot) processLoop(header *types.Header, headerExtra *HeaderExtra) error {
+	slot, err := s.slot(header.Time.Uint64())
+	if err != nil {
+		return err
+	}
+	next := s.copy()
+	next.applyHeaderExtra(header.Number, headerExtra)
+
+	headerExtra.LoopStartTime = s.LoopStartTime
+
+	if count := uint64(len(s.Signers)); count > 0 && slot >= count-1 {
+		headerExtra.LoopStartTime = s.LoopStartTime + (slot+1)*s.config.Period
+		headerExtra.SignerQueue = next.createSignerQueue(header.Number, header.ParentHash)
+	}
+	return nil
+}
+
+// snapshot retrieves the authorization snapshot at a given point in time.
+func (a *Alien) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or on disk for checkpoints
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := a.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// If an on-disk checkpoint snapshot can be found, use that
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(a.config, a.signatures, a.db, hash); err == nil {
+				log.Trace("Loaded alien snapshot from disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// If we're at the genesis, create the initial snapshot from the config
+		if number == 0 {
+			genesis := chain.GetHeaderByNumber(0)
+			if genesis == nil || genesis.Hash() != hash {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			snap = newGenesisSnapshot(a.config, a.signatures, genesis, a.genesisStakes(genesis))
+			if err := snap.store(a.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored genesis alien snapshot to disk")
+			break
+		}
+		// No snapshot for this header, gather the header and move backward
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	a.recents.Add(snap.Hash, snap)
+
+	// If we've generated a new checkpoint snapshot, save to disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(a.db); err != nil {
+			return nil, err
+		}
+		log.Trace("Stored alien snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// genesisStakes returns the balances of the self vote signers at genesis. Light
+// clients read them from the light config, full nodes from the genesis state.
+func (a *Alien) genesisStakes(genesis *types.Header) map[common.Address]*big.Int {
+	stakes := make(map[common.Address]*big.Int)
+	if a.config.LightConfig != nil {
+		for address, account := range a.config.LightConfig.Alloc {
+			if balance, ok := new(big.Int).SetString(account.Balance, 0); ok {
+				stakes[common.Address(address)] = balance
+			}
+		}
+		return stakes
+	}
+	statedb, err := state.New(genesis.Root, state.NewDatabase(a.db))
+	if err != nil {
+		log.Warn("Genesis state unavailable, using minimum voter balance", "err", err)
+		return stakes
+	}
+	for _, signer := range a.config.SelfVoteSigners {
+		stakes[common.Address(signer)] = statedb.GetBalance(common.Address(signer))
+	}
+	return stakes
+}
@@ -37,7 +37,7 @@ import (
 
 const (
 	testInstance = "console-tester"
-	testAddress  = hexutil.CustomHexPrefix + "8605cdbbdb6d264aa742e77020dcbc58fcdce182"
+	testAddress  = hexutil.HexPrefix() + "8605cdbbdb6d264aa742e77020dcbc58fcdce182"
 )
 
 // hookedPrompter implements UserPrompter to simulate use input via channels.
@@ -72,3 +72,31 @@ func TestInstructionIteratorEmpty(t *testing.T) {
 		t.Errorf("Expected 0, but got %v instead.", cnt)
 	}
 }
+
+// Tests that the Constantinople opcodes survive a compile and disassemble round trip
+func TestConstantinopleOpcodes(t *testing.T) {
+	compiler := NewCompiler(false)
+	compiler.Feed(Lex("test", []byte("push 1\npush 2\nshl\nextcodehash\ncreate2\n"), false))
+
+	bin, errs := compiler.Compile()
+	if len(errs) != 0 {
+		t.Fatalf("unexpected compile errors: %v", errs)
+	}
+	if bin != "600160021b3ff5" {
+		t.Fatalf("binary mismatch: have %s, want %s", bin, "600160021b3ff5")
+	}
+	script, _ := hex.DecodeString(bin)
+	instrs, err := Disassemble(script)
+	if err != nil {
+		t.Fatalf("failed to disassemble: %v", err)
+	}
+	want := []string{"000000: PUSH1 0x01\n", "000002: PUSH1 0x02\n", "000004: SHL\n", "000005: EXTCODEHASH\n", "000006: CREATE2\n"}
+	if len(instrs) != len(want) {
+		t.Fatalf("instruction count mismatch: have %d, want %d", len(instrs), len(want))
+	}
+	for i := range want {
+		if instrs[i] != want[i] {
+			t.Errorf("instruction %d mismatch: have %q, want %q", i, instrs[i], want[i])
+		}
+	}
+}
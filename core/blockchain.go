@@ -70,6 +70,7 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	TriesInMemory uint64        // Number of recent block states retained by the garbage collector (0 = default)
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -100,6 +101,7 @@ type BlockChain struct {
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
+	finalizedFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -111,6 +113,9 @@ type BlockChain struct {
 	currentBlock     atomic.Value // Current head of the block chain
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
+	currentFinalizedBlock atomic.Value // Highest canonical block confirmed by a two-thirds signer quorum
+	currentSafeBlock      atomic.Value // Highest canonical block confirmed by a signer majority
+
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
@@ -141,6 +146,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			TrieTimeLimit: 5 * time.Minute,
 		}
 	}
+	if cacheConfig.TriesInMemory == 0 {
+		config := *cacheConfig
+		config.TriesInMemory = triesInMemory
+		cacheConfig = &config
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -244,6 +254,19 @@ func (bc *BlockChain) loadLastState() error {
 		}
 	}
 
+	// Restore the last known finalized block, never beyond the head
+	finalized := bc.genesisBlock
+	if head := rawdb.ReadHeadFinalizedBlockHash(bc.db); head != (common.Hash{}) {
+		if block := bc.GetBlockByHash(head); block != nil {
+			finalized = block
+		}
+	}
+	if finalized.NumberU64() > currentBlock.NumberU64() || rawdb.ReadCanonicalHash(bc.db, finalized.NumberU64()) != finalized.Hash() {
+		finalized = currentBlock
+	}
+	bc.currentFinalizedBlock.Store(finalized)
+	bc.currentSafeBlock.Store(finalized)
+
 	// Issue a status log for the user
 	currentFastBlock := bc.CurrentFastBlock()
 
@@ -254,6 +277,7 @@ func (bc *BlockChain) loadLastState() error {
 	log.Info("Loaded most recent local header", "number", currentHeader.Number, "hash", currentHeader.Hash(), "td", headerTd)
 	log.Info("Loaded most recent local full block", "number", currentBlock.Number(), "hash", currentBlock.Hash(), "td", blockTd)
 	log.Info("Loaded most recent local fast block", "number", currentFastBlock.Number(), "hash", currentFastBlock.Hash(), "td", fastTd)
+	log.Info("Loaded most recent finalized block", "number", finalized.Number(), "hash", finalized.Hash())
 
 	return nil
 }
@@ -348,6 +372,101 @@ func (bc *BlockChain) CurrentFastBlock() *types.Block {
 	return bc.currentFastBlock.Load().(*types.Block)
 }
 
+// CurrentFinalizedBlock retrieves the highest canonical block confirmed by a
+// two-thirds signer quorum. Chains whose consensus engine does not support
+// confirmations never advance past the genesis block.
+func (bc *BlockChain) CurrentFinalizedBlock() *types.Block {
+	return bc.currentFinalizedBlock.Load().(*types.Block)
+}
+
+// CurrentSafeBlock retrieves the highest canonical block confirmed by a strict
+// majority of the signers. It is never below the finalized block.
+//
+// Honest signers confirm a single block per height, so two conflicting blocks
+// can only both gather a majority if some signer confirmed both of them: a safe
+// block is only reorged by an equivocating signer. Conflicting finalized blocks
+// would need more than a third of the signers to equivocate, which is beyond the
+// faults the two-thirds quorum tolerates, hence those are irreversible.
+func (bc *BlockChain) CurrentSafeBlock() *types.Block {
+	return bc.currentSafeBlock.Load().(*types.Block)
+}
+
+// updateFinality advances the safe and finalized blocks if the given canonical
+// block has collected enough signer confirmations.
+func (bc *BlockChain) updateFinality(header *types.Header, confirmations int) {
+	var (
+		hash   = header.Hash()
+		number = header.Number.Uint64()
+	)
+	bc.mu.Lock()
+	if rawdb.ReadCanonicalHash(bc.db, number) != hash {
+		bc.mu.Unlock()
+		return
+	}
+	block := bc.GetBlock(hash, number)
+	if block == nil {
+		bc.mu.Unlock()
+		return
+	}
+	event := bc.advanceFinality(block, confirmations)
+	bc.mu.Unlock()
+
+	if event != nil {
+		bc.finalizedFeed.Send(*event)
+	}
+}
+
+// recheckFinality advances the safe and finalized blocks with the confirmations
+// stored for blocks that just became canonical, in ascending order. Those may have
+// collected their confirmations while on a side chain or before being imported.
+// The caller must hold the chain mutex.
+func (bc *BlockChain) recheckFinality(blocks types.Blocks) (event *FinalizedEvent) {
+	if _, ok := bc.engine.(consensus.Confirmer); !ok {
+		return nil
+	}
+	for _, block := range blocks {
+		if confirmations := len(rawdb.ReadConfirmations(bc.db, block.Hash(), block.NumberU64())); confirmations > 0 {
+			if finalized := bc.advanceFinality(block, confirmations); finalized != nil {
+				event = finalized
+			}
+		}
+	}
+	return event
+}
+
+// advanceFinality marks the given canonical block safe or finalized if it has
+// collected enough signer confirmations, returning the event to announce if it
+// got finalized. The caller must hold the chain mutex.
+func (bc *BlockChain) advanceFinality(block *types.Block, confirmations int) *FinalizedEvent {
+	confirmer, ok := bc.engine.(consensus.Confirmer)
+	if !ok {
+		return nil
+	}
+	header := block.Header()
+	signers, err := confirmer.ConfirmationSigners(bc, header)
+	if err != nil || signers == 0 {
+		return nil
+	}
+	quorum, err := confirmer.ConfirmationQuorum(bc, header)
+	if err != nil || quorum == 0 {
+		return nil
+	}
+	number := block.NumberU64()
+	if 2*confirmations > signers && number > bc.CurrentSafeBlock().NumberU64() {
+		bc.currentSafeBlock.Store(block)
+	}
+	if confirmations < quorum || number <= bc.CurrentFinalizedBlock().NumberU64() {
+		return nil
+	}
+	rawdb.WriteHeadFinalizedBlockHash(bc.db, block.Hash())
+	bc.currentFinalizedBlock.Store(block)
+	if bc.CurrentSafeBlock().NumberU64() < number {
+		bc.currentSafeBlock.Store(block)
+	}
+	log.Debug("Finalized block", "number", number, "hash", block.Hash(), "confirmations", confirmations)
+	return &FinalizedEvent{Block: block}
+}
+
 // SetProcessor sets the processor required for making state modifications.
 func (bc *BlockChain) SetProcessor(processor Processor) {
 	bc.procmu.Lock()
@@ -413,6 +532,8 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	bc.hc.SetGenesis(bc.genesisBlock.Header())
 	bc.hc.SetCurrentHeader(bc.genesisBlock.Header())
 	bc.currentFastBlock.Store(bc.genesisBlock)
+	bc.currentFinalizedBlock.Store(bc.genesisBlock)
+	bc.currentSafeBlock.Store(bc.genesisBlock)
 
 	return nil
 }
@@ -657,11 +778,11 @@ func (bc *BlockChain) Stop() {
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
 	//  - HEAD-1:   So we don't do large reorgs if our HEAD becomes an uncle
-	//  - HEAD-127: So we have a hard limit on the number of blocks reexecuted
+	//  - HEAD-N+1: So we have a hard limit on the number of blocks reexecuted
 	if !bc.cacheConfig.Disabled {
 		triedb := bc.stateCache.TrieDB()
 
-		for _, offset := range []uint64{0, 1, triesInMemory - 1} {
+		for _, offset := range []uint64{0, 1, bc.cacheConfig.TriesInMemory - 1} {
 			if number := bc.CurrentBlock().NumberU64(); number > offset {
 				recent := bc.GetBlockByNumber(number - offset)
 
@@ -915,9 +1036,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
 		bc.triegc.Push(root, -float32(block.NumberU64()))
 
-		if current := block.NumberU64(); current > triesInMemory {
+		if current, retain := block.NumberU64(), bc.cacheConfig.TriesInMemory; current > retain {
 			// Find the next state trie we need to commit
-			header := bc.GetHeaderByNumber(current - triesInMemory)
+			header := bc.GetHeaderByNumber(current - retain)
 			chosen := header.Number.Uint64()
 
 			// Only write to disk if we exceeded our memory allowance *and* also have at
@@ -929,16 +1050,16 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			if size > limit || bc.gcproc > bc.cacheConfig.TrieTimeLimit {
 				// If we're exceeding limits but haven't reached a large enough memory gap,
 				// warn the user that the system is becoming unstable.
-				if chosen < lastWrite+triesInMemory {
+				if chosen < lastWrite+retain {
 					switch {
 					case size >= 2*limit:
-						log.Warn("State memory usage too high, committing", "size", size, "limit", limit, "optimum", float64(chosen-lastWrite)/triesInMemory)
+						log.Warn("State memory usage too high, committing", "size", size, "limit", limit, "optimum", float64(chosen-lastWrite)/float64(retain))
 					case bc.gcproc >= 2*bc.cacheConfig.TrieTimeLimit:
-						log.Info("State in memory for too long, committing", "time", bc.gcproc, "allowance", bc.cacheConfig.TrieTimeLimit, "optimum", float64(chosen-lastWrite)/triesInMemory)
+						log.Info("State in memory for too long, committing", "time", bc.gcproc, "allowance", bc.cacheConfig.TrieTimeLimit, "optimum", float64(chosen-lastWrite)/float64(retain))
 					}
 				}
 				// If optimum or critical limits reached, write to disk
-				if chosen >= lastWrite+triesInMemory || size >= 2*limit || bc.gcproc >= 2*bc.cacheConfig.TrieTimeLimit {
+				if chosen >= lastWrite+retain || size >= 2*limit || bc.gcproc >= 2*bc.cacheConfig.TrieTimeLimit {
 					triedb.Commit(header.Root, true)
 					lastWrite = chosen
 					bc.gcproc = 0
@@ -969,10 +1090,18 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
-			if err := bc.reorg(currentBlock, block); err != nil {
+			switch err := bc.reorg(currentBlock, block); err {
+			case nil:
+			case ErrReorgBelowFinalized:
+				// Finalized blocks are irreversible, keep the fork as a side chain
+				log.Warn("Rejected fork below the finalized block", "number", block.Number(), "hash", block.Hash(), "finalized", bc.CurrentFinalizedBlock().Number())
+				reorg = false
+			default:
 				return NonStatTy, err
 			}
 		}
+	}
+	if reorg {
 		// Write the positional metadata for transaction/receipt lookups and preimages
 		rawdb.WriteTxLookupEntries(batch, block)
 		rawdb.WritePreimages(batch, block.NumberU64(), state.Preimages())
@@ -985,9 +1114,12 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		return NonStatTy, err
 	}
 
-	// Set new head.
+	// Set new head, finalizing it if it was confirmed before being imported
 	if status == CanonStatTy {
 		bc.insert(block)
+		if event := bc.recheckFinality(types.Blocks{block}); event != nil {
+			go bc.finalizedFeed.Send(*event)
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
@@ -1311,6 +1443,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			return fmt.Errorf("Invalid new chain")
 		}
 	}
+	// Finalized blocks are irreversible, refuse forks branching off below them
+	if finalized := bc.CurrentFinalizedBlock(); finalized.NumberU64() > commonBlock.NumberU64() {
+		return ErrReorgBelowFinalized
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Debug
@@ -1319,17 +1455,30 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		}
 		logFn("Chain split detected", "number", commonBlock.Number(), "hash", commonBlock.Hash(),
 			"drop", len(oldChain), "dropfrom", oldChain[0].Hash(), "add", len(newChain), "addfrom", newChain[0].Hash())
+
+		// The reorged blocks lose their confirmations, rewind the safe block
+		if safe := bc.CurrentSafeBlock(); safe.NumberU64() > commonBlock.NumberU64() {
+			bc.currentSafeBlock.Store(commonBlock)
+		}
 	} else {
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
 	}
 	// Insert the new chain, taking care of the proper incremental order
-	var addedTxs types.Transactions
+	var (
+		addedTxs  types.Transactions
+		canonical types.Blocks
+	)
 	for i := len(newChain) - 1; i >= 0; i-- {
 		// insert the block in the canonical way, re-writing history
 		bc.insert(newChain[i])
 		// write lookup entries for hash based transaction/receipt searches
 		rawdb.WriteTxLookupEntries(bc.db, newChain[i])
 		addedTxs = append(addedTxs, newChain[i].Transactions()...)
+		canonical = append(canonical, newChain[i])
+	}
+	// The new chain may have been confirmed while it was a side chain
+	if event := bc.recheckFinality(canonical); event != nil {
+		go bc.finalizedFeed.Send(*event)
 	}
 	// calculate the difference between deleted and added transactions
 	diff := types.TxDifference(deletedTxs, addedTxs)
@@ -1561,3 +1710,8 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
 }
+
+// SubscribeFinalizedEvent registers a subscription of FinalizedEvent.
+func (bc *BlockChain) SubscribeFinalizedEvent(ch chan<- FinalizedEvent) event.Subscription {
+	return bc.scope.Track(bc.finalizedFeed.Subscribe(ch))
+}
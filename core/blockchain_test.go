@@ -17,6 +17,7 @@
 package core
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/consensus"
 	"github.com/dsplinz2019/dsplinz/consensus/ethash"
 	"github.com/dsplinz2019/dsplinz/core/rawdb"
 	"github.com/dsplinz2019/dsplinz/core/state"
@@ -1193,6 +1195,94 @@ func TestEIP161AccountRemoval(t *testing.T) {
 	}
 }
 
+// Tests that the calls of batch transactions are executed atomically, reporting
+// their individual results in the receipts.
+func TestBatchTransaction(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		funds    = big.NewInt(1000000000)
+		logger   = common.Address{0xaa}
+		reverter = common.Address{0xbb}
+		gspec    = &Genesis{
+			Config: &params.ChainConfig{
+				ChainId:        big.NewInt(1),
+				HomesteadBlock: new(big.Int),
+				EIP155Block:    new(big.Int),
+				EIP158Block:    new(big.Int),
+				ByzantiumBlock: new(big.Int),
+				BatchTxBlock:   new(big.Int),
+			},
+			Alloc: GenesisAlloc{
+				address:  {Balance: funds},
+				logger:   {Balance: new(big.Int), Code: common.FromHex("0x60006000a000")}, // LOG0(0, 0)
+				reverter: {Balance: new(big.Int), Code: common.FromHex("0x600080fd")},     // REVERT(0, 0)
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	blocks, receipts := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, block *BlockGen) {
+		var calls []types.BatchCall
+		switch i {
+		case 0:
+			calls = []types.BatchCall{
+				{To: common.Address{1}, Value: big.NewInt(1)},
+				{To: logger, Value: new(big.Int)},
+				{To: logger, Value: new(big.Int)},
+			}
+		case 1:
+			calls = []types.BatchCall{
+				{To: common.Address{2}, Value: big.NewInt(5)},
+				{To: reverter, Value: new(big.Int)},
+			}
+		}
+		tx, err := types.SignTx(types.NewBatchTransaction(gspec.Config.ChainId, block.TxNonce(address), calls, 200000, new(big.Int)), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert batch transactions: %v", err)
+	}
+	// The successful batch must report all the calls and their logs
+	receipt := receipts[0][0]
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("batch status mismatch: have %d, want %d", receipt.Status, types.ReceiptStatusSuccessful)
+	}
+	if len(receipt.Calls) != 3 || len(receipt.Logs) != 2 {
+		t.Fatalf("batch results mismatch: have %d calls and %d logs, want 3 and 2", len(receipt.Calls), len(receipt.Logs))
+	}
+	for i, want := range []int{0, 1, 1} {
+		if call := receipt.Calls[i]; call.Status != types.ReceiptStatusSuccessful || len(call.Logs) != want || call.GasUsed == 0 {
+			t.Errorf("call %d: result mismatch: have status %d, %d logs, %d gas", i, call.Status, len(call.Logs), call.GasUsed)
+		}
+	}
+	// The failing batch must revert all of its calls, but still consume the nonce
+	receipt = receipts[1][0]
+	if receipt.Status != types.ReceiptStatusFailed || len(receipt.Calls) != 2 || len(receipt.Logs) != 0 {
+		t.Fatalf("failed batch results mismatch: status %d, %d calls, %d logs", receipt.Status, len(receipt.Calls), len(receipt.Logs))
+	}
+	if receipt.Calls[0].Status != types.ReceiptStatusSuccessful || receipt.Calls[1].Status != types.ReceiptStatusFailed {
+		t.Fatalf("failed batch call status mismatch: have %d and %d", receipt.Calls[0].Status, receipt.Calls[1].Status)
+	}
+	state, _ := blockchain.State()
+	if balance := state.GetBalance(common.Address{1}); balance.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("successful batch transfer mismatch: have %v, want %v", balance, 1)
+	}
+	if balance := state.GetBalance(common.Address{2}); balance.Sign() != 0 {
+		t.Errorf("failed batch transfer not reverted: have %v", balance)
+	}
+	if nonce := state.GetNonce(address); nonce != 2 {
+		t.Errorf("sender nonce mismatch: have %d, want %d", nonce, 2)
+	}
+}
+
 // This is a regression test (i.e. as weird as it is, don't delete it ever), which
 // tests that under weird reorg conditions the blockchain and its internal header-
 // chain return the same latest block/header.
@@ -1340,6 +1430,136 @@ func TestLargeReorgTrieGC(t *testing.T) {
 	}
 }
 
+// testConfirmer is a fake consensus engine whose blocks may be confirmed by any
+// signer, with fixed signer and quorum counts.
+type testConfirmer struct {
+	consensus.Engine
+	signers int
+	quorum  int
+}
+
+func (c *testConfirmer) Confirm(chain consensus.ChainReader, header *types.Header) (*types.Confirmation, error) {
+	return nil, nil
+}
+
+func (c *testConfirmer) VerifyConfirmation(chain consensus.ChainReader, confirmation *types.Confirmation) (common.Address, error) {
+	return confirmation.Signer()
+}
+
+func (c *testConfirmer) ConfirmationSigners(chain consensus.ChainReader, header *types.Header) (int, error) {
+	return c.signers, nil
+}
+
+func (c *testConfirmer) ConfirmationQuorum(chain consensus.ChainReader, header *types.Header) (int, error) {
+	return c.quorum, nil
+}
+
+// Tests that blocks confirmed while on a side chain or before being imported
+// become safe or finalized once a reorg or import makes them canonical.
+func TestFinalityAfterReorg(t *testing.T) {
+	engine := &testConfirmer{Engine: ethash.NewFaker(), signers: 5, quorum: 4}
+
+	db := ethdb.NewMemDatabase()
+	genesis := new(Genesis).MustCommit(db)
+
+	original, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 3, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+	competitor, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 4, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{2}) })
+
+	diskdb := ethdb.NewMemDatabase()
+	new(Genesis).MustCommit(diskdb)
+
+	chain, err := NewBlockChain(diskdb, nil, params.TestChainConfig, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	pool := NewConfirmationPool(chain, diskdb)
+	defer pool.Stop()
+
+	finalized := make(chan FinalizedEvent, 4)
+	sub := chain.SubscribeFinalizedEvent(finalized)
+	defer sub.Unsubscribe()
+
+	keys := make([]*ecdsa.PrivateKey, engine.quorum)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	confirm := func(block *types.Block, signers int) {
+		for _, key := range keys[:signers] {
+			unsigned := types.NewConfirmation(block.NumberU64(), block.Hash())
+			sig, err := crypto.Sign(unsigned.SigHash().Bytes(), key)
+			if err != nil {
+				t.Fatalf("failed to sign confirmation: %v", err)
+			}
+			confirmation, _ := unsigned.WithSignature(sig)
+			if err := pool.Add(confirmation); err != nil {
+				t.Fatalf("failed to add confirmation: %v", err)
+			}
+		}
+	}
+	// Confirm a block of the competitor while it's a side chain and one before import
+	if _, err := chain.InsertChain(original); err != nil {
+		t.Fatalf("failed to insert original chain: %v", err)
+	}
+	if _, err := chain.InsertChain(competitor[:2]); err != nil {
+		t.Fatalf("failed to insert competitor chain: %v", err)
+	}
+	confirm(competitor[1], engine.quorum)
+	confirm(competitor[3], engine.signers/2+1)
+
+	if number := chain.CurrentFinalizedBlock().NumberU64(); number != 0 {
+		t.Fatalf("side chain block finalized: have #%d, want #0", number)
+	}
+	// Reorg to the competitor and ensure the stored confirmations are counted
+	if _, err := chain.InsertChain(competitor[2:]); err != nil {
+		t.Fatalf("failed to reorg to competitor chain: %v", err)
+	}
+	if hash := chain.CurrentFinalizedBlock().Hash(); hash != competitor[1].Hash() {
+		t.Errorf("finalized block mismatch: have %x, want %x", hash, competitor[1].Hash())
+	}
+	if hash := chain.CurrentSafeBlock().Hash(); hash != competitor[3].Hash() {
+		t.Errorf("safe block mismatch: have %x, want %x", hash, competitor[3].Hash())
+	}
+	select {
+	case ev := <-finalized:
+		if ev.Block.Hash() != competitor[1].Hash() {
+			t.Errorf("finalized event mismatch: have %x, want %x", ev.Block.Hash(), competitor[1].Hash())
+		}
+	case <-time.After(time.Second):
+		t.Errorf("finalized event timeout")
+	}
+}
+
+// Tests that rewinding the chain deletes the signer confirmations of the removed
+// blocks along with them.
+func TestSetHeadDeletesConfirmations(t *testing.T) {
+	_, blockchain, err := newCanonical(ethash.NewFaker(), 4, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	var blocks []*types.Block
+	for number := uint64(1); number <= 4; number++ {
+		block := blockchain.GetBlockByNumber(number)
+		rawdb.WriteConfirmation(blockchain.db, common.Address{1}, &types.Confirmation{Number: number, BlockHash: block.Hash(), Signature: make([]byte, 65)})
+		blocks = append(blocks, block)
+	}
+	if err := blockchain.SetHead(2); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	for _, block := range blocks {
+		want := 0
+		if block.NumberU64() <= 2 {
+			want = 1
+		}
+		if have := len(rawdb.ReadConfirmations(blockchain.db, block.Hash(), block.NumberU64())); have != want {
+			t.Errorf("block #%d: confirmation count mismatch: have %d, want %d", block.NumberU64(), have, want)
+		}
+	}
+}
+
 // Benchmarks large blocks with value transfers to non-existing accounts
 func benchmarkLargeNumberOfValueToNonexisting(b *testing.B, numTxs, numBlocks int, recipientFn func(uint64) common.Address, dataFn func(uint64) []byte) {
 	var (
@@ -0,0 +1,160 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/consensus"
+	"github.com/dsplinz2019/dsplinz/core/rawdb"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/event"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/hashicorp/golang-lru"
+)
+
+// confirmationCacheLimit is the number of blocks whose confirmations are kept
+// in memory to avoid recovering the signers from the database.
+const confirmationCacheLimit = 256
+
+// ConfirmationPool aggregates the signer confirmations of blocks. Confirmations
+// are verified by the consensus engine, deduplicated per signer and persisted
+// alongside the confirmed block, one database entry per signer.
+type ConfirmationPool struct {
+	chain  *BlockChain
+	engine consensus.Confirmer // Nil if the engine does not support confirmations
+	db     ethdb.Database
+
+	cache *lru.Cache // Confirmations of recent blocks, keyed by block hash
+	mu    sync.Mutex
+
+	confirmationFeed event.Feed
+	scope            event.SubscriptionScope
+}
+
+// NewConfirmationPool creates a confirmation pool on top of the given chain. If
+// the chain's consensus engine does not support confirmations, the pool rejects
+// all of them.
+func NewConfirmationPool(chain *BlockChain, db ethdb.Database) *ConfirmationPool {
+	cache, _ := lru.New(confirmationCacheLimit)
+	pool := &ConfirmationPool{
+		chain: chain,
+		db:    db,
+		cache: cache,
+	}
+	if confirmer, ok := chain.Engine().(consensus.Confirmer); ok {
+		pool.engine = confirmer
+	}
+	return pool
+}
+
+// Stop terminates all subscriptions of the confirmation pool.
+func (pool *ConfirmationPool) Stop() {
+	pool.scope.Close()
+	log.Info("Confirmation pool stopped")
+}
+
+// Add verifies a signer confirmation and, if it is valid and not yet known,
+// stores it and announces it to the subscribers.
+func (pool *ConfirmationPool) Add(confirmation *types.Confirmation) error {
+	if pool.engine == nil {
+		return ErrConfirmationsUnsupported
+	}
+	signer, err := pool.engine.VerifyConfirmation(pool.chain, confirmation)
+	if err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	signers := pool.load(confirmation.BlockHash, confirmation.Number)
+	if _, ok := signers[signer]; ok {
+		pool.mu.Unlock()
+		return ErrKnownConfirmation
+	}
+	signers[signer] = confirmation
+	count := len(signers)
+
+	rawdb.WriteConfirmation(pool.db, signer, confirmation)
+	pool.mu.Unlock()
+
+	log.Debug("Added signer confirmation", "number", confirmation.Number, "hash", confirmation.BlockHash, "signer", signer, "count", count)
+	pool.confirmationFeed.Send(NewConfirmationEvent{Confirmation: confirmation})
+
+	if header := pool.chain.GetHeader(confirmation.BlockHash, confirmation.Number); header != nil {
+		pool.chain.updateFinality(header, count)
+	}
+	return nil
+}
+
+// load retrieves the confirmations of a block keyed by their signer, either from
+// the cache or from the database. The caller must hold the pool lock.
+func (pool *ConfirmationPool) load(hash common.Hash, number uint64) map[common.Address]*types.Confirmation {
+	if cached, ok := pool.cache.Get(hash); ok {
+		return cached.(map[common.Address]*types.Confirmation)
+	}
+	signers := make(map[common.Address]*types.Confirmation)
+	for _, confirmation := range rawdb.ReadConfirmations(pool.db, hash, number) {
+		signer, err := confirmation.Signer()
+		if err != nil {
+			log.Error("Invalid stored confirmation", "number", number, "hash", hash, "err", err)
+			continue
+		}
+		signers[signer] = confirmation
+	}
+	pool.cache.Add(hash, signers)
+	return signers
+}
+
+// ConfirmationsByHash returns the signer confirmations collected for a block.
+func (pool *ConfirmationPool) ConfirmationsByHash(hash common.Hash) types.Confirmations {
+	number := rawdb.ReadHeaderNumber(pool.db, hash)
+	if number == nil {
+		return nil
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	signers := pool.load(hash, *number)
+	confirmations := make(types.Confirmations, 0, len(signers))
+	for _, c := range signers {
+		confirmations = append(confirmations, c)
+	}
+	return confirmations
+}
+
+// Confirmations returns the signer confirmations collected for the canonical
+// block with the given number.
+func (pool *ConfirmationPool) Confirmations(number uint64) types.Confirmations {
+	hash := rawdb.ReadCanonicalHash(pool.db, number)
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return pool.ConfirmationsByHash(hash)
+}
+
+// Count returns the number of distinct signers that confirmed the canonical
+// block with the given number.
+func (pool *ConfirmationPool) Count(number uint64) int {
+	return len(pool.Confirmations(number))
+}
+
+// SubscribeNewConfirmationEvent registers a subscription of NewConfirmationEvent
+// and starts sending events to the given channel.
+func (pool *ConfirmationPool) SubscribeNewConfirmationEvent(ch chan<- NewConfirmationEvent) event.Subscription {
+	return pool.scope.Track(pool.confirmationFeed.Subscribe(ch))
+}
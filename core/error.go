@@ -32,4 +32,20 @@ var (
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
+
+	// ErrTxNotYetValid is returned if a scheduled transaction is included into a
+	// block below its number or time bounds.
+	ErrTxNotYetValid = errors.New("transaction not yet valid")
+
+	// ErrKnownConfirmation is returned when a signer confirmation is already known
+	// locally.
+	ErrKnownConfirmation = errors.New("confirmation already known")
+
+	// ErrConfirmationsUnsupported is returned if a confirmation is added while the
+	// consensus engine does not support signer confirmations.
+	ErrConfirmationsUnsupported = errors.New("consensus engine does not support confirmations")
+
+	// ErrReorgBelowFinalized is returned if a chain reorganisation would revert the
+	// finalized block, which is irreversible.
+	ErrReorgBelowFinalized = errors.New("reorg below finalized block")
 )
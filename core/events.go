@@ -24,6 +24,14 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxStatusEvent is posted when transactions tracked by the transaction pool
+// change their status.
+type TxStatusEvent struct{ Transitions []*TxTransition }
+
+// NewConfirmationEvent is posted when a signer confirmation enters the
+// confirmation pool.
+type NewConfirmationEvent struct{ Confirmation *types.Confirmation }
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
@@ -49,3 +57,7 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// FinalizedEvent is posted when a new block is confirmed by a two-thirds signer
+// quorum and becomes irreversible.
+type FinalizedEvent struct{ Block *types.Block }
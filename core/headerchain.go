@@ -420,6 +420,7 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 		}
 		rawdb.DeleteHeader(hc.chainDb, hash, num)
 		rawdb.DeleteTd(hc.chainDb, hash, num)
+		rawdb.DeleteConfirmations(hc.chainDb, hc.chainDb, hash, num)
 
 		hc.currentHeader.Store(hc.GetHeader(hdr.ParentHash, hdr.Number.Uint64()-1))
 	}
@@ -427,6 +428,12 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 	for i := height; i > head; i-- {
 		rawdb.DeleteCanonicalHash(hc.chainDb, i)
 	}
+	// Drop any frozen blocks above the new head, they are no longer canonical
+	if ancients, ok := hc.chainDb.(rawdb.AncientWriter); ok {
+		if err := ancients.TruncateAncients(head + 1); err != nil {
+			log.Crit("Failed to truncate ancient store", "head", head, "err", err)
+		}
+	}
 	// Clear out any stale content from the caches
 	hc.headerCache.Purge()
 	hc.tdCache.Purge()
@@ -29,13 +29,39 @@ import (
 
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
-	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...))
+	data, _ := db.Get(headerHashKey(number))
+	if len(data) == 0 {
+		if ancients, ok := db.(AncientReader); ok {
+			data, _ = ancients.Ancient(freezerHashTable, number)
+		}
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
 	return common.BytesToHash(data)
 }
 
+// isAncient checks whether the block with the given hash is a frozen one, in
+// which case its data can be retrieved from the ancient store of the database.
+func isAncient(db DatabaseReader, hash common.Hash, number uint64) bool {
+	ancients, ok := db.(AncientReader)
+	if !ok {
+		return false
+	}
+	data, _ := ancients.Ancient(freezerHashTable, number)
+	return len(data) != 0 && common.BytesToHash(data) == hash
+}
+
+// readAncient retrieves an item of the given kind belonging to the block with
+// the given hash from the ancient store of the database, if it is frozen.
+func readAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if !isAncient(db, hash, number) {
+		return nil
+	}
+	data, _ := db.(AncientReader).Ancient(kind, number)
+	return data
+}
+
 // WriteCanonicalHash stores the hash assigned to a canonical block number.
 func WriteCanonicalHash(db DatabaseWriter, hash common.Hash, number uint64) {
 	key := append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...)
@@ -109,6 +135,24 @@ func WriteHeadFastBlockHash(db DatabaseWriter, hash common.Hash) {
 	}
 }
 
+// ReadHeadFinalizedBlockHash retrieves the hash of the latest block confirmed
+// by a signer quorum.
+func ReadHeadFinalizedBlockHash(db DatabaseReader) common.Hash {
+	data, _ := db.Get(headFinalizedBlockKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteHeadFinalizedBlockHash stores the hash of the latest block confirmed by
+// a signer quorum.
+func WriteHeadFinalizedBlockHash(db DatabaseWriter, hash common.Hash) {
+	if err := db.Put(headFinalizedBlockKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store last finalized block's hash", "err", err)
+	}
+}
+
 // ReadFastTrieProgress retrieves the number of tries nodes fast synced to allow
 // reporting correct numbers across restarts.
 func ReadFastTrieProgress(db DatabaseReader) uint64 {
@@ -129,17 +173,19 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
-	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		data = readAncient(db, freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
-	key := append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
-	if has, err := db.Has(key); !has || err != nil {
-		return false
+	if has, err := db.Has(headerKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isAncient(db, hash, number)
 }
 
 // ReadHeader retrieves the block header corresponding to the hash.
@@ -192,7 +238,10 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
-	data, _ := db.Get(append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	data, _ := db.Get(blockBodyKey(number, hash))
+	if len(data) == 0 {
+		data = readAncient(db, freezerBodiesTable, hash, number)
+	}
 	return data
 }
 
@@ -206,11 +255,10 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
-	key := append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
-	if has, err := db.Has(key); !has || err != nil {
-		return false
+	if has, err := db.Has(blockBodyKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isAncient(db, hash, number)
 }
 
 // ReadBody retrieves the block body corresponding to the hash.
@@ -243,9 +291,19 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadTdRLP retrieves a block's total difficulty corresponding to the hash in
+// its raw RLP database encoding.
+func ReadTdRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	data, _ := db.Get(headerTDKey(number, hash))
+	if len(data) == 0 {
+		data = readAncient(db, freezerDifficultyTable, hash, number)
+	}
+	return data
+}
+
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), headerTDSuffix...))
+	data := ReadTdRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -276,10 +334,20 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadReceiptsRLP retrieves all the transaction receipts belonging to a block
+// in their raw RLP database encoding.
+func ReadReceiptsRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	data, _ := db.Get(blockReceiptsKey(number, hash))
+	if len(data) == 0 {
+		data = readAncient(db, freezerReceiptTable, hash, number)
+	}
+	return data
+}
+
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data := ReadReceiptsRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -321,6 +389,53 @@ func DeleteReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadConfirmations retrieves the signer confirmations collected for a block,
+// ordered by signer.
+func ReadConfirmations(db DatabaseIteratee, hash common.Hash, number uint64) types.Confirmations {
+	it := db.NewIteratorWithPrefix(blockConfirmationsKey(number, hash))
+	defer it.Release()
+
+	var confirmations types.Confirmations
+	for it.Next() {
+		if len(it.Key()) != len(blockConfirmationsPrefix)+8+common.HashLength+common.AddressLength {
+			continue
+		}
+		confirmation := new(types.Confirmation)
+		if err := rlp.DecodeBytes(it.Value(), confirmation); err != nil {
+			log.Error("Invalid confirmation RLP", "hash", hash, "err", err)
+			continue
+		}
+		confirmations = append(confirmations, confirmation)
+	}
+	return confirmations
+}
+
+// WriteConfirmation stores the confirmation of a block by a signer. Each signer
+// has its own entry, so adding a confirmation doesn't rewrite the others.
+func WriteConfirmation(db DatabaseWriter, signer common.Address, confirmation *types.Confirmation) {
+	bytes, err := rlp.EncodeToBytes(confirmation)
+	if err != nil {
+		log.Crit("Failed to encode block confirmation", "err", err)
+	}
+	if err := db.Put(blockConfirmationKey(confirmation.Number, confirmation.BlockHash, signer), bytes); err != nil {
+		log.Crit("Failed to store block confirmation", "err", err)
+	}
+}
+
+// DeleteConfirmations removes all signer confirmations associated with a block
+// hash. The entries are looked up in db and deleted through deleter, which may
+// be db itself or a batch on top of it.
+func DeleteConfirmations(db DatabaseIteratee, deleter DatabaseDeleter, hash common.Hash, number uint64) {
+	it := db.NewIteratorWithPrefix(blockConfirmationsKey(number, hash))
+	defer it.Release()
+
+	for it.Next() {
+		if err := deleter.Delete(common.CopyBytes(it.Key())); err != nil {
+			log.Crit("Failed to delete block confirmations", "err", err)
+		}
+	}
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -345,7 +460,10 @@ func WriteBlock(db DatabaseWriter, block *types.Block) {
 	WriteHeader(db, block.Header())
 }
 
-// DeleteBlock removes all block data associated with a hash.
+// DeleteBlock removes all block data associated with a hash. The signer
+// confirmations need iterating the database, callers removing a block for good
+// must delete them too with DeleteConfirmations, as the freezer does with side
+// chains and the header chain with rewound headers.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
 	DeleteHeader(db, hash, number)
@@ -236,6 +236,7 @@ func TestHeadStorage(t *testing.T) {
 	blockHead := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block header")})
 	blockFull := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block full")})
 	blockFast := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block fast")})
+	blockFinal := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block finalized")})
 
 	// Check that no head entries are in a pristine database
 	if entry := ReadHeadHeaderHash(db); entry != (common.Hash{}) {
@@ -247,10 +248,14 @@ func TestHeadStorage(t *testing.T) {
 	if entry := ReadHeadFastBlockHash(db); entry != (common.Hash{}) {
 		t.Fatalf("Non fast head block entry returned: %v", entry)
 	}
+	if entry := ReadHeadFinalizedBlockHash(db); entry != (common.Hash{}) {
+		t.Fatalf("Non finalized head block entry returned: %v", entry)
+	}
 	// Assign separate entries for the head header and block
 	WriteHeadHeaderHash(db, blockHead.Hash())
 	WriteHeadBlockHash(db, blockFull.Hash())
 	WriteHeadFastBlockHash(db, blockFast.Hash())
+	WriteHeadFinalizedBlockHash(db, blockFinal.Hash())
 
 	// Check that both heads are present, and different (i.e. two heads maintained)
 	if entry := ReadHeadHeaderHash(db); entry != blockHead.Hash() {
@@ -262,6 +267,9 @@ func TestHeadStorage(t *testing.T) {
 	if entry := ReadHeadFastBlockHash(db); entry != blockFast.Hash() {
 		t.Fatalf("Fast head block hash mismatch: have %v, want %v", entry, blockFast.Hash())
 	}
+	if entry := ReadHeadFinalizedBlockHash(db); entry != blockFinal.Hash() {
+		t.Fatalf("Finalized head block hash mismatch: have %v, want %v", entry, blockFinal.Hash())
+	}
 }
 
 // Tests that receipts associated with a single block can be stored and retrieved.
@@ -317,3 +325,45 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that signer confirmations of a block can be stored and retrieved.
+func TestBlockConfirmationStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	signers := []common.Address{{0x01}, {0x02}}
+	confirmations := types.Confirmations{
+		{Number: 1, BlockHash: hash, Signature: bytes.Repeat([]byte{0x01}, 65)},
+		{Number: 1, BlockHash: hash, Signature: bytes.Repeat([]byte{0x02}, 65)},
+	}
+	// Check that no confirmations are in a pristine database
+	if cs := ReadConfirmations(db, hash, 1); len(cs) != 0 {
+		t.Fatalf("non existent confirmations returned: %v", cs)
+	}
+	// Insert the confirmations one by one and check presence
+	for i, confirmation := range confirmations {
+		WriteConfirmation(db, signers[i], confirmation)
+		if cs := ReadConfirmations(db, hash, 1); len(cs) != i+1 {
+			t.Fatalf("confirmation count mismatch: have %d, want %d", len(cs), i+1)
+		}
+	}
+	cs := ReadConfirmations(db, hash, 1)
+	for i := range confirmations {
+		if cs[i].Hash() != confirmations[i].Hash() {
+			t.Fatalf("confirmation #%d: hash mismatch: have %x, want %x", i, cs[i].Hash(), confirmations[i].Hash())
+		}
+	}
+	// Confirmations of other blocks must not be returned
+	WriteConfirmation(db, signers[0], &types.Confirmation{Number: 1, BlockHash: common.Hash{0xff}, Signature: bytes.Repeat([]byte{0x03}, 65)})
+	if cs := ReadConfirmations(db, hash, 1); len(cs) != len(confirmations) {
+		t.Fatalf("confirmation count mismatch: have %d, want %d", len(cs), len(confirmations))
+	}
+	// Delete the confirmations and check purge
+	DeleteConfirmations(db, db, hash, 1)
+	if cs := ReadConfirmations(db, hash, 1); len(cs) != 0 {
+		t.Fatalf("deleted confirmations returned: %v", cs)
+	}
+	if cs := ReadConfirmations(db, common.Hash{0xff}, 1); len(cs) != 1 {
+		t.Fatalf("unrelated confirmations deleted")
+	}
+}
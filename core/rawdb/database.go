@@ -0,0 +1,264 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/rlp"
+)
+
+// errNoAncientStore is returned if an ancient store operation is attempted on a
+// database without a freezer.
+var errNoAncientStore = errors.New("database has no ancient store")
+
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ethdb.Database
+	*freezer
+}
+
+// Close terminates the freezer, then closes the key-value store.
+func (frdb *freezerdb) Close() {
+	if err := frdb.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	frdb.Database.Close()
+}
+
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer moving immutable chain segments into cold
+// storage. Canonical blocks more than depth blocks below the head are moved
+// into the freezer in the background, a zero depth disables the moving but
+// keeps the already frozen blocks accessible.
+func NewDatabaseWithFreezer(db ethdb.Database, freezer string, namespace string, depth uint64) (ethdb.Database, error) {
+	frdb, err := newFreezer(freezer, namespace, depth)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the ancients belong to the chain in the key-value store, the
+	// genesis block is never deleted from the latter
+	if frozen, _ := frdb.Ancients(); frozen > 0 {
+		if kvgenesis, _ := db.Get(headerHashKey(0)); len(kvgenesis) > 0 {
+			if frgenesis, _ := frdb.Ancient(freezerHashTable, 0); !bytes.Equal(kvgenesis, frgenesis) {
+				frdb.Close()
+				return nil, fmt.Errorf("genesis mismatch: %#x (key-value store) != %#x (ancients)", kvgenesis, frgenesis)
+			}
+		}
+	}
+	if depth > 0 {
+		frdb.wg.Add(1)
+		go frdb.freeze(db)
+	}
+	return &freezerdb{
+		Database: db,
+		freezer:  frdb,
+	}, nil
+}
+
+// AncientTableStats contains the inspection results of a single ancient table.
+type AncientTableStats struct {
+	Name       string             // Name of the table
+	Items      uint64             // Number of items stored in the table
+	Size       common.StorageSize // Total size of the data and index files
+	Compressed bool               // Whether the items are snappy compressed
+}
+
+// InspectAncients reports the number of items and disk usage of every table of
+// the ancient store backing the database.
+func InspectAncients(db ethdb.Database) ([]AncientTableStats, error) {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return nil, errNoAncientStore
+	}
+	var stats []AncientTableStats
+	for name, table := range frdb.tables {
+		size, err := table.size()
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, AncientTableStats{
+			Name:       name,
+			Items:      atomic.LoadUint64(&table.items),
+			Size:       common.StorageSize(size),
+			Compressed: !table.noCompression,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+// VerifyAncients checks the integrity of the ancient store backing the database.
+// The table indices must be consistent with their data files, every frozen
+// header must hash to its stored canonical hash and link to its parent, and the
+// bodies and receipts must match the roots committed to by the headers.
+func VerifyAncients(db ethdb.Database) error {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return errNoAncientStore
+	}
+	if err := frdb.verify(); err != nil {
+		return err
+	}
+	var (
+		frozen, _ = frdb.Ancients()
+		start     = time.Now()
+		logged    = time.Now()
+		parent    common.Hash
+	)
+	for number := uint64(0); number < frozen; number++ {
+		blob, err := frdb.Ancient(freezerHashTable, number)
+		if err != nil {
+			return fmt.Errorf("block %d: failed to read hash: %v", number, err)
+		}
+		hash := common.BytesToHash(blob)
+
+		// Verify the header and its position in the chain
+		header := new(types.Header)
+		if err := frdb.decodeAncient(freezerHeaderTable, number, header); err != nil {
+			return err
+		}
+		if header.Hash() != hash {
+			return fmt.Errorf("block %d: header hash mismatch: have %x, want %x", number, header.Hash(), hash)
+		}
+		if header.Number.Uint64() != number {
+			return fmt.Errorf("block %d: header number mismatch: have %d", number, header.Number)
+		}
+		if number > 0 && header.ParentHash != parent {
+			return fmt.Errorf("block %d: parent hash mismatch: have %x, want %x", number, header.ParentHash, parent)
+		}
+		if stored := ReadHeaderNumber(frdb.Database, hash); stored == nil || *stored != number {
+			return fmt.Errorf("block %d: hash to number mapping missing for %x", number, hash)
+		}
+		// Verify the body and the receipts against the header
+		body := new(types.Body)
+		if err := frdb.decodeAncient(freezerBodiesTable, number, body); err != nil {
+			return err
+		}
+		if txHash := types.DeriveSha(types.Transactions(body.Transactions)); txHash != header.TxHash {
+			return fmt.Errorf("block %d: transaction root mismatch: have %x, want %x", number, txHash, header.TxHash)
+		}
+		if uncleHash := types.CalcUncleHash(body.Uncles); uncleHash != header.UncleHash {
+			return fmt.Errorf("block %d: uncle root mismatch: have %x, want %x", number, uncleHash, header.UncleHash)
+		}
+		var storageReceipts []*types.ReceiptForStorage
+		if err := frdb.decodeAncient(freezerReceiptTable, number, &storageReceipts); err != nil {
+			return err
+		}
+		receipts := make(types.Receipts, len(storageReceipts))
+		for i, receipt := range storageReceipts {
+			receipts[i] = (*types.Receipt)(receipt)
+		}
+		if receiptHash := types.DeriveSha(receipts); receiptHash != header.ReceiptHash {
+			return fmt.Errorf("block %d: receipt root mismatch: have %x, want %x", number, receiptHash, header.ReceiptHash)
+		}
+		if err := frdb.decodeAncient(freezerDifficultyTable, number, new(big.Int)); err != nil {
+			return err
+		}
+		parent = hash
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying ancient blocks", "number", number, "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	// Make sure the key-value store continues where the freezer ends
+	if frozen > 0 {
+		if hash := ReadCanonicalHash(frdb.Database, frozen); hash != (common.Hash{}) {
+			header := ReadHeader(frdb.Database, hash, frozen)
+			if header == nil {
+				return fmt.Errorf("block %d: first active header missing", frozen)
+			}
+			if header.ParentHash != parent {
+				return fmt.Errorf("block %d: first active block does not link to the ancients: have parent %x, want %x", frozen, header.ParentHash, parent)
+			}
+		}
+	}
+	log.Info("Verified ancient blocks", "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// CopyAncients appends all the frozen blocks of the source database to the empty
+// ancient store of the destination one. Sources without an ancient store are
+// skipped.
+func CopyAncients(dst, src ethdb.Database) error {
+	srcdb, ok := src.(*freezerdb)
+	if !ok {
+		return nil
+	}
+	frozen, _ := srcdb.Ancients()
+	if frozen == 0 {
+		return nil
+	}
+	dstdb, ok := dst.(*freezerdb)
+	if !ok {
+		return errNoAncientStore
+	}
+	// Block the background freezing of the destination while copying
+	dstdb.lock.Lock()
+	defer dstdb.lock.Unlock()
+
+	if have, _ := dstdb.Ancients(); have != 0 {
+		return fmt.Errorf("destination ancient store not empty: %d blocks", have)
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		kinds  = []string{freezerHashTable, freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerDifficultyTable}
+		blobs  = make([][]byte, len(kinds))
+	)
+	for number := uint64(0); number < frozen; number++ {
+		for i, kind := range kinds {
+			blob, err := srcdb.Ancient(kind, number)
+			if err != nil {
+				return fmt.Errorf("block %d: failed to read %s: %v", number, kind, err)
+			}
+			blobs[i] = blob
+		}
+		if err := dstdb.AppendAncient(number, blobs[0], blobs[1], blobs[2], blobs[3], blobs[4]); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying ancient blocks", "number", number, "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Copied ancient blocks", "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+	return dstdb.Sync()
+}
+
+// decodeAncient retrieves a frozen item and RLP decodes it into val.
+func (f *freezer) decodeAncient(kind string, number uint64, val interface{}) error {
+	blob, err := f.Ancient(kind, number)
+	if err != nil {
+		return fmt.Errorf("block %d: failed to read %s: %v", number, kind, err)
+	}
+	if err := rlp.DecodeBytes(blob, val); err != nil {
+		return fmt.Errorf("block %d: invalid %s: %v", number, kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+	"github.com/dsplinz2019/dsplinz/metrics"
+)
+
+var (
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+const (
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// freezer is an append-only database to store immutable chain data into flat
+// files. The append only nature ensures that disk writes are minimized and that
+// the key-value store is relieved of the bulk of the chain history, keeping its
+// compactions cheap.
+type freezer struct {
+	frozen uint64 // Number of blocks already frozen
+	depth  uint64 // Number of recent blocks kept in the key-value store (0 = no freezing)
+
+	tables map[string]*freezerTable // Data tables for storing everything
+	lock   sync.Mutex               // Lock serializing freezing rounds with truncations
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers.
+func newFreezer(datadir string, namespace string, depth uint64) (*freezer, error) {
+	// Create the initial freezer object
+	var (
+		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
+		writeMeter = metrics.NewRegisteredMeter(namespace+"ancient/write", nil)
+	)
+	freezer := &freezer{
+		depth:  depth,
+		tables: make(map[string]*freezerTable),
+		quit:   make(chan struct{}),
+	}
+	for name, disableSnappy := range freezerNoSnappy {
+		table, err := newTable(datadir, name, readMeter, writeMeter, disableSnappy)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "frozen", freezer.frozen, "depth", depth)
+	return freezer, nil
+}
+
+// Close terminates the chain freezer, unmapping all the data files.
+func (f *freezer) Close() error {
+	close(f.quit)
+	f.wg.Wait()
+
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the length of the frozen items.
+func (f *freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.size()
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files.
+//
+// Notably, this function is lock free but kind of thread-safe. All out-of-order
+// injection will be rejected. But if two injections with same number happen at
+// the same time, we can get into the trouble.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) (err error) {
+	// Ensure the binary blobs we are appending is continuous with freezer.
+	if atomic.LoadUint64(&f.frozen) != number {
+		return errOutOrderInsertion
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			rerr := f.repair()
+			if rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Info("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	// Inject all the components into the relevant data tables
+	if err := f.tables[freezerHashTable].Append(f.frozen, hash[:]); err != nil {
+		log.Error("Failed to append ancient hash", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerHeaderTable].Append(f.frozen, header); err != nil {
+		log.Error("Failed to append ancient header", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerBodiesTable].Append(f.frozen, body); err != nil {
+		log.Error("Failed to append ancient body", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerReceiptTable].Append(f.frozen, receipts); err != nil {
+		log.Error("Failed to append ancient receipts", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerDifficultyTable].Append(f.frozen, td); err != nil {
+		log.Error("Failed to append ancient difficulty", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	atomic.AddUint64(&f.frozen, 1) // Only modify atomically
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// repair truncates all data tables to the same length.
+func (f *freezer) repair() error {
+	min := uint64(0)
+	for i, table := range f.tables {
+		items := atomic.LoadUint64(&table.items)
+		if i == freezerHeaderTable || min > items {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// verify checks the structural integrity of all the data tables.
+func (f *freezer) verify() error {
+	for _, table := range f.tables {
+		if err := table.verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freeze is a background thread that periodically checks the blockchain for any
+// import progress and moves ancient data from the fast database into the freezer.
+//
+// This functionality is deliberately broken off from block importing to avoid
+// incurring additional data shuffling delays on block propagation.
+func (f *freezer) freeze(db ethdb.Database) {
+	defer f.wg.Done()
+
+	backoff := false
+	for {
+		select {
+		case <-f.quit:
+			log.Info("Freezer shutting down")
+			return
+		default:
+		}
+		if backoff {
+			select {
+			case <-time.NewTimer(freezerRecheckInterval).C:
+				backoff = false
+			case <-f.quit:
+				return
+			}
+		}
+		backoff = !f.freezeRound(db)
+	}
+}
+
+// freezeRound moves the next batch of blocks that fell out of the retention
+// depth from the key-value database into the freezer. It returns whether any
+// blocks were moved.
+func (f *freezer) freezeRound(db ethdb.Database) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// Retrieve the freezing threshold. The database passed in is the bare key-
+	// value store, so none of the lookups below hit the freezer itself.
+	hash := ReadHeadBlockHash(db)
+	if hash == (common.Hash{}) {
+		log.Debug("Current full block hash unavailable") // new chain, empty database
+		return false
+	}
+	number := ReadHeaderNumber(db, hash)
+	switch {
+	case number == nil:
+		log.Error("Current full block number unavailable", "hash", hash)
+		return false
+
+	case *number < f.depth:
+		log.Debug("Current full block not old enough", "number", *number, "hash", hash, "delay", f.depth)
+		return false
+
+	case *number-f.depth < f.frozen:
+		log.Debug("Ancient blocks frozen already", "number", *number, "hash", hash, "frozen", f.frozen)
+		return false
+	}
+	limit := *number - f.depth
+	if limit-f.frozen >= freezerBatchLimit {
+		limit = f.frozen + freezerBatchLimit - 1
+	}
+	var (
+		start    = time.Now()
+		first    = f.frozen
+		ancients = make([]common.Hash, 0, limit-first+1)
+	)
+	for f.frozen <= limit {
+		// Retrieves all the components of the canonical block
+		hash := ReadCanonicalHash(db, f.frozen)
+		if hash == (common.Hash{}) {
+			log.Error("Canonical hash missing, can't freeze", "number", f.frozen)
+			break
+		}
+		header := ReadHeaderRLP(db, hash, f.frozen)
+		if len(header) == 0 {
+			log.Error("Block header missing, can't freeze", "number", f.frozen, "hash", hash)
+			break
+		}
+		body := ReadBodyRLP(db, hash, f.frozen)
+		if len(body) == 0 {
+			log.Error("Block body missing, can't freeze", "number", f.frozen, "hash", hash)
+			break
+		}
+		receipts := ReadReceiptsRLP(db, hash, f.frozen)
+		if len(receipts) == 0 {
+			log.Error("Block receipts missing, can't freeze", "number", f.frozen, "hash", hash)
+			break
+		}
+		td := ReadTdRLP(db, hash, f.frozen)
+		if len(td) == 0 {
+			log.Error("Total difficulty missing, can't freeze", "number", f.frozen, "hash", hash)
+			break
+		}
+		log.Trace("Deep froze ancient block", "number", f.frozen, "hash", hash)
+
+		// Inject all the components into the relevant data tables
+		if err := f.AppendAncient(f.frozen, hash[:], header, body, receipts, td); err != nil {
+			break
+		}
+		ancients = append(ancients, hash)
+	}
+	if len(ancients) == 0 {
+		return false
+	}
+	// Batch of blocks have been frozen, flush them before wiping from the
+	// key-value store
+	if err := f.Sync(); err != nil {
+		log.Crit("Failed to flush frozen tables", "err", err)
+	}
+	// Wipe out all data from the active database, always keeping the genesis
+	// block around to detect mismatching ancient stores on startup. The hash to
+	// number mappings are retained too, they are needed for lookups by hash.
+	batch := db.NewBatch()
+	for i, hash := range ancients {
+		number := first + uint64(i)
+		if number == 0 {
+			continue
+		}
+		deleteBlockWithoutNumber(batch, hash, number)
+		DeleteCanonicalHash(batch, number)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete frozen canonical blocks", "err", err)
+	}
+	batch.Reset()
+
+	// Wipe out side chains at the frozen heights as well, they can never become
+	// canonical again
+	for number := first; number < f.frozen; number++ {
+		canonical := ancients[number-first]
+
+		it := db.NewIteratorWithPrefix(append(headerPrefix, encodeBlockNumber(number)...))
+		for it.Next() {
+			key := it.Key()
+			if len(key) != len(headerPrefix)+8+common.HashLength {
+				continue
+			}
+			if hash := common.BytesToHash(key[len(headerPrefix)+8:]); hash != canonical {
+				log.Trace("Deleting side chain block", "number", number, "hash", hash)
+				DeleteConfirmations(db, batch, hash, number)
+				DeleteBlock(batch, hash, number)
+			}
+		}
+		it.Release()
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete frozen side chains", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete frozen side chains", "err", err)
+	}
+	log.Info("Deep froze chain segment", "blocks", len(ancients), "elapsed", common.PrettyDuration(time.Since(start)), "number", f.frozen-1, "hash", ancients[len(ancients)-1])
+	return true
+}
+
+// deleteBlockWithoutNumber removes all block data associated with a hash, except
+// the hash to number mapping.
+func deleteBlockWithoutNumber(db DatabaseDeleter, hash common.Hash, number uint64) {
+	for _, key := range [][]byte{
+		blockReceiptsKey(number, hash),
+		headerKey(number, hash),
+		blockBodyKey(number, hash),
+		headerTDKey(number, hash),
+	} {
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete frozen block", "err", err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/metrics"
+)
+
+// getChunk returns a chunk of data of the given size, filled with b.
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// newTestTable opens a freezer table with tiny data files in the given directory.
+func newTestTable(t *testing.T, dir string, name string, compressed bool) *freezerTable {
+	rm, wm := metrics.NewMeter(), metrics.NewMeter()
+	table, err := newCustomTable(dir, name, rm, wm, 50, !compressed)
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	return table
+}
+
+// Tests that items can be appended and retrieved, both compressed and raw, and
+// spread over multiple data files.
+func TestFreezerTableBasics(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatalf("failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		table := newTestTable(t, dir, "test", compressed)
+		for i := 0; i < 255; i++ {
+			if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+				t.Fatalf("compressed %v: failed to append item %d: %v", compressed, i, err)
+			}
+		}
+		if err := table.Append(100, getChunk(15, 100)); err == nil {
+			t.Fatalf("compressed %v: out of order append succeeded", compressed)
+		}
+		for i := 0; i < 255; i++ {
+			blob, err := table.Retrieve(uint64(i))
+			if err != nil {
+				t.Fatalf("compressed %v: failed to retrieve item %d: %v", compressed, i, err)
+			}
+			if !bytes.Equal(blob, getChunk(15, i)) {
+				t.Fatalf("compressed %v: item %d mismatch: have %x, want %x", compressed, i, blob, getChunk(15, i))
+			}
+		}
+		if _, err := table.Retrieve(255); err != errOutOfBounds {
+			t.Fatalf("compressed %v: out of bounds retrieval error mismatch: have %v, want %v", compressed, err, errOutOfBounds)
+		}
+		if err := table.verify(); err != nil {
+			t.Fatalf("compressed %v: verification failed: %v", compressed, err)
+		}
+		table.Close()
+	}
+}
+
+// Tests that a table is restored to its last consistent state after the index
+// file was partially written before a crash.
+func TestFreezerTableRepairIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := newTestTable(t, dir, "test", false)
+	for i := 0; i < 10; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	table.Close()
+
+	// Chop off half of the last index entry and reopen
+	index := filepath.Join(dir, "test.ridx")
+	stat, err := os.Stat(index)
+	if err != nil {
+		t.Fatalf("failed to stat index: %v", err)
+	}
+	if err := os.Truncate(index, stat.Size()-indexEntrySize/2); err != nil {
+		t.Fatalf("failed to truncate index: %v", err)
+	}
+	table = newTestTable(t, dir, "test", false)
+	defer table.Close()
+
+	if table.items != 9 {
+		t.Fatalf("item count mismatch: have %d, want %d", table.items, 9)
+	}
+	if _, err := table.Retrieve(9); err != errOutOfBounds {
+		t.Fatalf("truncated item still retrievable: %v", err)
+	}
+	if err := table.Append(9, getChunk(15, 9)); err != nil {
+		t.Fatalf("failed to append after repair: %v", err)
+	}
+	if err := table.verify(); err != nil {
+		t.Fatalf("verification failed after repair: %v", err)
+	}
+}
+
+// Tests that truncating a table spanning multiple data files removes the files
+// beyond the new head and allows appending again.
+func TestFreezerTableTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := newTestTable(t, dir, "test", false)
+	defer table.Close()
+
+	for i := 0; i < 30; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	if err := table.truncate(4); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if table.items != 4 {
+		t.Fatalf("item count mismatch: have %d, want %d", table.items, 4)
+	}
+	// Three 15 byte items fit into a 50 byte file, so only file 1 may remain
+	for filenum := uint32(2); filenum < 10; filenum++ {
+		if _, err := os.Stat(table.dataFileName(filenum)); err == nil {
+			t.Fatalf("data file %d not removed", filenum)
+		}
+	}
+	for i := 4; i < 8; i++ {
+		if err := table.Append(uint64(i), getChunk(15, 0xff-i)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		want := getChunk(15, i)
+		if i >= 4 {
+			want = getChunk(15, 0xff-i)
+		}
+		if blob, err := table.Retrieve(uint64(i)); err != nil || !bytes.Equal(blob, want) {
+			t.Fatalf("item %d mismatch: have %x (%v), want %x", i, blob, err, want)
+		}
+	}
+}
+
+// Tests that verification detects data files shorter than the index claims.
+func TestFreezerTableVerifyCorruption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := newTestTable(t, dir, "test", false)
+	defer table.Close()
+
+	for i := 0; i < 6; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	if err := table.verify(); err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if err := os.Truncate(table.dataFileName(0), 20); err != nil {
+		t.Fatalf("failed to corrupt data file: %v", err)
+	}
+	if err := table.verify(); err == nil {
+		t.Fatalf("verification succeeded on corrupt table")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+)
+
+// Tests that blocks deep enough below the head are moved into the freezer, and
+// that they remain transparently accessible through the regular accessors.
+func TestFreezerBlockStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Assemble a short canonical chain with a side block in the key-value store
+	kvdb := ethdb.NewMemDatabase()
+
+	var blocks []*types.Block
+	for i := 0; i < 6; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1), Extra: []byte("test block")}
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		block := types.NewBlockWithHeader(header)
+		WriteBlock(kvdb, block)
+		WriteReceipts(kvdb, block.Hash(), block.NumberU64(), types.Receipts{{CumulativeGasUsed: uint64(i), Logs: []*types.Log{}}})
+		WriteTd(kvdb, block.Hash(), block.NumberU64(), big.NewInt(int64(i+1)))
+		WriteCanonicalHash(kvdb, block.Hash(), block.NumberU64())
+		blocks = append(blocks, block)
+	}
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: blocks[0].Hash(), Extra: []byte("side block")})
+	WriteBlock(kvdb, side)
+	for _, block := range []*types.Block{blocks[1], side} {
+		WriteConfirmation(kvdb, common.Address{1}, &types.Confirmation{Number: 1, BlockHash: block.Hash(), Signature: make([]byte, 65)})
+	}
+	WriteHeadBlockHash(kvdb, blocks[5].Hash())
+
+	// Freeze everything but the two most recent blocks
+	freezer, err := newFreezer(dir, "", 2)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	if !freezer.freezeRound(kvdb) {
+		t.Fatalf("no blocks frozen")
+	}
+	if frozen, _ := freezer.Ancients(); frozen != 4 {
+		t.Fatalf("frozen block count mismatch: have %d, want %d", frozen, 4)
+	}
+	if freezer.freezeRound(kvdb) {
+		t.Fatalf("blocks frozen without head progression")
+	}
+	db := &freezerdb{Database: kvdb, freezer: freezer}
+	defer db.Close()
+
+	for i, block := range blocks {
+		number := block.NumberU64()
+		if frozen := i < 4; frozen && i > 0 {
+			if HasHeader(kvdb, block.Hash(), number) {
+				t.Fatalf("block %d: frozen header still in key-value store", i)
+			}
+			if hash := ReadCanonicalHash(kvdb, number); hash != (common.Hash{}) {
+				t.Fatalf("block %d: frozen canonical hash still in key-value store", i)
+			}
+		}
+		if hash := ReadCanonicalHash(db, number); hash != block.Hash() {
+			t.Fatalf("block %d: canonical hash mismatch: have %x, want %x", i, hash, block.Hash())
+		}
+		if entry := ReadBlock(db, block.Hash(), number); entry == nil || entry.Hash() != block.Hash() {
+			t.Fatalf("block %d: block mismatch: have %v, want %v", i, entry, block)
+		}
+		if receipts := ReadReceipts(db, block.Hash(), number); len(receipts) != 1 || receipts[0].CumulativeGasUsed != uint64(i) {
+			t.Fatalf("block %d: receipts mismatch: have %v", i, receipts)
+		}
+		if td := ReadTd(db, block.Hash(), number); td == nil || td.Int64() != int64(i+1) {
+			t.Fatalf("block %d: total difficulty mismatch: have %v, want %d", i, td, i+1)
+		}
+	}
+	if HasHeader(db, side.Hash(), side.NumberU64()) {
+		t.Fatalf("side chain block at frozen height not deleted")
+	}
+	// Frozen blocks keep their confirmations, pruned side chains lose them
+	if confirmations := ReadConfirmations(db, blocks[1].Hash(), 1); len(confirmations) != 1 {
+		t.Fatalf("frozen block confirmation count mismatch: have %d, want %d", len(confirmations), 1)
+	}
+	if confirmations := ReadConfirmations(db, side.Hash(), 1); len(confirmations) != 0 {
+		t.Fatalf("side chain block confirmations not deleted")
+	}
+	// Rewinding into the frozen range must truncate the ancients
+	if err := db.TruncateAncients(2); err != nil {
+		t.Fatalf("failed to truncate ancients: %v", err)
+	}
+	if hash := ReadCanonicalHash(db, 2); hash != (common.Hash{}) {
+		t.Fatalf("truncated canonical hash still returned: %x", hash)
+	}
+	if entry := ReadBlock(db, blocks[1].Hash(), 1); entry == nil {
+		t.Fatalf("retained ancient block missing")
+	}
+}
@@ -16,6 +16,8 @@
 
 package rawdb
 
+import "github.com/dsplinz2019/dsplinz/ethdb"
+
 // DatabaseReader wraps the Has and Get method of a backing data store.
 type DatabaseReader interface {
 	Has(key []byte) (bool, error)
@@ -31,3 +33,36 @@ type DatabaseWriter interface {
 type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
+
+// DatabaseIteratee wraps the NewIteratorWithPrefix method of a backing data store.
+type DatabaseIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) ethdb.Iterator
+}
+
+// AncientReader wraps the read methods of an append-only store of immutable
+// chain segments (the "ancients").
+type AncientReader interface {
+	// HasAncient returns whether an item of the given kind is frozen.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient retrieves a frozen item of the given kind.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of frozen blocks.
+	Ancients() (uint64, error)
+
+	// AncientSize returns the disk size of the given kind of ancient items.
+	AncientSize(kind string) (uint64, error)
+}
+
+// AncientWriter wraps the write methods of an ancient store.
+type AncientWriter interface {
+	// AppendAncient freezes the next block, all items need to be RLP encoded.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients discards all but the first n frozen blocks.
+	TruncateAncients(n uint64) error
+
+	// Sync flushes all the frozen items to disk.
+	Sync() error
+}
@@ -107,6 +107,11 @@ type (
 		account *common.Address
 		prev    uint64
 	}
+	laneNonceChange struct {
+		account *common.Address
+		lane    uint64
+		prev    uint64
+	}
 	storageChange struct {
 		account       *common.Address
 		key, prevalue common.Hash
@@ -187,6 +192,14 @@ func (ch nonceChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch laneNonceChange) revert(s *StateDB) {
+	s.getStateObject(*ch.account).setLaneNonce(ch.lane, ch.prev)
+}
+
+func (ch laneNonceChange) dirtied() *common.Address {
+	return ch.account
+}
+
 func (ch codeChange) revert(s *StateDB) {
 	s.getStateObject(*ch.account).setCode(common.BytesToHash(ch.prevhash), ch.prevcode)
 }
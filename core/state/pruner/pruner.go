@@ -0,0 +1,125 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements offline garbage collection of the state trie
+// nodes and contract codes stored in a chain database.
+package pruner
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/log"
+)
+
+// Stats contains the outcome of a pruning run.
+type Stats struct {
+	Retained int                // Number of state entries reachable from the retained roots
+	Deleted  int                // Number of unreachable state entries deleted
+	Size     common.StorageSize // Total size of the deleted entries
+}
+
+// Prune removes every trie node and contract code from the database that is
+// not reachable from one of the given state roots. It must not be run while
+// the database is in use by a node, as states that are not yet referenced by
+// any block would be deleted as well.
+//
+// Trie nodes and codes are stored under their bare keccak256 hash, pruning
+// only deletes entries which match this layout, so no other chain data can
+// be removed.
+func Prune(db ethdb.Database, roots []common.Hash) (*Stats, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no state roots to retain")
+	}
+	// Mark all the entries reachable from the retained roots
+	var (
+		start  = time.Now()
+		marked = make(map[common.Hash]struct{})
+		sdb    = state.NewDatabase(db)
+		logged = time.Now()
+	)
+	for _, root := range roots {
+		statedb, err := state.New(root, sdb)
+		if err != nil {
+			return nil, fmt.Errorf("missing state %x: %v", root, err)
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+			if it.Hash != (common.Hash{}) {
+				marked[it.Hash] = struct{}{}
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Marking reachable state entries", "root", root, "marked", len(marked), "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		if it.Error != nil {
+			return nil, fmt.Errorf("failed to iterate state %x: %v", root, it.Error)
+		}
+	}
+	log.Info("Marked reachable state entries", "roots", len(roots), "marked", len(marked), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Sweep every state entry that was not marked
+	var (
+		stats = &Stats{Retained: len(marked)}
+		batch = db.NewBatch()
+		it    = db.NewIteratorWithPrefix(nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if _, ok := marked[common.BytesToHash(key)]; ok {
+			continue
+		}
+		value := it.Value()
+		if !bytes.Equal(crypto.Keccak256(value), key) {
+			continue
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return nil, err
+		}
+		stats.Deleted++
+		stats.Size += common.StorageSize(len(key) + len(value))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Deleting unreachable state entries", "deleted", stats.Deleted, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Pruned state database", "retained", stats.Retained, "deleted", stats.Deleted, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/state"
+	"github.com/dsplinz2019/dsplinz/ethdb"
+)
+
+// commitState writes a state with the given balances on top of the given root
+// into the database and returns the new root.
+func commitState(t *testing.T, db ethdb.Database, root common.Hash, balances map[byte]int64) common.Hash {
+	sdb := state.NewDatabase(db)
+	statedb, err := state.New(root, sdb)
+	if err != nil {
+		t.Fatalf("failed to open state %x: %v", root, err)
+	}
+	for b, balance := range balances {
+		addr := common.BytesToAddress([]byte{b})
+		statedb.SetBalance(addr, big.NewInt(balance))
+		statedb.SetCode(addr, []byte{b, b, b})
+		statedb.SetState(addr, common.BytesToHash([]byte{b}), common.BytesToHash([]byte{b, b}))
+	}
+	root, err = statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return root
+}
+
+func TestPrune(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	// Non state data must survive pruning, even if its key is hash sized
+	foreign := common.BytesToHash([]byte("not a trie node")).Bytes()
+	db.Put(foreign, []byte("some value"))
+
+	old := commitState(t, db, common.Hash{}, map[byte]int64{1: 1, 2: 2, 3: 3})
+	mid := commitState(t, db, old, map[byte]int64{1: 10, 4: 4})
+	head := commitState(t, db, mid, map[byte]int64{2: 20, 5: 5})
+
+	stats, err := Prune(db, []common.Hash{mid, head})
+	if err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if stats.Deleted == 0 {
+		t.Fatalf("nothing pruned")
+	}
+	// The retained states must be fully accessible, the pruned one not
+	for _, root := range []common.Hash{mid, head} {
+		statedb, err := state.New(root, state.NewDatabase(db))
+		if err != nil {
+			t.Fatalf("retained state %x missing: %v", root, err)
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+		}
+		if it.Error != nil {
+			t.Fatalf("retained state %x incomplete: %v", root, it.Error)
+		}
+	}
+	if ok, _ := db.Has(old[:]); ok {
+		t.Errorf("pruned state root %x still present", old)
+	}
+	if ok, _ := db.Has(foreign); !ok {
+		t.Errorf("non state entry deleted")
+	}
+	// Pruning again must be a noop
+	if stats, err = Prune(db, []common.Hash{mid, head}); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if stats.Deleted != 0 {
+		t.Errorf("second pruning deleted %d entries", stats.Deleted)
+	}
+}
+
+func TestPruneMissingRoot(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	if _, err := Prune(db, []common.Hash{{0x01}}); err == nil {
+		t.Fatalf("pruning with missing root succeeded")
+	}
+	if _, err := Prune(db, nil); err == nil {
+		t.Fatalf("pruning without roots succeeded")
+	}
+}
@@ -90,7 +90,7 @@ type stateObject struct {
 
 // empty returns whether the account is considered empty.
 func (s *stateObject) empty() bool {
-	return s.data.Nonce == 0 && s.data.Balance.Sign() == 0 && bytes.Equal(s.data.CodeHash, emptyCodeHash)
+	return s.data.Nonce == 0 && len(s.data.Lanes) == 0 && s.data.Balance.Sign() == 0 && bytes.Equal(s.data.CodeHash, emptyCodeHash)
 }
 
 // Account is the Dsplinz consensus representation of accounts.
@@ -100,6 +100,16 @@ type Account struct {
 	Balance  *big.Int
 	Root     common.Hash // merkle root of the storage trie
 	CodeHash []byte
+
+	// Lanes are the nonces of the non-default nonce lanes of the account, sorted
+	// by lane. Accounts without any are encoded the same as before lanes existed.
+	Lanes []AccountLane `rlp:"tail"`
+}
+
+// AccountLane is the nonce of a single nonce lane of an account.
+type AccountLane struct {
+	Lane  uint64
+	Nonce uint64
 }
 
 // newObject creates a state object.
@@ -166,6 +176,23 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		return value
 	}
 	// Load from DB in case it is missing.
+	value = self.loadState(db, key)
+	self.cachedStorage[key] = value
+	return value
+}
+
+// GetCommittedState returns a value in account storage, ignoring any changes
+// made since the storage trie was last updated, i.e. by the current transaction.
+func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	if _, dirty := self.dirtyStorage[key]; dirty {
+		return self.loadState(db, key)
+	}
+	return self.GetState(db, key)
+}
+
+// loadState reads a value from the account's storage trie.
+func (self *stateObject) loadState(db Database, key common.Hash) common.Hash {
+	var value common.Hash
 	enc, err := self.getTrie(db).TryGet(key[:])
 	if err != nil {
 		self.setError(err)
@@ -178,7 +205,6 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		}
 		value.SetBytes(content)
 	}
-	self.cachedStorage[key] = value
 	return value
 }
 
@@ -339,6 +365,45 @@ func (self *stateObject) setNonce(nonce uint64) {
 	self.data.Nonce = nonce
 }
 
+// SetLaneNonce sets the nonce of a nonce lane of the account. Lane 0 is the
+// default nonce of the account.
+func (self *stateObject) SetLaneNonce(lane uint64, nonce uint64) {
+	if lane == 0 {
+		self.SetNonce(nonce)
+		return
+	}
+	self.db.journal.append(laneNonceChange{
+		account: &self.address,
+		lane:    lane,
+		prev:    self.LaneNonce(lane),
+	})
+	self.setLaneNonce(lane, nonce)
+}
+
+// setLaneNonce replaces the lanes of the account with a copy containing the new
+// nonce, as the lanes may be shared with copies of the state object. Lanes with
+// a zero nonce are dropped to keep the encoding canonical.
+func (self *stateObject) setLaneNonce(lane uint64, nonce uint64) {
+	lanes := make([]AccountLane, 0, len(self.data.Lanes)+1)
+	for _, l := range self.data.Lanes {
+		if l.Lane < lane {
+			lanes = append(lanes, l)
+		}
+	}
+	if nonce > 0 {
+		lanes = append(lanes, AccountLane{Lane: lane, Nonce: nonce})
+	}
+	for _, l := range self.data.Lanes {
+		if l.Lane > lane {
+			lanes = append(lanes, l)
+		}
+	}
+	if len(lanes) == 0 {
+		lanes = nil
+	}
+	self.data.Lanes = lanes
+}
+
 func (self *stateObject) CodeHash() []byte {
 	return self.data.CodeHash
 }
@@ -351,6 +416,20 @@ func (self *stateObject) Nonce() uint64 {
 	return self.data.Nonce
 }
 
+// LaneNonce returns the nonce of a nonce lane of the account, lane 0 being the
+// default nonce.
+func (self *stateObject) LaneNonce(lane uint64) uint64 {
+	if lane == 0 {
+		return self.data.Nonce
+	}
+	for _, l := range self.data.Lanes {
+		if l.Lane == lane {
+			return l.Nonce
+		}
+	}
+	return 0
+}
+
 // Never called, but must be present to allow stateObject to be used
 // as a vm.Account interface that also satisfies the vm.ContractRef
 // interface. Interfaces are awesome.
@@ -33,6 +33,7 @@ import (
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/core/types"
 	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/rlp"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -435,3 +436,53 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that nonce lanes are tracked independently of the account nonce, that
+// they are reverted along with the snapshots and that they survive a commit.
+func TestLaneNonces(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+	addr := common.BytesToAddress([]byte{0x01})
+
+	state.SetNonce(addr, 1)
+	state.SetLaneNonce(addr, 5, 3)
+	if nonce := state.GetNonce(addr); nonce != 1 {
+		t.Fatalf("account nonce mismatch: have %d, want %d", nonce, 1)
+	}
+	if nonce := state.GetLaneNonce(addr, 0); nonce != 1 {
+		t.Fatalf("default lane nonce mismatch: have %d, want %d", nonce, 1)
+	}
+	snapshot := state.Snapshot()
+	state.SetLaneNonce(addr, 5, 4)
+	state.SetLaneNonce(addr, 2, 1)
+	state.RevertToSnapshot(snapshot)
+
+	if nonce := state.GetLaneNonce(addr, 5); nonce != 3 {
+		t.Fatalf("reverted lane nonce mismatch: have %d, want %d", nonce, 3)
+	}
+	if nonce := state.GetLaneNonce(addr, 2); nonce != 0 {
+		t.Fatalf("reverted new lane nonce mismatch: have %d, want %d", nonce, 0)
+	}
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, _ = New(root, state.Database())
+	if nonce := state.GetLaneNonce(addr, 5); nonce != 3 {
+		t.Fatalf("committed lane nonce mismatch: have %d, want %d", nonce, 3)
+	}
+}
+
+// Tests that accounts without nonce lanes are encoded the same as before lanes
+// were introduced, keeping the state roots of existing chains intact.
+func TestLanelessAccountEncoding(t *testing.T) {
+	legacy, _ := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(2), common.Hash{3}, []byte{4}})
+	current, _ := rlp.EncodeToBytes(&Account{Nonce: 1, Balance: big.NewInt(2), Root: common.Hash{3}, CodeHash: []byte{4}})
+	if !bytes.Equal(legacy, current) {
+		t.Fatalf("account encoding mismatch: have %x, want %x", current, legacy)
+	}
+	var decoded Account
+	if err := rlp.DecodeBytes(legacy, &decoded); err != nil || decoded.Nonce != 1 || len(decoded.Lanes) != 0 {
+		t.Fatalf("legacy account decoding mismatch: %+v, %v", decoded, err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
 	"github.com/dsplinz2019/dsplinz/core/vm"
 	"github.com/dsplinz2019/dsplinz/log"
 	"github.com/dsplinz2019/dsplinz/params"
@@ -58,6 +59,7 @@ type StateTransition struct {
 	data       []byte
 	state      vm.StateDB
 	evm        *vm.EVM
+	calls      []*types.CallReceipt // Results of the executed calls of a batch message
 }
 
 // Message represents a message sent to a contract.
@@ -73,6 +75,22 @@ type Message interface {
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+
+	// FeePayer returns the account paying for the gas of a sponsored message,
+	// or nil if the sender pays for it.
+	FeePayer() *common.Address
+
+	// Calls returns the calls a batch message executes atomically instead of
+	// the single call described by the other fields, or nil.
+	Calls() []types.BatchCall
+
+	// Lane returns the nonce lane of the sender the message consumes a nonce
+	// of, 0 being the default nonce of the account.
+	Lane() uint64
+
+	// Schedule returns the number and the timestamp a block has to reach at
+	// least to execute the message. Zero bounds are not enforced.
+	Schedule() (uint64, uint64)
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
@@ -85,6 +103,30 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error)
 		gas = params.TxGas
 	}
 	// Bump the required gas by the amount of transactional data
+	return addDataGas(gas, data)
+}
+
+// BatchIntrinsicGas computes the 'intrinsic gas' for a batch message with the
+// given calls. The base transaction gas is paid once, every call pays for its
+// data and a fixed fee on top.
+func BatchIntrinsicGas(calls []types.BatchCall) (uint64, error) {
+	gas := params.TxGas
+	for _, call := range calls {
+		if math.MaxUint64-gas < params.TxBatchCallGas {
+			return 0, vm.ErrOutOfGas
+		}
+		gas += params.TxBatchCallGas
+
+		var err error
+		if gas, err = addDataGas(gas, call.Data); err != nil {
+			return 0, err
+		}
+	}
+	return gas, nil
+}
+
+// addDataGas bumps the given gas by the price of the transactional data.
+func addDataGas(gas uint64, data []byte) (uint64, error) {
 	if len(data) > 0 {
 		// Zero and non-zero bytes are priced differently
 		var nz uint64
@@ -132,6 +174,14 @@ func ApplyMessage(evm *vm.EVM, msg Message, gp *GasPool) ([]byte, uint64, bool,
 	return NewStateTransition(evm, msg, gp).TransitionDb()
 }
 
+// gasPayer returns the account paying for the gas of the message.
+func (st *StateTransition) gasPayer() common.Address {
+	if payer := st.msg.FeePayer(); payer != nil {
+		return *payer
+	}
+	return st.msg.From()
+}
+
 // to returns the recipient of the message.
 func (st *StateTransition) to() common.Address {
 	if st.msg == nil || st.msg.To() == nil /* contract creation */ {
@@ -151,7 +201,7 @@ func (st *StateTransition) useGas(amount uint64) error {
 
 func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(st.msg.Gas()), st.gasPrice)
-	if st.state.GetBalance(st.msg.From()).Cmp(mgval) < 0 {
+	if st.state.GetBalance(st.gasPayer()).Cmp(mgval) < 0 {
 		return errInsufficientBalanceForGas
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
@@ -160,14 +210,33 @@ func (st *StateTransition) buyGas() error {
 	st.gas += st.msg.Gas()
 
 	st.initialGas = st.msg.Gas()
-	st.state.SubBalance(st.msg.From(), mgval)
+	st.state.SubBalance(st.gasPayer(), mgval)
 	return nil
 }
 
 func (st *StateTransition) preCheck() error {
+	// Make sure sponsored messages are only executed once the fork allows them
+	if st.msg.FeePayer() != nil && !st.evm.ChainConfig().IsSponsoredTx(st.evm.BlockNumber) {
+		return types.ErrTxTypeNotSupported
+	}
+	if len(st.msg.Calls()) > 0 && !st.evm.ChainConfig().IsBatchTx(st.evm.BlockNumber) {
+		return types.ErrTxTypeNotSupported
+	}
+	if st.msg.Lane() != 0 && !st.evm.ChainConfig().IsLaneTx(st.evm.BlockNumber) {
+		return types.ErrTxTypeNotSupported
+	}
+	// Make sure scheduled messages are only executed once their time has come
+	if number, time := st.msg.Schedule(); number != 0 || time != 0 {
+		if !st.evm.ChainConfig().IsScheduledTx(st.evm.BlockNumber) {
+			return types.ErrTxTypeNotSupported
+		}
+		if st.evm.BlockNumber.Cmp(new(big.Int).SetUint64(number)) < 0 || st.evm.Time.Cmp(new(big.Int).SetUint64(time)) < 0 {
+			return ErrTxNotYetValid
+		}
+	}
 	// Make sure this transaction's nonce is correct.
 	if st.msg.CheckNonce() {
-		nonce := st.state.GetNonce(st.msg.From())
+		nonce := st.state.GetLaneNonce(st.msg.From(), st.msg.Lane())
 		if nonce < st.msg.Nonce() {
 			return ErrNonceTooHigh
 		} else if nonce > st.msg.Nonce() {
@@ -190,7 +259,12 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	contractCreation := msg.To() == nil
 
 	// Pay intrinsic gas
-	gas, err := IntrinsicGas(st.data, contractCreation, homestead)
+	var gas uint64
+	if calls := msg.Calls(); len(calls) > 0 {
+		gas, err = BatchIntrinsicGas(calls)
+	} else {
+		gas, err = IntrinsicGas(st.data, contractCreation, homestead)
+	}
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -205,11 +279,15 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		// error.
 		vmerr error
 	)
-	if contractCreation {
+	if calls := msg.Calls(); len(calls) > 0 {
+		// Increment the nonce once for the whole batch
+		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		ret, vmerr = st.applyCalls(sender, calls)
+	} else if contractCreation {
 		ret, _, st.gas, vmerr = evm.Create(sender, st.data, st.gas, st.value)
 	} else {
-		// Increment the nonce for the next transaction
-		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		// Increment the nonce of the lane for the next transaction
+		st.state.SetLaneNonce(msg.From(), msg.Lane(), st.state.GetLaneNonce(sender.Address(), msg.Lane())+1)
 		ret, st.gas, vmerr = evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 	if vmerr != nil {
@@ -227,6 +305,47 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	return ret, st.gasUsed(), vmerr != nil, err
 }
 
+// applyCalls executes the calls of a batch message one after the other. If any
+// of them fails, the state changes of all the calls are reverted.
+func (st *StateTransition) applyCalls(sender vm.AccountRef, calls []types.BatchCall) (ret []byte, err error) {
+	if !st.evm.CanTransfer(st.state, sender.Address(), st.value) {
+		return nil, vm.ErrInsufficientBalance
+	}
+	snapshot := st.state.Snapshot()
+
+	for _, call := range calls {
+		var (
+			gas  = st.gas
+			logs = len(st.state.TxLogs())
+		)
+		ret, st.gas, err = st.evm.Call(sender, call.To, call.Data, st.gas, call.Value)
+
+		receipt := &types.CallReceipt{
+			Status:  types.ReceiptStatusSuccessful,
+			GasUsed: gas - st.gas,
+			Logs:    append([]*types.Log{}, st.state.TxLogs()[logs:]...),
+		}
+		st.calls = append(st.calls, receipt)
+
+		if err != nil {
+			receipt.Status = types.ReceiptStatusFailed
+
+			// Revert all the calls, dropping their logs too
+			st.state.RevertToSnapshot(snapshot)
+			for _, executed := range st.calls {
+				executed.Logs = []*types.Log{}
+			}
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// CallReceipts returns the results of the calls executed by a batch message.
+func (st *StateTransition) CallReceipts() []*types.CallReceipt {
+	return st.calls
+}
+
 func (st *StateTransition) refundGas() {
 	// Apply refund counter, capped to half of the used gas.
 	refund := st.gasUsed() / 2
@@ -237,7 +356,7 @@ func (st *StateTransition) refundGas() {
 
 	// Return ETH for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	st.state.AddBalance(st.gasPayer(), remaining)
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
@@ -0,0 +1,185 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/event"
+)
+
+// Statuses a transaction can transition into while tracked by the pool.
+const (
+	TxLifecycleScheduled = "scheduled" // Transaction is waiting for its schedule to come
+	TxLifecycleQueued    = "queued"    // Transaction is waiting for a nonce gap to fill
+	TxLifecyclePending   = "pending"   // Transaction is executable and eligible for mining
+	TxLifecycleIncluded  = "included"  // Transaction got included into the canonical chain
+	TxLifecycleDropped   = "dropped"   // Transaction got removed from the pool, see the reason
+)
+
+// Reasons for which transactions are dropped from the pool.
+const (
+	TxDropUnderpriced        = "underpriced"             // Evicted by better paying transactions or a raised price limit
+	TxDropReplaced           = "replaced"                // Replaced by a transaction with the same nonce and a price bump
+	TxDropReplaceUnderpriced = "replacement underpriced" // Lost against an already pending transaction with the same nonce
+	TxDropQueueLimit         = "queue limit"             // Evicted due to the AccountQueue or GlobalQueue limits
+	TxDropPendingLimit       = "pending limit"           // Evicted due to the GlobalSlots limit
+	TxDropNonceTooLow        = "nonce too low"           // Nonce used up by another transaction, e.g. after a reorg
+	TxDropUnexecutable       = "unexecutable"            // Sender can't pay for it or it exceeds the block gas limit
+	TxDropExpired            = "expired"                 // Queued for longer than the configured Lifetime
+	TxDropCancelled          = "cancelled"               // Cancelled while waiting for its schedule
+)
+
+// TxTransition is a status change of a transaction tracked by the pool.
+type TxTransition struct {
+	Hash       common.Hash  `json:"hash"`
+	Status     string       `json:"status"`
+	Reason     string       `json:"reason,omitempty"`     // Why the transaction was dropped
+	ReplacedBy *common.Hash `json:"replacedBy,omitempty"` // Transaction replacing a dropped one
+	Time       time.Time    `json:"time"`
+}
+
+// txLifecycle records the status transitions of the pooled transactions into a
+// bounded ring buffer, and streams them to subscribers in their recorded order.
+type txLifecycle struct {
+	ring []*TxTransition // Ring buffer of the most recent transitions
+	next int             // Position of the next transition in the ring
+	lock sync.RWMutex    // Protects the ring and the unsent transitions
+
+	feed   event.Feed      // Feed to stream the transitions through
+	unsent []*TxTransition // Transitions recorded but not yet streamed
+	wake   chan struct{}   // Notification channel for new unsent transitions
+	quit   chan struct{}   // Termination channel of the streaming loop
+	wg     sync.WaitGroup
+}
+
+// newTxLifecycle creates a transaction lifecycle tracker retaining the given
+// number of most recent transitions, and starts streaming them.
+func newTxLifecycle(size int) *txLifecycle {
+	l := &txLifecycle{
+		ring: make([]*TxTransition, size),
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.loop()
+
+	return l
+}
+
+// record appends a new status transition of a transaction.
+func (l *txLifecycle) record(hash common.Hash, status string, reason string, replacement *common.Hash) {
+	transition := &TxTransition{
+		Hash:       hash,
+		Status:     status,
+		Reason:     reason,
+		ReplacedBy: replacement,
+		Time:       time.Now(),
+	}
+	l.lock.Lock()
+	l.ring[l.next] = transition
+	l.next = (l.next + 1) % len(l.ring)
+
+	// Queue up the transition for streaming, dropping the oldest ones if the
+	// subscribers are too slow to keep up
+	if len(l.unsent) >= len(l.ring) {
+		l.unsent = l.unsent[1:]
+	}
+	l.unsent = append(l.unsent, transition)
+	l.lock.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduled records that a transaction entered the store of the transactions
+// waiting for their schedule.
+func (l *txLifecycle) scheduled(hash common.Hash) {
+	l.record(hash, TxLifecycleScheduled, "", nil)
+}
+
+// queued records that a transaction entered the non-executable queue.
+func (l *txLifecycle) queued(hash common.Hash) {
+	l.record(hash, TxLifecycleQueued, "", nil)
+}
+
+// pending records that a transaction became executable.
+func (l *txLifecycle) pending(hash common.Hash) {
+	l.record(hash, TxLifecyclePending, "", nil)
+}
+
+// included records that a transaction left the pool by being mined.
+func (l *txLifecycle) included(hash common.Hash) {
+	l.record(hash, TxLifecycleIncluded, "", nil)
+}
+
+// dropped records that a transaction was removed from the pool for the given
+// reason.
+func (l *txLifecycle) dropped(hash common.Hash, reason string) {
+	l.record(hash, TxLifecycleDropped, reason, nil)
+}
+
+// replaced records that a transaction was dropped in favour of another one with
+// the same nonce.
+func (l *txLifecycle) replaced(hash common.Hash, replacement common.Hash) {
+	l.record(hash, TxLifecycleDropped, TxDropReplaced, &replacement)
+}
+
+// history retrieves the retained transitions of a transaction, oldest first.
+func (l *txLifecycle) history(hash common.Hash) []*TxTransition {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	var transitions []*TxTransition
+	for i := 0; i < len(l.ring); i++ {
+		if transition := l.ring[(l.next+i)%len(l.ring)]; transition != nil && transition.Hash == hash {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions
+}
+
+// loop streams the recorded transitions to the subscribers in batches.
+func (l *txLifecycle) loop() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-l.wake:
+			l.lock.Lock()
+			transitions := l.unsent
+			l.unsent = nil
+			l.lock.Unlock()
+
+			if len(transitions) > 0 {
+				l.feed.Send(TxStatusEvent{Transitions: transitions})
+			}
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// stop terminates the streaming of the transitions.
+func (l *txLifecycle) stop() {
+	close(l.quit)
+	l.wg.Wait()
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+)
+
+// Tests that the lifecycle tracker retains only the most recent transitions and
+// returns them oldest first.
+func TestTxLifecycleHistory(t *testing.T) {
+	lifecycle := newTxLifecycle(4)
+	defer lifecycle.stop()
+
+	var (
+		a = common.HexToHash("0x01")
+		b = common.HexToHash("0x02")
+	)
+	lifecycle.queued(a)
+	lifecycle.queued(b)
+	lifecycle.pending(a)
+	lifecycle.replaced(a, b)
+
+	history := lifecycle.history(a)
+	if len(history) != 3 {
+		t.Fatalf("history length mismatch: have %d, want %d", len(history), 3)
+	}
+	for i, status := range []string{TxLifecycleQueued, TxLifecyclePending, TxLifecycleDropped} {
+		if history[i].Status != status {
+			t.Errorf("transition %d: status mismatch: have %s, want %s", i, history[i].Status, status)
+		}
+	}
+	if history[2].Reason != TxDropReplaced || history[2].ReplacedBy == nil || *history[2].ReplacedBy != b {
+		t.Errorf("replacement mismatch: have %s by %v, want %s by %x", history[2].Reason, history[2].ReplacedBy, TxDropReplaced, b)
+	}
+	// Overflow the ring and ensure the oldest transitions are gone
+	lifecycle.pending(b)
+	lifecycle.included(b)
+
+	if history := lifecycle.history(a); len(history) != 2 || history[0].Status != TxLifecyclePending {
+		t.Errorf("overflown history mismatch: have %d transitions, want %d", len(history), 2)
+	}
+	if history := lifecycle.history(b); len(history) != 2 || history[1].Status != TxLifecycleIncluded {
+		t.Errorf("overflown history mismatch: have %d transitions, want %d", len(history), 2)
+	}
+}
+
+// Tests that the recorded transitions are streamed to subscribers in order.
+func TestTxLifecycleStreaming(t *testing.T) {
+	lifecycle := newTxLifecycle(16)
+	defer lifecycle.stop()
+
+	events := make(chan TxStatusEvent, 16)
+	sub := lifecycle.feed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	hash := common.HexToHash("0x01")
+	lifecycle.queued(hash)
+	lifecycle.pending(hash)
+	lifecycle.dropped(hash, TxDropUnderpriced)
+
+	var have []*TxTransition
+	for len(have) < 3 {
+		select {
+		case ev := <-events:
+			have = append(have, ev.Transitions...)
+		case <-time.After(time.Second):
+			t.Fatalf("status event timeout: have %d transitions, want %d", len(have), 3)
+		}
+	}
+	for i, status := range []string{TxLifecycleQueued, TxLifecyclePending, TxLifecycleDropped} {
+		if have[i].Status != status {
+			t.Errorf("transition %d: status mismatch: have %s, want %s", i, have[i].Status, status)
+		}
+	}
+	if have[2].Reason != TxDropUnderpriced {
+		t.Errorf("drop reason mismatch: have %s, want %s", have[2].Reason, TxDropUnderpriced)
+	}
+}
@@ -215,13 +215,20 @@ func (m *txSortedMap) Flatten() types.Transactions {
 	return txs
 }
 
+// txNonces returns the next expected nonce of a nonce lane of an account.
+type txNonces func(lane uint64) uint64
+
 // txList is a "list" of transactions belonging to an account, sorted by account
 // nonce. The same type can be used both for storing contiguous transactions for
 // the executable/pending queue; and for storing gapped transactions for the non-
 // executable/future queue, with minor behavioral changes.
+//
+// Transactions of the non-default nonce lanes of the account are maintained in
+// separate sorted maps, nonces only need to be continuous within a single lane.
 type txList struct {
-	strict bool         // Whether nonces are strictly continuous or not
-	txs    *txSortedMap // Heap indexed sorted hash map of the transactions
+	strict bool                    // Whether nonces are strictly continuous or not
+	txs    *txSortedMap            // Heap indexed sorted hash map of the default lane transactions
+	lanes  map[uint64]*txSortedMap // Heap indexed sorted hash maps of the other lanes' transactions
 
 	costcap *big.Int // Price of the highest costing transaction (reset only if exceeds balance)
 	gascap  uint64   // Gas limit of the highest spending transaction (reset only if exceeds block limit)
@@ -237,10 +244,38 @@ func newTxList(strict bool) *txList {
 	}
 }
 
+// lane retrieves the sorted map of the transactions of a nonce lane, or nil if
+// the lane doesn't have any.
+func (l *txList) lane(lane uint64) *txSortedMap {
+	if lane == 0 {
+		return l.txs
+	}
+	return l.lanes[lane]
+}
+
+// laneIds returns the identifiers of the nonce lanes with transactions in the
+// list, the default lane first and the rest in ascending order.
+func (l *txList) laneIds() []uint64 {
+	ids := make([]uint64, 0, len(l.lanes)+1)
+	for lane := range l.lanes {
+		ids = append(ids, lane)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return append([]uint64{0}, ids...)
+}
+
+// prune drops the sorted map of a non-default nonce lane if it became empty.
+func (l *txList) prune(lane uint64) {
+	if lane != 0 && l.lanes[lane] != nil && l.lanes[lane].Len() == 0 {
+		delete(l.lanes, lane)
+	}
+}
+
 // Overlaps returns whether the transaction specified has the same nonce as one
 // already contained within the list.
 func (l *txList) Overlaps(tx *types.Transaction) bool {
-	return l.txs.Get(tx.Nonce()) != nil
+	txs := l.lane(tx.Lane())
+	return txs != nil && txs.Get(tx.Nonce()) != nil
 }
 
 // Add tries to insert a new transaction into the list, returning whether the
@@ -249,19 +284,28 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 // If the new transaction is accepted into the list, the lists' cost and gas
 // thresholds are also potentially updated.
 func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
+	txs := l.lane(tx.Lane())
+	if txs == nil {
+		if l.lanes == nil {
+			l.lanes = make(map[uint64]*txSortedMap)
+		}
+		txs = newTxSortedMap()
+		l.lanes[tx.Lane()] = txs
+	}
 	// If there's an older better transaction, abort
-	old := l.txs.Get(tx.Nonce())
+	old := txs.Get(tx.Nonce())
 	if old != nil {
 		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
 		// Have to ensure that the new gas price is higher than the old gas
 		// price as well as checking the percentage threshold to ensure that
 		// this is accurate for low (Wei-level) gas price replacements
 		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
+			l.prune(tx.Lane())
 			return false, nil
 		}
 	}
 	// Otherwise overwrite the old transaction with the current one
-	l.txs.Put(tx)
+	txs.Put(tx)
 	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
 	}
@@ -272,10 +316,15 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 }
 
 // Forward removes all transactions from the list with a nonce lower than the
-// provided threshold. Every removed transaction is returned for any post-removal
-// maintenance.
-func (l *txList) Forward(threshold uint64) types.Transactions {
-	return l.txs.Forward(threshold)
+// next nonce of their lane. Every removed transaction is returned for any
+// post-removal maintenance.
+func (l *txList) Forward(nonces txNonces) types.Transactions {
+	removed := l.txs.Forward(nonces(0))
+	for lane, txs := range l.lanes {
+		removed = append(removed, txs.Forward(nonces(lane))...)
+		l.prune(lane)
+	}
+	return removed
 }
 
 // Filter removes all transactions from the list with a cost or gas limit higher
@@ -296,59 +345,109 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 	l.gascap = gasLimit
 
 	// Filter out all the transactions above the account's funds
-	removed := l.txs.Filter(func(tx *types.Transaction) bool { return tx.Cost().Cmp(costLimit) > 0 || tx.Gas() > gasLimit })
-
-	// If the list was strict, filter anything above the lowest nonce
-	var invalids types.Transactions
-
-	if l.strict && len(removed) > 0 {
-		lowest := uint64(math.MaxUint64)
-		for _, tx := range removed {
-			if nonce := tx.Nonce(); lowest > nonce {
-				lowest = nonce
+	var removed, invalids types.Transactions
+
+	for _, lane := range l.laneIds() {
+		txs := l.lane(lane)
+		drops := txs.Filter(func(tx *types.Transaction) bool { return tx.Cost().Cmp(costLimit) > 0 || tx.Gas() > gasLimit })
+		removed = append(removed, drops...)
+
+		// If the list was strict, filter anything above the lowest nonce of the lane
+		if l.strict && len(drops) > 0 {
+			lowest := uint64(math.MaxUint64)
+			for _, tx := range drops {
+				if nonce := tx.Nonce(); lowest > nonce {
+					lowest = nonce
+				}
 			}
+			invalids = append(invalids, txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })...)
 		}
-		invalids = l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
+		l.prune(lane)
 	}
 	return removed, invalids
 }
 
 // Cap places a hard limit on the number of items, returning all transactions
-// exceeding that limit.
+// exceeding that limit. The highest nonce transactions of the lanes holding the
+// most transactions are dropped first.
 func (l *txList) Cap(threshold int) types.Transactions {
-	return l.txs.Cap(threshold)
+	if len(l.lanes) == 0 {
+		return l.txs.Cap(threshold)
+	}
+	var drops types.Transactions
+	for size := l.Len(); size > threshold; size-- {
+		longest := uint64(0)
+		for lane, txs := range l.lanes {
+			if have, best := txs.Len(), l.lane(longest).Len(); have > best || (have == best && lane > longest) {
+				longest = lane
+			}
+		}
+		txs := l.lane(longest)
+		drops = append(drops, txs.Cap(txs.Len()-1)...)
+		l.prune(longest)
+	}
+	return drops
 }
 
 // Remove deletes a transaction from the maintained list, returning whether the
 // transaction was found, and also returning any transaction invalidated due to
 // the deletion (strict mode only).
 func (l *txList) Remove(tx *types.Transaction) (bool, types.Transactions) {
+	lane := tx.Lane()
+	txs := l.lane(lane)
+	if txs == nil {
+		return false, nil
+	}
+	defer l.prune(lane)
+
 	// Remove the transaction from the set
 	nonce := tx.Nonce()
-	if removed := l.txs.Remove(nonce); !removed {
+	if removed := txs.Remove(nonce); !removed {
 		return false, nil
 	}
 	// In strict mode, filter out non-executable transactions
 	if l.strict {
-		return true, l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > nonce })
+		return true, txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > nonce })
 	}
 	return true, nil
 }
 
 // Ready retrieves a sequentially increasing list of transactions starting at the
-// provided nonce that is ready for processing. The returned transactions will be
-// removed from the list.
+// provided nonce of each lane that is ready for processing. The returned
+// transactions will be removed from the list.
 //
 // Note, all transactions with nonces lower than start will also be returned to
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
-func (l *txList) Ready(start uint64) types.Transactions {
-	return l.txs.Ready(start)
+func (l *txList) Ready(nonces txNonces) types.Transactions {
+	ready := l.txs.Ready(nonces(0))
+	for _, lane := range l.laneIds()[1:] {
+		ready = append(ready, l.lanes[lane].Ready(nonces(lane))...)
+		l.prune(lane)
+	}
+	return ready
+}
+
+// Gapped removes and returns the transactions of all the lanes not starting at
+// their next nonce. In the pending list this should never happen.
+func (l *txList) Gapped(nonces txNonces) types.Transactions {
+	var gapped types.Transactions
+	for _, lane := range l.laneIds() {
+		if txs := l.lane(lane); txs.Len() > 0 && txs.Get(nonces(lane)) == nil {
+			gapped = append(gapped, txs.Cap(0)...)
+			l.prune(lane)
+		}
+	}
+	return gapped
 }
 
 // Len returns the length of the transaction list.
 func (l *txList) Len() int {
-	return l.txs.Len()
+	size := l.txs.Len()
+	for _, txs := range l.lanes {
+		size += txs.Len()
+	}
+	return size
 }
 
 // Empty returns whether the list of transactions is empty or not.
@@ -359,38 +458,51 @@ func (l *txList) Empty() bool {
 // Flatten creates a nonce-sorted slice of transactions based on the loosely
 // sorted internal representation. The result of the sorting is cached in case
 // it's requested again before any modifications are made to the contents.
+//
+// Transactions of the non-default lanes follow the default lane ones, grouped
+// by lane in ascending order, each lane sorted by nonce.
 func (l *txList) Flatten() types.Transactions {
-	return l.txs.Flatten()
+	if len(l.lanes) == 0 {
+		return l.txs.Flatten()
+	}
+	txs := l.txs.Flatten()
+	for _, lane := range l.laneIds()[1:] {
+		txs = append(txs, l.lanes[lane].Flatten()...)
+	}
+	return txs
 }
 
 // priceHeap is a heap.Interface implementation over transactions for retrieving
 // price-sorted transactions to discard when the pool fills up.
-type priceHeap []*types.Transaction
+type priceHeap struct {
+	txs    []*types.Transaction
+	policy TxPolicy // Policy to break price ties with
+}
 
-func (h priceHeap) Len() int      { return len(h) }
-func (h priceHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *priceHeap) Len() int      { return len(h.txs) }
+func (h *priceHeap) Swap(i, j int) { h.txs[i], h.txs[j] = h.txs[j], h.txs[i] }
 
-func (h priceHeap) Less(i, j int) bool {
+func (h *priceHeap) Less(i, j int) bool {
 	// Sort primarily by price, returning the cheaper one
-	switch h[i].GasPrice().Cmp(h[j].GasPrice()) {
+	switch h.txs[i].GasPrice().Cmp(h.txs[j].GasPrice()) {
 	case -1:
 		return true
 	case 1:
 		return false
 	}
-	// If the prices match, stabilize via nonces (high nonce is worse)
-	return h[i].Nonce() > h[j].Nonce()
+	// If the prices match, let the policy decide which one is worse
+	return h.policy.Evicts(h.txs[i], h.txs[j])
 }
 
 func (h *priceHeap) Push(x interface{}) {
-	*h = append(*h, x.(*types.Transaction))
+	h.txs = append(h.txs, x.(*types.Transaction))
 }
 
 func (h *priceHeap) Pop() interface{} {
-	old := *h
+	old := h.txs
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.txs = old[0 : n-1]
 	return x
 }
 
@@ -402,11 +514,12 @@ type txPricedList struct {
 	stales int        // Number of stale price points to (re-heap trigger)
 }
 
-// newTxPricedList creates a new price-sorted transaction heap.
-func newTxPricedList(all *txLookup) *txPricedList {
+// newTxPricedList creates a new price-sorted transaction heap, breaking price
+// ties with the given policy.
+func newTxPricedList(all *txLookup, policy TxPolicy) *txPricedList {
 	return &txPricedList{
 		all:   all,
-		items: new(priceHeap),
+		items: &priceHeap{policy: policy},
 	}
 }
 
@@ -421,15 +534,23 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 func (l *txPricedList) Removed() {
 	// Bump the stale counter, but exit if still too low (< 25%)
 	l.stales++
-	if l.stales <= len(*l.items)/4 {
+	if l.stales <= l.items.Len()/4 {
 		return
 	}
 	// Seems we've reached a critical number of stale transactions, reheap
-	reheap := make(priceHeap, 0, l.all.Count())
+	l.Reheap(l.items.policy)
+}
 
-	l.stales, l.items = 0, &reheap
+// Reheap drops all stale price points and rebuilds the heap from the contents
+// of the pool, breaking price ties with the given policy.
+func (l *txPricedList) Reheap(policy TxPolicy) {
+	reheap := &priceHeap{
+		txs:    make([]*types.Transaction, 0, l.all.Count()),
+		policy: policy,
+	}
+	l.stales, l.items = 0, reheap
 	l.all.Range(func(hash common.Hash, tx *types.Transaction) bool {
-		*l.items = append(*l.items, tx)
+		l.items.txs = append(l.items.txs, tx)
 		return true
 	})
 	heap.Init(l.items)
@@ -441,7 +562,7 @@ func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transact
 	drop := make(types.Transactions, 0, 128) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)  // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 {
+	for l.items.Len() > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if l.all.Get(tx.Hash()) == nil {
@@ -474,8 +595,8 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		return false
 	}
 	// Discard stale price points if found at the heap start
-	for len(*l.items) > 0 {
-		head := []*types.Transaction(*l.items)[0]
+	for l.items.Len() > 0 {
+		head := l.items.txs[0]
 		if l.all.Get(head.Hash()) == nil {
 			l.stales--
 			heap.Pop(l.items)
@@ -484,11 +605,11 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		break
 	}
 	// Check if the transaction is underpriced or not
-	if len(*l.items) == 0 {
+	if l.items.Len() == 0 {
 		log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
 		return false
 	}
-	cheapest := []*types.Transaction(*l.items)[0]
+	cheapest := l.items.txs[0]
 	return cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0
 }
 
@@ -498,7 +619,7 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 && count > 0 {
+	for l.items.Len() > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if l.all.Get(tx.Hash()) == nil {
@@ -17,9 +17,11 @@
 package core
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/core/types"
 	"github.com/dsplinz2019/dsplinz/crypto"
 )
@@ -49,3 +51,41 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the transactions of the different nonce lanes of an account are
+// maintained independently, gaps and removals only affecting their own lane.
+func TestTxListLanes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(1))
+
+	laneTransaction := func(lane uint64, nonce uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewLaneTransaction(big.NewInt(1), lane, nonce, common.Address{}, big.NewInt(100), 0, big.NewInt(1), nil), signer, key)
+		return tx
+	}
+	txs := types.Transactions{transaction(0, 0, key), transaction(1, 0, key), laneTransaction(3, 0), laneTransaction(3, 1), laneTransaction(5, 2)}
+
+	list := newTxList(true)
+	for _, v := range rand.Perm(len(txs)) {
+		list.Add(txs[v], DefaultTxPoolConfig.PriceBump)
+	}
+	for i, tx := range list.Flatten() {
+		if tx != txs[i] {
+			t.Errorf("item %d: transaction mismatch: have lane %d nonce %d, want lane %d nonce %d", i, tx.Lane(), tx.Nonce(), txs[i].Lane(), txs[i].Nonce())
+		}
+	}
+	if !list.Overlaps(laneTransaction(3, 1)) || list.Overlaps(laneTransaction(4, 1)) {
+		t.Errorf("lane overlap mismatch")
+	}
+	// Only the lane not starting at its next nonce should be gapped
+	nonces := func(lane uint64) uint64 { return 0 }
+	if gapped := list.Gapped(nonces); len(gapped) != 1 || gapped[0] != txs[4] {
+		t.Errorf("gapped transactions mismatch: have %v, want %v", gapped, txs[4:])
+	}
+	// Removing a transaction should only invalidate the rest of its own lane
+	if removed, invalids := list.Remove(txs[2]); !removed || len(invalids) != 1 || invalids[0] != txs[3] {
+		t.Errorf("removal mismatch: removed %v, invalids %v", removed, invalids)
+	}
+	if ready := list.Ready(nonces); len(ready) != 2 || !list.Empty() {
+		t.Errorf("ready transactions mismatch: have %d, want %d", len(ready), 2)
+	}
+}
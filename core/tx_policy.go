@@ -0,0 +1,275 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"container/heap"
+	"sync/atomic"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/metrics"
+)
+
+const (
+	// TxPolicyPrice orders transactions by gas price only, breaking ties on
+	// eviction by nonce. This is the default behaviour of the pool.
+	TxPolicyPrice = "price"
+
+	// TxPolicyFIFO orders transactions by gas price, breaking ties in favour of
+	// the transaction seen first, both when packing blocks and when evicting.
+	TxPolicyFIFO = "fifo"
+)
+
+// cappedSenderCounter counts the senders that reached the per-sender fairness
+// cap while packing a block.
+var cappedSenderCounter = metrics.NewRegisteredCounter("txpool/policy/capped", nil)
+
+// TxOrdering is an iterator over a set of pending transactions, returning them
+// in the order they should be included into a block while honouring the nonce
+// order of each account. *types.TransactionsByPriceAndNonce implements it.
+type TxOrdering interface {
+	// Peek returns the next transaction to include, or nil if none are left.
+	Peek() *types.Transaction
+
+	// Shift replaces the current head with the next transaction of the same
+	// account nonce lane.
+	Shift()
+
+	// Pop removes the current head without replacing it with the next one of
+	// the same account nonce lane, skipping all the lane's remaining transactions.
+	Pop()
+}
+
+// TxPolicy decides in which order pending transactions are packed into blocks
+// and which of the equally priced transactions are evicted first once the pool
+// is full. Gas price remains the primary criterion for both.
+type TxPolicy interface {
+	// Name returns the name of the policy, as reported over RPC.
+	Name() string
+
+	// Ordering returns an iterator over the pending transactions, grouped by
+	// account and sorted by nonce. The map is reowned by the iterator.
+	Ordering(signer types.Signer, pending map[common.Address]types.Transactions) TxOrdering
+
+	// Evicts reports whether transaction a is to be evicted before b, provided
+	// that both pay the same gas price.
+	Evicts(a, b *types.Transaction) bool
+
+	// Stats returns the policy specific counters.
+	Stats() map[string]uint64
+}
+
+// NewTxPolicy creates the built-in policy with the given name, wrapping it into
+// a per-sender fairness cap if senderCap is non-zero. Unknown names fall back to
+// the price policy.
+func NewTxPolicy(name string, senderCap uint64) TxPolicy {
+	var policy TxPolicy
+	switch name {
+	case TxPolicyFIFO:
+		policy = fifoPolicy{}
+	default:
+		policy = pricePolicy{}
+	}
+	if senderCap > 0 {
+		policy = NewFairPolicy(policy, senderCap)
+	}
+	return policy
+}
+
+// pricePolicy orders transactions by gas price and evicts the higher nonces of
+// equally priced transactions first.
+type pricePolicy struct{}
+
+func (pricePolicy) Name() string { return TxPolicyPrice }
+
+func (pricePolicy) Ordering(signer types.Signer, pending map[common.Address]types.Transactions) TxOrdering {
+	return types.NewTransactionsByPriceAndNonce(signer, pending)
+}
+
+func (pricePolicy) Evicts(a, b *types.Transaction) bool { return a.Nonce() > b.Nonce() }
+
+func (pricePolicy) Stats() map[string]uint64 { return map[string]uint64{} }
+
+// fifoPolicy orders transactions by gas price, serving equally priced ones in
+// the order they were first seen and evicting the most recent ones first.
+type fifoPolicy struct{}
+
+func (fifoPolicy) Name() string { return TxPolicyFIFO }
+
+func (fifoPolicy) Ordering(signer types.Signer, pending map[common.Address]types.Transactions) TxOrdering {
+	return newTxsByPriceAndTime(signer, pending)
+}
+
+func (fifoPolicy) Evicts(a, b *types.Transaction) bool { return a.Time().After(b.Time()) }
+
+func (fifoPolicy) Stats() map[string]uint64 { return map[string]uint64{} }
+
+// txsByPriceAndTime is a price and arrival time sorted heap of the next
+// transaction of each account.
+type txsByPriceAndTime []*types.Transaction
+
+func (h txsByPriceAndTime) Len() int      { return len(h) }
+func (h txsByPriceAndTime) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h txsByPriceAndTime) Less(i, j int) bool {
+	// Sort primarily by price, returning the more expensive one
+	switch h[i].GasPrice().Cmp(h[j].GasPrice()) {
+	case -1:
+		return false
+	case 1:
+		return true
+	}
+	// If the prices match, serve the older transaction
+	return h[i].Time().Before(h[j].Time())
+}
+
+func (h *txsByPriceAndTime) Push(x interface{}) {
+	*h = append(*h, x.(*types.Transaction))
+}
+
+func (h *txsByPriceAndTime) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+// txOrderingFIFO is the TxOrdering of the FIFO policy.
+type txOrderingFIFO struct {
+	txs    map[types.SenderLane]types.Transactions // Per account lane nonce-sorted list of transactions
+	heads  txsByPriceAndTime                       // Next transaction for each unique account lane
+	signer types.Signer                            // Signer for the set of transactions
+}
+
+// newTxsByPriceAndTime creates a transaction set that retrieves price sorted
+// transactions in a nonce-honouring way, serving equally priced transactions in
+// their order of arrival.
+func newTxsByPriceAndTime(signer types.Signer, txs map[common.Address]types.Transactions) *txOrderingFIFO {
+	first, lanes := types.SplitLanes(signer, txs)
+	heads := txsByPriceAndTime(first)
+	heap.Init(&heads)
+
+	return &txOrderingFIFO{
+		txs:    lanes,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction by price and arrival time.
+func (t *txOrderingFIFO) Peek() *types.Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift replaces the current best head with the next one from the same account
+// lane.
+func (t *txOrderingFIFO) Shift() {
+	acc, _ := types.Sender(t.signer, t.heads[0])
+	lane := types.SenderLane{Sender: acc, Lane: t.heads[0].Lane()}
+	if txs, ok := t.txs[lane]; ok && len(txs) > 0 {
+		t.heads[0], t.txs[lane] = txs[0], txs[1:]
+		heap.Fix(&t.heads, 0)
+	} else {
+		heap.Pop(&t.heads)
+	}
+}
+
+// Pop removes the best transaction, *not* replacing it with the next one from
+// the same account lane.
+func (t *txOrderingFIFO) Pop() {
+	heap.Pop(&t.heads)
+}
+
+// FairPolicy wraps another policy, limiting the number of transactions a single
+// sender may get included into one block. Once the cap is reached the remaining
+// transactions of the sender are left for later blocks, giving other senders a
+// chance at equal prices.
+type FairPolicy struct {
+	capped uint64 // Number of senders that reached the cap (atomic, keep first for alignment)
+
+	TxPolicy
+	limit uint64 // Maximum number of transactions per sender and block
+}
+
+// NewFairPolicy wraps the given policy into a per-sender fairness cap.
+func NewFairPolicy(policy TxPolicy, limit uint64) *FairPolicy {
+	return &FairPolicy{TxPolicy: policy, limit: limit}
+}
+
+// Name returns the name of the wrapped policy, tagged with the fairness cap.
+func (p *FairPolicy) Name() string { return p.TxPolicy.Name() + "+fair" }
+
+// Ordering wraps the ordering of the inner policy into a per-sender cap.
+func (p *FairPolicy) Ordering(signer types.Signer, pending map[common.Address]types.Transactions) TxOrdering {
+	return &txOrderingFair{
+		TxOrdering: p.TxPolicy.Ordering(signer, pending),
+		policy:     p,
+		signer:     signer,
+		included:   make(map[common.Address]uint64),
+	}
+}
+
+// Stats extends the counters of the wrapped policy with the fairness ones.
+func (p *FairPolicy) Stats() map[string]uint64 {
+	stats := p.TxPolicy.Stats()
+	stats["senderCap"] = p.limit
+	stats["capped"] = atomic.LoadUint64(&p.capped)
+	return stats
+}
+
+// txOrderingFair is the TxOrdering of the fairness policy.
+type txOrderingFair struct {
+	TxOrdering
+
+	policy   *FairPolicy
+	signer   types.Signer
+	included map[common.Address]uint64 // Number of transactions shifted out per sender
+}
+
+// Peek returns the next transaction to include, skipping the remaining lanes of
+// the accounts that have already reached their fairness cap.
+func (t *txOrderingFair) Peek() *types.Transaction {
+	for {
+		tx := t.TxOrdering.Peek()
+		if tx == nil {
+			return nil
+		}
+		if acc, _ := types.Sender(t.signer, tx); t.included[acc] < t.policy.limit {
+			return tx
+		}
+		t.TxOrdering.Pop()
+	}
+}
+
+// Shift replaces the current head with the next transaction of the same account,
+// unless the account has already reached its fairness cap.
+func (t *txOrderingFair) Shift() {
+	acc, _ := types.Sender(t.signer, t.Peek())
+	if t.included[acc]++; t.included[acc] < t.policy.limit {
+		t.TxOrdering.Shift()
+		return
+	}
+	t.TxOrdering.Pop()
+
+	atomic.AddUint64(&t.policy.capped, 1)
+	cappedSenderCounter.Inc(1)
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/crypto"
+)
+
+// drainOrdering shifts through an ordering, returning the transactions in the
+// order they would be included into a block.
+func drainOrdering(ordering TxOrdering) types.Transactions {
+	var txs types.Transactions
+	for tx := ordering.Peek(); tx != nil; tx = ordering.Peek() {
+		txs = append(txs, tx)
+		ordering.Shift()
+	}
+	return txs
+}
+
+// Tests that the FIFO policy orders transactions by price, serving equally priced
+// ones in their order of arrival while honouring the account nonces.
+func TestTxPolicyFIFOOrdering(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	// Create the transactions in a well defined arrival order
+	var (
+		signer  = types.HomesteadSigner{}
+		pending = make(map[common.Address]types.Transactions)
+		want    types.Transactions
+	)
+	for _, spec := range []struct {
+		key   int
+		nonce uint64
+		price int64
+	}{
+		{0, 0, 1}, {1, 0, 1}, {2, 0, 1}, {0, 1, 1}, {1, 1, 1}, {3, 0, 2},
+	} {
+		tx := pricedTransaction(spec.nonce, 100000, big.NewInt(spec.price), keys[spec.key])
+		if spec.price == 1 {
+			want = append(want, tx)
+		} else {
+			want = append(types.Transactions{tx}, want...)
+		}
+		addr := crypto.PubkeyToAddress(keys[spec.key].PublicKey)
+		pending[addr] = append(pending[addr], tx)
+
+		time.Sleep(time.Millisecond)
+	}
+	have := drainOrdering(NewTxPolicy(TxPolicyFIFO, 0).Ordering(signer, pending))
+	if len(have) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i].Hash() != want[i].Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, have[i].Hash(), want[i].Hash())
+		}
+	}
+}
+
+// Tests that the fairness policy stops including the transactions of a sender
+// once the per-block cap is reached, but still serves the other senders.
+func TestTxPolicyFairOrdering(t *testing.T) {
+	rich, _ := crypto.GenerateKey()
+	poor, _ := crypto.GenerateKey()
+
+	pending := map[common.Address]types.Transactions{
+		crypto.PubkeyToAddress(rich.PublicKey): {
+			pricedTransaction(0, 100000, big.NewInt(3), rich),
+			pricedTransaction(1, 100000, big.NewInt(3), rich),
+			pricedTransaction(2, 100000, big.NewInt(3), rich),
+			pricedTransaction(3, 100000, big.NewInt(3), rich),
+		},
+		crypto.PubkeyToAddress(poor.PublicKey): {
+			pricedTransaction(0, 100000, big.NewInt(1), poor),
+		},
+	}
+	policy := NewTxPolicy(TxPolicyPrice, 2)
+	if name := policy.Name(); name != "price+fair" {
+		t.Fatalf("policy name mismatch: have %s, want %s", name, "price+fair")
+	}
+	have := drainOrdering(policy.Ordering(types.HomesteadSigner{}, pending))
+	if len(have) != 3 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(have), 3)
+	}
+	for i, nonce := range []uint64{0, 1, 0} {
+		if have[i].Nonce() != nonce {
+			t.Errorf("transaction %d: nonce mismatch: have %d, want %d", i, have[i].Nonce(), nonce)
+		}
+	}
+	if capped := policy.Stats()["capped"]; capped != 1 {
+		t.Errorf("capped sender count mismatch: have %d, want %d", capped, 1)
+	}
+}
+
+// Tests that the FIFO policy evicts the most recently seen of the equally priced
+// transactions first.
+func TestTxPolicyFIFOEviction(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	txs := make(types.Transactions, len(keys))
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		txs[i] = pricedTransaction(0, 100000, big.NewInt(1), keys[i])
+
+		time.Sleep(time.Millisecond)
+	}
+	all := newTxLookup()
+	priced := newTxPricedList(all, NewTxPolicy(TxPolicyFIFO, 0))
+	for _, tx := range txs {
+		all.Add(tx)
+		priced.Put(tx)
+	}
+	drop := priced.Discard(2, newAccountSet(types.HomesteadSigner{}))
+	if len(drop) != 2 {
+		t.Fatalf("dropped transaction count mismatch: have %d, want %d", len(drop), 2)
+	}
+	if drop[0].Hash() != txs[2].Hash() || drop[1].Hash() != txs[1].Hash() {
+		t.Errorf("dropped transactions mismatch: have %x, %x, want %x, %x", drop[0].Hash(), drop[1].Hash(), txs[2].Hash(), txs[1].Hash())
+	}
+}
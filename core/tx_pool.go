@@ -60,6 +60,14 @@ var (
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
 
+	// ErrInvalidFeePayer is returned if a sponsored transaction's fee payer
+	// signature is invalid or does not belong to the declared fee payer.
+	ErrInvalidFeePayer = errors.New("invalid fee payer")
+
+	// ErrInsufficientFeePayerFunds is returned if the fee payer of a sponsored
+	// transaction can't cover the gas costs of it.
+	ErrInsufficientFeePayerFunds = errors.New("insufficient fee payer funds for gas * price")
+
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
@@ -76,6 +84,15 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrScheduleFull is returned if a scheduled transaction is not yet valid
+	// and the store of the transactions waiting for their schedule is full.
+	ErrScheduleFull = errors.New("scheduled transaction store full")
+
+	// ErrNotScheduled is returned if a transaction to cancel is not waiting for
+	// its schedule, either because it is unknown or because it got released
+	// into the pool already.
+	ErrNotScheduled = errors.New("transaction not scheduled")
 )
 
 var (
@@ -99,6 +116,7 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+	exemptTxCounter      = metrics.NewRegisteredCounter("txpool/policy/exempt", nil) // Local transactions accepted below the price limit
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -123,9 +141,13 @@ type blockChain interface {
 
 // TxPoolConfig are the configuration parameters of the transaction pool.
 type TxPoolConfig struct {
-	NoLocals  bool          // Whether local transaction handling should be disabled
-	Journal   string        // Journal of local transactions to survive node restarts
-	Rejournal time.Duration // Time interval to regenerate the local transaction journal
+	Locals    []common.Address // Addresses that should be treated by default as local
+	NoLocals  bool             // Whether local transaction handling should be disabled
+	Journal   string           // Journal of local transactions to survive node restarts
+	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+
+	Snapshot   string        // Snapshot of all pooled transactions to survive node restarts (empty = disabled)
+	Resnapshot time.Duration // Time interval to regenerate the transaction pool snapshot
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -135,7 +157,12 @@ type TxPoolConfig struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
-	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	Lifetime  time.Duration // Maximum amount of time non-executable transaction are queued
+	History   uint64        // Number of recent transaction status transitions to retain
+	Scheduled uint64        // Maximum number of scheduled transactions waiting for their schedule
+
+	Policy    string // Ordering and eviction policy of equally priced transactions ("price" or "fifo")
+	SenderCap uint64 // Maximum number of transactions included per sender and block (0 = unlimited)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -144,6 +171,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	Resnapshot: 5 * time.Minute,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -152,7 +181,11 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
-	Lifetime: 3 * time.Hour,
+	Lifetime:  3 * time.Hour,
+	History:   8192,
+	Scheduled: 1024,
+
+	Policy: TxPolicyPrice,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -163,6 +196,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Resnapshot < time.Second {
+		log.Warn("Sanitizing invalid txpool snapshot time", "provided", conf.Resnapshot, "updated", time.Second)
+		conf.Resnapshot = time.Second
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
@@ -171,6 +208,18 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.History < 1 {
+		log.Warn("Sanitizing invalid txpool history", "provided", conf.History, "updated", DefaultTxPoolConfig.History)
+		conf.History = DefaultTxPoolConfig.History
+	}
+	if conf.Scheduled < 1 {
+		log.Warn("Sanitizing invalid txpool scheduled slots", "provided", conf.Scheduled, "updated", DefaultTxPoolConfig.Scheduled)
+		conf.Scheduled = DefaultTxPoolConfig.Scheduled
+	}
+	if conf.Policy != TxPolicyPrice && conf.Policy != TxPolicyFIFO {
+		log.Warn("Sanitizing invalid txpool policy", "provided", conf.Policy, "updated", DefaultTxPoolConfig.Policy)
+		conf.Policy = DefaultTxPoolConfig.Policy
+	}
 	return conf
 }
 
@@ -195,7 +244,10 @@ type TxPool struct {
 
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
+	pendingLanes  map[txLane]uint64   // Virtual nonces of the non-default nonce lanes
 	currentMaxGas uint64              // Current gas limit for transaction caps
+	currentNumber uint64              // Number of the current head block
+	currentTime   uint64              // Timestamp of the current head block
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
@@ -206,9 +258,27 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	scheduled *txSchedule // Scheduled transactions waiting for their schedule, not in all
+
+	policy TxPolicy // Policy ordering pending transactions and breaking eviction ties
+	exempt uint64   // Number of local transactions accepted below the price limit
+
+	lifecycle *txLifecycle             // Recent status transitions of the pooled transactions
+	mined     map[common.Hash]struct{} // Transactions included by the head being reset to
+
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	sponsored bool // Whether sponsored transactions are accepted for the next block
+	batch     bool // Whether batch transactions are accepted for the next block
+	lanes     bool // Whether nonce lane transactions are accepted for the next block
+	schedules bool // Whether scheduled transactions are accepted for the next block
+}
+
+// txLane identifies a nonce lane of an account.
+type txLane struct {
+	addr common.Address
+	lane uint64
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -227,11 +297,18 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
 		all:         newTxLookup(),
+		scheduled:   newTxSchedule(),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		policy:      NewTxPolicy(config.Policy, config.SenderCap),
+		lifecycle:   newTxLifecycle(int(config.History)),
 	}
 	pool.locals = newAccountSet(pool.signer)
-	pool.priced = newTxPricedList(pool.all)
+	for _, addr := range config.Locals {
+		log.Info("Setting new local account", "address", addr)
+		pool.locals.add(addr)
+	}
+	pool.priced = newTxPricedList(pool.all, pool.policy)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
@@ -245,6 +322,12 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If pool snapshots are enabled, reload the remote transactions too
+	if config.Snapshot != "" {
+		if err := pool.loadSnapshot(); err != nil {
+			log.Warn("Failed to load transaction pool snapshot", "err", err)
+		}
+	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
@@ -273,6 +356,9 @@ func (pool *TxPool) loop() {
 	journal := time.NewTicker(pool.config.Rejournal)
 	defer journal.Stop()
 
+	snapshot := time.NewTicker(pool.config.Resnapshot)
+	defer snapshot.Stop()
+
 	// Track the previous head headers for transaction reorgs
 	head := pool.chain.CurrentBlock()
 
@@ -307,9 +393,12 @@ func (pool *TxPool) loop() {
 				prevPending, prevQueued, prevStales = pending, queued, stales
 			}
 
-		// Handle inactive account transaction eviction
+		// Handle inactive account transaction eviction and scheduled releases
 		case <-evict.C:
 			pool.mu.Lock()
+			if released := pool.releaseScheduled(); len(released) > 0 {
+				pool.promoteExecutables(released)
+			}
 			for addr := range pool.queue {
 				// Skip local transactions from the eviction mechanism
 				if pool.locals.contains(addr) {
@@ -318,6 +407,7 @@ func (pool *TxPool) loop() {
 				// Any non-locals old enough should be removed
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
+						pool.lifecycle.dropped(tx.Hash(), TxDropExpired)
 						pool.removeTx(tx.Hash(), true)
 					}
 				}
@@ -333,6 +423,14 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle transaction pool snapshot regeneration
+		case <-snapshot.C:
+			if pool.config.Snapshot != "" {
+				if err := pool.saveSnapshot(); err != nil {
+					log.Warn("Failed to save tx pool snapshot", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -350,8 +448,14 @@ func (pool *TxPool) lockedReset(oldHead, newHead *types.Header) {
 // of the transaction pool is valid with regard to the chain state.
 func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
-	var reinject types.Transactions
+	var reinject, included types.Transactions
 
+	if oldHead != nil && oldHead.Hash() == newHead.ParentHash {
+		// Plain chain extension, only the new head included transactions
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
 		oldNum := oldHead.Number.Uint64()
@@ -361,7 +465,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 			log.Debug("Skipping deep transaction reorg", "depth", depth)
 		} else {
 			// Reorg seems shallow enough to pull in all transactions into memory
-			var discarded, included types.Transactions
+			var discarded types.Transactions
 
 			var (
 				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number.Uint64())
@@ -407,7 +511,22 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	}
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
+	pool.pendingLanes = make(map[txLane]uint64)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.currentNumber = newHead.Number.Uint64()
+	pool.currentTime = newHead.Time.Uint64()
+	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
+	pool.sponsored = pool.chainconfig.IsSponsoredTx(next)
+	pool.batch = pool.chainconfig.IsBatchTx(next)
+	pool.lanes = pool.chainconfig.IsLaneTx(next)
+	pool.schedules = pool.chainconfig.IsScheduledTx(next)
+
+	// Track the newly mined transactions to tell them apart from the stale ones
+	pool.mined = make(map[common.Hash]struct{}, len(included))
+	for _, tx := range included {
+		pool.mined[tx.Hash()] = struct{}{}
+	}
+	defer func() { pool.mined = nil }()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -422,13 +541,81 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Update all accounts to the latest known pending nonce
 	for addr, list := range pool.pending {
 		txs := list.Flatten() // Heavy but will be cached and is needed by the miner anyway
-		pool.pendingState.SetNonce(addr, txs[len(txs)-1].Nonce()+1)
+		for i, tx := range txs {
+			if i == len(txs)-1 || txs[i+1].Lane() != tx.Lane() {
+				pool.setPendingNonce(addr, tx.Lane(), tx.Nonce()+1)
+			}
+		}
 	}
-	// Check the queue and move transactions over to the pending if possible
-	// or remove those that have become invalid
+	// Release the scheduled transactions whose time has come, check the queue
+	// and move transactions over to the pending if possible or remove those
+	// that have become invalid
+	pool.releaseScheduled()
 	pool.promoteExecutables(nil)
 }
 
+// due reports whether a transaction may be included into the next block as far
+// as its schedule is concerned. The next block is expected to be stamped with
+// the current time, but at least one second after the head.
+func (pool *TxPool) due(tx *types.Transaction) bool {
+	number, timestamp := tx.Schedule()
+	if number > pool.currentNumber+1 {
+		return false
+	}
+	next := uint64(time.Now().Unix())
+	if next <= pool.currentTime {
+		next = pool.currentTime + 1
+	}
+	return timestamp <= next
+}
+
+// schedule stores a transaction not yet valid for the next block until its
+// schedule comes.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) schedule(from common.Address, tx *types.Transaction, local bool) error {
+	hash := tx.Hash()
+	if uint64(pool.scheduled.Len()) >= pool.config.Scheduled {
+		return ErrScheduleFull
+	}
+	pool.scheduled.Add(from, tx)
+	if local {
+		pool.locals.add(from)
+	}
+	pool.journalTx(from, tx)
+	pool.lifecycle.scheduled(hash)
+
+	number, timestamp := tx.Schedule()
+	log.Trace("Scheduled new transaction", "hash", hash, "from", from, "number", number, "time", timestamp)
+	return nil
+}
+
+// releaseScheduled drops the scheduled transactions whose nonce got used up and
+// moves the ones whose schedule has come into the pool, returning the accounts
+// with released transactions.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) releaseScheduled() []common.Address {
+	stales := pool.scheduled.Filter(func(from common.Address, tx *types.Transaction) bool {
+		return pool.currentState.GetLaneNonce(from, tx.Lane()) > tx.Nonce()
+	})
+	for _, tx := range stales {
+		log.Trace("Removed stale scheduled transaction", "hash", tx.Hash())
+		pool.lifecycle.dropped(tx.Hash(), TxDropNonceTooLow)
+	}
+	var released []common.Address
+	for _, tx := range pool.scheduled.Filter(func(from common.Address, tx *types.Transaction) bool { return pool.due(tx) }) {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		if _, err := pool.add(tx, pool.locals.contains(from)); err != nil {
+			log.Trace("Discarding released scheduled transaction", "hash", tx.Hash(), "err", err)
+			pool.lifecycle.dropped(tx.Hash(), TxDropUnexecutable)
+			continue
+		}
+		released = append(released, from)
+	}
+	return released
+}
+
 // Stop terminates the transaction pool.
 func (pool *TxPool) Stop() {
 	// Unsubscribe all subscriptions registered from txpool
@@ -441,9 +628,28 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.config.Snapshot != "" {
+		if err := pool.saveSnapshot(); err != nil {
+			log.Warn("Failed to save tx pool snapshot", "err", err)
+		}
+	}
+	pool.lifecycle.stop()
+
 	log.Info("Transaction pool stopped")
 }
 
+// SubscribeTxStatusEvent registers a subscription of TxStatusEvent and starts
+// sending the status transitions of the pooled transactions to the given channel.
+func (pool *TxPool) SubscribeTxStatusEvent(ch chan<- TxStatusEvent) event.Subscription {
+	return pool.scope.Track(pool.lifecycle.feed.Subscribe(ch))
+}
+
+// TxHistory retrieves the retained status transitions of a transaction, oldest
+// first. Transactions not seen recently have no history.
+func (pool *TxPool) TxHistory(hash common.Hash) []*TxTransition {
+	return pool.lifecycle.history(hash)
+}
+
 // SubscribeNewTxsEvent registers a subscription of NewTxsEvent and
 // starts sending event to the given channel.
 func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscription {
@@ -466,11 +672,45 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
+		pool.lifecycle.dropped(tx.Hash(), TxDropUnderpriced)
 		pool.removeTx(tx.Hash(), false)
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// Policy returns the policy ordering the pending transactions of the pool.
+func (pool *TxPool) Policy() TxPolicy {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.policy
+}
+
+// SetPolicy replaces the ordering and eviction policy of the pool, rebuilding
+// the eviction order of the already tracked transactions.
+func (pool *TxPool) SetPolicy(policy TxPolicy) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.policy = policy
+	pool.priced.Reheap(policy)
+
+	log.Info("Transaction pool policy updated", "policy", policy.Name())
+}
+
+// PolicyStats retrieves the counters of the ordering and eviction policy, along
+// with the number of local accounts and the local transactions accepted below
+// the price limit.
+func (pool *TxPool) PolicyStats() map[string]uint64 {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	stats := pool.policy.Stats()
+	stats["locals"] = uint64(len(pool.locals.accounts))
+	stats["exempt"] = pool.exempt
+	return stats
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -479,6 +719,42 @@ func (pool *TxPool) State() *state.ManagedState {
 	return pool.pendingState
 }
 
+// LaneNonce returns the next nonce of a nonce lane of an account, taking the
+// pending transactions of the pool into account.
+func (pool *TxPool) LaneNonce(addr common.Address, lane uint64) uint64 {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.pendingNonce(addr, lane)
+}
+
+// stateNonces returns the nonces of the lanes of an account in the current state.
+func (pool *TxPool) stateNonces(addr common.Address) txNonces {
+	return func(lane uint64) uint64 {
+		return pool.currentState.GetLaneNonce(addr, lane)
+	}
+}
+
+// pendingNonce returns the virtual next nonce of a nonce lane of an account.
+func (pool *TxPool) pendingNonce(addr common.Address, lane uint64) uint64 {
+	if lane == 0 {
+		return pool.pendingState.GetNonce(addr)
+	}
+	if nonce, ok := pool.pendingLanes[txLane{addr, lane}]; ok {
+		return nonce
+	}
+	return pool.currentState.GetLaneNonce(addr, lane)
+}
+
+// setPendingNonce sets the virtual next nonce of a nonce lane of an account.
+func (pool *TxPool) setPendingNonce(addr common.Address, lane uint64, nonce uint64) {
+	if lane == 0 {
+		pool.pendingState.SetNonce(addr, nonce)
+		return
+	}
+	pool.pendingLanes[txLane{addr, lane}] = nonce
+}
+
 // Stats retrieves the current pool stats, namely the number of pending and the
 // number of queued (non-executable) transactions.
 func (pool *TxPool) Stats() (int, int) {
@@ -533,6 +809,119 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 	return pending, nil
 }
 
+// Scheduled retrieves the transactions of an account still waiting for their
+// schedule, sorted by nonce lane and nonce.
+func (pool *TxPool) Scheduled(addr common.Address) types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.scheduled.Account(addr)
+}
+
+// CancelScheduled removes a transaction still waiting for its schedule from the
+// pool and returns it. Transactions already released into the pool can't be
+// cancelled anymore, only replaced.
+func (pool *TxPool) CancelScheduled(hash common.Hash) (*types.Transaction, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx, _ := pool.scheduled.Remove(hash)
+	if tx == nil {
+		return nil, ErrNotScheduled
+	}
+	pool.lifecycle.dropped(hash, TxDropCancelled)
+
+	// Make sure cancelled local transactions don't come back after a restart
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return tx, nil
+}
+
+// ExportSnapshot serializes the pending transactions of the pool into a versioned
+// and checksummed snapshot. If queued is set, the non-executable transactions
+// are included too, both the queued and the scheduled ones.
+func (pool *TxPool) ExportSnapshot(queued bool) ([]byte, error) {
+	blob, _, err := pool.encodeSnapshot(queued)
+	return blob, err
+}
+
+// ImportSnapshot verifies a serialized snapshot and adds its unknown transactions
+// to the pool as remote ones. Every transaction is revalidated against the current
+// head, the number of added and dropped ones is returned.
+func (pool *TxPool) ImportSnapshot(blob []byte) (int, int, error) {
+	snapshot, err := decodeTxSnapshot(blob)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Skip the already known transactions, e.g. locals loaded from the journal
+	txs := make([]*types.Transaction, 0, len(snapshot.Txs))
+	for _, tx := range snapshot.Txs {
+		if pool.all.Get(tx.Hash()) == nil {
+			txs = append(txs, tx)
+		}
+	}
+	dropped := 0
+	for _, err := range pool.AddRemotes(txs) {
+		if err != nil {
+			log.Debug("Failed to add snapshotted transaction", "err", err)
+			dropped++
+		}
+	}
+	return len(txs) - dropped, dropped, nil
+}
+
+// encodeSnapshot serializes the pending, and optionally the queued transactions
+// of the pool, returning the snapshot and the number of transactions in it.
+func (pool *TxPool) encodeSnapshot(queued bool) ([]byte, int, error) {
+	pool.mu.Lock()
+	var txs types.Transactions
+	for _, list := range pool.pending {
+		txs = append(txs, list.Flatten()...)
+	}
+	if queued {
+		for _, list := range pool.queue {
+			txs = append(txs, list.Flatten()...)
+		}
+		for addr := range pool.scheduled.accounts {
+			txs = append(txs, pool.scheduled.Account(addr)...)
+		}
+	}
+	pool.mu.Unlock()
+
+	blob, err := encodeTxSnapshot(pool.chain.CurrentBlock().Header(), txs)
+	return blob, len(txs), err
+}
+
+// loadSnapshot reloads the transactions of the snapshot on disk into the pool.
+func (pool *TxPool) loadSnapshot() error {
+	blob, err := readTxSnapshot(pool.config.Snapshot)
+	if err != nil || blob == nil {
+		return err
+	}
+	added, dropped, err := pool.ImportSnapshot(blob)
+	if err != nil {
+		return err
+	}
+	log.Info("Loaded transaction pool snapshot", "transactions", added+dropped, "dropped", dropped)
+	return nil
+}
+
+// saveSnapshot regenerates the snapshot on disk from the contents of the pool.
+func (pool *TxPool) saveSnapshot() error {
+	blob, count, err := pool.encodeSnapshot(true)
+	if err != nil {
+		return err
+	}
+	if err := writeTxSnapshot(pool.config.Snapshot, blob); err != nil {
+		return err
+	}
+	log.Debug("Regenerated transaction pool snapshot", "transactions", count, "size", common.StorageSize(len(blob)))
+	return nil
+}
+
 // local retrieves all currently known local transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -545,6 +934,9 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 		if queued := pool.queue[addr]; queued != nil {
 			txs[addr] = append(txs[addr], queued.Flatten()...)
 		}
+		if scheduled := pool.scheduled.Account(addr); len(scheduled) > 0 {
+			txs[addr] = append(txs[addr], scheduled...)
+		}
 	}
 	return txs
 }
@@ -565,6 +957,19 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentMaxGas < tx.Gas() {
 		return ErrGasLimit
 	}
+	// Reject sponsored transactions until the fork activates them
+	if tx.Type() == types.SponsoredTxType && !pool.sponsored {
+		return types.ErrTxTypeNotSupported
+	}
+	if tx.Type() == types.BatchTxType && !pool.batch {
+		return types.ErrTxTypeNotSupported
+	}
+	if tx.Type() == types.LaneTxType && !pool.lanes {
+		return types.ErrTxTypeNotSupported
+	}
+	if tx.Type() == types.ScheduledTxType && !pool.schedules {
+		return types.ErrTxTypeNotSupported
+	}
 	// Make sure the transaction is signed properly
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
@@ -576,7 +981,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
-	if pool.currentState.GetNonce(from) > tx.Nonce() {
+	if pool.currentState.GetLaneNonce(from, tx.Lane()) > tx.Nonce() {
 		return ErrNonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
@@ -584,7 +989,24 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	// The fee payer of sponsored transactions has to prove its consent and
+	// should have enough funds to cover the gas, cost == GP * GL
+	if tx.Type() == types.SponsoredTxType {
+		payer, err := types.FeePayer(pool.signer, tx)
+		if err != nil {
+			return ErrInvalidFeePayer
+		}
+		gasCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+		if pool.currentState.GetBalance(payer).Cmp(gasCost) < 0 {
+			return ErrInsufficientFeePayerFunds
+		}
+	}
+	var intrGas uint64
+	if tx.Type() == types.BatchTxType {
+		intrGas, err = BatchIntrinsicGas(tx.Calls())
+	} else {
+		intrGas, err = IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	}
 	if err != nil {
 		return err
 	}
@@ -605,7 +1027,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
-	if pool.all.Get(hash) != nil {
+	if pool.all.Get(hash) != nil || pool.scheduled.Get(hash) != nil {
 		log.Trace("Discarding already known transaction", "hash", hash)
 		return false, fmt.Errorf("known transaction: %x", hash)
 	}
@@ -615,6 +1037,16 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// If the transaction is not yet valid, store it until its schedule comes
+	if !pool.due(tx) {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		return false, pool.schedule(from, tx, local)
+	}
+	// Local transactions below the price limit are only let in due to being local
+	if pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
+		pool.exempt++
+		exemptTxCounter.Inc(1)
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -628,6 +1060,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
+			pool.lifecycle.dropped(tx.Hash(), TxDropUnderpriced)
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
@@ -645,10 +1078,12 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.lifecycle.replaced(old.Hash(), hash)
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		pool.lifecycle.pending(hash)
 
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
@@ -692,11 +1127,13 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.lifecycle.replaced(old.Hash(), hash)
 	}
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
 	}
+	pool.lifecycle.queued(hash)
 	return old != nil, nil
 }
 
@@ -730,6 +1167,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.lifecycle.dropped(hash, TxDropReplaceUnderpriced)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -738,6 +1176,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.lifecycle.replaced(old.Hash(), hash)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all.Get(hash) == nil {
@@ -746,7 +1185,8 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.beats[addr] = time.Now()
-	pool.pendingState.SetNonce(addr, tx.Nonce()+1)
+	pool.setPendingNonce(addr, tx.Lane(), tx.Nonce()+1)
+	pool.lifecycle.pending(hash)
 
 	return true
 }
@@ -842,7 +1282,7 @@ func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
 	for i, hash := range hashes {
 		if tx := pool.all.Get(hash); tx != nil {
 			from, _ := types.Sender(pool.signer, tx) // already validated
-			if pool.pending[from] != nil && pool.pending[from].txs.items[tx.Nonce()] != nil {
+			if pool.pending[from] != nil && pool.pending[from].Overlaps(tx) {
 				status[i] = TxStatusPending
 			} else {
 				status[i] = TxStatusQueued
@@ -886,8 +1326,8 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 				pool.enqueueTx(tx.Hash(), tx)
 			}
 			// Update the account nonce if needed
-			if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
-				pool.pendingState.SetNonce(addr, nonce)
+			if nonce := tx.Nonce(); pool.pendingNonce(addr, tx.Lane()) > nonce {
+				pool.setPendingNonce(addr, tx.Lane(), nonce)
 			}
 			return
 		}
@@ -922,9 +1362,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			continue // Just in case someone calls with a non existing account
 		}
 		// Drop all transactions that are deemed too old (low nonce)
-		for _, tx := range list.Forward(pool.currentState.GetNonce(addr)) {
+		for _, tx := range list.Forward(pool.stateNonces(addr)) {
 			hash := tx.Hash()
 			log.Trace("Removed old queued transaction", "hash", hash)
+			pool.staled(hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
@@ -933,12 +1374,13 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable queued transaction", "hash", hash)
+			pool.lifecycle.dropped(hash, TxDropUnexecutable)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
 		}
 		// Gather all executable transactions and promote them
-		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
+		for _, tx := range list.Ready(func(lane uint64) uint64 { return pool.pendingNonce(addr, lane) }) {
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
 				log.Trace("Promoting queued transaction", "hash", hash)
@@ -949,6 +1391,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		if !pool.locals.contains(addr) {
 			for _, tx := range list.Cap(int(pool.config.AccountQueue)) {
 				hash := tx.Hash()
+				pool.lifecycle.dropped(hash, TxDropQueueLimit)
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
@@ -998,12 +1441,13 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 						for _, tx := range list.Cap(list.Len() - 1) {
 							// Drop the transaction from the global pools too
 							hash := tx.Hash()
+							pool.lifecycle.dropped(hash, TxDropPendingLimit)
 							pool.all.Remove(hash)
 							pool.priced.Removed()
 
 							// Update the account nonce to the dropped transaction
-							if nonce := tx.Nonce(); pool.pendingState.GetNonce(offenders[i]) > nonce {
-								pool.pendingState.SetNonce(offenders[i], nonce)
+							if nonce := tx.Nonce(); pool.pendingNonce(offenders[i], tx.Lane()) > nonce {
+								pool.setPendingNonce(offenders[i], tx.Lane(), nonce)
 							}
 							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 						}
@@ -1020,12 +1464,13 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 					for _, tx := range list.Cap(list.Len() - 1) {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
+						pool.lifecycle.dropped(hash, TxDropPendingLimit)
 						pool.all.Remove(hash)
 						pool.priced.Removed()
 
 						// Update the account nonce to the dropped transaction
-						if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
-							pool.pendingState.SetNonce(addr, nonce)
+						if nonce := tx.Nonce(); pool.pendingNonce(addr, tx.Lane()) > nonce {
+							pool.setPendingNonce(addr, tx.Lane(), nonce)
 						}
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
@@ -1060,6 +1505,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Drop all transactions if they are less than the overflow
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
+					pool.lifecycle.dropped(tx.Hash(), TxDropQueueLimit)
 					pool.removeTx(tx.Hash(), true)
 				}
 				drop -= size
@@ -1069,6 +1515,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Otherwise drop only last few transactions
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+				pool.lifecycle.dropped(txs[i].Hash(), TxDropQueueLimit)
 				pool.removeTx(txs[i].Hash(), true)
 				drop--
 				queuedRateLimitCounter.Inc(1)
@@ -1083,12 +1530,13 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 func (pool *TxPool) demoteUnexecutables() {
 	// Iterate over all accounts and demote any non-executable transactions
 	for addr, list := range pool.pending {
-		nonce := pool.currentState.GetNonce(addr)
+		nonces := pool.stateNonces(addr)
 
 		// Drop all transactions that are deemed too old (low nonce)
-		for _, tx := range list.Forward(nonce) {
+		for _, tx := range list.Forward(nonces) {
 			hash := tx.Hash()
 			log.Trace("Removed old pending transaction", "hash", hash)
+			pool.staled(hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
@@ -1097,6 +1545,7 @@ func (pool *TxPool) demoteUnexecutables() {
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
+			pool.lifecycle.dropped(hash, TxDropUnexecutable)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
@@ -1106,13 +1555,11 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Demoting pending transaction", "hash", hash)
 			pool.enqueueTx(hash, tx)
 		}
-		// If there's a gap in front, warn (should never happen) and postpone all transactions
-		if list.Len() > 0 && list.txs.Get(nonce) == nil {
-			for _, tx := range list.Cap(0) {
-				hash := tx.Hash()
-				log.Error("Demoting invalidated transaction", "hash", hash)
-				pool.enqueueTx(hash, tx)
-			}
+		// If there's a gap in front of a lane, warn (should never happen) and postpone all its transactions
+		for _, tx := range list.Gapped(nonces) {
+			hash := tx.Hash()
+			log.Error("Demoting invalidated transaction", "hash", hash)
+			pool.enqueueTx(hash, tx)
 		}
 		// Delete the entire queue entry if it became empty.
 		if list.Empty() {
@@ -1122,6 +1569,16 @@ func (pool *TxPool) demoteUnexecutables() {
 	}
 }
 
+// staled records the removal of a transaction whose nonce got used up, telling
+// apart the ones mined by the new head from the ones superseded by others.
+func (pool *TxPool) staled(hash common.Hash) {
+	if _, ok := pool.mined[hash]; ok {
+		pool.lifecycle.included(hash)
+		return
+	}
+	pool.lifecycle.dropped(hash, TxDropNonceTooLow)
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -1488,6 +1489,49 @@ func TestTransactionPoolStableUnderpricing(t *testing.T) {
 	}
 }
 
+// Tests that accounts configured as locals are exempt from the price limit even
+// if their transactions arrive from the network, and that the exemptions are
+// counted in the policy stats.
+func TestTransactionPoolConfiguredLocals(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.PriceLimit = 2
+	config.Locals = []common.Address{crypto.PubkeyToAddress(local.PublicKey)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000))
+
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), remote)); err != ErrUnderpriced {
+		t.Fatalf("adding underpriced remote transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add underpriced transaction of configured local: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	stats := pool.PolicyStats()
+	if stats["locals"] != 1 {
+		t.Fatalf("local account count mismatch: have %d, want %d", stats["locals"], 1)
+	}
+	if stats["exempt"] != 1 {
+		t.Fatalf("exempt transaction count mismatch: have %d, want %d", stats["exempt"], 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that the pool rejects replacement transactions that don't meet the minimum
 // price bump required.
 func TestTransactionReplacement(t *testing.T) {
@@ -1680,6 +1724,241 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that with snapshots enabled, remote transactions survive restarts too,
+// being revalidated against the head on reload.
+func TestTransactionPoolSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "txsnapshot")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Create the original pool and fill it with pending and queued transactions
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Snapshot = filepath.Join(dir, "txpool.rlp")
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	first, _ := crypto.GenerateKey()
+	second, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(first.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(second.PublicKey), big.NewInt(1000000000))
+
+	pool.AddRemotes(types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), first),
+		pricedTransaction(1, 100000, big.NewInt(1), first),
+		pricedTransaction(0, 100000, big.NewInt(1), second),
+		pricedTransaction(2, 100000, big.NewInt(1), second),
+	})
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("pool content mismatch: have %d/%d pending/queued, want %d/%d", pending, queued, 3, 1)
+	}
+	// Only the executable transactions should be exported over RPC
+	blob, err := pool.ExportSnapshot(false)
+	if err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	if snapshot, err := decodeTxSnapshot(blob); err != nil || len(snapshot.Txs) != 3 {
+		t.Fatalf("exported snapshot mismatch: have %v (%v), want %d transactions", snapshot, err, 3)
+	}
+	// Terminate the old pool, include a transaction, create a new pool and ensure
+	// all still valid transactions survive
+	pool.Stop()
+	statedb.SetNonce(crypto.PubkeyToAddress(first.PublicKey), 1)
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("reloaded pool content mismatch: have %d/%d pending/queued, want %d/%d", pending, queued, 2, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Importing the exported snapshot should only add back the stale transaction
+	// to be dropped again
+	added, dropped, err := pool.ImportSnapshot(blob)
+	if err != nil {
+		t.Fatalf("failed to import snapshot: %v", err)
+	}
+	if added != 0 || dropped != 1 {
+		t.Fatalf("import result mismatch: have %d/%d added/dropped, want %d/%d", added, dropped, 0, 1)
+	}
+}
+
+// Tests that the pool records the status transitions of its transactions along
+// with the reasons they are dropped.
+func TestTransactionLifecycle(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	// Queue up a transaction, fill the nonce gap and replace the gap filler
+	var (
+		gapped      = pricedTransaction(1, 100000, big.NewInt(1), key)
+		filler      = pricedTransaction(0, 100000, big.NewInt(1), key)
+		replacement = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	for _, tx := range []*types.Transaction{gapped, filler, replacement} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add transaction %x: %v", tx.Hash(), err)
+		}
+	}
+	// Include the replacement by someone else's hands and ensure it's dropped
+	pool.currentState.SetNonce(account, 1)
+	pool.lockedReset(nil, nil)
+
+	tests := []struct {
+		tx       *types.Transaction
+		statuses []string
+		reason   string
+	}{
+		{gapped, []string{TxLifecycleQueued, TxLifecyclePending}, ""},
+		{filler, []string{TxLifecycleQueued, TxLifecyclePending, TxLifecycleDropped}, TxDropReplaced},
+		{replacement, []string{TxLifecyclePending, TxLifecycleDropped}, TxDropNonceTooLow},
+	}
+	for i, tt := range tests {
+		history := pool.TxHistory(tt.tx.Hash())
+		if len(history) != len(tt.statuses) {
+			t.Errorf("test %d: history length mismatch: have %d, want %d", i, len(history), len(tt.statuses))
+			continue
+		}
+		for j, status := range tt.statuses {
+			if history[j].Status != status {
+				t.Errorf("test %d, transition %d: status mismatch: have %s, want %s", i, j, history[j].Status, status)
+			}
+		}
+		if reason := history[len(history)-1].Reason; reason != tt.reason {
+			t.Errorf("test %d: drop reason mismatch: have %q, want %q", i, reason, tt.reason)
+		}
+	}
+	if by := pool.TxHistory(filler.Hash())[2].ReplacedBy; by == nil || *by != replacement.Hash() {
+		t.Errorf("replacement mismatch: have %v, want %x", by, replacement.Hash())
+	}
+}
+
+// Tests that the nonce lanes of an account progress independently in the pool,
+// a nonce gap in one lane not holding back the transactions of the others.
+func TestTransactionLanes(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	laneTransaction := func(lane uint64, nonce uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewLaneTransaction(params.TestChainConfig.ChainId, lane, nonce, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, key)
+		return tx
+	}
+	// Gap the default lane and one of the other lanes, keep a third one executable
+	for _, tx := range []*types.Transaction{transaction(1, 100000, key), laneTransaction(1, 0), laneTransaction(1, 1), laneTransaction(2, 1)} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add transaction %x: %v", tx.Hash(), err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 2, 2)
+	}
+	for lane, want := range map[uint64]uint64{0: 0, 1: 2, 2: 0} {
+		if nonce := pool.LaneNonce(account, lane); nonce != want {
+			t.Errorf("lane %d: pending nonce mismatch: have %d, want %d", lane, nonce, want)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Include the executable lane and ensure only its transactions are removed
+	pool.currentState.SetLaneNonce(account, 1, 2)
+	pool.lockedReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 0, 2)
+	}
+	if err := pool.AddRemote(laneTransaction(1, 1)); err != ErrNonceTooLow {
+		t.Errorf("used up lane nonce error mismatch: have %v, want %v", err, ErrNonceTooLow)
+	}
+	// Fill the gap of a lane and ensure only its own transactions get promoted
+	if err := pool.AddRemote(laneTransaction(2, 0)); err != nil {
+		t.Fatalf("failed to add gap filling transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 2, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that scheduled transactions are held back outside of the pool until the
+// block of their schedule is next, and that they can be cancelled until then.
+func TestTransactionScheduled(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	scheduledTransaction := func(nonce uint64, number uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewScheduledTransaction(params.TestChainConfig.ChainId, number, 0, nonce, &common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, key)
+		return tx
+	}
+	// Schedule two transactions into the future and ensure they stay out of the pool
+	first, second := scheduledTransaction(0, 5), scheduledTransaction(1, 10)
+	for _, tx := range []*types.Transaction{first, second} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add scheduled transaction %x: %v", tx.Hash(), err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 0, 0)
+	}
+	if txs := pool.Scheduled(account); len(txs) != 2 || txs[0].Hash() != first.Hash() || txs[1].Hash() != second.Hash() {
+		t.Fatalf("scheduled transactions mismatch: have %v", txs)
+	}
+	if err := pool.AddRemote(first); err == nil {
+		t.Errorf("duplicate scheduled transaction accepted")
+	}
+	// Advance the chain right before the first schedule and ensure it's released
+	pool.mu.Lock()
+	pool.currentNumber = 4
+	pool.promoteExecutables(pool.releaseScheduled())
+	pool.mu.Unlock()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 1, 0)
+	}
+	if _, err := pool.CancelScheduled(first.Hash()); err != ErrNotScheduled {
+		t.Errorf("released transaction cancel error mismatch: have %v, want %v", err, ErrNotScheduled)
+	}
+	// Cancel the second one and ensure it's gone for good
+	if tx, err := pool.CancelScheduled(second.Hash()); err != nil || tx.Hash() != second.Hash() {
+		t.Fatalf("failed to cancel scheduled transaction: %v", err)
+	}
+	if txs := pool.Scheduled(account); len(txs) != 0 {
+		t.Fatalf("cancelled transaction still scheduled: %v", txs)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
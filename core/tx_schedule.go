@@ -0,0 +1,112 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+)
+
+// txSchedule stores the scheduled transactions that are not yet valid for the
+// next block. Unlike the future queue, the store is not subject to the Lifetime
+// limit: transactions wait for as long as their schedule requires, until they
+// are released into the pool or cancelled.
+type txSchedule struct {
+	accounts map[common.Address]map[common.Hash]*types.Transaction // Scheduled transactions grouped by sender
+	senders  map[common.Hash]common.Address                        // Sender of each scheduled transaction
+}
+
+// newTxSchedule creates a new empty scheduled transaction store.
+func newTxSchedule() *txSchedule {
+	return &txSchedule{
+		accounts: make(map[common.Address]map[common.Hash]*types.Transaction),
+		senders:  make(map[common.Hash]common.Address),
+	}
+}
+
+// Len returns the number of scheduled transactions.
+func (s *txSchedule) Len() int {
+	return len(s.senders)
+}
+
+// Get returns a scheduled transaction, or nil if it's not in the store.
+func (s *txSchedule) Get(hash common.Hash) *types.Transaction {
+	from, ok := s.senders[hash]
+	if !ok {
+		return nil
+	}
+	return s.accounts[from][hash]
+}
+
+// Add inserts a transaction of the given sender into the store.
+func (s *txSchedule) Add(from common.Address, tx *types.Transaction) {
+	hash := tx.Hash()
+	if s.accounts[from] == nil {
+		s.accounts[from] = make(map[common.Hash]*types.Transaction)
+	}
+	s.accounts[from][hash] = tx
+	s.senders[hash] = from
+}
+
+// Remove deletes a transaction from the store, returning it along with its
+// sender, or nil if it was not in the store.
+func (s *txSchedule) Remove(hash common.Hash) (*types.Transaction, common.Address) {
+	from, ok := s.senders[hash]
+	if !ok {
+		return nil, common.Address{}
+	}
+	tx := s.accounts[from][hash]
+
+	delete(s.senders, hash)
+	if delete(s.accounts[from], hash); len(s.accounts[from]) == 0 {
+		delete(s.accounts, from)
+	}
+	return tx, from
+}
+
+// Account returns the scheduled transactions of a sender, sorted by nonce lane
+// and nonce.
+func (s *txSchedule) Account(from common.Address) types.Transactions {
+	txs := make(types.Transactions, 0, len(s.accounts[from]))
+	for _, tx := range s.accounts[from] {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Lane() != txs[j].Lane() {
+			return txs[i].Lane() < txs[j].Lane()
+		}
+		return txs[i].Nonce() < txs[j].Nonce()
+	})
+	return txs
+}
+
+// Filter removes all the transactions matching the filter from the store and
+// returns them.
+func (s *txSchedule) Filter(filter func(from common.Address, tx *types.Transaction) bool) types.Transactions {
+	var removed types.Transactions
+	for from, txs := range s.accounts {
+		for hash, tx := range txs {
+			if filter(from, tx) {
+				removed = append(removed, tx)
+				s.Remove(hash)
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/rlp"
+)
+
+// txSnapshotVersion is the version of the transaction pool snapshot format. It
+// needs to be bumped whenever the encoding changes in an incompatible way.
+const txSnapshotVersion = 1
+
+var (
+	// errSnapshotTruncated is returned if a snapshot is too short to even hold
+	// its checksum.
+	errSnapshotTruncated = errors.New("truncated transaction snapshot")
+
+	// errSnapshotChecksum is returned if the contents of a snapshot don't match
+	// its checksum, e.g. due to a partial write.
+	errSnapshotChecksum = errors.New("transaction snapshot checksum mismatch")
+)
+
+// txSnapshot is a point in time copy of the transactions of the pool, along with
+// the chain head they were valid on.
+type txSnapshot struct {
+	Version uint64               // Format version of the snapshot
+	Number  uint64               // Number of the head block the snapshot was taken at
+	Head    common.Hash          // Hash of the head block the snapshot was taken at
+	Txs     []*types.Transaction // Transactions grouped by account and sorted by nonce
+}
+
+// encodeTxSnapshot serializes a snapshot of the given transactions, appending
+// the Keccak256 checksum of the content.
+func encodeTxSnapshot(head *types.Header, txs []*types.Transaction) ([]byte, error) {
+	blob, err := rlp.EncodeToBytes(&txSnapshot{
+		Version: txSnapshotVersion,
+		Number:  head.Number.Uint64(),
+		Head:    head.Hash(),
+		Txs:     txs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(blob, crypto.Keccak256(blob)...), nil
+}
+
+// decodeTxSnapshot verifies the checksum and the version of a serialized
+// snapshot and decodes its content.
+func decodeTxSnapshot(blob []byte) (*txSnapshot, error) {
+	if len(blob) < common.HashLength {
+		return nil, errSnapshotTruncated
+	}
+	content, checksum := blob[:len(blob)-common.HashLength], blob[len(blob)-common.HashLength:]
+	if !bytes.Equal(crypto.Keccak256(content), checksum) {
+		return nil, errSnapshotChecksum
+	}
+	snapshot := new(txSnapshot)
+	if err := rlp.DecodeBytes(content, snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != txSnapshotVersion {
+		return nil, fmt.Errorf("unsupported transaction snapshot version: have %d, want %d", snapshot.Version, txSnapshotVersion)
+	}
+	return snapshot, nil
+}
+
+// readTxSnapshot loads a serialized snapshot from disk. A missing snapshot is
+// not an error, rather nil is returned.
+func readTxSnapshot(path string) ([]byte, error) {
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return blob, err
+}
+
+// writeTxSnapshot atomically replaces the snapshot on disk with the given one.
+// The new snapshot is flushed into a temporary file first and only moved over
+// the old one once complete, so a crash midway leaves the previous one intact.
+func writeTxSnapshot(path string, blob []byte) error {
+	replacement, err := os.OpenFile(path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := replacement.Write(blob); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err := replacement.Sync(); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err := replacement.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".new", path)
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/types"
+	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/rlp"
+)
+
+// Tests that transaction snapshots survive an encoding roundtrip through disk
+// and that corrupted or incompatible snapshots are rejected.
+func TestTxSnapshotEncoding(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txsnapshot")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		pricedTransaction(1, 100000, big.NewInt(2), key),
+	}
+	head := &types.Header{Number: big.NewInt(42)}
+
+	blob, err := encodeTxSnapshot(head, txs)
+	if err != nil {
+		t.Fatalf("failed to encode snapshot: %v", err)
+	}
+	path := filepath.Join(dir, "txpool.rlp")
+	if err := writeTxSnapshot(path, blob); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	stored, err := readTxSnapshot(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	snapshot, err := decodeTxSnapshot(stored)
+	if err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if snapshot.Number != 42 || snapshot.Head != head.Hash() {
+		t.Errorf("head mismatch: have #%d [%x], want #%d [%x]", snapshot.Number, snapshot.Head, 42, head.Hash())
+	}
+	if len(snapshot.Txs) != len(txs) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(snapshot.Txs), len(txs))
+	}
+	for i, tx := range txs {
+		if snapshot.Txs[i].Hash() != tx.Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, snapshot.Txs[i].Hash(), tx.Hash())
+		}
+	}
+	// Missing snapshots should be silently ignored
+	if blob, err := readTxSnapshot(filepath.Join(dir, "missing.rlp")); blob != nil || err != nil {
+		t.Errorf("missing snapshot mismatch: have %x, %v, want nil, nil", blob, err)
+	}
+	// Corrupted and truncated snapshots must be rejected
+	corrupt := common.CopyBytes(blob)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := decodeTxSnapshot(corrupt); err != errSnapshotChecksum {
+		t.Errorf("corrupted snapshot error mismatch: have %v, want %v", err, errSnapshotChecksum)
+	}
+	if _, err := decodeTxSnapshot(blob[:len(blob)/2]); err != errSnapshotChecksum {
+		t.Errorf("truncated snapshot error mismatch: have %v, want %v", err, errSnapshotChecksum)
+	}
+	if _, err := decodeTxSnapshot(blob[:4]); err != errSnapshotTruncated {
+		t.Errorf("short snapshot error mismatch: have %v, want %v", err, errSnapshotTruncated)
+	}
+	// Snapshots of a different version must be rejected
+	content, _ := rlp.EncodeToBytes(&txSnapshot{Version: txSnapshotVersion + 1})
+	if _, err := decodeTxSnapshot(append(content, crypto.Keccak256(content)...)); err == nil {
+		t.Errorf("future version snapshot accepted")
+	}
+}
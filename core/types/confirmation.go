@@ -0,0 +1,92 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/crypto"
+)
+
+// ErrInvalidConfirmationSig is returned if the signature of a confirmation is malformed.
+var ErrInvalidConfirmationSig = errors.New("invalid confirmation signature")
+
+// Confirmation is a signed statement of a block signer that it has accepted a
+// block. Confirmations are gossiped between nodes on their own, they are not
+// part of any block.
+type Confirmation struct {
+	Number    uint64      `json:"number"`
+	BlockHash common.Hash `json:"blockHash"`
+	Signature []byte      `json:"signature"`
+
+	// caches
+	hash   atomic.Value
+	signer atomic.Value
+}
+
+// NewConfirmation creates an unsigned confirmation of the given block.
+func NewConfirmation(number uint64, hash common.Hash) *Confirmation {
+	return &Confirmation{Number: number, BlockHash: hash}
+}
+
+// SigHash returns the hash to be signed by the confirming signer.
+func (c *Confirmation) SigHash() common.Hash {
+	return rlpHash([]interface{}{c.Number, c.BlockHash})
+}
+
+// Hash returns the hash identifying the signed confirmation message.
+func (c *Confirmation) Hash() common.Hash {
+	if hash := c.hash.Load(); hash != nil {
+		return hash.(common.Hash)
+	}
+	v := rlpHash([]interface{}{c.Number, c.BlockHash, c.Signature})
+	c.hash.Store(v)
+	return v
+}
+
+// WithSignature returns a new confirmation with the given signature.
+func (c *Confirmation) WithSignature(sig []byte) (*Confirmation, error) {
+	if len(sig) != 65 {
+		return nil, ErrInvalidConfirmationSig
+	}
+	cpy := &Confirmation{Number: c.Number, BlockHash: c.BlockHash, Signature: common.CopyBytes(sig)}
+	return cpy, nil
+}
+
+// Signer returns the address of the account that signed the confirmation.
+func (c *Confirmation) Signer() (common.Address, error) {
+	if signer := c.signer.Load(); signer != nil {
+		return signer.(common.Address), nil
+	}
+	if len(c.Signature) != 65 {
+		return common.Address{}, ErrInvalidConfirmationSig
+	}
+	pubkey, err := crypto.Ecrecover(c.SigHash().Bytes(), c.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	c.signer.Store(signer)
+	return signer, nil
+}
+
+// Confirmations is a list of confirmations.
+type Confirmations []*Confirmation
@@ -15,16 +15,26 @@ var _ = (*txdataMarshaling)(nil)
 
 func (t txdata) MarshalJSON() ([]byte, error) {
 	type txdata struct {
-		AccountNonce hexutil.Uint64  `json:"nonce"    gencodec:"required"`
-		Price        *hexutil.Big    `json:"gasPrice" gencodec:"required"`
-		GasLimit     hexutil.Uint64  `json:"gas"      gencodec:"required"`
-		Recipient    *common.Address `json:"to"       rlp:"nil"`
-		Amount       *hexutil.Big    `json:"value"    gencodec:"required"`
-		Payload      hexutil.Bytes   `json:"input"    gencodec:"required"`
-		V            *hexutil.Big    `json:"v" gencodec:"required"`
-		R            *hexutil.Big    `json:"r" gencodec:"required"`
-		S            *hexutil.Big    `json:"s" gencodec:"required"`
-		Hash         *common.Hash    `json:"hash" rlp:"-"`
+		AccountNonce  hexutil.Uint64  `json:"nonce"    gencodec:"required"`
+		Price         *hexutil.Big    `json:"gasPrice" gencodec:"required"`
+		GasLimit      hexutil.Uint64  `json:"gas"      gencodec:"required"`
+		Recipient     *common.Address `json:"to"       rlp:"nil"`
+		Amount        *hexutil.Big    `json:"value"    gencodec:"required"`
+		Payload       hexutil.Bytes   `json:"input"    gencodec:"required"`
+		V             *hexutil.Big    `json:"v" gencodec:"required"`
+		R             *hexutil.Big    `json:"r" gencodec:"required"`
+		S             *hexutil.Big    `json:"s" gencodec:"required"`
+		Hash          *common.Hash    `json:"hash" rlp:"-"`
+		Type          *hexutil.Uint64 `json:"type,omitempty" rlp:"-"`
+		ChainID       *hexutil.Big    `json:"chainId,omitempty" rlp:"-"`
+		FeePayer      *common.Address `json:"feePayer,omitempty" rlp:"-"`
+		PayerV        *hexutil.Big    `json:"payerV,omitempty" rlp:"-"`
+		PayerR        *hexutil.Big    `json:"payerR,omitempty" rlp:"-"`
+		PayerS        *hexutil.Big    `json:"payerS,omitempty" rlp:"-"`
+		Calls         []BatchCall     `json:"calls,omitempty" rlp:"-"`
+		Lane          *hexutil.Uint64 `json:"lane,omitempty" rlp:"-"`
+		NotBefore     *hexutil.Uint64 `json:"notBefore,omitempty" rlp:"-"`
+		NotBeforeTime *hexutil.Uint64 `json:"notBeforeTime,omitempty" rlp:"-"`
 	}
 	var enc txdata
 	enc.AccountNonce = hexutil.Uint64(t.AccountNonce)
@@ -37,21 +47,41 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 	enc.R = (*hexutil.Big)(t.R)
 	enc.S = (*hexutil.Big)(t.S)
 	enc.Hash = t.Hash
+	enc.Type = t.Type
+	enc.ChainID = t.ChainID
+	enc.FeePayer = t.FeePayer
+	enc.PayerV = t.PayerV
+	enc.PayerR = t.PayerR
+	enc.PayerS = t.PayerS
+	enc.Calls = t.Calls
+	enc.Lane = t.Lane
+	enc.NotBefore = t.NotBefore
+	enc.NotBeforeTime = t.NotBeforeTime
 	return json.Marshal(&enc)
 }
 
 func (t *txdata) UnmarshalJSON(input []byte) error {
 	type txdata struct {
-		AccountNonce *hexutil.Uint64 `json:"nonce"    gencodec:"required"`
-		Price        *hexutil.Big    `json:"gasPrice" gencodec:"required"`
-		GasLimit     *hexutil.Uint64 `json:"gas"      gencodec:"required"`
-		Recipient    *common.Address `json:"to"       rlp:"nil"`
-		Amount       *hexutil.Big    `json:"value"    gencodec:"required"`
-		Payload      *hexutil.Bytes  `json:"input"    gencodec:"required"`
-		V            *hexutil.Big    `json:"v" gencodec:"required"`
-		R            *hexutil.Big    `json:"r" gencodec:"required"`
-		S            *hexutil.Big    `json:"s" gencodec:"required"`
-		Hash         *common.Hash    `json:"hash" rlp:"-"`
+		AccountNonce  *hexutil.Uint64 `json:"nonce"    gencodec:"required"`
+		Price         *hexutil.Big    `json:"gasPrice" gencodec:"required"`
+		GasLimit      *hexutil.Uint64 `json:"gas"      gencodec:"required"`
+		Recipient     *common.Address `json:"to"       rlp:"nil"`
+		Amount        *hexutil.Big    `json:"value"    gencodec:"required"`
+		Payload       *hexutil.Bytes  `json:"input"    gencodec:"required"`
+		V             *hexutil.Big    `json:"v" gencodec:"required"`
+		R             *hexutil.Big    `json:"r" gencodec:"required"`
+		S             *hexutil.Big    `json:"s" gencodec:"required"`
+		Hash          *common.Hash    `json:"hash" rlp:"-"`
+		Type          *hexutil.Uint64 `json:"type,omitempty" rlp:"-"`
+		ChainID       *hexutil.Big    `json:"chainId,omitempty" rlp:"-"`
+		FeePayer      *common.Address `json:"feePayer,omitempty" rlp:"-"`
+		PayerV        *hexutil.Big    `json:"payerV,omitempty" rlp:"-"`
+		PayerR        *hexutil.Big    `json:"payerR,omitempty" rlp:"-"`
+		PayerS        *hexutil.Big    `json:"payerS,omitempty" rlp:"-"`
+		Calls         []BatchCall     `json:"calls,omitempty" rlp:"-"`
+		Lane          *hexutil.Uint64 `json:"lane,omitempty" rlp:"-"`
+		NotBefore     *hexutil.Uint64 `json:"notBefore,omitempty" rlp:"-"`
+		NotBeforeTime *hexutil.Uint64 `json:"notBeforeTime,omitempty" rlp:"-"`
 	}
 	var dec txdata
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -95,5 +125,35 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 	if dec.Hash != nil {
 		t.Hash = dec.Hash
 	}
+	if dec.Type != nil {
+		t.Type = dec.Type
+	}
+	if dec.ChainID != nil {
+		t.ChainID = dec.ChainID
+	}
+	if dec.FeePayer != nil {
+		t.FeePayer = dec.FeePayer
+	}
+	if dec.PayerV != nil {
+		t.PayerV = dec.PayerV
+	}
+	if dec.PayerR != nil {
+		t.PayerR = dec.PayerR
+	}
+	if dec.PayerS != nil {
+		t.PayerS = dec.PayerS
+	}
+	if dec.Calls != nil {
+		t.Calls = dec.Calls
+	}
+	if dec.Lane != nil {
+		t.Lane = dec.Lane
+	}
+	if dec.NotBefore != nil {
+		t.NotBefore = dec.NotBefore
+	}
+	if dec.NotBeforeTime != nil {
+		t.NotBeforeTime = dec.NotBeforeTime
+	}
 	return nil
 }
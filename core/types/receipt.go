@@ -28,6 +28,7 @@ import (
 )
 
 //go:generate gencodec -type Receipt -field-override receiptMarshaling -out gen_receipt_json.go
+//go:generate gencodec -type CallReceipt -field-override callReceiptMarshaling -out gen_call_receipt_json.go
 
 var (
 	receiptStatusFailedRLP     = []byte{}
@@ -55,6 +56,7 @@ type Receipt struct {
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
+	Calls           []*CallReceipt `json:"calls,omitempty"`
 }
 
 type receiptMarshaling struct {
@@ -64,6 +66,20 @@ type receiptMarshaling struct {
 	GasUsed           hexutil.Uint64
 }
 
+// CallReceipt represents the result of a single call of a batch transaction. If
+// any call of a batch fails, the effects of all its calls are reverted and the
+// receipt only contains the calls up to and including the failed one.
+type CallReceipt struct {
+	Status  uint64 `json:"status"`
+	GasUsed uint64 `json:"gasUsed" gencodec:"required"`
+	Logs    []*Log `json:"logs"    gencodec:"required"`
+}
+
+type callReceiptMarshaling struct {
+	Status  hexutil.Uint64
+	GasUsed hexutil.Uint64
+}
+
 // receiptRLP is the consensus encoding of a receipt.
 type receiptRLP struct {
 	PostStateOrStatus []byte
@@ -80,6 +96,16 @@ type receiptStorageRLP struct {
 	ContractAddress   common.Address
 	Logs              []*LogForStorage
 	GasUsed           uint64
+	Calls             []callReceiptStorageRLP `rlp:"tail"`
+}
+
+// callReceiptStorageRLP is the storage encoding of a call receipt. The logs of
+// the calls are stored only once in the transaction receipt, the call receipts
+// store the number of logs belonging to them.
+type callReceiptStorageRLP struct {
+	Status  uint64
+	GasUsed uint64
+	Logs    uint64
 }
 
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
@@ -168,6 +194,9 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
+	for _, call := range r.Calls {
+		enc.Calls = append(enc.Calls, callReceiptStorageRLP{call.Status, call.GasUsed, uint64(len(call.Logs))})
+	}
 	return rlp.Encode(w, enc)
 }
 
@@ -189,6 +218,20 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	}
 	// Assign the implementation fields
 	r.TxHash, r.ContractAddress, r.GasUsed = dec.TxHash, dec.ContractAddress, dec.GasUsed
+
+	// Split the logs among the calls of batch transactions
+	var logs uint64
+	for _, call := range dec.Calls {
+		if call.Logs > uint64(len(r.Logs))-logs {
+			return fmt.Errorf("invalid call receipt log count %d, %d logs left", call.Logs, uint64(len(r.Logs))-logs)
+		}
+		r.Calls = append(r.Calls, &CallReceipt{
+			Status:  call.Status,
+			GasUsed: call.GasUsed,
+			Logs:    r.Logs[logs : logs+call.Logs],
+		})
+		logs += call.Logs
+	}
 	return nil
 }
 
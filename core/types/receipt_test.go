@@ -0,0 +1,83 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/rlp"
+)
+
+// Tests that the call results of batch transaction receipts survive a storage
+// round trip, with the logs split among the calls, while leaving the consensus
+// encoding untouched.
+func TestReceiptCallsStorage(t *testing.T) {
+	logs := []*Log{
+		{Address: common.Address{1}, Topics: []common.Hash{}, Data: []byte{}},
+		{Address: common.Address{2}, Topics: []common.Hash{}, Data: []byte{}},
+		{Address: common.Address{3}, Topics: []common.Hash{}, Data: []byte{}},
+	}
+	receipt := &Receipt{
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 100,
+		Logs:              logs,
+		TxHash:            common.Hash{0x11},
+		GasUsed:           100,
+		Calls: []*CallReceipt{
+			{Status: ReceiptStatusSuccessful, GasUsed: 10, Logs: logs[:1]},
+			{Status: ReceiptStatusSuccessful, GasUsed: 20, Logs: []*Log{}},
+			{Status: ReceiptStatusSuccessful, GasUsed: 30, Logs: logs[1:]},
+		},
+	}
+	blob, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("failed to encode receipt: %v", err)
+	}
+	dec := new(ReceiptForStorage)
+	if err := rlp.DecodeBytes(blob, dec); err != nil {
+		t.Fatalf("failed to decode receipt: %v", err)
+	}
+	if len(dec.Calls) != 3 {
+		t.Fatalf("call count mismatch: have %d, want %d", len(dec.Calls), 3)
+	}
+	for i, call := range dec.Calls {
+		want := receipt.Calls[i]
+		if call.Status != want.Status || call.GasUsed != want.GasUsed || len(call.Logs) != len(want.Logs) {
+			t.Fatalf("call %d: mismatch: have %+v, want %+v", i, call, want)
+		}
+		for j, log := range call.Logs {
+			if log.Address != want.Logs[j].Address {
+				t.Errorf("call %d, log %d: address mismatch: have %x, want %x", i, j, log.Address, want.Logs[j].Address)
+			}
+		}
+	}
+	// Receipts without calls must decode from the old storage format
+	plain := *receipt
+	plain.Calls = nil
+
+	blob, _ = rlp.EncodeToBytes((*ReceiptForStorage)(&plain))
+	if dec := new(ReceiptForStorage); rlp.DecodeBytes(blob, dec) != nil || dec.Calls != nil {
+		t.Fatalf("plain receipt round trip failed: %+v", dec)
+	}
+	// The consensus encoding must not include the calls
+	consensus, _ := rlp.EncodeToBytes(receipt)
+	if want, _ := rlp.EncodeToBytes(&plain); !bytes.Equal(consensus, want) {
+		t.Fatalf("consensus encoding includes the calls: have %x, want %x", consensus, want)
+	}
+}
@@ -19,9 +19,11 @@ package types
 import (
 	"container/heap"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"sync/atomic"
+	"time"
 
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/common/hexutil"
@@ -30,9 +32,28 @@ import (
 )
 
 //go:generate gencodec -type txdata -field-override txdataMarshaling -out gen_tx_json.go
+//go:generate gencodec -type BatchCall -field-override batchCallMarshaling -out gen_batch_call_json.go
 
 var (
-	ErrInvalidSig = errors.New("invalid transaction v, r, s values")
+	ErrInvalidSig         = errors.New("invalid transaction v, r, s values")
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+	ErrFeePayerMismatch   = errors.New("fee payer signature does not match fee payer")
+	errEmptyTypedTx       = errors.New("empty typed transaction bytes")
+	errEmptyBatchTx       = errors.New("batch transaction without calls")
+	errLaneTxCreation     = errors.New("lane transaction can't create contracts")
+	errDefaultLaneTx      = errors.New("lane transaction on the default lane")
+	errUnscheduledTx      = errors.New("scheduled transaction without a schedule")
+)
+
+// Transaction envelope types. Legacy transactions are plain RLP lists, all the
+// other types are encoded as the type byte followed by the RLP payload, wrapped
+// into an RLP string wherever transactions are embedded into RLP structures.
+const (
+	LegacyTxType    = 0x00
+	SponsoredTxType = 0x01
+	BatchTxType     = 0x02
+	LaneTxType      = 0x03
+	ScheduledTxType = 0x04
 )
 
 // deriveSigner makes a *best* guess about which signer to use.
@@ -45,11 +66,19 @@ func deriveSigner(V *big.Int) Signer {
 }
 
 type Transaction struct {
-	data txdata
+	typ     uint8        // Envelope type, LegacyTxType for plain RLP transactions
+	data    txdata       // Fields shared by all transaction types
+	sponsor *sponsordata // Fee payer details, only set for sponsored transactions
+	batch   *batchdata   // Calls to execute, only set for batch transactions
+	lane    *lanedata    // Nonce lane of the sender, only set for lane transactions
+	sched   *scheddata   // Earliest inclusion bounds, only set for scheduled transactions
+	time    time.Time    // Time the transaction was first seen locally
+
 	// caches
-	hash atomic.Value
-	size atomic.Value
-	from atomic.Value
+	hash  atomic.Value
+	size  atomic.Value
+	from  atomic.Value
+	payer atomic.Value
 }
 
 type txdata struct {
@@ -67,6 +96,128 @@ type txdata struct {
 
 	// This is only used when marshaling to JSON.
 	Hash *common.Hash `json:"hash" rlp:"-"`
+
+	// These are only used when marshaling typed transactions to JSON.
+	Type          *hexutil.Uint64 `json:"type,omitempty" rlp:"-"`
+	ChainID       *hexutil.Big    `json:"chainId,omitempty" rlp:"-"`
+	FeePayer      *common.Address `json:"feePayer,omitempty" rlp:"-"`
+	PayerV        *hexutil.Big    `json:"payerV,omitempty" rlp:"-"`
+	PayerR        *hexutil.Big    `json:"payerR,omitempty" rlp:"-"`
+	PayerS        *hexutil.Big    `json:"payerS,omitempty" rlp:"-"`
+	Calls         []BatchCall     `json:"calls,omitempty" rlp:"-"`
+	Lane          *hexutil.Uint64 `json:"lane,omitempty" rlp:"-"`
+	NotBefore     *hexutil.Uint64 `json:"notBefore,omitempty" rlp:"-"`
+	NotBeforeTime *hexutil.Uint64 `json:"notBeforeTime,omitempty" rlp:"-"`
+}
+
+// sponsordata contains the fields a sponsored transaction carries on top of the
+// legacy ones: the chain it is bound to, the account paying for its gas and the
+// signature of that account. The V values of sponsored transactions are plain
+// recovery ids (0 or 1) for both signatures.
+type sponsordata struct {
+	ChainId  *big.Int
+	FeePayer common.Address
+	V, R, S  *big.Int
+}
+
+// sponsoredTx is the RLP payload of a sponsored transaction envelope.
+type sponsoredTx struct {
+	ChainId      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	FeePayer     common.Address
+
+	V, R, S                *big.Int // Signature of the sender
+	PayerV, PayerR, PayerS *big.Int // Signature of the fee payer
+}
+
+// BatchCall is a single message call of a batch transaction. Batch calls can't
+// create contracts.
+type BatchCall struct {
+	To    common.Address `json:"to"    gencodec:"required"`
+	Value *big.Int       `json:"value" gencodec:"required"`
+	Data  []byte         `json:"input"`
+}
+
+type batchCallMarshaling struct {
+	Value *hexutil.Big
+	Data  hexutil.Bytes
+}
+
+// batchdata contains the fields a batch transaction carries instead of the
+// recipient, amount and payload of legacy ones. Like sponsored transactions,
+// batch transactions are bound to a chain and their V value is a plain recovery
+// id (0 or 1).
+type batchdata struct {
+	ChainId *big.Int
+	Calls   []BatchCall
+}
+
+// batchTx is the RLP payload of a batch transaction envelope.
+type batchTx struct {
+	ChainId      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Calls        []BatchCall
+	V, R, S      *big.Int
+}
+
+// lanedata contains the fields a lane transaction carries on top of the legacy
+// ones: the chain it is bound to and the nonce lane of the sender it consumes a
+// nonce of. Every lane of an account has a nonce sequence of its own, so a stuck
+// transaction only holds up the ones of its lane. Lane 0 is the nonce sequence
+// of all the other transaction types and can't be used by lane transactions,
+// hence a non-zero lane identifies lane transactions. They can't create contracts,
+// as contract addresses are derived from the lane 0 nonce. Like the other typed
+// transactions, their V value is a plain recovery id (0 or 1).
+type lanedata struct {
+	ChainId *big.Int
+	Lane    uint64
+}
+
+// laneTx is the RLP payload of a lane transaction envelope.
+type laneTx struct {
+	ChainId      *big.Int
+	Lane         uint64
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    common.Address
+	Amount       *big.Int
+	Payload      []byte
+	V, R, S      *big.Int
+}
+
+// scheddata contains the fields a scheduled transaction carries on top of the
+// legacy ones: the chain it is bound to and the earliest block it may be included
+// in, given as a block number, a block timestamp or both. A zero bound is not
+// enforced, but at least one of them has to be set. Until both bounds are met
+// the transaction is invalid, which allows signing payroll or vesting payouts
+// ahead of time. Like the other typed transactions, their V value is a plain
+// recovery id (0 or 1).
+type scheddata struct {
+	ChainId       *big.Int
+	NotBefore     uint64 // Number of the first block the transaction is valid in
+	NotBeforeTime uint64 // Earliest timestamp of a block the transaction is valid in
+}
+
+// scheduledTx is the RLP payload of a scheduled transaction envelope.
+type scheduledTx struct {
+	ChainId       *big.Int
+	NotBefore     uint64
+	NotBeforeTime uint64
+	AccountNonce  uint64
+	Price         *big.Int
+	GasLimit      uint64
+	Recipient     *common.Address `rlp:"nil"`
+	Amount        *big.Int
+	Payload       []byte
+	V, R, S       *big.Int
 }
 
 type txdataMarshaling struct {
@@ -110,19 +261,122 @@ func newTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit
 		d.Price.Set(gasPrice)
 	}
 
-	return &Transaction{data: d}
+	return &Transaction{data: d, time: time.Now()}
+}
+
+// NewSponsoredTransaction creates an unsigned transaction whose gas is paid by
+// the fee payer instead of the sender. A nil recipient creates a contract. The
+// transaction has to be signed by the sender first and by the fee payer after.
+func NewSponsoredTransaction(chainId *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, feePayer common.Address) *Transaction {
+	tx := newTransaction(nonce, to, amount, gasLimit, gasPrice, data)
+	tx.typ = SponsoredTxType
+	tx.sponsor = &sponsordata{
+		ChainId:  new(big.Int),
+		FeePayer: feePayer,
+		V:        new(big.Int),
+		R:        new(big.Int),
+		S:        new(big.Int),
+	}
+	if chainId != nil {
+		tx.sponsor.ChainId.Set(chainId)
+	}
+	return tx
+}
+
+// NewBatchTransaction creates an unsigned transaction executing all the calls
+// atomically: either all of them succeed, or the effects of all are reverted.
+func NewBatchTransaction(chainId *big.Int, nonce uint64, calls []BatchCall, gasLimit uint64, gasPrice *big.Int) *Transaction {
+	tx := newTransaction(nonce, nil, nil, gasLimit, gasPrice, nil)
+	tx.typ = BatchTxType
+	tx.batch = &batchdata{
+		ChainId: new(big.Int),
+		Calls:   make([]BatchCall, len(calls)),
+	}
+	if chainId != nil {
+		tx.batch.ChainId.Set(chainId)
+	}
+	for i, call := range calls {
+		tx.batch.Calls[i] = BatchCall{To: call.To, Value: new(big.Int), Data: common.CopyBytes(call.Data)}
+		if call.Value != nil {
+			tx.batch.Calls[i].Value.Set(call.Value)
+		}
+	}
+	tx.data.Amount = batchValue(tx.batch.Calls)
+	return tx
+}
+
+// NewLaneTransaction creates an unsigned transaction consuming the next nonce of
+// the given lane of the sender, independently of the nonces of the other lanes.
+func NewLaneTransaction(chainId *big.Int, lane uint64, nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	tx := newTransaction(nonce, &to, amount, gasLimit, gasPrice, data)
+	tx.typ = LaneTxType
+	tx.lane = &lanedata{
+		ChainId: new(big.Int),
+		Lane:    lane,
+	}
+	if chainId != nil {
+		tx.lane.ChainId.Set(chainId)
+	}
+	return tx
+}
+
+// NewScheduledTransaction creates an unsigned transaction which isn't valid until
+// the block number reaches notBefore and the block time reaches notBeforeTime. A
+// zero bound is not enforced. A nil recipient creates a contract.
+func NewScheduledTransaction(chainId *big.Int, notBefore uint64, notBeforeTime uint64, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	tx := newTransaction(nonce, to, amount, gasLimit, gasPrice, data)
+	tx.typ = ScheduledTxType
+	tx.sched = &scheddata{
+		ChainId:       new(big.Int),
+		NotBefore:     notBefore,
+		NotBeforeTime: notBeforeTime,
+	}
+	if chainId != nil {
+		tx.sched.ChainId.Set(chainId)
+	}
+	return tx
+}
+
+// batchValue returns the total value transferred by the calls of a batch.
+func batchValue(calls []BatchCall) *big.Int {
+	total := new(big.Int)
+	for _, call := range calls {
+		if call.Value != nil {
+			total.Add(total, call.Value)
+		}
+	}
+	return total
 }
 
 // ChainId returns which chain id this transaction was signed for (if at all)
 func (tx *Transaction) ChainId() *big.Int {
+	switch {
+	case tx.sponsor != nil:
+		return new(big.Int).Set(tx.sponsor.ChainId)
+	case tx.batch != nil:
+		return new(big.Int).Set(tx.batch.ChainId)
+	case tx.lane != nil:
+		return new(big.Int).Set(tx.lane.ChainId)
+	case tx.sched != nil:
+		return new(big.Int).Set(tx.sched.ChainId)
+	}
 	return deriveChainId(tx.data.V)
 }
 
 // Protected returns whether the transaction is protected from replay protection.
+// Typed transactions always carry their chain id and are hence protected.
 func (tx *Transaction) Protected() bool {
+	if tx.typ != LegacyTxType {
+		return true
+	}
 	return isProtectedV(tx.data.V)
 }
 
+// Type returns the envelope type of the transaction.
+func (tx *Transaction) Type() uint8 {
+	return tx.typ
+}
+
 func isProtectedV(V *big.Int) bool {
 	if V.BitLen() <= 8 {
 		v := V.Uint64()
@@ -132,20 +386,254 @@ func isProtectedV(V *big.Int) bool {
 	return true
 }
 
-// EncodeRLP implements rlp.Encoder
+// EncodeRLP implements rlp.Encoder. Legacy transactions are encoded as RLP
+// lists, typed ones as an RLP string containing the typed envelope.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &tx.data)
+	if tx.typ == LegacyTxType {
+		return rlp.Encode(w, &tx.data)
+	}
+	enc, err := tx.encodeTyped()
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, enc)
 }
 
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
-	if err == nil {
-		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+	kind, size, err := s.Kind()
+	if err != nil {
+		return err
+	}
+	if kind == rlp.List {
+		err := s.Decode(&tx.data)
+		if err == nil {
+			tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+			tx.time = time.Now()
+		}
+		return err
+	}
+	// Not a legacy transaction, unwrap the typed envelope
+	enc, err := s.Bytes()
+	if err != nil {
+		return err
 	}
+	if err := tx.decodeTyped(enc); err != nil {
+		return err
+	}
+	tx.size.Store(common.StorageSize(rlp.ListSize(uint64(len(enc)))))
+	tx.time = time.Now()
+	return nil
+}
 
-	return err
+// encodeTyped returns the envelope of a typed transaction: the type byte
+// followed by the RLP encoding of the payload.
+func (tx *Transaction) encodeTyped() ([]byte, error) {
+	payload := tx.typedPayload()
+	if payload == nil {
+		return nil, ErrTxTypeNotSupported
+	}
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{tx.typ}, enc...), nil
+}
+
+// typedPayload assembles the RLP payload of a typed transaction, or returns nil
+// for legacy and unknown transaction types.
+func (tx *Transaction) typedPayload() interface{} {
+	switch tx.typ {
+	case SponsoredTxType:
+		return tx.sponsoredTx()
+	case BatchTxType:
+		return tx.batchTx()
+	case LaneTxType:
+		return tx.laneTx()
+	case ScheduledTxType:
+		return tx.scheduledTx()
+	default:
+		return nil
+	}
+}
+
+// decodeTyped fills the transaction from a typed transaction envelope.
+func (tx *Transaction) decodeTyped(enc []byte) error {
+	if len(enc) == 0 {
+		return errEmptyTypedTx
+	}
+	switch enc[0] {
+	case SponsoredTxType:
+		var dec sponsoredTx
+		if err := rlp.DecodeBytes(enc[1:], &dec); err != nil {
+			return err
+		}
+		tx.typ = SponsoredTxType
+		tx.data = txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Recipient:    dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+		}
+		tx.sponsor = &sponsordata{
+			ChainId:  dec.ChainId,
+			FeePayer: dec.FeePayer,
+			V:        dec.PayerV,
+			R:        dec.PayerR,
+			S:        dec.PayerS,
+		}
+		return nil
+	case BatchTxType:
+		var dec batchTx
+		if err := rlp.DecodeBytes(enc[1:], &dec); err != nil {
+			return err
+		}
+		if len(dec.Calls) == 0 {
+			return errEmptyBatchTx
+		}
+		tx.typ = BatchTxType
+		tx.data = txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Amount:       batchValue(dec.Calls),
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+		}
+		tx.batch = &batchdata{
+			ChainId: dec.ChainId,
+			Calls:   dec.Calls,
+		}
+		return nil
+	case LaneTxType:
+		var dec laneTx
+		if err := rlp.DecodeBytes(enc[1:], &dec); err != nil {
+			return err
+		}
+		if dec.Lane == 0 {
+			return errDefaultLaneTx
+		}
+		tx.typ = LaneTxType
+		tx.data = txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Recipient:    &dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+		}
+		tx.lane = &lanedata{
+			ChainId: dec.ChainId,
+			Lane:    dec.Lane,
+		}
+		return nil
+	case ScheduledTxType:
+		var dec scheduledTx
+		if err := rlp.DecodeBytes(enc[1:], &dec); err != nil {
+			return err
+		}
+		if dec.NotBefore == 0 && dec.NotBeforeTime == 0 {
+			return errUnscheduledTx
+		}
+		tx.typ = ScheduledTxType
+		tx.data = txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Recipient:    dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+		}
+		tx.sched = &scheddata{
+			ChainId:       dec.ChainId,
+			NotBefore:     dec.NotBefore,
+			NotBeforeTime: dec.NotBeforeTime,
+		}
+		return nil
+	default:
+		return ErrTxTypeNotSupported
+	}
+}
+
+// sponsoredTx assembles the RLP payload of a sponsored transaction.
+func (tx *Transaction) sponsoredTx() *sponsoredTx {
+	return &sponsoredTx{
+		ChainId:      tx.sponsor.ChainId,
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Recipient:    tx.data.Recipient,
+		Amount:       tx.data.Amount,
+		Payload:      tx.data.Payload,
+		FeePayer:     tx.sponsor.FeePayer,
+		V:            tx.data.V,
+		R:            tx.data.R,
+		S:            tx.data.S,
+		PayerV:       tx.sponsor.V,
+		PayerR:       tx.sponsor.R,
+		PayerS:       tx.sponsor.S,
+	}
+}
+
+// batchTx assembles the RLP payload of a batch transaction.
+func (tx *Transaction) batchTx() *batchTx {
+	return &batchTx{
+		ChainId:      tx.batch.ChainId,
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Calls:        tx.batch.Calls,
+		V:            tx.data.V,
+		R:            tx.data.R,
+		S:            tx.data.S,
+	}
+}
+
+// laneTx assembles the RLP payload of a lane transaction.
+func (tx *Transaction) laneTx() *laneTx {
+	return &laneTx{
+		ChainId:      tx.lane.ChainId,
+		Lane:         tx.lane.Lane,
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Recipient:    *tx.data.Recipient,
+		Amount:       tx.data.Amount,
+		Payload:      tx.data.Payload,
+		V:            tx.data.V,
+		R:            tx.data.R,
+		S:            tx.data.S,
+	}
+}
+
+// scheduledTx assembles the RLP payload of a scheduled transaction.
+func (tx *Transaction) scheduledTx() *scheduledTx {
+	return &scheduledTx{
+		ChainId:       tx.sched.ChainId,
+		NotBefore:     tx.sched.NotBefore,
+		NotBeforeTime: tx.sched.NotBeforeTime,
+		AccountNonce:  tx.data.AccountNonce,
+		Price:         tx.data.Price,
+		GasLimit:      tx.data.GasLimit,
+		Recipient:     tx.data.Recipient,
+		Amount:        tx.data.Amount,
+		Payload:       tx.data.Payload,
+		V:             tx.data.V,
+		R:             tx.data.R,
+		S:             tx.data.S,
+	}
 }
 
 // MarshalJSON encodes the web3 RPC transaction format.
@@ -153,6 +641,41 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 	hash := tx.Hash()
 	data := tx.data
 	data.Hash = &hash
+	if tx.sponsor != nil {
+		typ := hexutil.Uint64(tx.typ)
+		feePayer := tx.sponsor.FeePayer
+
+		data.Type = &typ
+		data.ChainID = (*hexutil.Big)(tx.sponsor.ChainId)
+		data.FeePayer = &feePayer
+		data.PayerV = (*hexutil.Big)(tx.sponsor.V)
+		data.PayerR = (*hexutil.Big)(tx.sponsor.R)
+		data.PayerS = (*hexutil.Big)(tx.sponsor.S)
+	}
+	if tx.batch != nil {
+		typ := hexutil.Uint64(tx.typ)
+
+		data.Type = &typ
+		data.ChainID = (*hexutil.Big)(tx.batch.ChainId)
+		data.Calls = tx.batch.Calls
+	}
+	if tx.lane != nil {
+		typ := hexutil.Uint64(tx.typ)
+		lane := hexutil.Uint64(tx.lane.Lane)
+
+		data.Type = &typ
+		data.ChainID = (*hexutil.Big)(tx.lane.ChainId)
+		data.Lane = &lane
+	}
+	if tx.sched != nil {
+		typ := hexutil.Uint64(tx.typ)
+		number, time := hexutil.Uint64(tx.sched.NotBefore), hexutil.Uint64(tx.sched.NotBeforeTime)
+
+		data.Type = &typ
+		data.ChainID = (*hexutil.Big)(tx.sched.ChainId)
+		data.NotBefore = &number
+		data.NotBeforeTime = &time
+	}
 	return data.MarshalJSON()
 }
 
@@ -162,6 +685,13 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if err := dec.UnmarshalJSON(input); err != nil {
 		return err
 	}
+	if dec.Type != nil && *dec.Type != LegacyTxType {
+		if err := tx.unmarshalTypedJSON(dec); err != nil {
+			return err
+		}
+		tx.time = time.Now()
+		return nil
+	}
 	var V byte
 	if isProtectedV(dec.V) {
 		chainID := deriveChainId(dec.V).Uint64()
@@ -172,7 +702,124 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if !crypto.ValidateSignatureValues(V, dec.R, dec.S, false) {
 		return ErrInvalidSig
 	}
-	*tx = Transaction{data: dec}
+	*tx = Transaction{data: dec, time: time.Now()}
+	return nil
+}
+
+// unmarshalTypedJSON assembles a typed transaction from its decoded web3 RPC
+// representation.
+func (tx *Transaction) unmarshalTypedJSON(dec txdata) error {
+	switch *dec.Type {
+	case SponsoredTxType:
+		return tx.unmarshalSponsoredJSON(dec)
+	case BatchTxType:
+		return tx.unmarshalBatchJSON(dec)
+	case LaneTxType:
+		return tx.unmarshalLaneJSON(dec)
+	case ScheduledTxType:
+		return tx.unmarshalScheduledJSON(dec)
+	default:
+		return ErrTxTypeNotSupported
+	}
+}
+
+// unmarshalSponsoredJSON assembles a sponsored transaction from its decoded web3
+// RPC representation.
+func (tx *Transaction) unmarshalSponsoredJSON(dec txdata) error {
+	if dec.ChainID == nil || dec.FeePayer == nil || dec.PayerV == nil || dec.PayerR == nil || dec.PayerS == nil {
+		return errors.New("missing required sponsored fields for txdata")
+	}
+	for _, sig := range [][3]*big.Int{{dec.V, dec.R, dec.S}, {(*big.Int)(dec.PayerV), (*big.Int)(dec.PayerR), (*big.Int)(dec.PayerS)}} {
+		if sig[0].BitLen() > 8 || !crypto.ValidateSignatureValues(byte(sig[0].Uint64()), sig[1], sig[2], false) {
+			return ErrInvalidSig
+		}
+	}
+	*tx = Transaction{
+		typ:  SponsoredTxType,
+		data: dec,
+		sponsor: &sponsordata{
+			ChainId:  (*big.Int)(dec.ChainID),
+			FeePayer: *dec.FeePayer,
+			V:        (*big.Int)(dec.PayerV),
+			R:        (*big.Int)(dec.PayerR),
+			S:        (*big.Int)(dec.PayerS),
+		},
+	}
+	return nil
+}
+
+// unmarshalBatchJSON assembles a batch transaction from its decoded web3 RPC
+// representation.
+func (tx *Transaction) unmarshalBatchJSON(dec txdata) error {
+	if dec.ChainID == nil {
+		return errors.New("missing required field 'chainId' for txdata")
+	}
+	if len(dec.Calls) == 0 {
+		return errEmptyBatchTx
+	}
+	if dec.V.BitLen() > 8 || !crypto.ValidateSignatureValues(byte(dec.V.Uint64()), dec.R, dec.S, false) {
+		return ErrInvalidSig
+	}
+	dec.Recipient, dec.Payload, dec.Amount = nil, nil, batchValue(dec.Calls)
+
+	*tx = Transaction{
+		typ:  BatchTxType,
+		data: dec,
+		batch: &batchdata{
+			ChainId: (*big.Int)(dec.ChainID),
+			Calls:   dec.Calls,
+		},
+	}
+	return nil
+}
+
+// unmarshalLaneJSON assembles a lane transaction from its decoded web3 RPC
+// representation.
+func (tx *Transaction) unmarshalLaneJSON(dec txdata) error {
+	if dec.ChainID == nil || dec.Lane == nil {
+		return errors.New("missing required lane fields for txdata")
+	}
+	if *dec.Lane == 0 {
+		return errDefaultLaneTx
+	}
+	if dec.Recipient == nil {
+		return errLaneTxCreation
+	}
+	if dec.V.BitLen() > 8 || !crypto.ValidateSignatureValues(byte(dec.V.Uint64()), dec.R, dec.S, false) {
+		return ErrInvalidSig
+	}
+	*tx = Transaction{
+		typ:  LaneTxType,
+		data: dec,
+		lane: &lanedata{
+			ChainId: (*big.Int)(dec.ChainID),
+			Lane:    uint64(*dec.Lane),
+		},
+	}
+	return nil
+}
+
+// unmarshalScheduledJSON assembles a scheduled transaction from its decoded web3
+// RPC representation.
+func (tx *Transaction) unmarshalScheduledJSON(dec txdata) error {
+	if dec.ChainID == nil || dec.NotBefore == nil || dec.NotBeforeTime == nil {
+		return errors.New("missing required schedule fields for txdata")
+	}
+	if *dec.NotBefore == 0 && *dec.NotBeforeTime == 0 {
+		return errUnscheduledTx
+	}
+	if dec.V.BitLen() > 8 || !crypto.ValidateSignatureValues(byte(dec.V.Uint64()), dec.R, dec.S, false) {
+		return ErrInvalidSig
+	}
+	*tx = Transaction{
+		typ:  ScheduledTxType,
+		data: dec,
+		sched: &scheddata{
+			ChainId:       (*big.Int)(dec.ChainID),
+			NotBefore:     uint64(*dec.NotBefore),
+			NotBeforeTime: uint64(*dec.NotBeforeTime),
+		},
+	}
 	return nil
 }
 
@@ -183,6 +830,10 @@ func (tx *Transaction) Value() *big.Int    { return new(big.Int).Set(tx.data.Amo
 func (tx *Transaction) Nonce() uint64      { return tx.data.AccountNonce }
 func (tx *Transaction) CheckNonce() bool   { return true }
 
+// Time returns the time the transaction was first seen locally, either created,
+// decoded from the network or signed. It is not part of the consensus encoding.
+func (tx *Transaction) Time() time.Time { return tx.time }
+
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
 func (tx *Transaction) To() *common.Address {
@@ -193,13 +844,58 @@ func (tx *Transaction) To() *common.Address {
 	return &to
 }
 
+// Lane returns the nonce lane of the sender the transaction consumes a nonce of.
+// All transactions but lane ones use the default lane 0.
+func (tx *Transaction) Lane() uint64 {
+	if tx.lane == nil {
+		return 0
+	}
+	return tx.lane.Lane
+}
+
+// Schedule returns the number and the timestamp a block has to reach at least
+// to include the transaction. Zero bounds are not enforced, hence both are zero
+// for all transactions but scheduled ones.
+func (tx *Transaction) Schedule() (uint64, uint64) {
+	if tx.sched == nil {
+		return 0, 0
+	}
+	return tx.sched.NotBefore, tx.sched.NotBeforeTime
+}
+
+// Calls returns the calls of a batch transaction, or nil for other transactions.
+func (tx *Transaction) Calls() []BatchCall {
+	if tx.batch == nil {
+		return nil
+	}
+	calls := make([]BatchCall, len(tx.batch.Calls))
+	copy(calls, tx.batch.Calls)
+	return calls
+}
+
+// FeePayer returns the account declared to pay for the gas of a sponsored
+// transaction, or nil for other transactions. Use the package level FeePayer to
+// retrieve the account proven by the fee payer signature.
+func (tx *Transaction) FeePayer() *common.Address {
+	if tx.sponsor == nil {
+		return nil
+	}
+	payer := tx.sponsor.FeePayer
+	return &payer
+}
+
 // Hash hashes the RLP encoding of tx.
 // It uniquely identifies the transaction.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
 		return hash.(common.Hash)
 	}
-	v := rlpHash(tx)
+	var v common.Hash
+	if tx.typ == LegacyTxType {
+		v = rlpHash(tx)
+	} else {
+		v = prefixedRlpHash(tx.typ, tx.typedPayload())
+	}
 	tx.hash.Store(v)
 	return v
 }
@@ -211,7 +907,7 @@ func (tx *Transaction) Size() common.StorageSize {
 		return size.(common.StorageSize)
 	}
 	c := writeCounter(0)
-	rlp.Encode(&c, &tx.data)
+	rlp.Encode(&c, tx)
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
@@ -234,7 +930,26 @@ func (tx *Transaction) AsMessage(s Signer) (Message, error) {
 
 	var err error
 	msg.from, err = Sender(s, tx)
-	return msg, err
+	if err != nil {
+		return msg, err
+	}
+	if tx.typ == SponsoredTxType {
+		payer, err := FeePayer(s, tx)
+		if err != nil {
+			return msg, err
+		}
+		msg.feePayer = &payer
+	}
+	if tx.batch != nil {
+		msg.calls = tx.batch.Calls
+	}
+	if tx.lane != nil {
+		msg.lane = tx.lane.Lane
+	}
+	if tx.sched != nil {
+		msg.notBefore, msg.notBeforeTime = tx.sched.NotBefore, tx.sched.NotBeforeTime
+	}
+	return msg, nil
 }
 
 // WithSignature returns a new transaction with the given signature.
@@ -244,13 +959,34 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 	if err != nil {
 		return nil, err
 	}
-	cpy := &Transaction{data: tx.data}
+	cpy := &Transaction{typ: tx.typ, data: tx.data, sponsor: tx.sponsor, batch: tx.batch, lane: tx.lane, sched: tx.sched, time: tx.time}
 	cpy.data.R, cpy.data.S, cpy.data.V = r, s, v
 	return cpy, nil
 }
 
-// Cost returns amount + gasprice * gaslimit.
+// WithFeePayerSignature returns a new sponsored transaction with the given fee
+// payer signature. This signature needs to be in the [R || S || V] format where
+// V is 0 or 1.
+func (tx *Transaction) WithFeePayerSignature(sig []byte) (*Transaction, error) {
+	if tx.typ != SponsoredTxType {
+		return nil, ErrTxTypeNotSupported
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("wrong size for signature: got %d, want 65", len(sig))
+	}
+	sponsor := *tx.sponsor
+	sponsor.R, sponsor.S, sponsor.V = decodeSignature(sig)
+
+	return &Transaction{typ: tx.typ, data: tx.data, sponsor: &sponsor, time: tx.time}, nil
+}
+
+// Cost returns amount + gasprice * gaslimit, the amount the sender has to own
+// for the transaction to be executable. The gas of sponsored transactions is
+// paid by the fee payer, so their cost is only the amount.
 func (tx *Transaction) Cost() *big.Int {
+	if tx.typ == SponsoredTxType {
+		return new(big.Int).Set(tx.data.Amount)
+	}
 	total := new(big.Int).Mul(tx.data.Price, new(big.Int).SetUint64(tx.data.GasLimit))
 	total.Add(total, tx.data.Amount)
 	return total
@@ -260,6 +996,15 @@ func (tx *Transaction) RawSignatureValues() (*big.Int, *big.Int, *big.Int) {
 	return tx.data.V, tx.data.R, tx.data.S
 }
 
+// RawFeePayerSignatureValues returns the V, R, S fee payer signature values of
+// a sponsored transaction, or nils for other transactions.
+func (tx *Transaction) RawFeePayerSignatureValues() (*big.Int, *big.Int, *big.Int) {
+	if tx.sponsor == nil {
+		return nil, nil, nil
+	}
+	return tx.sponsor.V, tx.sponsor.R, tx.sponsor.S
+}
+
 // Transactions is a Transaction slice type for basic sorting.
 type Transactions []*Transaction
 
@@ -326,33 +1071,56 @@ func (s *TxByPrice) Pop() interface{} {
 // transactions in a profit-maximizing sorted order, while supporting removing
 // entire batches of transactions for non-executable accounts.
 type TransactionsByPriceAndNonce struct {
-	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
-	heads  TxByPrice                       // Next transaction for each unique account (price heap)
-	signer Signer                          // Signer for the set of transactions
+	txs    map[SenderLane]Transactions // Per account lane nonce-sorted list of transactions
+	heads  TxByPrice                   // Next transaction for each unique account lane (price heap)
+	signer Signer                      // Signer for the set of transactions
+}
+
+// SenderLane identifies a nonce lane of a transaction sender.
+type SenderLane struct {
+	Sender common.Address
+	Lane   uint64
+}
+
+// SplitLanes regroups the per account nonce-sorted transactions by the nonce
+// lanes of their senders, as derived by the signer. The first transaction of
+// each lane is returned separately from the remaining ones.
+//
+// Note, the transactions of an account are expected to be grouped by lane.
+func SplitLanes(signer Signer, txs map[common.Address]Transactions) (Transactions, map[SenderLane]Transactions) {
+	heads := make(Transactions, 0, len(txs))
+	lanes := make(map[SenderLane]Transactions, len(txs))
+	for _, accTxs := range txs {
+		// Ensure the sender address is from the signer
+		acc, _ := Sender(signer, accTxs[0])
+		for i := 0; i < len(accTxs); {
+			j := i + 1
+			for j < len(accTxs) && accTxs[j].Lane() == accTxs[i].Lane() {
+				j++
+			}
+			heads = append(heads, accTxs[i])
+			lanes[SenderLane{acc, accTxs[i].Lane()}] = accTxs[i+1 : j]
+			i = j
+		}
+	}
+	return heads, lanes
 }
 
 // NewTransactionsByPriceAndNonce creates a transaction set that can retrieve
-// price sorted transactions in a nonce-honouring way.
+// price sorted transactions in a nonce-honouring way. The nonce lanes of the
+// accounts are iterated independently of each other.
 //
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByPriceAndNonce {
 	// Initialize a price based heap with the head transactions
-	heads := make(TxByPrice, 0, len(txs))
-	for from, accTxs := range txs {
-		heads = append(heads, accTxs[0])
-		// Ensure the sender address is from the signer
-		acc, _ := Sender(signer, accTxs[0])
-		txs[acc] = accTxs[1:]
-		if from != acc {
-			delete(txs, from)
-		}
-	}
+	first, lanes := SplitLanes(signer, txs)
+	heads := TxByPrice(first)
 	heap.Init(&heads)
 
 	// Assemble and return the transaction set
 	return &TransactionsByPriceAndNonce{
-		txs:    txs,
+		txs:    lanes,
 		heads:  heads,
 		signer: signer,
 	}
@@ -366,11 +1134,13 @@ func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
 	return t.heads[0]
 }
 
-// Shift replaces the current best head with the next one from the same account.
+// Shift replaces the current best head with the next one from the same account
+// lane.
 func (t *TransactionsByPriceAndNonce) Shift() {
 	acc, _ := Sender(t.signer, t.heads[0])
-	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+	lane := SenderLane{acc, t.heads[0].Lane()}
+	if txs, ok := t.txs[lane]; ok && len(txs) > 0 {
+		t.heads[0], t.txs[lane] = txs[0], txs[1:]
 		heap.Fix(&t.heads, 0)
 	} else {
 		heap.Pop(&t.heads)
@@ -378,8 +1148,8 @@ func (t *TransactionsByPriceAndNonce) Shift() {
 }
 
 // Pop removes the best transaction, *not* replacing it with the next one from
-// the same account. This should be used when a transaction cannot be executed
-// and hence all subsequent ones should be discarded from the same account.
+// the same account lane. This should be used when a transaction cannot be executed
+// and hence all subsequent ones should be discarded from the same account lane.
 func (t *TransactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
@@ -396,6 +1166,11 @@ type Message struct {
 	gasPrice   *big.Int
 	data       []byte
 	checkNonce bool
+	feePayer   *common.Address
+	calls      []BatchCall
+	lane       uint64
+
+	notBefore, notBeforeTime uint64
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, checkNonce bool) Message {
@@ -411,6 +1186,14 @@ func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *b
 	}
 }
 
+// NewBatchMessage creates a message executing the given calls atomically, with
+// the value of the message being the total value of the calls.
+func NewBatchMessage(from common.Address, nonce uint64, calls []BatchCall, gasLimit uint64, gasPrice *big.Int, checkNonce bool) Message {
+	msg := NewMessage(from, nil, nonce, batchValue(calls), gasLimit, gasPrice, nil, checkNonce)
+	msg.calls = calls
+	return msg
+}
+
 func (m Message) From() common.Address { return m.from }
 func (m Message) To() *common.Address  { return m.to }
 func (m Message) GasPrice() *big.Int   { return m.gasPrice }
@@ -419,3 +1202,17 @@ func (m Message) Gas() uint64          { return m.gasLimit }
 func (m Message) Nonce() uint64        { return m.nonce }
 func (m Message) Data() []byte         { return m.data }
 func (m Message) CheckNonce() bool     { return m.checkNonce }
+
+// FeePayer returns the account paying for the gas of the message, or nil if
+// the gas is paid by the sender.
+func (m Message) FeePayer() *common.Address { return m.feePayer }
+
+// Calls returns the calls of a batch message, or nil for a single call message.
+func (m Message) Calls() []BatchCall { return m.calls }
+
+// Lane returns the nonce lane of the sender the message consumes a nonce of.
+func (m Message) Lane() uint64 { return m.lane }
+
+// Schedule returns the number and the timestamp a block has to reach at least
+// to include the message, zero bounds are not enforced.
+func (m Message) Schedule() (uint64, uint64) { return m.notBefore, m.notBeforeTime }
@@ -62,6 +62,20 @@ func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, err
 	return tx.WithSignature(s, sig)
 }
 
+// SignTxAsFeePayer countersigns a sponsored transaction, already signed by its
+// sender, with the private key of its fee payer.
+func SignTxAsFeePayer(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	if err := checkSponsorSigner(s, tx); err != nil {
+		return nil, err
+	}
+	h := FeePayerHash(tx)
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithFeePayerSignature(sig)
+}
+
 // Sender returns the address derived from the signature (V, R, S) using secp256k1
 // elliptic curve and an error if it failed deriving or upon an incorrect
 // signature.
@@ -88,6 +102,77 @@ func Sender(signer Signer, tx *Transaction) (common.Address, error) {
 	return addr, nil
 }
 
+// FeePayer returns the address of the account paying for the gas of a sponsored
+// transaction, derived from the fee payer signature. It fails if the signature
+// was not made by the fee payer the sender committed to.
+//
+// FeePayer may cache the address the same way Sender does.
+func FeePayer(signer Signer, tx *Transaction) (common.Address, error) {
+	if sc := tx.payer.Load(); sc != nil {
+		sigCache := sc.(sigCache)
+		if sigCache.signer.Equal(signer) {
+			return sigCache.from, nil
+		}
+	}
+	if err := checkSponsorSigner(signer, tx); err != nil {
+		return common.Address{}, err
+	}
+	V := new(big.Int).Add(tx.sponsor.V, big27)
+	addr, err := recoverPlain(FeePayerHash(tx), tx.sponsor.R, tx.sponsor.S, V, true)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if addr != tx.sponsor.FeePayer {
+		return common.Address{}, ErrFeePayerMismatch
+	}
+	tx.payer.Store(sigCache{signer: signer, from: addr})
+	return addr, nil
+}
+
+// FeePayerHash returns the hash to be signed by the fee payer of a sponsored
+// transaction. It commits to the signature of the sender too, so a fee payer
+// only ever pays for the exact transaction it countersigned.
+func FeePayerHash(tx *Transaction) common.Hash {
+	if tx.sponsor == nil {
+		return common.Hash{}
+	}
+	return prefixedRlpHash(tx.typ, []interface{}{
+		tx.sponsor.ChainId,
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.sponsor.FeePayer,
+		tx.data.V,
+		tx.data.R,
+		tx.data.S,
+	})
+}
+
+// checkSponsorSigner ensures the transaction is a sponsored one and that the
+// signer is able to handle it.
+func checkSponsorSigner(signer Signer, tx *Transaction) error {
+	if tx.typ != SponsoredTxType {
+		return ErrTxTypeNotSupported
+	}
+	return checkTypedSigner(signer, tx)
+}
+
+// checkTypedSigner ensures the signer is able to handle the typed transaction
+// and that it is bound to the chain of the signer.
+func checkTypedSigner(signer Signer, tx *Transaction) error {
+	eip155, ok := signer.(EIP155Signer)
+	if !ok {
+		return ErrTxTypeNotSupported
+	}
+	if tx.ChainId().Cmp(eip155.chainId) != 0 {
+		return ErrInvalidChainId
+	}
+	return nil
+}
+
 // Signer encapsulates transaction signature handling. Note that this interface is not a
 // stable API and may change at any time to accommodate new protocol rules.
 type Signer interface {
@@ -122,9 +207,19 @@ func (s EIP155Signer) Equal(s2 Signer) bool {
 	return ok && eip155.chainId.Cmp(s.chainId) == 0
 }
 
-var big8 = big.NewInt(8)
+var (
+	big8  = big.NewInt(8)
+	big27 = big.NewInt(27)
+)
 
 func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		if err := checkTypedSigner(s, tx); err != nil {
+			return common.Address{}, err
+		}
+		V := new(big.Int).Add(tx.data.V, big27)
+		return recoverPlain(s.Hash(tx), tx.data.R, tx.data.S, V, true)
+	}
 	if !tx.Protected() {
 		return HomesteadSigner{}.Sender(tx)
 	}
@@ -139,6 +234,13 @@ func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
 // WithSignature returns a new transaction with the given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != LegacyTxType {
+		if err := checkTypedSigner(s, tx); err != nil {
+			return nil, nil, nil, err
+		}
+		R, S, V = decodeSignature(sig)
+		return R, S, V, nil
+	}
 	R, S, V, err = HomesteadSigner{}.SignatureValues(tx, sig)
 	if err != nil {
 		return nil, nil, nil, err
@@ -153,6 +255,50 @@ func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big
 // Hash returns the hash to be signed by the sender.
 // It does not uniquely identify the transaction.
 func (s EIP155Signer) Hash(tx *Transaction) common.Hash {
+	switch tx.Type() {
+	case SponsoredTxType:
+		return prefixedRlpHash(tx.typ, []interface{}{
+			s.chainId,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+			tx.sponsor.FeePayer,
+		})
+	case BatchTxType:
+		return prefixedRlpHash(tx.typ, []interface{}{
+			s.chainId,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.batch.Calls,
+		})
+	case LaneTxType:
+		return prefixedRlpHash(tx.typ, []interface{}{
+			s.chainId,
+			tx.lane.Lane,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+		})
+	case ScheduledTxType:
+		return prefixedRlpHash(tx.typ, []interface{}{
+			s.chainId,
+			tx.sched.NotBefore,
+			tx.sched.NotBeforeTime,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+		})
+	}
 	return rlpHash([]interface{}{
 		tx.data.AccountNonce,
 		tx.data.Price,
@@ -180,6 +326,9 @@ func (hs HomesteadSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v
 }
 
 func (hs HomesteadSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	return recoverPlain(hs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, true)
 }
 
@@ -193,12 +342,11 @@ func (s FrontierSigner) Equal(s2 Signer) bool {
 // SignatureValues returns signature values. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (fs FrontierSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error) {
-	if len(sig) != 65 {
-		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
+	if tx.Type() != LegacyTxType {
+		return nil, nil, nil, ErrTxTypeNotSupported
 	}
-	r = new(big.Int).SetBytes(sig[:32])
-	s = new(big.Int).SetBytes(sig[32:64])
-	v = new(big.Int).SetBytes([]byte{sig[64] + 27})
+	r, s, v = decodeSignature(sig)
+	v.Add(v, big27)
 	return r, s, v, nil
 }
 
@@ -216,9 +364,23 @@ func (fs FrontierSigner) Hash(tx *Transaction) common.Hash {
 }
 
 func (fs FrontierSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	return recoverPlain(fs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, false)
 }
 
+// decodeSignature splits a signature in the [R || S || V] format into its values.
+func decodeSignature(sig []byte) (r, s, v *big.Int) {
+	if len(sig) != 65 {
+		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
+	}
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	v = new(big.Int).SetBytes([]byte{sig[64]})
+	return r, s, v
+}
+
 func recoverPlain(sighash common.Hash, R, S, Vb *big.Int, homestead bool) (common.Address, error) {
 	if Vb.BitLen() > 8 {
 		return common.Address{}, ErrInvalidSig
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -136,3 +137,105 @@ func TestChainId(t *testing.T) {
 		t.Error("expected no error")
 	}
 }
+
+// Tests that sponsored transactions are signed by both the sender and the fee
+// payer, and that both of them are recoverable.
+func TestSponsoredSigning(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	signer := NewEIP155Signer(big.NewInt(18))
+	tx := NewSponsoredTransaction(big.NewInt(18), 0, &common.Address{1}, big.NewInt(1), 21000, big.NewInt(2), nil, payer)
+	if !tx.Protected() || tx.ChainId().Cmp(big.NewInt(18)) != 0 {
+		t.Fatalf("sponsored transaction not replay protected: chain id %v", tx.ChainId())
+	}
+	if _, err := SignTx(tx, HomesteadSigner{}, senderKey); err != ErrTxTypeNotSupported {
+		t.Fatalf("homestead signing error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	tx, err := SignTx(tx, signer, senderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FeePayer(signer, tx); err == nil {
+		t.Fatalf("fee payer recovered without fee payer signature")
+	}
+	// A fee payer signature by anyone but the declared fee payer is invalid
+	if forged, err := SignTxAsFeePayer(tx, signer, senderKey); err != nil {
+		t.Fatal(err)
+	} else if _, err := FeePayer(signer, forged); err != ErrFeePayerMismatch {
+		t.Fatalf("forged fee payer error mismatch: have %v, want %v", err, ErrFeePayerMismatch)
+	}
+	tx, err = SignTxAsFeePayer(tx, signer, payerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != sender {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	if from, err := FeePayer(signer, tx); err != nil || from != payer {
+		t.Fatalf("fee payer mismatch: have %x (%v), want %x", from, err, payer)
+	}
+	if _, err := Sender(NewEIP155Signer(big.NewInt(19)), tx); err != ErrInvalidChainId {
+		t.Fatalf("foreign chain sender error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.From() != sender || msg.FeePayer() == nil || *msg.FeePayer() != payer {
+		t.Fatalf("message accounts mismatch: from %x, fee payer %v", msg.From(), msg.FeePayer())
+	}
+	if tx.Cost().Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("sender cost mismatch: have %v, want %v", tx.Cost(), 1)
+	}
+}
+
+// Tests that sponsored transactions survive RLP and JSON round trips, both on
+// their own and embedded among legacy transactions.
+func TestSponsoredEncoding(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP155Signer(big.NewInt(18))
+
+	tx, _ := SignTx(NewSponsoredTransaction(big.NewInt(18), 3, nil, big.NewInt(0), 100000, big.NewInt(1), []byte("code"), addr), signer, key)
+	tx, _ = SignTxAsFeePayer(tx, signer, key)
+	legacy, _ := SignTx(NewTransaction(4, common.Address{3}, big.NewInt(5), 21000, big.NewInt(1), nil), signer, key)
+
+	blob, err := rlp.EncodeToBytes(Transactions{legacy, tx})
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	var txs Transactions
+	if err := rlp.DecodeBytes(blob, &txs); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(txs) != 2 || txs[0].Type() != LegacyTxType || txs[1].Type() != SponsoredTxType {
+		t.Fatalf("decoded transactions mismatch: %v", txs)
+	}
+	if txs[0].Hash() != legacy.Hash() || txs[1].Hash() != tx.Hash() {
+		t.Fatalf("decoded hashes mismatch: have %x %x, want %x %x", txs[0].Hash(), txs[1].Hash(), legacy.Hash(), tx.Hash())
+	}
+	if txs[1].Size() != tx.Size() {
+		t.Fatalf("decoded size mismatch: have %v, want %v", txs[1].Size(), tx.Size())
+	}
+	if from, err := FeePayer(signer, txs[1]); err != nil || from != addr {
+		t.Fatalf("decoded fee payer mismatch: have %x (%v)", from, err)
+	}
+	// JSON round trip
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var parsed *Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() || parsed.Type() != SponsoredTxType {
+		t.Fatalf("parsed tx differs from original tx, want %v, got %v", tx, parsed)
+	}
+	// Unknown envelope types must be rejected
+	if err := rlp.DecodeBytes(common.FromHex("0x83ff0102"), new(Transaction)); err != ErrTxTypeNotSupported {
+		t.Fatalf("unknown type error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
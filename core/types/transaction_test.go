@@ -233,3 +233,218 @@ func TestTransactionJSON(t *testing.T) {
 		}
 	}
 }
+
+// Tests that batch transactions can be signed and survive RLP and JSON round
+// trips, with their value being the total value of their calls.
+func TestBatchTransaction(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP155Signer(big.NewInt(18))
+
+	calls := []BatchCall{
+		{To: common.Address{1}, Value: big.NewInt(3)},
+		{To: common.Address{2}, Value: big.NewInt(4), Data: []byte{0xde, 0xad}},
+	}
+	tx, err := SignTx(NewBatchTransaction(big.NewInt(18), 1, calls, 100000, big.NewInt(2)), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign batch: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, addr)
+	}
+	if _, err := Sender(NewEIP155Signer(big.NewInt(1)), tx); err != ErrInvalidChainId {
+		t.Fatalf("foreign chain error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	if tx.Value().Cmp(big.NewInt(7)) != 0 || tx.Cost().Cmp(big.NewInt(200007)) != 0 || tx.To() != nil {
+		t.Fatalf("batch value mismatch: value %v, cost %v, to %v", tx.Value(), tx.Cost(), tx.To())
+	}
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to convert batch to message: %v", err)
+	}
+	if len(msg.Calls()) != 2 || msg.Value().Cmp(big.NewInt(7)) != 0 {
+		t.Fatalf("message mismatch: %d calls, value %v", len(msg.Calls()), msg.Value())
+	}
+	// RLP round trip
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	decoded, err := decodeTx(blob)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.Type() != BatchTxType || decoded.Value().Cmp(tx.Value()) != 0 {
+		t.Fatalf("decoded batch mismatch: have %v, want %v", decoded, tx)
+	}
+	if have := decoded.Calls(); len(have) != 2 || have[1].To != calls[1].To || !bytes.Equal(have[1].Data, calls[1].Data) {
+		t.Fatalf("decoded calls mismatch: have %v, want %v", have, calls)
+	}
+	// JSON round trip
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var parsed *Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Fatalf("parsed batch differs from original, want %v, got %v", tx, parsed)
+	}
+	// Batches without calls must be rejected
+	payload, _ := rlp.EncodeToBytes(&batchTx{ChainId: big.NewInt(18), Price: new(big.Int), V: new(big.Int), R: new(big.Int), S: new(big.Int)})
+	empty, _ := rlp.EncodeToBytes(append([]byte{BatchTxType}, payload...))
+	if _, err := decodeTx(empty); err != errEmptyBatchTx {
+		t.Fatalf("empty batch error mismatch: have %v, want %v", err, errEmptyBatchTx)
+	}
+}
+
+func TestLaneTransaction(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP155Signer(big.NewInt(18))
+
+	tx, err := SignTx(NewLaneTransaction(big.NewInt(18), 7, 3, common.Address{1}, big.NewInt(10), 21000, big.NewInt(2), nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign lane transaction: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, addr)
+	}
+	// The lane is covered by the signature
+	other, _ := SignTx(NewLaneTransaction(big.NewInt(18), 8, 3, common.Address{1}, big.NewInt(10), 21000, big.NewInt(2), nil), signer, key)
+	if signer.Hash(other) == signer.Hash(tx) {
+		t.Fatalf("lane not covered by the signature hash")
+	}
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to convert lane transaction to message: %v", err)
+	}
+	if msg.Lane() != 7 || msg.Nonce() != 3 {
+		t.Fatalf("message mismatch: lane %d, nonce %d", msg.Lane(), msg.Nonce())
+	}
+	if lane := emptyTx.Lane(); lane != 0 {
+		t.Fatalf("legacy transaction lane mismatch: have %d, want 0", lane)
+	}
+	// RLP round trip
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	decoded, err := decodeTx(blob)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.Type() != LaneTxType || decoded.Lane() != 7 || decoded.Nonce() != 3 {
+		t.Fatalf("decoded lane transaction mismatch: have %v, want %v", decoded, tx)
+	}
+	// JSON round trip
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var parsed *Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() || parsed.Lane() != 7 {
+		t.Fatalf("parsed lane transaction differs from original, want %v, got %v", tx, parsed)
+	}
+	// Lane transactions on the default lane must be rejected
+	payload, _ := rlp.EncodeToBytes(&laneTx{ChainId: big.NewInt(18), Price: new(big.Int), Amount: new(big.Int), V: new(big.Int), R: new(big.Int), S: new(big.Int)})
+	zero, _ := rlp.EncodeToBytes(append([]byte{LaneTxType}, payload...))
+	if _, err := decodeTx(zero); err != errDefaultLaneTx {
+		t.Fatalf("default lane error mismatch: have %v, want %v", err, errDefaultLaneTx)
+	}
+}
+
+func TestScheduledTransaction(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP155Signer(big.NewInt(18))
+
+	to := common.Address{1}
+	tx, err := SignTx(NewScheduledTransaction(big.NewInt(18), 100, 1550000000, 3, &to, big.NewInt(10), 21000, big.NewInt(2), nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign scheduled transaction: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, addr)
+	}
+	// The schedule is covered by the signature
+	other, _ := SignTx(NewScheduledTransaction(big.NewInt(18), 101, 1550000000, 3, &to, big.NewInt(10), 21000, big.NewInt(2), nil), signer, key)
+	if signer.Hash(other) == signer.Hash(tx) {
+		t.Fatalf("schedule not covered by the signature hash")
+	}
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to convert scheduled transaction to message: %v", err)
+	}
+	if number, time := msg.Schedule(); number != 100 || time != 1550000000 {
+		t.Fatalf("message schedule mismatch: have %d/%d, want %d/%d", number, time, 100, 1550000000)
+	}
+	if number, time := emptyTx.Schedule(); number != 0 || time != 0 {
+		t.Fatalf("legacy transaction schedule mismatch: have %d/%d, want 0/0", number, time)
+	}
+	// RLP round trip
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	decoded, err := decodeTx(blob)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if number, time := decoded.Schedule(); decoded.Hash() != tx.Hash() || decoded.Type() != ScheduledTxType || number != 100 || time != 1550000000 {
+		t.Fatalf("decoded scheduled transaction mismatch: have %v, want %v", decoded, tx)
+	}
+	// JSON round trip
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var parsed *Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if number, _ := parsed.Schedule(); parsed.Hash() != tx.Hash() || number != 100 {
+		t.Fatalf("parsed scheduled transaction differs from original, want %v, got %v", tx, parsed)
+	}
+	// Scheduled transactions without a schedule must be rejected
+	payload, _ := rlp.EncodeToBytes(&scheduledTx{ChainId: big.NewInt(18), Price: new(big.Int), Amount: new(big.Int), V: new(big.Int), R: new(big.Int), S: new(big.Int)})
+	zero, _ := rlp.EncodeToBytes(append([]byte{ScheduledTxType}, payload...))
+	if _, err := decodeTx(zero); err != errUnscheduledTx {
+		t.Fatalf("missing schedule error mismatch: have %v, want %v", err, errUnscheduledTx)
+	}
+}
+
+// Tests that the nonce lanes of an account are ordered independently of each
+// other, so discarding one of them leaves the rest intact.
+func TestTransactionLaneSort(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP155Signer(big.NewInt(18))
+
+	var txs Transactions
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, _ := SignTx(NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil), signer, key)
+		txs = append(txs, tx)
+	}
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, _ := SignTx(NewLaneTransaction(big.NewInt(18), 5, nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(2), nil), signer, key)
+		txs = append(txs, tx)
+	}
+	set := NewTransactionsByPriceAndNonce(signer, map[common.Address]Transactions{addr: txs})
+
+	// The better paying lane is served first, dropping it must keep the default one
+	if tx := set.Peek(); tx == nil || tx.Lane() != 5 || tx.Nonce() != 0 {
+		t.Fatalf("first transaction mismatch: have %v, want lane 5 nonce 0", tx)
+	}
+	set.Pop()
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if tx := set.Peek(); tx == nil || tx.Lane() != 0 || tx.Nonce() != nonce {
+			t.Fatalf("transaction %d mismatch: have %v, want lane 0 nonce %d", nonce, tx, nonce)
+		}
+		set.Shift()
+	}
+	if tx := set.Peek(); tx != nil {
+		t.Fatalf("unexpected leftover transaction: %v", tx)
+	}
+}
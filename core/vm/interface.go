@@ -33,6 +33,8 @@ type StateDB interface {
 
 	GetNonce(common.Address) uint64
 	SetNonce(common.Address, uint64)
+	GetLaneNonce(common.Address, uint64) uint64
+	SetLaneNonce(common.Address, uint64, uint64)
 
 	GetCodeHash(common.Address) common.Hash
 	GetCode(common.Address) []byte
@@ -40,8 +42,10 @@ type StateDB interface {
 	GetCodeSize(common.Address) int
 
 	AddRefund(uint64)
+	SubRefund(uint64)
 	GetRefund() uint64
 
+	GetCommittedState(common.Address, common.Hash) common.Hash
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
 
@@ -59,6 +63,8 @@ type StateDB interface {
 	Snapshot() int
 
 	AddLog(*types.Log)
+	// TxLogs returns the logs emitted so far by the transaction being processed.
+	TxLogs() []*types.Log
 	AddPreimage(common.Hash, []byte)
 
 	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool)
@@ -51,12 +51,16 @@ func (NoopStateDB) AddBalance(common.Address, *big.Int)
 func (NoopStateDB) GetBalance(common.Address) *big.Int                                 { return nil }
 func (NoopStateDB) GetNonce(common.Address) uint64                                     { return 0 }
 func (NoopStateDB) SetNonce(common.Address, uint64)                                    {}
+func (NoopStateDB) GetLaneNonce(common.Address, uint64) uint64                         { return 0 }
+func (NoopStateDB) SetLaneNonce(common.Address, uint64, uint64)                        {}
 func (NoopStateDB) GetCodeHash(common.Address) common.Hash                             { return common.Hash{} }
 func (NoopStateDB) GetCode(common.Address) []byte                                      { return nil }
 func (NoopStateDB) SetCode(common.Address, []byte)                                     {}
 func (NoopStateDB) GetCodeSize(common.Address) int                                     { return 0 }
 func (NoopStateDB) AddRefund(uint64)                                                   {}
+func (NoopStateDB) SubRefund(uint64)                                                   {}
 func (NoopStateDB) GetRefund() uint64                                                  { return 0 }
+func (NoopStateDB) GetCommittedState(common.Address, common.Hash) common.Hash          { return common.Hash{} }
 func (NoopStateDB) GetState(common.Address, common.Hash) common.Hash                   { return common.Hash{} }
 func (NoopStateDB) SetState(common.Address, common.Hash, common.Hash)                  {}
 func (NoopStateDB) Suicide(common.Address) bool                                        { return false }
@@ -66,5 +70,6 @@ func (NoopStateDB) Empty(common.Address) bool
 func (NoopStateDB) RevertToSnapshot(int)                                               {}
 func (NoopStateDB) Snapshot() int                                                      { return 0 }
 func (NoopStateDB) AddLog(*types.Log)                                                  {}
+func (NoopStateDB) TxLogs() []*types.Log                                               { return nil }
 func (NoopStateDB) AddPreimage(common.Hash, []byte)                                    {}
 func (NoopStateDB) ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) {}
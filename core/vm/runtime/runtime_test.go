@@ -25,7 +25,9 @@ import (
 	"github.com/dsplinz2019/dsplinz/common"
 	"github.com/dsplinz2019/dsplinz/core/state"
 	"github.com/dsplinz2019/dsplinz/core/vm"
+	"github.com/dsplinz2019/dsplinz/crypto"
 	"github.com/dsplinz2019/dsplinz/ethdb"
+	"github.com/dsplinz2019/dsplinz/params"
 )
 
 func TestDefaults(t *testing.T) {
@@ -116,6 +118,164 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// constantinopleConfig returns a chain configuration with every fork up to and
+// including Constantinople enabled from genesis.
+func constantinopleConfig() *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainId:             big.NewInt(1),
+		HomesteadBlock:      new(big.Int),
+		EIP150Block:         new(big.Int),
+		EIP155Block:         new(big.Int),
+		EIP158Block:         new(big.Int),
+		ByzantiumBlock:      new(big.Int),
+		ConstantinopleBlock: new(big.Int),
+	}
+}
+
+// Tests that the Constantinople opcodes are only available after the fork.
+func TestConstantinopleOpcodesGated(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 1,
+		byte(vm.SHL),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	byzantium := constantinopleConfig()
+	byzantium.ConstantinopleBlock = big.NewInt(10)
+
+	if _, _, err := Execute(code, nil, &Config{ChainConfig: byzantium}); err == nil {
+		t.Fatal("expected SHL to be invalid before Constantinople")
+	}
+	ret, _, err := Execute(code, nil, &Config{ChainConfig: constantinopleConfig()})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(2)) != 0 {
+		t.Error("Expected 2, got", num)
+	}
+}
+
+func TestExtCodeHash(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	target := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	state.SetCode(common.HexToAddress("0x0a"), target)
+
+	tests := []struct {
+		addr byte
+		want common.Hash
+	}{
+		{0x0a, crypto.Keccak256Hash(target)}, // contract account
+		{0x0b, common.Hash{}},                // non-existent account
+	}
+	for i, tt := range tests {
+		code := []byte{
+			byte(vm.PUSH1), tt.addr,
+			byte(vm.EXTCODEHASH),
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		}
+		ret, _, err := Execute(code, nil, &Config{ChainConfig: constantinopleConfig(), State: state})
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if have := common.BytesToHash(ret); have != tt.want {
+			t.Errorf("test %d: code hash mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
+
+func TestCreate2(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), byte(vm.STOP), // init code, a single STOP
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE8),
+		byte(vm.PUSH1), 42, // salt
+		byte(vm.PUSH1), 1, // size
+		byte(vm.PUSH1), 0, // offset
+		byte(vm.PUSH1), 0, // endowment
+		byte(vm.CREATE2),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	ret, _, err := Execute(code, nil, &Config{ChainConfig: constantinopleConfig()})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	creator := common.BytesToAddress([]byte("contract"))
+	want := vm.CreateAddress2(creator, common.BigToHash(big.NewInt(42)), crypto.Keccak256Hash([]byte{byte(vm.STOP)}))
+	if have := common.BytesToAddress(ret); have != want {
+		t.Errorf("contract address mismatch: have %x, want %x", have, want)
+	}
+}
+
+// Tests the SSTORE gas metering of EIP-1283 between Constantinople and Petersburg,
+// using the test cases of the EIP.
+func TestSstoreNetGasMetering(t *testing.T) {
+	petersburg := constantinopleConfig()
+	petersburg.PetersburgBlock = new(big.Int)
+
+	tests := []struct {
+		config   *params.ChainConfig
+		code     string
+		used     uint64
+		refund   uint64
+		original byte
+	}{
+		{constantinopleConfig(), "60006000556000600055", 412, 0, 0},
+		{constantinopleConfig(), "60006000556001600055", 20212, 0, 0},
+		{constantinopleConfig(), "60016000556000600055", 20212, 19800, 0},
+		{constantinopleConfig(), "60016000556002600055", 20212, 0, 0},
+		{constantinopleConfig(), "60016000556001600055", 20212, 0, 0},
+		{constantinopleConfig(), "60006000556000600055", 5212, 15000, 1},
+		{constantinopleConfig(), "60006000556001600055", 5212, 4800, 1},
+		{constantinopleConfig(), "60006000556002600055", 5212, 0, 1},
+		{constantinopleConfig(), "60026000556000600055", 5212, 15000, 1},
+		{constantinopleConfig(), "60026000556003600055", 5212, 0, 1},
+		{constantinopleConfig(), "60026000556001600055", 5212, 4800, 1},
+		{constantinopleConfig(), "60026000556002600055", 5212, 0, 1},
+		{constantinopleConfig(), "60016000556000600055", 5212, 15000, 1},
+		{constantinopleConfig(), "60016000556002600055", 5212, 0, 1},
+		{constantinopleConfig(), "60016000556001600055", 412, 0, 1},
+		{constantinopleConfig(), "600160005560006000556001600055", 40218, 19800, 0},
+		{constantinopleConfig(), "600060005560016000556000600055", 10218, 19800, 1},
+		{petersburg, "60016000556000600055", 25012, 15000, 0},
+	}
+	for i, tt := range tests {
+		address := common.BytesToAddress([]byte("contract"))
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, common.Hex2Bytes(tt.code))
+		statedb.SetState(address, common.Hash{}, common.BytesToHash([]byte{tt.original}))
+		statedb.Finalise(true) // Push the state into the "original" slot
+
+		cfg := &Config{ChainConfig: tt.config, State: statedb, GasLimit: 100000}
+		setDefaults(cfg)
+
+		vmenv := NewEnv(cfg)
+		_, gas, err := vmenv.Call(vm.AccountRef(cfg.Origin), address, nil, cfg.GasLimit, new(big.Int))
+		if err != nil {
+			t.Errorf("test %d: failed to execute: %v", i, err)
+			continue
+		}
+		if used := cfg.GasLimit - gas; used != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.used)
+		}
+		if refund := statedb.GetRefund(); refund != tt.refund {
+			t.Errorf("test %d: gas refund mismatch: have %v, want %v", i, refund, tt.refund)
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
@@ -28,6 +28,7 @@ import (
 
 	"github.com/relianz2019/relianz/common"
 	"github.com/relianz2019/relianz/common/hexutil"
+	"github.com/relianz2019/relianz/consensus"
 	"github.com/relianz2019/relianz/core"
 	"github.com/relianz2019/relianz/core/rawdb"
 	"github.com/relianz2019/relianz/core/state"
@@ -66,6 +67,82 @@ func (api *PublicRlzereumAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().HashRate())
 }
 
+// PublicConfirmationAPI provides an API to inspect the signer confirmations
+// collected for the blocks of the local chain.
+type PublicConfirmationAPI struct {
+	e *Rlzereum
+}
+
+// NewPublicConfirmationAPI creates a new confirmation API for full nodes.
+func NewPublicConfirmationAPI(e *Rlzereum) *PublicConfirmationAPI {
+	return &PublicConfirmationAPI{e}
+}
+
+// ConfirmationStatus summarizes the signer confirmations of a single block.
+type ConfirmationStatus struct {
+	Number  hexutil.Uint64   `json:"number"`
+	Hash    common.Hash      `json:"hash"`
+	Signers []common.Address `json:"signers"`
+	Count   hexutil.Uint     `json:"count"`
+	Quorum  hexutil.Uint     `json:"quorum"`
+}
+
+// header resolves the canonical header of the given block number.
+func (api *PublicConfirmationAPI) header(blockNr rpc.BlockNumber) (*types.Header, error) {
+	var header *types.Header
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		header = api.e.blockchain.CurrentHeader()
+	} else {
+		header = api.e.blockchain.GetHeaderByNumber(uint64(blockNr))
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return header, nil
+}
+
+// GetConfirmationCount returns the number of distinct signers that confirmed the
+// block with the given number.
+func (api *PublicConfirmationAPI) GetConfirmationCount(blockNr rpc.BlockNumber) (hexutil.Uint, error) {
+	header, err := api.header(blockNr)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint(len(api.e.confirmationPool.ConfirmationsByHash(header.Hash()))), nil
+}
+
+// GetConfirmationStatus returns the signers that confirmed the block with the
+// given number, along with the number of confirmations needed for the block to
+// become irreversible.
+func (api *PublicConfirmationAPI) GetConfirmationStatus(blockNr rpc.BlockNumber) (*ConfirmationStatus, error) {
+	header, err := api.header(blockNr)
+	if err != nil {
+		return nil, err
+	}
+	status := &ConfirmationStatus{
+		Number:  hexutil.Uint64(header.Number.Uint64()),
+		Hash:    header.Hash(),
+		Signers: []common.Address{},
+	}
+	for _, confirmation := range api.e.confirmationPool.ConfirmationsByHash(status.Hash) {
+		signer, err := confirmation.Signer()
+		if err != nil {
+			continue
+		}
+		status.Signers = append(status.Signers, signer)
+	}
+	status.Count = hexutil.Uint(len(status.Signers))
+
+	if confirmer, ok := api.e.engine.(consensus.Confirmer); ok {
+		quorum, err := confirmer.ConfirmationQuorum(api.e.blockchain, header)
+		if err != nil {
+			return nil, err
+		}
+		status.Quorum = hexutil.Uint(quorum)
+	}
+	return status, nil
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only mdspods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
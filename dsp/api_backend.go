@@ -63,8 +63,13 @@ func (b *RlzAPIBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 		return block.Header(), nil
 	}
 	// Otherwise resolve and return the block
-	if blockNr == rpc.LatestBlockNumber {
+	switch blockNr {
+	case rpc.LatestBlockNumber:
 		return b.dsp.blockchain.CurrentBlock().Header(), nil
+	case rpc.FinalizedBlockNumber:
+		return b.dsp.blockchain.CurrentFinalizedBlock().Header(), nil
+	case rpc.SafeBlockNumber:
+		return b.dsp.blockchain.CurrentSafeBlock().Header(), nil
 	}
 	return b.dsp.blockchain.GetHeaderByNumber(uint64(blockNr)), nil
 }
@@ -76,8 +81,13 @@ func (b *RlzAPIBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 		return block, nil
 	}
 	// Otherwise resolve and return the block
-	if blockNr == rpc.LatestBlockNumber {
+	switch blockNr {
+	case rpc.LatestBlockNumber:
 		return b.dsp.blockchain.CurrentBlock(), nil
+	case rpc.FinalizedBlockNumber:
+		return b.dsp.blockchain.CurrentFinalizedBlock(), nil
+	case rpc.SafeBlockNumber:
+		return b.dsp.blockchain.CurrentSafeBlock(), nil
 	}
 	return b.dsp.blockchain.GetBlockByNumber(uint64(blockNr)), nil
 }
@@ -180,6 +190,10 @@ func (b *RlzAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (
 	return b.dsp.txPool.State().GetNonce(addr), nil
 }
 
+func (b *RlzAPIBackend) GetPoolLaneNonce(ctx context.Context, addr common.Address, lane uint64) (uint64, error) {
+	return b.dsp.txPool.LaneNonce(addr, lane), nil
+}
+
 func (b *RlzAPIBackend) Stats() (pending int, queued int) {
 	return b.dsp.txPool.Stats()
 }
@@ -188,10 +202,38 @@ func (b *RlzAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.dsp.TxPool().Content()
 }
 
+func (b *RlzAPIBackend) TxPoolPolicy() (string, map[string]uint64) {
+	return b.dsp.TxPool().Policy().Name(), b.dsp.TxPool().PolicyStats()
+}
+
+func (b *RlzAPIBackend) TxPoolExport() ([]byte, error) {
+	return b.dsp.TxPool().ExportSnapshot(false)
+}
+
+func (b *RlzAPIBackend) TxPoolImport(snapshot []byte) (int, int, error) {
+	return b.dsp.TxPool().ImportSnapshot(snapshot)
+}
+
+func (b *RlzAPIBackend) TxPoolHistory(hash common.Hash) []*core.TxTransition {
+	return b.dsp.TxPool().TxHistory(hash)
+}
+
+func (b *RlzAPIBackend) TxPoolScheduled(addr common.Address) types.Transactions {
+	return b.dsp.TxPool().Scheduled(addr)
+}
+
+func (b *RlzAPIBackend) CancelScheduledTx(hash common.Hash) (*types.Transaction, error) {
+	return b.dsp.TxPool().CancelScheduled(hash)
+}
+
 func (b *RlzAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.dsp.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *RlzAPIBackend) SubscribeTxStatusEvent(ch chan<- core.TxStatusEvent) event.Subscription {
+	return b.dsp.TxPool().SubscribeTxStatusEvent(ch)
+}
+
 func (b *RlzAPIBackend) Downloader() *downloader.Downloader {
 	return b.dsp.Downloader()
 }
@@ -41,6 +41,7 @@ import (
 	"github.com/relianz2019/relianz/dsp/filters"
 	"github.com/relianz2019/relianz/dsp/gasprice"
 	"github.com/relianz2019/relianz/dspdb"
+	"github.com/relianz2019/relianz/dspgrpc"
 	"github.com/relianz2019/relianz/event"
 	"github.com/relianz2019/relianz/internal/dspapi"
 	"github.com/relianz2019/relianz/log"
@@ -50,6 +51,7 @@ import (
 	"github.com/relianz2019/relianz/params"
 	"github.com/relianz2019/relianz/rlp"
 	"github.com/relianz2019/relianz/rpc"
+	"google.golang.org/grpc"
 )
 
 type LesServer interface {
@@ -68,10 +70,11 @@ type Rlzereum struct {
 	shutdownChan chan bool // Channel for shutting down the Rlzereum
 
 	// Handlers
-	txPool          *core.TxPool
-	blockchain      *core.BlockChain
-	protocolManager *ProtocolManager
-	lesServer       LesServer
+	txPool           *core.TxPool
+	confirmationPool *core.ConfirmationPool
+	blockchain       *core.BlockChain
+	protocolManager  *ProtocolManager
+	lesServer        LesServer
 
 	// DB interfaces
 	chainDb dspdb.Database // Block chain database
@@ -109,7 +112,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Rlzereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := ctx.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, config.DatabaseFreezerDepth, "dsp/db/chaindata/")
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +145,16 @@ func New(ctx *node.ServiceContext, config *Config) (*Rlzereum, error) {
 
 	log.Info("Initialising TTC protocol", "versions", ProtocolVersions, "network", config.NetworkId)
 
+	// Connect side chains to the main chain they anchor their blocks to
+	if engine, ok := dsp.engine.(*alien.Alien); ok && chainConfig.Alien.SideChain && config.MainChainEndpoint != "" {
+		client, err := rpc.Dial(config.MainChainEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to main chain: %v", err)
+		}
+		engine.SetMainChain(alien.NewRPCMainChain(client))
+		log.Info("Connected to main chain", "endpoint", config.MainChainEndpoint)
+	}
+
 	if !config.SkipBcVersionCheck {
 		bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 		if bcVersion != core.BlockChainVersion && bcVersion != 0 {
@@ -151,7 +164,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Rlzereum, error) {
 	}
 	var (
 		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, TriesInMemory: config.TrieRetention}
 	)
 	dsp.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, dsp.chainConfig, dsp.engine, vmConfig)
 	if err != nil {
@@ -168,9 +181,13 @@ func New(ctx *node.ServiceContext, config *Config) (*Rlzereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Snapshot != "" {
+		config.TxPool.Snapshot = ctx.ResolvePath(config.TxPool.Snapshot)
+	}
 	dsp.txPool = core.NewTxPool(config.TxPool, dsp.chainConfig, dsp.blockchain)
+	dsp.confirmationPool = core.NewConfirmationPool(dsp.blockchain, chainDb)
 
-	if dsp.protocolManager, err = NewProtocolManager(dsp.chainConfig, config.SyncMode, config.NetworkId, dsp.eventMux, dsp.txPool, dsp.engine, dsp.blockchain, chainDb); err != nil {
+	if dsp.protocolManager, err = NewProtocolManager(dsp.chainConfig, config.SyncMode, config.NetworkId, dsp.eventMux, dsp.txPool, dsp.confirmationPool, dsp.engine, dsp.blockchain, chainDb); err != nil {
 		return nil, err
 	}
 	dsp.miner = miner.New(dsp, dsp.chainConfig, dsp.EventMux(), dsp.engine)
@@ -209,7 +226,7 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (dspdb.Data
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*dspdb.LDBDatabase); ok {
+	if db, ok := db.(interface{ Meter(prefix string) }); ok {
 		db.Meter("dsp/db/chaindata/")
 	}
 	return db, nil
@@ -256,6 +273,21 @@ func (s *Rlzereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Expose the signers and votes of the delegated-proof-of-stake scheme and
+	// accept the checkpoints of side chains anchored to this chain
+	if engine, ok := s.engine.(*alien.Alien); ok {
+		apis = append(apis, rpc.API{
+			Namespace: "alien",
+			Version:   "1.0",
+			Service:   alien.NewPublicExplorerAPI(s.blockchain, engine),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "alien",
+			Version:   "1.0",
+			Service:   alien.NewPrivateMainChainAPI(s.chainDb, s.blockchain.Config().Alien.SideChains),
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -263,6 +295,11 @@ func (s *Rlzereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicRlzereumAPI(s),
 			Public:    true,
+		}, {
+			Namespace: "dsp",
+			Version:   "1.0",
+			Service:   NewPublicConfirmationAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "dsp",
 			Version:   "1.0",
@@ -305,6 +342,12 @@ func (s *Rlzereum) APIs() []rpc.API {
 	}...)
 }
 
+// RegisterGRPC implements node.GRPCService, serving the blocks, receipts and logs
+// of the chain over gRPC.
+func (s *Rlzereum) RegisterGRPC(server *grpc.Server) {
+	dspgrpc.RegisterChainServer(server, dspgrpc.NewChainService(s.APIBackend))
+}
+
 func (s *Rlzereum) ResetWithGenesisBlock(gb *types.Block) {
 	s.blockchain.ResetWithGenesisBlock(gb)
 }
@@ -378,16 +421,17 @@ func (s *Rlzereum) StopMining()         { s.miner.Stop() }
 func (s *Rlzereum) IsMining() bool      { return s.miner.Mining() }
 func (s *Rlzereum) Miner() *miner.Miner { return s.miner }
 
-func (s *Rlzereum) AccountManager() *accounts.Manager  { return s.accountManager }
-func (s *Rlzereum) BlockChain() *core.BlockChain       { return s.blockchain }
-func (s *Rlzereum) TxPool() *core.TxPool               { return s.txPool }
-func (s *Rlzereum) EventMux() *event.TypeMux           { return s.eventMux }
-func (s *Rlzereum) Engine() consensus.Engine           { return s.engine }
-func (s *Rlzereum) ChainDb() dspdb.Database            { return s.chainDb }
-func (s *Rlzereum) IsListening() bool                  { return true } // Always listening
-func (s *Rlzereum) RlzVersion() int                    { return int(s.protocolManager.SubProtocols[0].Version) }
-func (s *Rlzereum) NetVersion() uint64                 { return s.networkId }
-func (s *Rlzereum) Downloader() *downloader.Downloader { return s.protocolManager.downloader }
+func (s *Rlzereum) AccountManager() *accounts.Manager        { return s.accountManager }
+func (s *Rlzereum) BlockChain() *core.BlockChain             { return s.blockchain }
+func (s *Rlzereum) TxPool() *core.TxPool                     { return s.txPool }
+func (s *Rlzereum) ConfirmationPool() *core.ConfirmationPool { return s.confirmationPool }
+func (s *Rlzereum) EventMux() *event.TypeMux                 { return s.eventMux }
+func (s *Rlzereum) Engine() consensus.Engine                 { return s.engine }
+func (s *Rlzereum) ChainDb() dspdb.Database                  { return s.chainDb }
+func (s *Rlzereum) IsListening() bool                        { return true } // Always listening
+func (s *Rlzereum) RlzVersion() int                          { return int(s.protocolManager.SubProtocols[0].Version) }
+func (s *Rlzereum) NetVersion() uint64                       { return s.networkId }
+func (s *Rlzereum) Downloader() *downloader.Downloader       { return s.protocolManager.downloader }
 
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
@@ -433,6 +477,7 @@ func (s *Rlzereum) Stop() error {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	s.confirmationPool.Stop()
 	s.miner.Stop()
 	s.eventMux.Stop()
 
@@ -43,12 +43,13 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:     1,
-	LightPeers:    100,
-	DatabaseCache: 768,
-	TrieCache:     256,
-	TrieTimeout:   5 * time.Minute,
-	GasPrice:      big.NewInt(18 * params.Shannon),
+	NetworkId:            1,
+	LightPeers:           100,
+	DatabaseCache:        768,
+	DatabaseFreezerDepth: 90000,
+	TrieCache:            256,
+	TrieTimeout:          5 * time.Minute,
+	GasPrice:             big.NewInt(18 * params.Shannon),
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -83,6 +84,9 @@ type Config struct {
 	SyncMode  downloader.SyncMode
 	NoPruning bool
 
+	// Number of recent block states kept by the pruning garbage collector (0 = default)
+	TrieRetention uint64 `toml:",omitempty"`
+
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
@@ -94,6 +98,13 @@ type Config struct {
 	TrieCache          int
 	TrieTimeout        time.Duration
 
+	// Ancient store options. Canonical blocks deeper than DatabaseFreezerDepth
+	// below the head are moved out of the key-value store into flat files at
+	// DatabaseFreezer (default = inside the chain database), 0 keeps new blocks
+	// in the key-value store.
+	DatabaseFreezer      string `toml:",omitempty"`
+	DatabaseFreezerDepth uint64
+
 	// Mining-related options
 	Rlzerbase    common.Address `toml:",omitempty"`
 	MinerThreads int            `toml:",omitempty"`
@@ -112,6 +123,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// RPC endpoint of the main chain a side chain anchors its blocks to
+	MainChainEndpoint string `toml:",omitempty"`
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 }
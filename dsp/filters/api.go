@@ -28,6 +28,7 @@ import (
 	dspereum "github.com/relianz2019/relianz"
 	"github.com/relianz2019/relianz/common"
 	"github.com/relianz2019/relianz/common/hexutil"
+	"github.com/relianz2019/relianz/core"
 	"github.com/relianz2019/relianz/core/types"
 	"github.com/relianz2019/relianz/dspdb"
 	"github.com/relianz2019/relianz/event"
@@ -168,6 +169,43 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// TransactionStatus creates a subscription that is triggered each time a
+// transaction tracked by the transaction pool changes its status, i.e. it gets
+// queued, becomes pending, or leaves the pool by inclusion or for a drop reason.
+// If a hash is given, only the transitions of that transaction are streamed.
+func (api *PublicFilterAPI) TransactionStatus(ctx context.Context, hash *common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		statuses := make(chan []*core.TxTransition, 128)
+		statusSub := api.events.SubscribeTxStatus(statuses)
+
+		for {
+			select {
+			case transitions := <-statuses:
+				for _, transition := range transitions {
+					if hash == nil || transition.Hash == *hash {
+						notifier.Notify(rpcSub.ID, transition)
+					}
+				}
+			case <-rpcSub.Err():
+				statusSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				statusSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with dsp_getFilterChanges.
 //
@@ -18,6 +18,7 @@ package filters
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/relianz2019/relianz/common"
@@ -40,6 +41,7 @@ type Backend interface {
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeTxStatusEvent(ch chan<- core.TxStatusEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
@@ -102,6 +104,14 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	}
 	head := header.Number.Uint64()
 
+	// Resolve the finality tags into concrete block numbers
+	var err error
+	if f.begin, err = f.resolveTag(ctx, f.begin); err != nil {
+		return nil, err
+	}
+	if f.end, err = f.resolveTag(ctx, f.end); err != nil {
+		return nil, err
+	}
 	if f.begin == -1 {
 		f.begin = int64(head)
 	}
@@ -110,10 +120,8 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		end = head
 	}
 	// Gather all indexed logs, and finish with non indexed ones
-	var (
-		logs []*types.Log
-		err  error
-	)
+	var logs []*types.Log
+
 	size, sections := f.backend.BloomStatus()
 	if indexed := sections * size; indexed > uint64(f.begin) {
 		if indexed > end {
@@ -130,6 +138,22 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// resolveTag converts the finalized and safe block tags into the number of the
+// block they currently refer to. Other numbers are returned unchanged.
+func (f *Filter) resolveTag(ctx context.Context, number int64) (int64, error) {
+	if number != rpc.FinalizedBlockNumber.Int64() && number != rpc.SafeBlockNumber.Int64() {
+		return number, nil
+	}
+	header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errors.New("finalized block not found")
+	}
+	return header.Number.Int64(), nil
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -53,6 +53,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// TxStatusSubscription queries the status transitions of the transactions
+	// tracked by the transaction pool
+	TxStatusSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -68,6 +71,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// statusChanSize is the size of channel listening to TxStatusEvent.
+	statusChanSize = 100
 )
 
 var (
@@ -82,6 +87,7 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *types.Header
+	statuses  chan []*core.TxTransition
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -99,6 +105,7 @@ type EventSystem struct {
 	logsSub       event.Subscription         // Subscription for new log event
 	rmLogsSub     event.Subscription         // Subscription for removed log event
 	chainSub      event.Subscription         // Subscription for new chain event
+	statusSub     event.Subscription         // Subscription for transaction status event
 	pendingLogSub *event.TypeMuxSubscription // Subscription for pending log event
 
 	// Channels
@@ -108,6 +115,7 @@ type EventSystem struct {
 	logsCh    chan []*types.Log          // Channel to receive new log event
 	rmLogsCh  chan core.RemovedLogsEvent // Channel to receive removed log event
 	chainCh   chan core.ChainEvent       // Channel to receive new chain event
+	statusCh  chan core.TxStatusEvent    // Channel to receive transaction status event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -127,6 +135,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan core.ChainEvent, chainEvChanSize),
+		statusCh:  make(chan core.TxStatusEvent, statusChanSize),
 	}
 
 	// Subscribe events
@@ -134,12 +143,13 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
+	m.statusSub = m.backend.SubscribeTxStatusEvent(m.statusCh)
 	// TODO(rjl493456442): use feed to subscribe pending log event
 	m.pendingLogSub = m.mux.Subscribe(core.PendingLogsEvent{})
 
 	// Make sure none of the subscriptions are empty
 	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
-		m.pendingLogSub.Closed() {
+		m.statusSub == nil || m.pendingLogSub.Closed() {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -175,6 +185,7 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
 			case <-sub.f.headers:
+			case <-sub.f.statuses:
 			}
 		}
 
@@ -208,6 +219,10 @@ func (es *EventSystem) SubscribeLogs(crit dspereum.FilterQuery, logs chan []*typ
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
 
+	// finality tags are resolved when the logs are queried, live logs are delivered as mined
+	if from <= rpc.FinalizedBlockNumber || to <= rpc.FinalizedBlockNumber {
+		return es.subscribeLogs(crit, logs), nil
+	}
 	// only interested in pending logs
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber {
 		return es.subscribePendingLogs(crit, logs), nil
@@ -242,6 +257,7 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit dspereum.FilterQuery, logs
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		statuses:  make(chan []*core.TxTransition),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -259,6 +275,7 @@ func (es *EventSystem) subscribeLogs(crit dspereum.FilterQuery, logs chan []*typ
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		statuses:  make(chan []*core.TxTransition),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -276,6 +293,7 @@ func (es *EventSystem) subscribePendingLogs(crit dspereum.FilterQuery, logs chan
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		statuses:  make(chan []*core.TxTransition),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -292,6 +310,7 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		logs:      make(chan []*types.Log),
 		hashes:    make(chan []common.Hash),
 		headers:   headers,
+		statuses:  make(chan []*core.TxTransition),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -308,6 +327,24 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 		logs:      make(chan []*types.Log),
 		hashes:    hashes,
 		headers:   make(chan *types.Header),
+		statuses:  make(chan []*core.TxTransition),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeTxStatus creates a subscription that writes the status transitions of
+// the transactions tracked by the transaction pool.
+func (es *EventSystem) SubscribeTxStatus(statuses chan []*core.TxTransition) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       TxStatusSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		statuses:  statuses,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -356,6 +393,10 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[PendingTransactionsSubscription] {
 			f.hashes <- hashes
 		}
+	case core.TxStatusEvent:
+		for _, f := range filters[TxStatusSubscription] {
+			f.statuses <- e.Transitions
+		}
 	case core.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
 			f.headers <- e.Block.Header()
@@ -454,6 +495,7 @@ func (es *EventSystem) eventLoop() {
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.statusSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -472,6 +514,8 @@ func (es *EventSystem) eventLoop() {
 			es.broadcast(index, ev)
 		case ev := <-es.chainCh:
 			es.broadcast(index, ev)
+		case ev := <-es.statusCh:
+			es.broadcast(index, ev)
 		case ev, active := <-es.pendingLogSub.Chan():
 			if !active { // system stopped
 				return
@@ -507,6 +551,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.statusSub.Err():
+			return
 		}
 	}
 }
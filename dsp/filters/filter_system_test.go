@@ -46,6 +46,7 @@ type testBackend struct {
 	rmLogsFeed *event.Feed
 	logsFeed   *event.Feed
 	chainFeed  *event.Feed
+	statusFeed *event.Feed
 }
 
 func (b *testBackend) ChainDb() dspdb.Database {
@@ -112,6 +113,10 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxStatusEvent(ch chan<- core.TxStatusEvent) event.Subscription {
+	return b.statusFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
@@ -158,7 +163,7 @@ func TestBlockSubscription(t *testing.T) {
 		rmLogsFeed  = new(event.Feed)
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
-		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api         = NewPublicFilterAPI(backend, false)
 		genesis     = new(core.Genesis).MustCommit(db)
 		chain, _    = core.GenerateChain(params.TestChainConfig, genesis, dspash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
@@ -215,7 +220,7 @@ func TestPendingTxFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		transactions = []*types.Transaction{
@@ -265,6 +270,59 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestTxStatusSubscription tests that the status transitions posted by the
+// transaction pool are delivered to the subscribers in order.
+func TestTxStatusSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db         = dspdb.NewMemDatabase()
+		statusFeed = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), statusFeed}
+		events     = NewEventSystem(mux, backend, false)
+
+		hash        = common.HexToHash("0x01")
+		replacement = common.HexToHash("0x02")
+		transitions = []*core.TxTransition{
+			{Hash: hash, Status: core.TxLifecycleQueued},
+			{Hash: hash, Status: core.TxLifecyclePending},
+			{Hash: hash, Status: core.TxLifecycleDropped, Reason: core.TxDropReplaced, ReplacedBy: &replacement},
+			{Hash: replacement, Status: core.TxLifecyclePending},
+		}
+	)
+	statuses := make(chan []*core.TxTransition)
+	sub := events.SubscribeTxStatus(statuses)
+	defer sub.Unsubscribe()
+
+	go statusFeed.Send(core.TxStatusEvent{Transitions: transitions[:2]})
+
+	var have []*core.TxTransition
+	for len(have) < 2 {
+		select {
+		case batch := <-statuses:
+			have = append(have, batch...)
+		case <-time.After(time.Second):
+			t.Fatalf("status transitions timeout: have %d, want %d", len(have), 2)
+		}
+	}
+	go statusFeed.Send(core.TxStatusEvent{Transitions: transitions[2:]})
+
+	for len(have) < len(transitions) {
+		select {
+		case batch := <-statuses:
+			have = append(have, batch...)
+		case <-time.After(time.Second):
+			t.Fatalf("status transitions timeout: have %d, want %d", len(have), len(transitions))
+		}
+	}
+	for i, transition := range transitions {
+		if have[i] != transition {
+			t.Errorf("transition %d mismatch: have %+v, want %+v", i, have[i], transition)
+		}
+	}
+}
+
 // TestLogFilterCreation test whdsper a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -275,7 +333,7 @@ func TestLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		testCases = []struct {
@@ -324,7 +382,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 	)
 
@@ -354,7 +412,7 @@ func TestLogFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -473,7 +531,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -48,6 +48,9 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+
+	// confirmationChanSize is the size of channel listening to NewConfirmationEvent.
+	confirmationChanSize = 256
 )
 
 var (
@@ -68,10 +71,11 @@ type ProtocolManager struct {
 	fastSync  uint32 // Flag whdsper fast sync is enabled (gets disabled if we already have blocks)
 	acceptTxs uint32 // Flag whdsper we're considered synchronised (enables transaction processing)
 
-	txpool      txPool
-	blockchain  *core.BlockChain
-	chainconfig *params.ChainConfig
-	maxPeers    int
+	txpool        txPool
+	confirmations confirmationPool
+	blockchain    *core.BlockChain
+	chainconfig   *params.ChainConfig
+	maxPeers      int
 
 	downloader *downloader.Downloader
 	fetcher    *fetcher.Fetcher
@@ -84,6 +88,9 @@ type ProtocolManager struct {
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
 
+	confirmationCh  chan core.NewConfirmationEvent
+	confirmationSub event.Subscription
+
 	// channels for fetcher, syncer, txsyncLoop
 	newPeerCh   chan *peer
 	txsyncCh    chan *txsync
@@ -97,19 +104,20 @@ type ProtocolManager struct {
 
 // NewProtocolManager returns a new Rlzereum sub protocol manager. The Rlzereum sub protocol manages peers capable
 // with the Rlzereum network.
-func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, networkId uint64, mux *event.TypeMux, txpool txPool, engine consensus.Engine, blockchain *core.BlockChain, chaindb dspdb.Database) (*ProtocolManager, error) {
+func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, networkId uint64, mux *event.TypeMux, txpool txPool, confirmations confirmationPool, engine consensus.Engine, blockchain *core.BlockChain, chaindb dspdb.Database) (*ProtocolManager, error) {
 	// Create the protocol manager with the base fields
 	manager := &ProtocolManager{
-		networkId:   networkId,
-		eventMux:    mux,
-		txpool:      txpool,
-		blockchain:  blockchain,
-		chainconfig: config,
-		peers:       newPeerSet(),
-		newPeerCh:   make(chan *peer),
-		noMorePeers: make(chan struct{}),
-		txsyncCh:    make(chan *txsync),
-		quitSync:    make(chan struct{}),
+		networkId:     networkId,
+		eventMux:      mux,
+		txpool:        txpool,
+		confirmations: confirmations,
+		blockchain:    blockchain,
+		chainconfig:   config,
+		peers:         newPeerSet(),
+		newPeerCh:     make(chan *peer),
+		noMorePeers:   make(chan struct{}),
+		txsyncCh:      make(chan *txsync),
+		quitSync:      make(chan struct{}),
 	}
 	// Figure out whdsper to allow fast sync or not
 	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
@@ -211,6 +219,11 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.minedBlockSub = pm.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go pm.minedBroadcastLoop()
 
+	// broadcast signer confirmations
+	pm.confirmationCh = make(chan core.NewConfirmationEvent, confirmationChanSize)
+	pm.confirmationSub = pm.confirmations.SubscribeNewConfirmationEvent(pm.confirmationCh)
+	go pm.confirmationBroadcastLoop()
+
 	// start sync handlers
 	go pm.syncer()
 	go pm.txsyncLoop()
@@ -219,8 +232,9 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 func (pm *ProtocolManager) Stop() {
 	log.Info("Stopping TTC protocol")
 
-	pm.txsSub.Unsubscribe()        // quits txBroadcastLoop
-	pm.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	pm.txsSub.Unsubscribe()          // quits txBroadcastLoop
+	pm.minedBlockSub.Unsubscribe()   // quits blockBroadcastLoop
+	pm.confirmationSub.Unsubscribe() // quits confirmationBroadcastLoop
 
 	// Quit the sync loop.
 	// After this send has completed, no new peers will be accepted.
@@ -620,6 +634,25 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		pm.txpool.AddRemotes(txs)
 
+	case p.version >= dsp64 && msg.Code == ConfirmationMsg:
+		// Confirmations are only meaningful for blocks we already have
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+			break
+		}
+		var confirmations []*types.Confirmation
+		if err := msg.Decode(&confirmations); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		for i, confirmation := range confirmations {
+			if confirmation == nil {
+				return errResp(ErrDecode, "confirmation %d is nil", i)
+			}
+			p.MarkConfirmation(confirmation.Hash())
+			if err := pm.confirmations.Add(confirmation); err != nil && err != core.ErrKnownConfirmation {
+				p.Log().Trace("Discarded signer confirmation", "number", confirmation.Number, "hash", confirmation.BlockHash, "err", err)
+			}
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -678,6 +711,19 @@ func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
 	}
 }
 
+// BroadcastConfirmation will propagate a signer confirmation to all peers which
+// are not known to already have it. Peers older than dsp/64 can't receive them.
+func (pm *ProtocolManager) BroadcastConfirmation(confirmation *types.Confirmation) {
+	var recipients int
+	for _, peer := range pm.peers.PeersWithoutConfirmation(confirmation.Hash()) {
+		if peer.version >= dsp64 {
+			peer.AsyncSendConfirmation(confirmation)
+			recipients++
+		}
+	}
+	log.Trace("Broadcast confirmation", "number", confirmation.Number, "hash", confirmation.BlockHash, "recipients", recipients)
+}
+
 // Mined broadcast loop
 func (pm *ProtocolManager) minedBroadcastLoop() {
 	// automatically stops if unsubscribe
@@ -703,6 +749,19 @@ func (pm *ProtocolManager) txBroadcastLoop() {
 	}
 }
 
+func (pm *ProtocolManager) confirmationBroadcastLoop() {
+	for {
+		select {
+		case event := <-pm.confirmationCh:
+			pm.BroadcastConfirmation(event.Confirmation)
+
+		// Err() channel will be closed when unsubscribing.
+		case <-pm.confirmationSub.Err():
+			return
+		}
+	}
+}
+
 // NodeInfo represents a short summary of the Rlzereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -40,6 +40,8 @@ const (
 	maxKnownTxs    = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
 	maxKnownBlocks = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
 
+	maxKnownConfirmations = 4096 // Maximum confirmation hashes to keep in the known list (prevent DOS)
+
 	// maxQueuedTxs is the maximum number of transaction lists to queue up before
 	// dropping broadcasts. This is a sensitive number as a transaction list might
 	// contain a single transaction, or thousands.
@@ -55,6 +57,11 @@ const (
 	// above some healthy uncle limit, so use that.
 	maxQueuedAnns = 4
 
+	// maxQueuedConfirmations is the maximum number of signer confirmations to
+	// queue up before dropping broadcasts. Each signer confirms every block, so
+	// allow for a few blocks worth of a full signer queue.
+	maxQueuedConfirmations = 128
+
 	handshakeTimeout = 5 * time.Second
 )
 
@@ -85,31 +92,35 @@ type peer struct {
 	td   *big.Int
 	lock sync.RWMutex
 
-	knownTxs    *set.Set                  // Set of transaction hashes known to be known by this peer
-	knownBlocks *set.Set                  // Set of block hashes known to be known by this peer
-	queuedTxs   chan []*types.Transaction // Queue of transactions to broadcast to the peer
-	queuedProps chan *propEvent           // Queue of blocks to broadcast to the peer
-	queuedAnns  chan *types.Block         // Queue of blocks to announce to the peer
-	term        chan struct{}             // Termination channel to stop the broadcaster
+	knownTxs           *set.Set                  // Set of transaction hashes known to be known by this peer
+	knownBlocks        *set.Set                  // Set of block hashes known to be known by this peer
+	knownConfirmations *set.Set                  // Set of confirmation hashes known to be known by this peer
+	queuedTxs          chan []*types.Transaction // Queue of transactions to broadcast to the peer
+	queuedProps        chan *propEvent           // Queue of blocks to broadcast to the peer
+	queuedAnns         chan *types.Block         // Queue of blocks to announce to the peer
+	queuedConfirms     chan *types.Confirmation  // Queue of signer confirmations to broadcast to the peer
+	term               chan struct{}             // Termination channel to stop the broadcaster
 }
 
 func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 	return &peer{
-		Peer:        p,
-		rw:          rw,
-		version:     version,
-		id:          fmt.Sprintf("%x", p.ID().Bytes()[:8]),
-		knownTxs:    set.New(),
-		knownBlocks: set.New(),
-		queuedTxs:   make(chan []*types.Transaction, maxQueuedTxs),
-		queuedProps: make(chan *propEvent, maxQueuedProps),
-		queuedAnns:  make(chan *types.Block, maxQueuedAnns),
-		term:        make(chan struct{}),
+		Peer:               p,
+		rw:                 rw,
+		version:            version,
+		id:                 fmt.Sprintf("%x", p.ID().Bytes()[:8]),
+		knownTxs:           set.New(),
+		knownBlocks:        set.New(),
+		knownConfirmations: set.New(),
+		queuedTxs:          make(chan []*types.Transaction, maxQueuedTxs),
+		queuedProps:        make(chan *propEvent, maxQueuedProps),
+		queuedAnns:         make(chan *types.Block, maxQueuedAnns),
+		queuedConfirms:     make(chan *types.Confirmation, maxQueuedConfirmations),
+		term:               make(chan struct{}),
 	}
 }
 
-// broadcast is a write loop that multiplexes block propagations, announcements
-// and transaction broadcasts into the remote peer. The goal is to have an async
+// broadcast is a write loop that multiplexes block propagations, announcements,
+// transaction and confirmation broadcasts into the remote peer. The goal is to have an async
 // writer that does not lock up node internals.
 func (p *peer) broadcast() {
 	for {
@@ -132,6 +143,12 @@ func (p *peer) broadcast() {
 			}
 			p.Log().Trace("Announced block", "number", block.Number(), "hash", block.Hash())
 
+		case confirmation := <-p.queuedConfirms:
+			if err := p.SendConfirmations([]*types.Confirmation{confirmation}); err != nil {
+				return
+			}
+			p.Log().Trace("Broadcast confirmation", "number", confirmation.Number, "hash", confirmation.BlockHash)
+
 		case <-p.term:
 			return
 		}
@@ -193,6 +210,16 @@ func (p *peer) MarkTransaction(hash common.Hash) {
 	p.knownTxs.Add(hash)
 }
 
+// MarkConfirmation marks a signer confirmation as known for the peer, ensuring
+// that it will never be propagated to this particular peer.
+func (p *peer) MarkConfirmation(hash common.Hash) {
+	// If we reached the memory allowance, drop a previously known confirmation hash
+	for p.knownConfirmations.Size() >= maxKnownConfirmations {
+		p.knownConfirmations.Pop()
+	}
+	p.knownConfirmations.Add(hash)
+}
+
 // SendTransactions sends transactions to the peer and includes the hashes
 // in its transaction hash set for future reference.
 func (p *peer) SendTransactions(txs types.Transactions) error {
@@ -215,6 +242,26 @@ func (p *peer) AsyncSendTransactions(txs []*types.Transaction) {
 	}
 }
 
+// SendConfirmations sends signer confirmations to the peer and includes their
+// hashes in its confirmation hash set for future reference.
+func (p *peer) SendConfirmations(confirmations []*types.Confirmation) error {
+	for _, confirmation := range confirmations {
+		p.knownConfirmations.Add(confirmation.Hash())
+	}
+	return p2p.Send(p.rw, ConfirmationMsg, confirmations)
+}
+
+// AsyncSendConfirmation queues a signer confirmation for propagation to a remote
+// peer. If the peer's broadcast queue is full, the event is silently dropped.
+func (p *peer) AsyncSendConfirmation(confirmation *types.Confirmation) {
+	select {
+	case p.queuedConfirms <- confirmation:
+		p.knownConfirmations.Add(confirmation.Hash())
+	default:
+		p.Log().Debug("Dropping confirmation propagation", "number", confirmation.Number, "hash", confirmation.BlockHash)
+	}
+}
+
 // SendNewBlockHashes announces the availability of a number of blocks through
 // a hash notification.
 func (p *peer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error {
@@ -492,6 +539,21 @@ func (ps *peerSet) PeersWithoutTx(hash common.Hash) []*peer {
 	return list
 }
 
+// PeersWithoutConfirmation retrieves a list of peers that do not have a given
+// signer confirmation in their set of known hashes.
+func (ps *peerSet) PeersWithoutConfirmation(hash common.Hash) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if !p.knownConfirmations.Has(hash) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
 	ps.lock.RLock()
@@ -32,16 +32,17 @@ import (
 const (
 	dsp62 = 62
 	dsp63 = 63
+	dsp64 = 64
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 var ProtocolName = "dsp"
 
 // ProtocolVersions are the upported versions of the dsp protocol (first is primary).
-var ProtocolVersions = []uint{dsp63, dsp62}
+var ProtocolVersions = []uint{dsp64, dsp63, dsp62}
 
 // ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{17, 8}
+var ProtocolLengths = []uint64{18, 17, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -62,6 +63,10 @@ const (
 	NodeDataMsg    = 0x0e
 	GetReceiptsMsg = 0x0f
 	ReceiptsMsg    = 0x10
+
+	// Protocol messages belonging to dsp/64, signer confirmations of the PBFT
+	// enabled alien consensus
+	ConfirmationMsg = 0x11
 )
 
 type errCode int
@@ -108,6 +113,15 @@ type txPool interface {
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 }
 
+type confirmationPool interface {
+	// Add should verify and store the given signer confirmation.
+	Add(*types.Confirmation) error
+
+	// SubscribeNewConfirmationEvent should return an event subscription of
+	// NewConfirmationEvent and send events to the given channel.
+	SubscribeNewConfirmationEvent(chan<- core.NewConfirmationEvent) event.Subscription
+}
+
 // statusData is the network packet for the status message.
 type statusData struct {
 	ProtocolVersion uint32
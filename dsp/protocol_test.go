@@ -179,6 +179,42 @@ func testSendTransactions(t *testing.T, protocol int) {
 	wg.Wait()
 }
 
+// Tests that signer confirmations are only broadcast to dsp/64 peers, older ones
+// would drop the connection on the unknown message.
+func TestBroadcastConfirmationVersions(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	p63, _ := newTestPeer("peer-63", dsp63, pm, true)
+	defer p63.close()
+	p64, _ := newTestPeer("peer-64", dsp64, pm, true)
+	defer p64.close()
+
+	for start := time.Now(); pm.peers.Len() < 2; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 2*time.Second {
+			t.Fatalf("peers not registered: have %d, want 2", pm.peers.Len())
+		}
+	}
+	confirmation := types.NewConfirmation(1, common.Hash{0x01})
+	confirmation.Signature = make([]byte, 65)
+	pm.BroadcastConfirmation(confirmation)
+
+	msg, err := p64.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if msg.Code != ConfirmationMsg {
+		t.Fatalf("message code mismatch: have %d, want %d", msg.Code, ConfirmationMsg)
+	}
+	var confirmations []*types.Confirmation
+	if err := msg.Decode(&confirmations); err != nil || len(confirmations) != 1 || confirmations[0].Hash() != confirmation.Hash() {
+		t.Fatalf("confirmation mismatch: have %v, %v", confirmations, err)
+	}
+	if p63.knownConfirmations.Has(confirmation.Hash()) {
+		t.Fatalf("confirmation queued to dsp/63 peer")
+	}
+}
+
 // Tests that the custom union field encoder and decoder works correctly.
 func TestGetBlockHeadersDataEncodeDecode(t *testing.T) {
 	// Create a "random" hash for testing
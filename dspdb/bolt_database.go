@@ -0,0 +1,407 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relianz2019/relianz/common"
+	"github.com/relianz2019/relianz/log"
+	"github.com/relianz2019/relianz/metrics"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// boltFileName is the name of the database file within the bolt directory.
+	boltFileName = "bolt.db"
+
+	// boltIteratorChunk is the number of entries an iterator loads from the
+	// database in a single read transaction.
+	boltIteratorChunk = 1024
+)
+
+var (
+	// boltBucket is the single bucket holding all the key-value pairs.
+	boltBucket = []byte("kv")
+
+	// boltKeyPrefix is prepended to every key stored in the bucket, since bolt
+	// does not permit empty keys but the database interface does.
+	boltKeyPrefix = []byte{'k'}
+
+	errBoltNotFound = errors.New("not found")
+)
+
+// BoltDatabase is a Database backed by a single bbolt B+tree file. It is a pure
+// Go alternative to LevelDB, trading write throughput for predictable reads and
+// the absence of background compactions.
+type BoltDatabase struct {
+	fn string   // directory name for reporting
+	db *bolt.DB // bbolt instance
+
+	diskReadMeter  metrics.Meter // Meter for measuring the number of read transactions
+	diskWriteMeter metrics.Meter // Meter for measuring the amount of data written
+	writeTimeMeter metrics.Meter // Meter for measuring the time spent writing to disk
+
+	quitLock sync.Mutex      // Mutex protecting the quit channel access
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewBoltDatabase opens (or creates) a bolt database within the given directory.
+// The cache allowance is used to presize the memory map, bolt keeps no file
+// handles apart from the database file itself.
+func NewBoltDatabase(file string, cache int) (*BoltDatabase, error) {
+	logger := log.New("database", file)
+
+	if cache < 16 {
+		cache = 16
+	}
+	logger.Info("Allocated memory map", "cache", cache)
+
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(file, boltFileName), 0600, &bolt.Options{
+		Timeout:         time.Second,
+		InitialMmapSize: cache * 1024 * 1024,
+		FreelistType:    bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltDatabase{
+		fn:  file,
+		db:  db,
+		log: logger,
+	}, nil
+}
+
+// boltKey converts a database key into its internal representation.
+func boltKey(key []byte) []byte {
+	return append(append(make([]byte, 0, len(boltKeyPrefix)+len(key)), boltKeyPrefix...), key...)
+}
+
+// Path returns the path to the database directory.
+func (db *BoltDatabase) Path() string {
+	return db.fn
+}
+
+// Put puts the given key / value to the database.
+func (db *BoltDatabase) Put(key []byte, value []byte) error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(boltKey(key), value)
+	})
+}
+
+func (db *BoltDatabase) Has(key []byte) (bool, error) {
+	var has bool
+	err := db.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(boltBucket).Get(boltKey(key)) != nil
+		return nil
+	})
+	return has, err
+}
+
+// Get returns the given key if it's present.
+func (db *BoltDatabase) Get(key []byte) ([]byte, error) {
+	var dat []byte
+	err := db.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid during the transaction, copy them out
+		dat = common.CopyBytes(tx.Bucket(boltBucket).Get(boltKey(key)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dat == nil {
+		return nil, errBoltNotFound
+	}
+	return dat, nil
+}
+
+// Delete deletes the key from the database.
+func (db *BoltDatabase) Delete(key []byte) error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete(boltKey(key))
+	})
+}
+
+// NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
+func (db *BoltDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &boltIterator{
+		db:     db.db,
+		prefix: boltKey(prefix),
+		next:   boltKey(prefix),
+	}
+}
+
+// DeleteRange deletes all keys in the range [start, limit) in a single transaction.
+func (db *BoltDatabase) DeleteRange(start, limit []byte) error {
+	start = boltKey(start)
+	if limit != nil {
+		limit = boltKey(limit)
+	}
+	return db.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+
+		// Deleting through a cursor invalidates its position, so seek anew
+		// after every deletion.
+		for k, _ := c.Seek(start); k != nil; k, _ = c.Seek(start) {
+			if limit != nil && bytes.Compare(k, limit) >= 0 {
+				break
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Stat returns a particular internal stat of the database. The only property
+// supported is "stats" (optionally prefixed with "bolt."), which an empty
+// property also maps to.
+func (db *BoltDatabase) Stat(property string) (string, error) {
+	property = strings.TrimPrefix(property, "bolt.")
+	if property != "" && property != "stats" {
+		return "", fmt.Errorf("unknown property: %s", property)
+	}
+	var (
+		stats  = db.db.Stats()
+		bstats bolt.BucketStats
+		size   int64
+	)
+	err := db.db.View(func(tx *bolt.Tx) error {
+		bstats = tx.Bucket(boltBucket).Stats()
+		size = tx.Size()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Size(MB):%.5f Keys:%d Depth:%d\n", float64(size)/1048576.0, bstats.KeyN, bstats.Depth)
+	fmt.Fprintf(&buf, "Pages Branch:%d Leaf:%d Overflow:%d Free:%d Pending:%d\n", bstats.BranchPageN, bstats.LeafPageN, bstats.LeafOverflowN, stats.FreePageN, stats.PendingPageN)
+	fmt.Fprintf(&buf, "Transactions Read:%d Open:%d Writes:%d WriteTime:%v\n", stats.TxN, stats.OpenTxN, stats.TxStats.Write, stats.TxStats.WriteTime)
+	return buf.String(), nil
+}
+
+// Compact is a no-op for bolt, the B+tree is kept balanced on every write and
+// freed pages are reused in place. Shrinking the file requires copying the data
+// into a fresh database.
+func (db *BoltDatabase) Compact(start, limit []byte) error {
+	return nil
+}
+
+func (db *BoltDatabase) Close() {
+	// Stop the metrics collection to avoid internal database races
+	db.quitLock.Lock()
+	defer db.quitLock.Unlock()
+
+	if db.quitChan != nil {
+		errc := make(chan error)
+		db.quitChan <- errc
+		if err := <-errc; err != nil {
+			db.log.Error("Metrics collection failed", "err", err)
+		}
+	}
+	err := db.db.Close()
+	if err == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", err)
+	}
+}
+
+// Meter configures the database metrics collectors and starts the periodic
+// collection of the bolt transaction statistics.
+func (db *BoltDatabase) Meter(prefix string) {
+	if !metrics.Enabled {
+		return
+	}
+	db.diskReadMeter = metrics.NewRegisteredMeter(prefix+"disk/read", nil)
+	db.diskWriteMeter = metrics.NewRegisteredMeter(prefix+"disk/write", nil)
+	db.writeTimeMeter = metrics.NewRegisteredMeter(prefix+"disk/writetime", nil)
+
+	// Create a quit channel for the periodic collector and run it
+	db.quitLock.Lock()
+	db.quitChan = make(chan chan error)
+	db.quitLock.Unlock()
+
+	go db.meter(3 * time.Second)
+}
+
+// meter periodically retrieves the bolt statistics and reports the changes
+// since the last round to the metrics subsystem.
+func (db *BoltDatabase) meter(refresh time.Duration) {
+	prev := db.db.Stats()
+	for {
+		select {
+		case errc := <-db.quitChan:
+			errc <- nil
+			return
+
+		case <-time.After(refresh):
+			stats := db.db.Stats()
+			diff := stats.Sub(&prev)
+
+			db.diskReadMeter.Mark(int64(diff.TxN))
+			db.diskWriteMeter.Mark(int64(diff.TxStats.Write) * int64(os.Getpagesize()))
+			db.writeTimeMeter.Mark(int64(diff.TxStats.WriteTime))
+
+			prev = stats
+		}
+	}
+}
+
+func (db *BoltDatabase) NewBatch() Batch {
+	return &boltBatch{db: db.db}
+}
+
+// boltBatch gathers writes in memory and applies them atomically in a single
+// update transaction.
+type boltBatch struct {
+	db     *bolt.DB
+	writes []kv
+	size   int
+}
+
+func (b *boltBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, kv{boltKey(key), common.CopyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+func (b *boltBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, kv{boltKey(key), nil, true})
+	b.size += 1
+	return nil
+}
+
+func (b *boltBatch) Write() error {
+	if len(b.writes) == 0 {
+		return nil
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, kv := range b.writes {
+			if kv.del {
+				if err := bucket.Delete(kv.k); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := bucket.Put(kv.k, kv.v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *boltBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// boltIterator walks a key prefix of a bolt database. Entries are loaded in
+// chunks through short lived read transactions, as holding a transaction open
+// for the whole iteration would block writers from growing the memory map.
+type boltIterator struct {
+	db     *bolt.DB
+	prefix []byte // Internal key prefix to iterate over
+	next   []byte // Internal key to load the next chunk from
+
+	keys   [][]byte
+	values [][]byte
+	index  int
+	done   bool
+	err    error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *boltIterator) Next() bool {
+	if it.index+1 < len(it.keys) {
+		it.index++
+		return true
+	}
+	if it.done || it.err != nil {
+		it.keys, it.values, it.index = nil, nil, 0
+		return false
+	}
+	it.keys, it.values, it.index = it.keys[:0], it.values[:0], 0
+	it.err = it.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, v := c.Seek(it.next); k != nil && bytes.HasPrefix(k, it.prefix); k, v = c.Next() {
+			if len(it.keys) == boltIteratorChunk {
+				it.next = common.CopyBytes(k)
+				return nil
+			}
+			it.keys = append(it.keys, common.CopyBytes(k[len(boltKeyPrefix):]))
+			it.values = append(it.values, common.CopyBytes(v))
+		}
+		it.done = true
+		return nil
+	})
+	return it.err == nil && len(it.keys) > 0
+}
+
+// Error returns any accumulated error.
+func (it *boltIterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *boltIterator) Key() []byte {
+	if it.index < len(it.keys) {
+		return it.keys[it.index]
+	}
+	return nil
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *boltIterator) Value() []byte {
+	if it.index < len(it.values) {
+		return it.values[it.index]
+	}
+	return nil
+}
+
+// Release releases associated resources.
+func (it *boltIterator) Release() {
+	it.keys, it.values, it.done = nil, nil, true
+}
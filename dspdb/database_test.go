@@ -44,6 +44,22 @@ func newTestLDB() (*ethdb.LDBDatabase, func()) {
 	}
 }
 
+func newTestBoltDB() (*ethdb.BoltDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := ethdb.NewBoltDatabase(dirname, 0)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
 var test_values = []string{"", "a", "1251", "\x00123\x00"}
 
 func TestLDB_PutGet(t *testing.T) {
@@ -52,6 +68,12 @@ func TestLDB_PutGet(t *testing.T) {
 	testPutGet(db, t)
 }
 
+func TestBoltDB_PutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testPutGet(db, t)
+}
+
 func TestMemoryDB_PutGet(t *testing.T) {
 	testPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -129,6 +151,12 @@ func TestLDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(db, t)
 }
 
+func TestBoltDB_ParallelPutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
 func TestMemoryDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -188,3 +216,163 @@ func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IterateDelete(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterateDelete(db, t)
+}
+
+func TestBoltDB_IterateDelete(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testIterateDelete(db, t)
+}
+
+func TestMemoryDB_IterateDelete(t *testing.T) {
+	testIterateDelete(ethdb.NewMemDatabase(), t)
+}
+
+func TestTable_IterateDelete(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("other"), []byte("x"))
+
+	testIterateDelete(ethdb.NewTable(db, "tbl-"), t)
+	if ok, _ := db.Has([]byte("other")); !ok {
+		t.Fatalf("table range deletion removed a key outside of the table")
+	}
+}
+
+func testIterateDelete(db ethdb.Database, t *testing.T) {
+	keys := []string{"a1", "a2", "a3", "b1", "b2", "c1"}
+	for _, k := range []string{"c1", "a2", "b1", "a1", "b2", "a3"} {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	collect := func(prefix string) []string {
+		it := db.NewIteratorWithPrefix([]byte(prefix))
+		defer it.Release()
+
+		var have []string
+		for it.Next() {
+			if !bytes.Equal(it.Value(), []byte("v"+string(it.Key()))) {
+				t.Fatalf("value mismatch for %q: %q", it.Key(), it.Value())
+			}
+			have = append(have, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		return have
+	}
+	if have := collect(""); fmt.Sprint(have) != fmt.Sprint(keys) {
+		t.Fatalf("full iteration mismatch: have %v, want %v", have, keys)
+	}
+	if have := collect("b"); fmt.Sprint(have) != fmt.Sprint(keys[3:5]) {
+		t.Fatalf("prefix iteration mismatch: have %v, want %v", have, keys[3:5])
+	}
+	// Delete a bounded range, then everything from a key onwards
+	if err := db.DeleteRange([]byte("a2"), []byte("b2")); err != nil {
+		t.Fatalf("range deletion failed: %v", err)
+	}
+	if have, want := collect(""), []string{"a1", "b2", "c1"}; fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Fatalf("bounded range deletion mismatch: have %v, want %v", have, want)
+	}
+	if err := db.DeleteRange([]byte("b"), nil); err != nil {
+		t.Fatalf("open range deletion failed: %v", err)
+	}
+	if have, want := collect(""), []string{"a1"}; fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Fatalf("open range deletion mismatch: have %v, want %v", have, want)
+	}
+	// Deletions through a batch must be applied on write
+	batch := db.NewBatch()
+	batch.Delete([]byte("a1"))
+	if have := collect(""); len(have) != 1 {
+		t.Fatalf("batch deletion applied before write")
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	if have := collect(""); len(have) != 0 {
+		t.Fatalf("batch deletion not applied: %v", have)
+	}
+}
+
+// Tests that bolt iterators crossing the boundaries of the internally loaded
+// chunks neither skip nor repeat entries, even if the database is modified.
+func TestBoltDB_IterateChunks(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+
+	const n = 2500
+	batch := db.NewBatch()
+	for i := 0; i < n; i++ {
+		batch.Put([]byte(fmt.Sprintf("key-%05d", i)), []byte{byte(i)})
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	it := db.NewIteratorWithPrefix([]byte("key-"))
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		if want := fmt.Sprintf("key-%05d", count); string(it.Key()) != want {
+			t.Fatalf("entry %d: key mismatch: have %q, want %q", count, it.Key(), want)
+		}
+		if !bytes.Equal(it.Value(), []byte{byte(count)}) {
+			t.Fatalf("entry %d: value mismatch: have %x, want %x", count, it.Value(), byte(count))
+		}
+		// Writing while iterating must not deadlock
+		if err := db.Put([]byte(fmt.Sprintf("zzz-%05d", count)), nil); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+		count++
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if count != n {
+		t.Fatalf("iterated entry count mismatch: have %d, want %d", count, n)
+	}
+}
+
+// Tests that databases are reopened with the engine they were created with and
+// that opening them with a different one is refused.
+func TestNewDatabaseEngine(t *testing.T) {
+	for _, engine := range ethdb.Engines {
+		dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+		if err != nil {
+			t.Fatalf("failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(dirname)
+
+		db, err := ethdb.NewDatabase(engine, dirname, 0, 0)
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		db.Put([]byte("key"), []byte("value"))
+		db.Close()
+
+		if have := ethdb.DetectEngine(dirname); have != engine {
+			t.Fatalf("%s: detected engine mismatch: have %q", engine, have)
+		}
+		if db, err = ethdb.NewDatabase("", dirname, 0, 0); err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+			t.Fatalf("%s: reopened value mismatch: have %q, %v", engine, value, err)
+		}
+		db.Close()
+
+		for _, other := range ethdb.Engines {
+			if other == engine {
+				continue
+			}
+			if _, err := ethdb.NewDatabase(other, dirname, 0, 0); err == nil {
+				t.Fatalf("%s: opened with mismatching engine %s", engine, other)
+			}
+		}
+	}
+}
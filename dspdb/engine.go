@@ -0,0 +1,71 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Supported on-disk database engines.
+const (
+	EngineLevelDB = "leveldb" // LevelDB log-structured merge tree (default)
+	EngineBolt    = "bolt"    // Pure Go bbolt B+tree
+)
+
+// DefaultEngine is the engine used for new databases if none is requested.
+const DefaultEngine = EngineLevelDB
+
+// Engines is the list of supported database engines.
+var Engines = []string{EngineLevelDB, EngineBolt}
+
+// DetectEngine returns the engine of the database stored at the given path, or
+// an empty string if there is no database there yet.
+func DetectEngine(file string) string {
+	if _, err := os.Stat(filepath.Join(file, "CURRENT")); err == nil {
+		return EngineLevelDB
+	}
+	if _, err := os.Stat(filepath.Join(file, boltFileName)); err == nil {
+		return EngineBolt
+	}
+	return ""
+}
+
+// NewDatabase opens the database at the given path with the requested engine.
+// If no engine is requested, the engine of an existing database is used, or the
+// default one for fresh databases. Opening an existing database with a different
+// engine than it was created with is an error.
+func NewDatabase(engine string, file string, cache int, handles int) (Database, error) {
+	existing := DetectEngine(file)
+	switch {
+	case engine == "" && existing != "":
+		engine = existing
+	case engine == "":
+		engine = DefaultEngine
+	case existing != "" && existing != engine:
+		return nil, fmt.Errorf("database %s was created with engine %q, cannot open with %q", file, existing, engine)
+	}
+	switch engine {
+	case EngineLevelDB:
+		return NewLDBDatabase(file, cache, handles)
+	case EngineBolt:
+		return NewBoltDatabase(file, cache)
+	default:
+		return nil, fmt.Errorf("unknown database engine %q", engine)
+	}
+}
@@ -25,20 +25,62 @@ type Putter interface {
 	Put(key []byte, value []byte) error
 }
 
+// Deleter wraps the database delete operation supported by both batches and regular databases.
+type Deleter interface {
+	Delete(key []byte) error
+}
+
+// Iterator iterates over a database's key/value pairs in ascending key order.
+// An iterator must be released after use, it is not safe for concurrent use.
+type Iterator interface {
+	// Next moves the iterator to the next key/value pair, returning whether
+	// the iterator is exhausted.
+	Next() bool
+	// Error returns any accumulated error.
+	Error() error
+	// Key returns the key of the current pair. The caller must not modify the
+	// returned slice, and its contents may change on the next call to Next.
+	Key() []byte
+	// Value returns the value of the current pair, with the same restrictions
+	// as Key.
+	Value() []byte
+	// Release releases associated resources.
+	Release()
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
+	Deleter
 	Get(key []byte) ([]byte, error)
 	Has(key []byte) (bool, error)
-	Delete(key []byte) error
 	Close()
 	NewBatch() Batch
+
+	// NewIteratorWithPrefix returns an iterator over the subset of database
+	// content with a particular key prefix, or over everything if it is nil.
+	NewIteratorWithPrefix(prefix []byte) Iterator
+
+	// DeleteRange removes all keys in the range [start, limit). A nil limit
+	// removes everything from start onwards.
+	DeleteRange(start, limit []byte) error
+
+	// Stat returns a particular internal stat of the database, or a summary
+	// of the most important ones if the property is empty.
+	Stat(property string) (string, error)
+
+	// Compact flattens the underlying data store for the key range [start, limit).
+	// Deleted and overwritten versions are discarded, and the data is rearranged
+	// to reduce the cost of operations needed to access them. A nil start or
+	// limit means the range is unbounded on that side.
+	Compact(start, limit []byte) error
 }
 
 // Batch is a write-only database that commits changes to its host database
 // when Write is called. Batch cannot be used concurrently.
 type Batch interface {
 	Putter
+	Deleter
 	ValueSize() int // amount of data in the batch
 	Write() error
 	// Reset resets the batch for reuse
@@ -0,0 +1,1383 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: api.proto
+
+package dspgrpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Header struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	ParentHash           []byte   `protobuf:"bytes,2,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
+	UncleHash            []byte   `protobuf:"bytes,3,opt,name=uncle_hash,json=uncleHash,proto3" json:"uncle_hash,omitempty"`
+	Coinbase             []byte   `protobuf:"bytes,4,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
+	Root                 []byte   `protobuf:"bytes,5,opt,name=root,proto3" json:"root,omitempty"`
+	TxHash               []byte   `protobuf:"bytes,6,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	ReceiptHash          []byte   `protobuf:"bytes,7,opt,name=receipt_hash,json=receiptHash,proto3" json:"receipt_hash,omitempty"`
+	Bloom                []byte   `protobuf:"bytes,8,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Difficulty           []byte   `protobuf:"bytes,9,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	Number               uint64   `protobuf:"varint,10,opt,name=number" json:"number,omitempty"`
+	GasLimit             uint64   `protobuf:"varint,11,opt,name=gas_limit,json=gasLimit" json:"gas_limit,omitempty"`
+	GasUsed              uint64   `protobuf:"varint,12,opt,name=gas_used,json=gasUsed" json:"gas_used,omitempty"`
+	Time                 uint64   `protobuf:"varint,13,opt,name=time" json:"time,omitempty"`
+	Extra                []byte   `protobuf:"bytes,14,opt,name=extra,proto3" json:"extra,omitempty"`
+	MixDigest            []byte   `protobuf:"bytes,15,opt,name=mix_digest,json=mixDigest,proto3" json:"mix_digest,omitempty"`
+	Nonce                uint64   `protobuf:"varint,16,opt,name=nonce" json:"nonce,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Header) Reset()         { *m = Header{} }
+func (m *Header) String() string { return proto.CompactTextString(m) }
+func (*Header) ProtoMessage()    {}
+func (*Header) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{0}
+}
+func (m *Header) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Header.Unmarshal(m, b)
+}
+func (m *Header) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Header.Marshal(b, m, deterministic)
+}
+func (dst *Header) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Header.Merge(dst, src)
+}
+func (m *Header) XXX_Size() int {
+	return xxx_messageInfo_Header.Size(m)
+}
+func (m *Header) XXX_DiscardUnknown() {
+	xxx_messageInfo_Header.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Header proto.InternalMessageInfo
+
+func (m *Header) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Header) GetParentHash() []byte {
+	if m != nil {
+		return m.ParentHash
+	}
+	return nil
+}
+
+func (m *Header) GetUncleHash() []byte {
+	if m != nil {
+		return m.UncleHash
+	}
+	return nil
+}
+
+func (m *Header) GetCoinbase() []byte {
+	if m != nil {
+		return m.Coinbase
+	}
+	return nil
+}
+
+func (m *Header) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *Header) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Header) GetReceiptHash() []byte {
+	if m != nil {
+		return m.ReceiptHash
+	}
+	return nil
+}
+
+func (m *Header) GetBloom() []byte {
+	if m != nil {
+		return m.Bloom
+	}
+	return nil
+}
+
+func (m *Header) GetDifficulty() []byte {
+	if m != nil {
+		return m.Difficulty
+	}
+	return nil
+}
+
+func (m *Header) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *Header) GetGasLimit() uint64 {
+	if m != nil {
+		return m.GasLimit
+	}
+	return 0
+}
+
+func (m *Header) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *Header) GetTime() uint64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Header) GetExtra() []byte {
+	if m != nil {
+		return m.Extra
+	}
+	return nil
+}
+
+func (m *Header) GetMixDigest() []byte {
+	if m != nil {
+		return m.MixDigest
+	}
+	return nil
+}
+
+func (m *Header) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+type Transaction struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Type                 uint32   `protobuf:"varint,2,opt,name=type" json:"type,omitempty"`
+	From                 []byte   `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To                   []byte   `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Nonce                uint64   `protobuf:"varint,5,opt,name=nonce" json:"nonce,omitempty"`
+	GasPrice             []byte   `protobuf:"bytes,6,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	Gas                  uint64   `protobuf:"varint,7,opt,name=gas" json:"gas,omitempty"`
+	Value                []byte   `protobuf:"bytes,8,opt,name=value,proto3" json:"value,omitempty"`
+	Input                []byte   `protobuf:"bytes,9,opt,name=input,proto3" json:"input,omitempty"`
+	Raw                  []byte   `protobuf:"bytes,10,opt,name=raw,proto3" json:"raw,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Transaction) Reset()         { *m = Transaction{} }
+func (m *Transaction) String() string { return proto.CompactTextString(m) }
+func (*Transaction) ProtoMessage()    {}
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{1}
+}
+func (m *Transaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transaction.Unmarshal(m, b)
+}
+func (m *Transaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transaction.Marshal(b, m, deterministic)
+}
+func (dst *Transaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transaction.Merge(dst, src)
+}
+func (m *Transaction) XXX_Size() int {
+	return xxx_messageInfo_Transaction.Size(m)
+}
+func (m *Transaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transaction proto.InternalMessageInfo
+
+func (m *Transaction) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Transaction) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Transaction) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *Transaction) GetTo() []byte {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *Transaction) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *Transaction) GetGasPrice() []byte {
+	if m != nil {
+		return m.GasPrice
+	}
+	return nil
+}
+
+func (m *Transaction) GetGas() uint64 {
+	if m != nil {
+		return m.Gas
+	}
+	return 0
+}
+
+func (m *Transaction) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Transaction) GetInput() []byte {
+	if m != nil {
+		return m.Input
+	}
+	return nil
+}
+
+func (m *Transaction) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+type Log struct {
+	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Topics               [][]byte `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	BlockNumber          uint64   `protobuf:"varint,4,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	TxHash               []byte   `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	TxIndex              uint32   `protobuf:"varint,6,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+	BlockHash            []byte   `protobuf:"bytes,7,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Index                uint32   `protobuf:"varint,8,opt,name=index" json:"index,omitempty"`
+	Removed              bool     `protobuf:"varint,9,opt,name=removed" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Log) Reset()         { *m = Log{} }
+func (m *Log) String() string { return proto.CompactTextString(m) }
+func (*Log) ProtoMessage()    {}
+func (*Log) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{2}
+}
+func (m *Log) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Log.Unmarshal(m, b)
+}
+func (m *Log) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Log.Marshal(b, m, deterministic)
+}
+func (dst *Log) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Log.Merge(dst, src)
+}
+func (m *Log) XXX_Size() int {
+	return xxx_messageInfo_Log.Size(m)
+}
+func (m *Log) XXX_DiscardUnknown() {
+	xxx_messageInfo_Log.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Log proto.InternalMessageInfo
+
+func (m *Log) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Log) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *Log) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Log) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *Log) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Log) GetTxIndex() uint32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *Log) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *Log) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Log) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type Receipt struct {
+	TxHash               []byte   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	Status               uint64   `protobuf:"varint,2,opt,name=status" json:"status,omitempty"`
+	CumulativeGasUsed    uint64   `protobuf:"varint,3,opt,name=cumulative_gas_used,json=cumulativeGasUsed" json:"cumulative_gas_used,omitempty"`
+	GasUsed              uint64   `protobuf:"varint,4,opt,name=gas_used,json=gasUsed" json:"gas_used,omitempty"`
+	ContractAddress      []byte   `protobuf:"bytes,5,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	Bloom                []byte   `protobuf:"bytes,6,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Logs                 []*Log   `protobuf:"bytes,7,rep,name=logs" json:"logs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Receipt) Reset()         { *m = Receipt{} }
+func (m *Receipt) String() string { return proto.CompactTextString(m) }
+func (*Receipt) ProtoMessage()    {}
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{3}
+}
+func (m *Receipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Receipt.Unmarshal(m, b)
+}
+func (m *Receipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Receipt.Marshal(b, m, deterministic)
+}
+func (dst *Receipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Receipt.Merge(dst, src)
+}
+func (m *Receipt) XXX_Size() int {
+	return xxx_messageInfo_Receipt.Size(m)
+}
+func (m *Receipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_Receipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Receipt proto.InternalMessageInfo
+
+func (m *Receipt) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Receipt) GetStatus() uint64 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *Receipt) GetCumulativeGasUsed() uint64 {
+	if m != nil {
+		return m.CumulativeGasUsed
+	}
+	return 0
+}
+
+func (m *Receipt) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *Receipt) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *Receipt) GetBloom() []byte {
+	if m != nil {
+		return m.Bloom
+	}
+	return nil
+}
+
+func (m *Receipt) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+type Block struct {
+	Header               *Header        `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	Transactions         []*Transaction `protobuf:"bytes,2,rep,name=transactions" json:"transactions,omitempty"`
+	Receipts             []*Receipt     `protobuf:"bytes,3,rep,name=receipts" json:"receipts,omitempty"`
+	Uncles               [][]byte       `protobuf:"bytes,4,rep,name=uncles,proto3" json:"uncles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{4}
+}
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (dst *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(dst, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *Block) GetTransactions() []*Transaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func (m *Block) GetReceipts() []*Receipt {
+	if m != nil {
+		return m.Receipts
+	}
+	return nil
+}
+
+func (m *Block) GetUncles() [][]byte {
+	if m != nil {
+		return m.Uncles
+	}
+	return nil
+}
+
+// BlockRequest selects a block by hash if set, the head block if latest is set,
+// by number otherwise.
+type BlockRequest struct {
+	Number               uint64   `protobuf:"varint,1,opt,name=number" json:"number,omitempty"`
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Latest               bool     `protobuf:"varint,3,opt,name=latest" json:"latest,omitempty"`
+	Receipts             bool     `protobuf:"varint,4,opt,name=receipts" json:"receipts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockRequest) Reset()         { *m = BlockRequest{} }
+func (m *BlockRequest) String() string { return proto.CompactTextString(m) }
+func (*BlockRequest) ProtoMessage()    {}
+func (*BlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{5}
+}
+func (m *BlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockRequest.Unmarshal(m, b)
+}
+func (m *BlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockRequest.Marshal(b, m, deterministic)
+}
+func (dst *BlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockRequest.Merge(dst, src)
+}
+func (m *BlockRequest) XXX_Size() int {
+	return xxx_messageInfo_BlockRequest.Size(m)
+}
+func (m *BlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockRequest proto.InternalMessageInfo
+
+func (m *BlockRequest) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *BlockRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *BlockRequest) GetLatest() bool {
+	if m != nil {
+		return m.Latest
+	}
+	return false
+}
+
+func (m *BlockRequest) GetReceipts() bool {
+	if m != nil {
+		return m.Receipts
+	}
+	return false
+}
+
+type ReceiptsRequest struct {
+	BlockHash            []byte   `protobuf:"bytes,1,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReceiptsRequest) Reset()         { *m = ReceiptsRequest{} }
+func (m *ReceiptsRequest) String() string { return proto.CompactTextString(m) }
+func (*ReceiptsRequest) ProtoMessage()    {}
+func (*ReceiptsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{6}
+}
+func (m *ReceiptsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReceiptsRequest.Unmarshal(m, b)
+}
+func (m *ReceiptsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReceiptsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ReceiptsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReceiptsRequest.Merge(dst, src)
+}
+func (m *ReceiptsRequest) XXX_Size() int {
+	return xxx_messageInfo_ReceiptsRequest.Size(m)
+}
+func (m *ReceiptsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReceiptsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReceiptsRequest proto.InternalMessageInfo
+
+func (m *ReceiptsRequest) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+type Receipts struct {
+	Receipts             []*Receipt `protobuf:"bytes,1,rep,name=receipts" json:"receipts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Receipts) Reset()         { *m = Receipts{} }
+func (m *Receipts) String() string { return proto.CompactTextString(m) }
+func (*Receipts) ProtoMessage()    {}
+func (*Receipts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{7}
+}
+func (m *Receipts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Receipts.Unmarshal(m, b)
+}
+func (m *Receipts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Receipts.Marshal(b, m, deterministic)
+}
+func (dst *Receipts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Receipts.Merge(dst, src)
+}
+func (m *Receipts) XXX_Size() int {
+	return xxx_messageInfo_Receipts.Size(m)
+}
+func (m *Receipts) XXX_DiscardUnknown() {
+	xxx_messageInfo_Receipts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Receipts proto.InternalMessageInfo
+
+func (m *Receipts) GetReceipts() []*Receipt {
+	if m != nil {
+		return m.Receipts
+	}
+	return nil
+}
+
+type Topics struct {
+	Topics               [][]byte `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Topics) Reset()         { *m = Topics{} }
+func (m *Topics) String() string { return proto.CompactTextString(m) }
+func (*Topics) ProtoMessage()    {}
+func (*Topics) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{8}
+}
+func (m *Topics) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Topics.Unmarshal(m, b)
+}
+func (m *Topics) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Topics.Marshal(b, m, deterministic)
+}
+func (dst *Topics) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Topics.Merge(dst, src)
+}
+func (m *Topics) XXX_Size() int {
+	return xxx_messageInfo_Topics.Size(m)
+}
+func (m *Topics) XXX_DiscardUnknown() {
+	xxx_messageInfo_Topics.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Topics proto.InternalMessageInfo
+
+func (m *Topics) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+type LogFilter struct {
+	Addresses            [][]byte  `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Topics               []*Topics `protobuf:"bytes,2,rep,name=topics" json:"topics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *LogFilter) Reset()         { *m = LogFilter{} }
+func (m *LogFilter) String() string { return proto.CompactTextString(m) }
+func (*LogFilter) ProtoMessage()    {}
+func (*LogFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{9}
+}
+func (m *LogFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogFilter.Unmarshal(m, b)
+}
+func (m *LogFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogFilter.Marshal(b, m, deterministic)
+}
+func (dst *LogFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogFilter.Merge(dst, src)
+}
+func (m *LogFilter) XXX_Size() int {
+	return xxx_messageInfo_LogFilter.Size(m)
+}
+func (m *LogFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogFilter proto.InternalMessageInfo
+
+func (m *LogFilter) GetAddresses() [][]byte {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *LogFilter) GetTopics() []*Topics {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+type LogsRequest struct {
+	FromBlock            uint64     `protobuf:"varint,1,opt,name=from_block,json=fromBlock" json:"from_block,omitempty"`
+	ToBlock              uint64     `protobuf:"varint,2,opt,name=to_block,json=toBlock" json:"to_block,omitempty"`
+	Filter               *LogFilter `protobuf:"bytes,3,opt,name=filter" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *LogsRequest) Reset()         { *m = LogsRequest{} }
+func (m *LogsRequest) String() string { return proto.CompactTextString(m) }
+func (*LogsRequest) ProtoMessage()    {}
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{10}
+}
+func (m *LogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogsRequest.Unmarshal(m, b)
+}
+func (m *LogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *LogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogsRequest.Merge(dst, src)
+}
+func (m *LogsRequest) XXX_Size() int {
+	return xxx_messageInfo_LogsRequest.Size(m)
+}
+func (m *LogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogsRequest proto.InternalMessageInfo
+
+func (m *LogsRequest) GetFromBlock() uint64 {
+	if m != nil {
+		return m.FromBlock
+	}
+	return 0
+}
+
+func (m *LogsRequest) GetToBlock() uint64 {
+	if m != nil {
+		return m.ToBlock
+	}
+	return 0
+}
+
+func (m *LogsRequest) GetFilter() *LogFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type Logs struct {
+	Logs                 []*Log   `protobuf:"bytes,1,rep,name=logs" json:"logs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Logs) Reset()         { *m = Logs{} }
+func (m *Logs) String() string { return proto.CompactTextString(m) }
+func (*Logs) ProtoMessage()    {}
+func (*Logs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{11}
+}
+func (m *Logs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Logs.Unmarshal(m, b)
+}
+func (m *Logs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Logs.Marshal(b, m, deterministic)
+}
+func (dst *Logs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Logs.Merge(dst, src)
+}
+func (m *Logs) XXX_Size() int {
+	return xxx_messageInfo_Logs.Size(m)
+}
+func (m *Logs) XXX_DiscardUnknown() {
+	xxx_messageInfo_Logs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Logs proto.InternalMessageInfo
+
+func (m *Logs) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+type SendTransactionRequest struct {
+	Raw                  []byte   `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SendTransactionRequest) Reset()         { *m = SendTransactionRequest{} }
+func (m *SendTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*SendTransactionRequest) ProtoMessage()    {}
+func (*SendTransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{12}
+}
+func (m *SendTransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SendTransactionRequest.Unmarshal(m, b)
+}
+func (m *SendTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SendTransactionRequest.Marshal(b, m, deterministic)
+}
+func (dst *SendTransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SendTransactionRequest.Merge(dst, src)
+}
+func (m *SendTransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_SendTransactionRequest.Size(m)
+}
+func (m *SendTransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SendTransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SendTransactionRequest proto.InternalMessageInfo
+
+func (m *SendTransactionRequest) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+type SendTransactionReply struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SendTransactionReply) Reset()         { *m = SendTransactionReply{} }
+func (m *SendTransactionReply) String() string { return proto.CompactTextString(m) }
+func (*SendTransactionReply) ProtoMessage()    {}
+func (*SendTransactionReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{13}
+}
+func (m *SendTransactionReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SendTransactionReply.Unmarshal(m, b)
+}
+func (m *SendTransactionReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SendTransactionReply.Marshal(b, m, deterministic)
+}
+func (dst *SendTransactionReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SendTransactionReply.Merge(dst, src)
+}
+func (m *SendTransactionReply) XXX_Size() int {
+	return xxx_messageInfo_SendTransactionReply.Size(m)
+}
+func (m *SendTransactionReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SendTransactionReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SendTransactionReply proto.InternalMessageInfo
+
+func (m *SendTransactionReply) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type SubscribeNewHeadsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeNewHeadsRequest) Reset()         { *m = SubscribeNewHeadsRequest{} }
+func (m *SubscribeNewHeadsRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeNewHeadsRequest) ProtoMessage()    {}
+func (*SubscribeNewHeadsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{14}
+}
+func (m *SubscribeNewHeadsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeNewHeadsRequest.Unmarshal(m, b)
+}
+func (m *SubscribeNewHeadsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeNewHeadsRequest.Marshal(b, m, deterministic)
+}
+func (dst *SubscribeNewHeadsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeNewHeadsRequest.Merge(dst, src)
+}
+func (m *SubscribeNewHeadsRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeNewHeadsRequest.Size(m)
+}
+func (m *SubscribeNewHeadsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeNewHeadsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeNewHeadsRequest proto.InternalMessageInfo
+
+type SubscribeLogsRequest struct {
+	Filter               *LogFilter `protobuf:"bytes,1,opt,name=filter" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *SubscribeLogsRequest) Reset()         { *m = SubscribeLogsRequest{} }
+func (m *SubscribeLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeLogsRequest) ProtoMessage()    {}
+func (*SubscribeLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_01b055ac1cce6825, []int{15}
+}
+func (m *SubscribeLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeLogsRequest.Unmarshal(m, b)
+}
+func (m *SubscribeLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeLogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *SubscribeLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeLogsRequest.Merge(dst, src)
+}
+func (m *SubscribeLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeLogsRequest.Size(m)
+}
+func (m *SubscribeLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeLogsRequest proto.InternalMessageInfo
+
+func (m *SubscribeLogsRequest) GetFilter() *LogFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Header)(nil), "dspgrpc.Header")
+	proto.RegisterType((*Transaction)(nil), "dspgrpc.Transaction")
+	proto.RegisterType((*Log)(nil), "dspgrpc.Log")
+	proto.RegisterType((*Receipt)(nil), "dspgrpc.Receipt")
+	proto.RegisterType((*Block)(nil), "dspgrpc.Block")
+	proto.RegisterType((*BlockRequest)(nil), "dspgrpc.BlockRequest")
+	proto.RegisterType((*ReceiptsRequest)(nil), "dspgrpc.ReceiptsRequest")
+	proto.RegisterType((*Receipts)(nil), "dspgrpc.Receipts")
+	proto.RegisterType((*Topics)(nil), "dspgrpc.Topics")
+	proto.RegisterType((*LogFilter)(nil), "dspgrpc.LogFilter")
+	proto.RegisterType((*LogsRequest)(nil), "dspgrpc.LogsRequest")
+	proto.RegisterType((*Logs)(nil), "dspgrpc.Logs")
+	proto.RegisterType((*SendTransactionRequest)(nil), "dspgrpc.SendTransactionRequest")
+	proto.RegisterType((*SendTransactionReply)(nil), "dspgrpc.SendTransactionReply")
+	proto.RegisterType((*SubscribeNewHeadsRequest)(nil), "dspgrpc.SubscribeNewHeadsRequest")
+	proto.RegisterType((*SubscribeLogsRequest)(nil), "dspgrpc.SubscribeLogsRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Chain service
+
+type ChainClient interface {
+	GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error)
+	GetReceipts(ctx context.Context, in *ReceiptsRequest, opts ...grpc.CallOption) (*Receipts, error)
+	GetLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (*Logs, error)
+	SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error)
+	SubscribeNewHeads(ctx context.Context, in *SubscribeNewHeadsRequest, opts ...grpc.CallOption) (Chain_SubscribeNewHeadsClient, error)
+	SubscribeLogs(ctx context.Context, in *SubscribeLogsRequest, opts ...grpc.CallOption) (Chain_SubscribeLogsClient, error)
+}
+
+type chainClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChainClient(cc *grpc.ClientConn) ChainClient {
+	return &chainClient{cc}
+}
+
+func (c *chainClient) GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := grpc.Invoke(ctx, "/dspgrpc.Chain/GetBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainClient) GetReceipts(ctx context.Context, in *ReceiptsRequest, opts ...grpc.CallOption) (*Receipts, error) {
+	out := new(Receipts)
+	err := grpc.Invoke(ctx, "/dspgrpc.Chain/GetReceipts", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainClient) GetLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (*Logs, error) {
+	out := new(Logs)
+	err := grpc.Invoke(ctx, "/dspgrpc.Chain/GetLogs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainClient) SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error) {
+	out := new(SendTransactionReply)
+	err := grpc.Invoke(ctx, "/dspgrpc.Chain/SendTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainClient) SubscribeNewHeads(ctx context.Context, in *SubscribeNewHeadsRequest, opts ...grpc.CallOption) (Chain_SubscribeNewHeadsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chain_serviceDesc.Streams[0], c.cc, "/dspgrpc.Chain/SubscribeNewHeads", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chainSubscribeNewHeadsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Chain_SubscribeNewHeadsClient interface {
+	Recv() (*Header, error)
+	grpc.ClientStream
+}
+
+type chainSubscribeNewHeadsClient struct {
+	grpc.ClientStream
+}
+
+func (x *chainSubscribeNewHeadsClient) Recv() (*Header, error) {
+	m := new(Header)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chainClient) SubscribeLogs(ctx context.Context, in *SubscribeLogsRequest, opts ...grpc.CallOption) (Chain_SubscribeLogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chain_serviceDesc.Streams[1], c.cc, "/dspgrpc.Chain/SubscribeLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chainSubscribeLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Chain_SubscribeLogsClient interface {
+	Recv() (*Log, error)
+	grpc.ClientStream
+}
+
+type chainSubscribeLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *chainSubscribeLogsClient) Recv() (*Log, error) {
+	m := new(Log)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Chain service
+
+type ChainServer interface {
+	GetBlock(context.Context, *BlockRequest) (*Block, error)
+	GetReceipts(context.Context, *ReceiptsRequest) (*Receipts, error)
+	GetLogs(context.Context, *LogsRequest) (*Logs, error)
+	SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionReply, error)
+	SubscribeNewHeads(*SubscribeNewHeadsRequest, Chain_SubscribeNewHeadsServer) error
+	SubscribeLogs(*SubscribeLogsRequest, Chain_SubscribeLogsServer) error
+}
+
+func RegisterChainServer(s *grpc.Server, srv ChainServer) {
+	s.RegisterService(&_Chain_serviceDesc, srv)
+}
+
+func _Chain_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dspgrpc.Chain/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServer).GetBlock(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chain_GetReceipts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReceiptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServer).GetReceipts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dspgrpc.Chain/GetReceipts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServer).GetReceipts(ctx, req.(*ReceiptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chain_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dspgrpc.Chain/GetLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServer).GetLogs(ctx, req.(*LogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chain_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dspgrpc.Chain/SendTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServer).SendTransaction(ctx, req.(*SendTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chain_SubscribeNewHeads_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeNewHeadsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChainServer).SubscribeNewHeads(m, &chainSubscribeNewHeadsServer{stream})
+}
+
+type Chain_SubscribeNewHeadsServer interface {
+	Send(*Header) error
+	grpc.ServerStream
+}
+
+type chainSubscribeNewHeadsServer struct {
+	grpc.ServerStream
+}
+
+func (x *chainSubscribeNewHeadsServer) Send(m *Header) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Chain_SubscribeLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChainServer).SubscribeLogs(m, &chainSubscribeLogsServer{stream})
+}
+
+type Chain_SubscribeLogsServer interface {
+	Send(*Log) error
+	grpc.ServerStream
+}
+
+type chainSubscribeLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *chainSubscribeLogsServer) Send(m *Log) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Chain_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dspgrpc.Chain",
+	HandlerType: (*ChainServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlock",
+			Handler:    _Chain_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetReceipts",
+			Handler:    _Chain_GetReceipts_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _Chain_GetLogs_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _Chain_SendTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeNewHeads",
+			Handler:       _Chain_SubscribeNewHeads_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeLogs",
+			Handler:       _Chain_SubscribeLogs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+func init() { proto.RegisterFile("api.proto", fileDescriptor_api_01b055ac1cce6825) }
+
+var fileDescriptor_api_01b055ac1cce6825 = []byte{
+	// 1016 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x56, 0xcb, 0x6e, 0xe4, 0x44,
+	0x17, 0x96, 0x63, 0xb7, 0xbb, 0xfb, 0xb8, 0x73, 0xab, 0x3f, 0x7f, 0xf0, 0x34, 0x84, 0xc9, 0x78,
+	0x43, 0x88, 0x50, 0x14, 0x9a, 0xcd, 0x88, 0x05, 0x12, 0x03, 0x22, 0x33, 0x52, 0x6b, 0x04, 0x9e,
+	0x61, 0xdd, 0xaa, 0xb6, 0x2b, 0x4e, 0x09, 0xb7, 0xcb, 0xb8, 0xca, 0x99, 0xce, 0x9e, 0xd7, 0x41,
+	0xe2, 0x81, 0x78, 0x00, 0xb6, 0xbc, 0x01, 0xaa, 0x53, 0xe5, 0x5b, 0x2e, 0x9a, 0x5d, 0x9d, 0x4b,
+	0xb9, 0xce, 0xf9, 0xbe, 0xef, 0x1c, 0x19, 0xa6, 0xb4, 0xe4, 0x17, 0x65, 0x25, 0x94, 0x20, 0xe3,
+	0x54, 0x96, 0x59, 0x55, 0x26, 0xd1, 0x9f, 0x2e, 0xf8, 0xaf, 0x19, 0x4d, 0x59, 0x45, 0x08, 0x78,
+	0x37, 0x54, 0xde, 0x84, 0xce, 0xa9, 0x73, 0x36, 0x8b, 0xf1, 0x4c, 0x9e, 0x43, 0x50, 0xd2, 0x8a,
+	0x15, 0x6a, 0x85, 0xa1, 0x1d, 0x0c, 0x81, 0x71, 0xbd, 0xd6, 0x09, 0x27, 0x00, 0x75, 0x91, 0xe4,
+	0xcc, 0xc4, 0x5d, 0x8c, 0x4f, 0xd1, 0x83, 0xe1, 0x39, 0x4c, 0x12, 0xc1, 0x8b, 0x35, 0x95, 0x2c,
+	0xf4, 0x30, 0xd8, 0xda, 0xfa, 0xbd, 0x4a, 0x08, 0x15, 0x8e, 0xcc, 0x7b, 0xfa, 0x4c, 0x3e, 0x81,
+	0xb1, 0xda, 0x9a, 0x6f, 0xf9, 0xe8, 0xf6, 0xd5, 0x16, 0x3f, 0xf4, 0x02, 0x66, 0x15, 0x4b, 0x18,
+	0x2f, 0x6d, 0x25, 0x63, 0x8c, 0x06, 0xd6, 0x87, 0x29, 0x47, 0x30, 0x5a, 0xe7, 0x42, 0x6c, 0xc2,
+	0x09, 0xc6, 0x8c, 0x41, 0x3e, 0x07, 0x48, 0xf9, 0xf5, 0x35, 0x4f, 0xea, 0x5c, 0xdd, 0x85, 0x53,
+	0xd3, 0x40, 0xe7, 0x21, 0xc7, 0xe0, 0x17, 0xf5, 0x66, 0xcd, 0xaa, 0x10, 0x4e, 0x9d, 0x33, 0x2f,
+	0xb6, 0x16, 0xf9, 0x14, 0xa6, 0x19, 0x95, 0xab, 0x9c, 0x6f, 0xb8, 0x0a, 0x03, 0x0c, 0x4d, 0x32,
+	0x2a, 0x97, 0xda, 0x26, 0xcf, 0x40, 0x9f, 0x57, 0xb5, 0x64, 0x69, 0x38, 0xc3, 0xd8, 0x38, 0xa3,
+	0xf2, 0x57, 0xc9, 0x52, 0xdd, 0x95, 0xe2, 0x1b, 0x16, 0xee, 0xa2, 0x1b, 0xcf, 0xba, 0x32, 0xb6,
+	0x55, 0x15, 0x0d, 0xf7, 0x4c, 0x65, 0x68, 0x68, 0xe8, 0x36, 0x7c, 0xbb, 0x4a, 0x79, 0xc6, 0xa4,
+	0x0a, 0xf7, 0x0d, 0x74, 0x1b, 0xbe, 0xfd, 0x11, 0x1d, 0xfa, 0x52, 0x21, 0x8a, 0x84, 0x85, 0x07,
+	0xf8, 0x25, 0x63, 0x44, 0x7f, 0x3b, 0x10, 0xbc, 0xaf, 0x68, 0x21, 0x69, 0xa2, 0xb8, 0x28, 0x1e,
+	0x25, 0x4d, 0x97, 0x70, 0x57, 0x32, 0x64, 0x6b, 0x37, 0xc6, 0xb3, 0xf6, 0x5d, 0x57, 0x62, 0x63,
+	0x19, 0xc2, 0x33, 0xd9, 0x83, 0x1d, 0x25, 0x2c, 0x2d, 0x3b, 0x4a, 0x74, 0x2f, 0x8e, 0x7a, 0x2f,
+	0x36, 0x40, 0x94, 0x15, 0x4f, 0x98, 0x25, 0x45, 0x37, 0xff, 0xb3, 0xb6, 0xc9, 0x01, 0xb8, 0x19,
+	0x95, 0xc8, 0x86, 0x17, 0xeb, 0xa3, 0xfe, 0xc8, 0x2d, 0xcd, 0x6b, 0xd6, 0xb0, 0x80, 0x86, 0xf6,
+	0xf2, 0xa2, 0xac, 0x95, 0x25, 0xc0, 0x18, 0xfa, 0x76, 0x45, 0x3f, 0x20, 0xf0, 0xb3, 0x58, 0x1f,
+	0xa3, 0x7f, 0x1d, 0x70, 0x97, 0x22, 0x23, 0x21, 0x8c, 0x69, 0x9a, 0x56, 0x4c, 0x4a, 0xdb, 0x59,
+	0x63, 0x6a, 0xbe, 0x94, 0x28, 0x79, 0x22, 0xc3, 0x9d, 0x53, 0x17, 0x05, 0x82, 0x96, 0x6e, 0x30,
+	0xa5, 0x8a, 0x36, 0x0d, 0xea, 0xb3, 0x16, 0xcd, 0x3a, 0x17, 0xc9, 0x6f, 0x2b, 0xcb, 0xb0, 0x87,
+	0x65, 0x06, 0xe8, 0x7b, 0x6b, 0x68, 0xee, 0x09, 0x6e, 0x34, 0x10, 0xdc, 0x33, 0x98, 0xa8, 0xed,
+	0x8a, 0x17, 0x29, 0xdb, 0x62, 0xd7, 0xbb, 0xf1, 0x58, 0x6d, 0xdf, 0x68, 0x53, 0x13, 0x67, 0x3e,
+	0xdb, 0x53, 0xe2, 0x14, 0x3d, 0x8d, 0x0e, 0xcd, 0xb5, 0x09, 0x5e, 0x33, 0x86, 0xee, 0xa8, 0x62,
+	0x1b, 0x71, 0xcb, 0x52, 0xc4, 0x60, 0x12, 0x37, 0x66, 0xf4, 0x8f, 0x03, 0xe3, 0xd8, 0xe8, 0xb8,
+	0x5f, 0x8e, 0x33, 0x28, 0xe7, 0x18, 0x7c, 0xa9, 0xa8, 0xaa, 0x25, 0xb2, 0xea, 0xc5, 0xd6, 0x22,
+	0x17, 0xf0, 0xbf, 0xa4, 0xde, 0xd4, 0x39, 0x55, 0xfc, 0x96, 0xad, 0x5a, 0x51, 0xba, 0x98, 0x74,
+	0xd8, 0x85, 0xae, 0xac, 0x3c, 0xfb, 0xca, 0xf5, 0x86, 0xca, 0xfd, 0x12, 0x0e, 0x12, 0x51, 0xa8,
+	0x8a, 0x26, 0x6a, 0xd5, 0x80, 0x6f, 0x30, 0xd9, 0x6f, 0xfc, 0xdf, 0x5b, 0x12, 0xda, 0x51, 0xf3,
+	0xfb, 0xa3, 0x76, 0x0a, 0x5e, 0x2e, 0x32, 0xad, 0x06, 0xf7, 0x2c, 0x58, 0xcc, 0x2e, 0xec, 0x8e,
+	0xb9, 0x58, 0x8a, 0x2c, 0xc6, 0x48, 0xf4, 0x97, 0x03, 0xa3, 0x57, 0x1a, 0x28, 0xf2, 0x05, 0xf8,
+	0x37, 0xb8, 0x76, 0xb0, 0xcf, 0x60, 0xb1, 0xdf, 0x66, 0x9b, 0x6d, 0x14, 0xdb, 0x30, 0x79, 0x09,
+	0x33, 0xd5, 0xe9, 0xdd, 0xb0, 0x1e, 0x2c, 0x8e, 0xda, 0xf4, 0xde, 0x30, 0xc4, 0x83, 0x4c, 0xf2,
+	0x15, 0x4c, 0xec, 0x7a, 0x90, 0xa1, 0x8b, 0xb7, 0x0e, 0xda, 0x5b, 0x16, 0xef, 0xb8, 0xcd, 0xd0,
+	0x00, 0xe3, 0xda, 0x92, 0xa1, 0x67, 0x74, 0x65, 0xac, 0xa8, 0x80, 0x19, 0x56, 0x1c, 0xb3, 0xdf,
+	0x6b, 0x3d, 0x96, 0xdd, 0xbe, 0x70, 0x06, 0xfb, 0xa2, 0x19, 0xc4, 0x9d, 0xde, 0x20, 0x1e, 0x83,
+	0x9f, 0x53, 0xa5, 0xa7, 0xdb, 0x45, 0xca, 0xad, 0xa5, 0xb7, 0x62, 0x5b, 0x99, 0x87, 0x91, 0xd6,
+	0x8e, 0x2e, 0x61, 0xdf, 0x16, 0x27, 0x9b, 0x27, 0x87, 0x7a, 0x73, 0xee, 0xe9, 0x2d, 0x7a, 0x09,
+	0x93, 0xe6, 0xc6, 0xa0, 0x67, 0xe7, 0x63, 0x3d, 0x47, 0xa7, 0xe0, 0xbf, 0x37, 0xd3, 0xd3, 0x4d,
+	0x95, 0xd3, 0x9f, 0xaa, 0x28, 0x86, 0xe9, 0x52, 0x64, 0x3f, 0xf1, 0x5c, 0xb1, 0x8a, 0x7c, 0x06,
+	0x53, 0xab, 0x0b, 0xd6, 0xe4, 0x75, 0x0e, 0xcd, 0x68, 0x6f, 0x30, 0xfb, 0x8c, 0x9a, 0x37, 0xda,
+	0x6f, 0x4a, 0x08, 0x96, 0x22, 0xeb, 0x77, 0xa7, 0xb7, 0xd1, 0x0a, 0x1b, 0xb2, 0xa0, 0x4e, 0xb5,
+	0xc7, 0x08, 0x45, 0xcf, 0xa1, 0xb0, 0x41, 0x23, 0xfd, 0xb1, 0x12, 0x26, 0x74, 0x0e, 0xfe, 0x35,
+	0x56, 0x86, 0xf0, 0x06, 0x0b, 0xd2, 0x57, 0x9c, 0xa9, 0x39, 0xb6, 0x19, 0xd1, 0x19, 0x78, 0xfa,
+	0xd1, 0x56, 0xa3, 0xce, 0x93, 0x1a, 0x3d, 0x87, 0xe3, 0x77, 0xac, 0x48, 0xfb, 0xba, 0xb2, 0x95,
+	0xda, 0x75, 0xe5, 0x74, 0xeb, 0xea, 0x1c, 0x8e, 0x1e, 0xe4, 0x96, 0xf9, 0xdd, 0x63, 0x5b, 0x39,
+	0x9a, 0x43, 0xf8, 0xae, 0x5e, 0xcb, 0xa4, 0xe2, 0x6b, 0xf6, 0x96, 0x7d, 0xd0, 0x32, 0x6f, 0x30,
+	0x88, 0x5e, 0xc1, 0x51, 0x1b, 0xeb, 0x63, 0xd3, 0x75, 0xe8, 0x7c, 0xac, 0xc3, 0xc5, 0x1f, 0x2e,
+	0x8c, 0x7e, 0xb8, 0xa1, 0xbc, 0x20, 0x5f, 0xc3, 0xe4, 0x8a, 0x29, 0x83, 0xd1, 0xff, 0xdb, 0x1b,
+	0x7d, 0x15, 0xcf, 0xf7, 0x86, 0x6e, 0xf2, 0x2d, 0x04, 0x57, 0x4c, 0xb5, 0x32, 0x0a, 0xef, 0x8b,
+	0xa6, 0xa9, 0x68, 0x7e, 0xf8, 0x20, 0x42, 0x2e, 0x60, 0x7c, 0xc5, 0x14, 0xa2, 0x7b, 0xd4, 0xaf,
+	0xaf, 0xbd, 0xb3, 0x3b, 0xf0, 0x92, 0x5f, 0x60, 0xff, 0x1e, 0x68, 0xe4, 0x79, 0x9b, 0xf1, 0x38,
+	0xf4, 0xf3, 0x93, 0xa7, 0x13, 0x34, 0xde, 0x6f, 0xe0, 0xf0, 0x01, 0xb6, 0xe4, 0x45, 0x77, 0xe7,
+	0x09, 0xdc, 0xe7, 0xf7, 0xb7, 0xce, 0xa5, 0x43, 0xbe, 0x83, 0xdd, 0x01, 0x15, 0xe4, 0xe4, 0xe1,
+	0x67, 0xfa, 0xcd, 0x0d, 0x24, 0x74, 0xe9, 0xac, 0x7d, 0xfc, 0xc1, 0xfa, 0xe6, 0xbf, 0x01, 0x00,
+	0x92, 0x18, 0xdf, 0x3f, 0x6d, 0x09, 0x00, 0x00,
+}
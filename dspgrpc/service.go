@@ -0,0 +1,404 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:generate protoc --go_out=plugins=grpc:. api.proto
+
+// Package dspgrpc implements a gRPC service for the chain data of a node, serving
+// blocks, receipts and logs as protocol buffers instead of JSON.
+package dspgrpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/relianz2019/relianz/common"
+	"github.com/relianz2019/relianz/core"
+	"github.com/relianz2019/relianz/core/types"
+	"github.com/relianz2019/relianz/dsp/filters"
+	"github.com/relianz2019/relianz/event"
+	"github.com/relianz2019/relianz/rlp"
+	"github.com/relianz2019/relianz/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxLogsRange is the maximum number of blocks a GetLogs call may span.
+	maxLogsRange = 10000
+
+	// eventChanSize is the size of the channels buffering the chain events of
+	// the subscriptions.
+	eventChanSize = 128
+)
+
+// Backend is the chain access needed by the Chain service, a subset of the one
+// behind the JSON-RPC APIs.
+type Backend interface {
+	filters.Backend
+
+	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
+	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
+	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// ChainService implements ChainServer on top of a node's chain backend.
+type ChainService struct {
+	backend Backend
+}
+
+// NewChainService creates a Chain service serving the chain of backend.
+func NewChainService(backend Backend) *ChainService {
+	return &ChainService{backend: backend}
+}
+
+// GetBlock retrieves a block by hash or number, along with its receipts if
+// requested.
+func (s *ChainService) GetBlock(ctx context.Context, req *BlockRequest) (*Block, error) {
+	var (
+		block *types.Block
+		err   error
+	)
+	switch {
+	case len(req.Hash) > 0:
+		if len(req.Hash) != common.HashLength {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block hash length %d", len(req.Hash))
+		}
+		block, err = s.backend.GetBlock(ctx, common.BytesToHash(req.Hash))
+	case req.Latest:
+		block, err = s.backend.BlockByNumber(ctx, rpc.LatestBlockNumber)
+	default:
+		block, err = s.backend.BlockByNumber(ctx, rpc.BlockNumber(req.Number))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "block not found")
+	}
+	var receipts types.Receipts
+	if req.Receipts {
+		if receipts, err = s.backend.GetReceipts(ctx, block.Hash()); err != nil {
+			return nil, err
+		}
+	}
+	return newBlock(block, receipts), nil
+}
+
+// GetReceipts retrieves the receipts of a block.
+func (s *ChainService) GetReceipts(ctx context.Context, req *ReceiptsRequest) (*Receipts, error) {
+	if len(req.BlockHash) != common.HashLength {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid block hash length %d", len(req.BlockHash))
+	}
+	receipts, err := s.backend.GetReceipts(ctx, common.BytesToHash(req.BlockHash))
+	if err != nil {
+		return nil, err
+	}
+	if receipts == nil {
+		return nil, status.Errorf(codes.NotFound, "receipts not found")
+	}
+	reply := &Receipts{Receipts: make([]*Receipt, len(receipts))}
+	for i, receipt := range receipts {
+		reply.Receipts[i] = newReceipt(receipt)
+	}
+	return reply, nil
+}
+
+// GetLogs retrieves the logs matching a filter within a range of blocks. A zero
+// ToBlock means the head of the chain.
+func (s *ChainService) GetLogs(ctx context.Context, req *LogsRequest) (*Logs, error) {
+	addresses, topics, err := parseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	end := int64(rpc.LatestBlockNumber)
+	if req.ToBlock != 0 {
+		if req.ToBlock < req.FromBlock {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block range %d-%d", req.FromBlock, req.ToBlock)
+		}
+		end = int64(req.ToBlock)
+	}
+	head, err := s.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	last := head.Number.Uint64()
+	if req.ToBlock != 0 && req.ToBlock < last {
+		last = req.ToBlock
+	}
+	if last >= req.FromBlock && last-req.FromBlock >= maxLogsRange {
+		return nil, status.Errorf(codes.ResourceExhausted, "block range too large (%d>%d)", last-req.FromBlock+1, maxLogsRange)
+	}
+	logs, err := filters.New(s.backend, int64(req.FromBlock), end, addresses, topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &Logs{Logs: make([]*Log, len(logs))}
+	for i, log := range logs {
+		reply.Logs[i] = newLog(log)
+	}
+	return reply, nil
+}
+
+// SendTransaction submits a signed transaction to the transaction pool.
+func (s *ChainService) SendTransaction(ctx context.Context, req *SendTransactionRequest) (*SendTransactionReply, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(req.Raw, tx); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction: %v", err)
+	}
+	if err := s.backend.SendTx(ctx, tx); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	return &SendTransactionReply{Hash: tx.Hash().Bytes()}, nil
+}
+
+// SubscribeNewHeads streams the headers of the blocks becoming the chain head.
+func (s *ChainService) SubscribeNewHeads(req *SubscribeNewHeadsRequest, stream Chain_SubscribeNewHeadsServer) error {
+	heads := make(chan core.ChainHeadEvent, eventChanSize)
+	sub := s.backend.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-heads:
+			if err := stream.Send(newHeader(ev.Block.Header())); err != nil {
+				return err
+			}
+		case <-sub.Err():
+			return status.Errorf(codes.Unavailable, "chain subscription closed")
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// SubscribeLogs streams the logs matching a filter as their blocks get imported,
+// and again with Removed set if a reorg drops them.
+func (s *ChainService) SubscribeLogs(req *SubscribeLogsRequest, stream Chain_SubscribeLogsServer) error {
+	addresses, topics, err := parseFilter(req.Filter)
+	if err != nil {
+		return err
+	}
+	var (
+		logs       = make(chan []*types.Log, eventChanSize)
+		removed    = make(chan core.RemovedLogsEvent, eventChanSize)
+		logsSub    = s.backend.SubscribeLogsEvent(logs)
+		removedSub = s.backend.SubscribeRemovedLogsEvent(removed)
+	)
+	defer logsSub.Unsubscribe()
+	defer removedSub.Unsubscribe()
+
+	send := func(logs []*types.Log) error {
+		for _, log := range logs {
+			if matchLog(log, addresses, topics) {
+				if err := stream.Send(newLog(log)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for {
+		select {
+		case ev := <-logs:
+			if err := send(ev); err != nil {
+				return err
+			}
+		case ev := <-removed:
+			if err := send(ev.Logs); err != nil {
+				return err
+			}
+		case <-logsSub.Err():
+			return status.Errorf(codes.Unavailable, "log subscription closed")
+		case <-removedSub.Err():
+			return status.Errorf(codes.Unavailable, "log subscription closed")
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// parseFilter converts a log filter into the addresses and topics it matches.
+func parseFilter(filter *LogFilter) ([]common.Address, [][]common.Hash, error) {
+	if filter == nil {
+		return nil, nil, nil
+	}
+	addresses := make([]common.Address, len(filter.Addresses))
+	for i, addr := range filter.Addresses {
+		if len(addr) != common.AddressLength {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid address length %d", len(addr))
+		}
+		addresses[i] = common.BytesToAddress(addr)
+	}
+	topics := make([][]common.Hash, len(filter.Topics))
+	for i, position := range filter.Topics {
+		for _, topic := range position.GetTopics() {
+			if len(topic) != common.HashLength {
+				return nil, nil, status.Errorf(codes.InvalidArgument, "invalid topic length %d", len(topic))
+			}
+			topics[i] = append(topics[i], common.BytesToHash(topic))
+		}
+	}
+	return addresses, topics, nil
+}
+
+// matchLog reports whether a log is emitted by one of the addresses and has the
+// topics at their positions, empty lists matching anything.
+func matchLog(log *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var found bool
+		for _, addr := range addresses {
+			if log.Address == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, position := range topics {
+		match := len(position) == 0
+		for _, topic := range position {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// bigBytes encodes a big integer as big endian bytes, nil if unset.
+func bigBytes(n *big.Int) []byte {
+	if n == nil {
+		return nil
+	}
+	return n.Bytes()
+}
+
+// newHeader converts a block header into its protobuf representation.
+func newHeader(head *types.Header) *Header {
+	return &Header{
+		Hash:        head.Hash().Bytes(),
+		ParentHash:  head.ParentHash.Bytes(),
+		UncleHash:   head.UncleHash.Bytes(),
+		Coinbase:    head.Coinbase.Bytes(),
+		Root:        head.Root.Bytes(),
+		TxHash:      head.TxHash.Bytes(),
+		ReceiptHash: head.ReceiptHash.Bytes(),
+		Bloom:       head.Bloom.Bytes(),
+		Difficulty:  bigBytes(head.Difficulty),
+		Number:      head.Number.Uint64(),
+		GasLimit:    head.GasLimit,
+		GasUsed:     head.GasUsed,
+		Time:        head.Time.Uint64(),
+		Extra:       head.Extra,
+		MixDigest:   head.MixDigest.Bytes(),
+		Nonce:       head.Nonce.Uint64(),
+	}
+}
+
+// newTransaction converts a transaction into its protobuf representation.
+func newTransaction(tx *types.Transaction) *Transaction {
+	var signer types.Signer = types.FrontierSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, _ := types.Sender(signer, tx)
+	raw, _ := rlp.EncodeToBytes(tx)
+
+	result := &Transaction{
+		Hash:     tx.Hash().Bytes(),
+		Type:     uint32(tx.Type()),
+		From:     from.Bytes(),
+		Nonce:    tx.Nonce(),
+		GasPrice: bigBytes(tx.GasPrice()),
+		Gas:      tx.Gas(),
+		Value:    bigBytes(tx.Value()),
+		Input:    tx.Data(),
+		Raw:      raw,
+	}
+	if to := tx.To(); to != nil {
+		result.To = to.Bytes()
+	}
+	return result
+}
+
+// newLog converts a contract log into its protobuf representation.
+func newLog(log *types.Log) *Log {
+	topics := make([][]byte, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.Bytes()
+	}
+	return &Log{
+		Address:     log.Address.Bytes(),
+		Topics:      topics,
+		Data:        log.Data,
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash.Bytes(),
+		TxIndex:     uint32(log.TxIndex),
+		BlockHash:   log.BlockHash.Bytes(),
+		Index:       uint32(log.Index),
+		Removed:     log.Removed,
+	}
+}
+
+// newReceipt converts a transaction receipt into its protobuf representation.
+func newReceipt(receipt *types.Receipt) *Receipt {
+	result := &Receipt{
+		TxHash:            receipt.TxHash.Bytes(),
+		Status:            receipt.Status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		GasUsed:           receipt.GasUsed,
+		Bloom:             receipt.Bloom.Bytes(),
+		Logs:              make([]*Log, len(receipt.Logs)),
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		result.ContractAddress = receipt.ContractAddress.Bytes()
+	}
+	for i, log := range receipt.Logs {
+		result.Logs[i] = newLog(log)
+	}
+	return result
+}
+
+// newBlock converts a block, and optionally its receipts, into its protobuf
+// representation. Uncles are included in their RLP encoding.
+func newBlock(block *types.Block, receipts types.Receipts) *Block {
+	result := &Block{
+		Header:       newHeader(block.Header()),
+		Transactions: make([]*Transaction, len(block.Transactions())),
+		Receipts:     make([]*Receipt, len(receipts)),
+	}
+	for i, tx := range block.Transactions() {
+		result.Transactions[i] = newTransaction(tx)
+	}
+	for i, receipt := range receipts {
+		result.Receipts[i] = newReceipt(receipt)
+	}
+	for _, uncle := range block.Uncles() {
+		blob, _ := rlp.EncodeToBytes(uncle)
+		result.Uncles = append(result.Uncles, blob)
+	}
+	return result
+}
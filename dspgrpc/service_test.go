@@ -0,0 +1,330 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package dspgrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/relianz2019/relianz/common"
+	"github.com/relianz2019/relianz/core"
+	"github.com/relianz2019/relianz/core/types"
+	"github.com/relianz2019/relianz/crypto"
+	"github.com/relianz2019/relianz/dsp/filters"
+	"github.com/relianz2019/relianz/event"
+	"github.com/relianz2019/relianz/rlp"
+	"github.com/relianz2019/relianz/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testBackend is a chain of a few blocks, only implementing the methods of the
+// Backend used by the tests.
+type testBackend struct {
+	filters.Backend
+
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+	sent     []*types.Transaction
+	heads    event.Feed
+}
+
+// newTestBlock creates a block at the given number with txs transactions, each
+// with a receipt carrying a log.
+func newTestBlock(number int64, txs int) (*types.Block, types.Receipts) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(1))
+
+	var (
+		transactions []*types.Transaction
+		receipts     []*types.Receipt
+	)
+	for i := 0; i < txs; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1000), 21000, big.NewInt(1), make([]byte, 64)), signer, key)
+		transactions = append(transactions, tx)
+
+		receipt := types.NewReceipt(nil, false, uint64(i+1)*21000)
+		receipt.TxHash = tx.Hash()
+		receipt.GasUsed = 21000
+		receipt.Logs = []*types.Log{{
+			Address:     common.Address{0x01},
+			Topics:      []common.Hash{{0x02}, {0x03}},
+			Data:        make([]byte, 32),
+			BlockNumber: uint64(number),
+			TxHash:      tx.Hash(),
+			TxIndex:     uint(i),
+		}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipts = append(receipts, receipt)
+	}
+	header := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(131072), GasLimit: 8000000, Time: big.NewInt(1550000000)}
+	return types.NewBlock(header, transactions, nil, receipts), receipts
+}
+
+func newTestBackend(blocks int) *testBackend {
+	b := &testBackend{receipts: make(map[common.Hash]types.Receipts)}
+	for i := 0; i < blocks; i++ {
+		block, receipts := newTestBlock(int64(i), i)
+		b.blocks = append(b.blocks, block)
+		b.receipts[block.Hash()] = receipts
+	}
+	return b
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.blocks[len(b.blocks)-1], nil
+	}
+	if int(number) < 0 || int(number) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, block := range b.blocks {
+		if block.Hash() == hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) SendTx(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.heads.Subscribe(ch)
+}
+
+// newTestClient serves the Chain service of backend, returning a client to it.
+func newTestClient(t *testing.T, backend Backend) (ChainClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	RegisterChainServer(srv, NewChainService(backend))
+	go srv.Serve(listener)
+
+	cc, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	return NewChainClient(cc), func() { cc.Close(); srv.Stop() }
+}
+
+func TestGetBlock(t *testing.T) {
+	backend := newTestBackend(4)
+	client, teardown := newTestClient(t, backend)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// Retrieve the blocks by number and hash, and ensure they match the chain
+	block, err := client.GetBlock(ctx, &BlockRequest{Number: 2})
+	if err != nil {
+		t.Fatalf("failed to retrieve block by number: %v", err)
+	}
+	if want := backend.blocks[2].Hash(); !bytes.Equal(block.Header.Hash, want[:]) {
+		t.Fatalf("block hash mismatch: have %x, want %x", block.Header.Hash, want)
+	}
+	if len(block.Transactions) != 2 || len(block.Receipts) != 0 {
+		t.Fatalf("block contents mismatch: have %d txs, %d receipts, want 2 txs, 0 receipts", len(block.Transactions), len(block.Receipts))
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(block.Transactions[1].Raw, tx); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if tx.Hash() != backend.blocks[2].Transactions()[1].Hash() {
+		t.Fatalf("transaction mismatch: have %x, want %x", tx.Hash(), backend.blocks[2].Transactions()[1].Hash())
+	}
+	hash := backend.blocks[3].Hash()
+	if block, err = client.GetBlock(ctx, &BlockRequest{Hash: hash[:], Receipts: true}); err != nil {
+		t.Fatalf("failed to retrieve block by hash: %v", err)
+	}
+	if block.Header.Number != 3 || len(block.Receipts) != 3 || len(block.Receipts[2].Logs) != 1 {
+		t.Fatalf("block mismatch: have number %d with %d receipts", block.Header.Number, len(block.Receipts))
+	}
+	if block, err = client.GetBlock(ctx, &BlockRequest{Latest: true}); err != nil || block.Header.Number != 3 {
+		t.Fatalf("latest block mismatch: have %v, %v", block, err)
+	}
+	// Ensure missing and malformed requests are reported as such
+	if _, err := client.GetBlock(ctx, &BlockRequest{Number: 10}); status.Code(err) != codes.NotFound {
+		t.Fatalf("missing block error mismatch: have %v, want code %v", err, codes.NotFound)
+	}
+	if _, err := client.GetBlock(ctx, &BlockRequest{Hash: []byte{0x01}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("malformed hash error mismatch: have %v, want code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestSendTransaction(t *testing.T) {
+	backend := newTestBackend(2)
+	client, teardown := newTestClient(t, backend)
+	defer teardown()
+
+	tx := backend.blocks[1].Transactions()[0]
+	raw, _ := rlp.EncodeToBytes(tx)
+
+	reply, err := client.SendTransaction(context.Background(), &SendTransactionRequest{Raw: raw})
+	if err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if hash := tx.Hash(); !bytes.Equal(reply.Hash, hash[:]) {
+		t.Fatalf("transaction hash mismatch: have %x, want %x", reply.Hash, hash)
+	}
+	if len(backend.sent) != 1 || backend.sent[0].Hash() != tx.Hash() {
+		t.Fatalf("transaction not submitted to the backend")
+	}
+	if _, err := client.SendTransaction(context.Background(), &SendTransactionRequest{Raw: []byte{0x01}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("malformed transaction error mismatch: have %v, want code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	backend := newTestBackend(3)
+	client, teardown := newTestClient(t, backend)
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.SubscribeNewHeads(ctx, new(SubscribeNewHeadsRequest))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	// Wait for the subscription to be installed, then announce the chain
+	for start := time.Now(); backend.heads.Send(core.ChainHeadEvent{Block: backend.blocks[0]}) == 0; {
+		if time.Since(start) > time.Second {
+			t.Fatalf("subscription not installed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, block := range backend.blocks[1:] {
+		backend.heads.Send(core.ChainHeadEvent{Block: block})
+	}
+	for i, block := range backend.blocks {
+		head, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("header %d: failed to receive: %v", i, err)
+		}
+		if hash := block.Hash(); !bytes.Equal(head.Hash, hash[:]) {
+			t.Fatalf("header %d: hash mismatch: have %x, want %x", i, head.Hash, hash)
+		}
+	}
+}
+
+func TestMatchLog(t *testing.T) {
+	log := &types.Log{Address: common.Address{0x01}, Topics: []common.Hash{{0x02}, {0x03}}}
+
+	tests := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		match     bool
+	}{
+		{nil, nil, true},
+		{[]common.Address{{0x02}, {0x01}}, nil, true},
+		{[]common.Address{{0x02}}, nil, false},
+		{nil, [][]common.Hash{{}, {{0x03}}}, true},
+		{nil, [][]common.Hash{{{0x01}, {0x02}}}, true},
+		{nil, [][]common.Hash{{{0x03}}}, false},
+		{nil, [][]common.Hash{{}, {}, {}}, false},
+	}
+	for i, tt := range tests {
+		if match := matchLog(log, tt.addresses, tt.topics); match != tt.match {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, match, tt.match)
+		}
+	}
+}
+
+// jsonBlock mirrors the JSON-RPC representation of a block with its receipts,
+// as pulled by indexers over eth_getBlockByNumber and eth_getTransactionReceipt.
+type jsonBlock struct {
+	Header       *types.Header
+	Transactions []*types.Transaction
+	Receipts     []*types.Receipt
+}
+
+func BenchmarkEncodeBlockProtobuf(b *testing.B) {
+	block, receipts := newTestBlock(1, 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blob, err := proto.Marshal(newBlock(block, receipts))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(blob)))
+	}
+}
+
+func BenchmarkEncodeBlockJSON(b *testing.B) {
+	block, receipts := newTestBlock(1, 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blob, err := json.Marshal(&jsonBlock{block.Header(), block.Transactions(), receipts})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(blob)))
+	}
+}
+
+func BenchmarkDecodeBlockProtobuf(b *testing.B) {
+	block, receipts := newTestBlock(1, 200)
+	blob, _ := proto.Marshal(newBlock(block, receipts))
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(blob)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := proto.Unmarshal(blob, new(Block)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBlockJSON(b *testing.B) {
+	block, receipts := newTestBlock(1, 200)
+	blob, _ := json.Marshal(&jsonBlock{block.Header(), block.Transactions(), receipts})
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(blob)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := json.Unmarshal(blob, new(jsonBlock)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,1029 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to the chain data, served on the
+// HTTP endpoint of the node.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/relianz2019/relianz/common"
+	"github.com/relianz2019/relianz/common/hexutil"
+	"github.com/relianz2019/relianz/core/rawdb"
+	"github.com/relianz2019/relianz/core/state"
+	"github.com/relianz2019/relianz/core/types"
+	"github.com/relianz2019/relianz/core/vm"
+	ethapi "github.com/relianz2019/relianz/internal/rlzapi"
+	"github.com/relianz2019/relianz/rpc"
+)
+
+// maxQueryRange is the maximum number of blocks a blocks or logs query may span.
+const maxQueryRange = 10000
+
+var (
+	errBlockNotFound = errors.New("block not found")
+	errStateNotFound = errors.New("state not found")
+	errTDNotFound    = errors.New("total difficulty not found")
+	errNumberAndHash = errors.New("only one of number or hash may be given")
+	errInvalidRange  = errors.New("invalid block range")
+	errRangeTooLarge = errors.New("block range too large")
+)
+
+// blockNumber converts a block number of a query to the one of the backend. The
+// numbers beyond the range of the latter select no block rather than a tag.
+func blockNumber(number hexutil.Uint64) rpc.BlockNumber {
+	if uint64(number) > math.MaxInt64 {
+		return rpc.BlockNumber(math.MaxInt64)
+	}
+	return rpc.BlockNumber(number)
+}
+
+// BlockNumberArgs are the arguments selecting the block accounts are resolved at.
+type BlockNumberArgs struct {
+	Block *hexutil.Uint64
+}
+
+// number returns the block number selected, fallback if none was.
+func (a BlockNumberArgs) number(fallback rpc.BlockNumber) rpc.BlockNumber {
+	if a.Block != nil {
+		return blockNumber(*a.Block)
+	}
+	return fallback
+}
+
+// Account is an account at a particular block.
+type Account struct {
+	backend     ethapi.Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the state the account is resolved at.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	statedb, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if statedb == nil && err == nil {
+		err = errStateNotFound
+	}
+	return statedb, err
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*statedb.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(statedb.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(statedb.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return statedb.GetState(a.address, args.Slot), nil
+}
+
+// Log is a log entry emitted by a contract.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) (*Transaction, error) {
+	return l.transaction, nil
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.number(rpc.BlockNumber(l.log.BlockNumber)),
+	}, nil
+}
+
+func (l *Log) Index(ctx context.Context) (int32, error) {
+	return int32(l.log.Index), nil
+}
+
+func (l *Log) Topics(ctx context.Context) ([]common.Hash, error) {
+	return l.log.Topics, nil
+}
+
+func (l *Log) Data(ctx context.Context) (hexutil.Bytes, error) {
+	return hexutil.Bytes(l.log.Data), nil
+}
+
+// Transaction is a transaction, either included in a block or pending in the
+// transaction pool. It's looked up by hash when first needed, unless known.
+type Transaction struct {
+	backend ethapi.Backend
+	hash    common.Hash
+	tx      *types.Transaction
+	block   *Block // Block including the transaction, nil if pending
+	index   uint64 // Index of the transaction in its block
+
+	lock sync.Mutex
+}
+
+// resolve returns the transaction, looking it up if not known yet. Nil is
+// returned if the transaction is unknown.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.tx != nil {
+		return t.tx, nil
+	}
+	// Take the transaction from its block if known, look it up otherwise
+	if t.block != nil {
+		block, err := t.block.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if txs := block.Transactions(); t.index < uint64(len(txs)) {
+			t.tx = txs[t.index]
+		}
+		return t.tx, nil
+	}
+	if tx, blockHash, _, index := rawdb.ReadTransaction(t.backend.ChainDb(), t.hash); tx != nil {
+		t.tx = tx
+		t.block = &Block{backend: t.backend, hash: blockHash}
+		t.index = index
+	} else {
+		t.tx = t.backend.GetPoolTransaction(t.hash)
+	}
+	return t.tx, nil
+}
+
+// blockNumber returns the number of the block including the transaction, the
+// pending one if it's not included yet. It must be called after resolve.
+func (t *Transaction) blockNumber(ctx context.Context) (rpc.BlockNumber, error) {
+	if t.block == nil {
+		return rpc.PendingBlockNumber, nil
+	}
+	block, err := t.block.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rpc.BlockNumber(block.NumberU64()), nil
+}
+
+// account returns the account of address at the block selected by args, the one
+// including the transaction by default.
+func (t *Transaction) account(ctx context.Context, address common.Address, args BlockNumberArgs) (*Account, error) {
+	number, err := t.blockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     address,
+		blockNumber: args.number(number),
+	}, nil
+}
+
+// getReceipt returns the receipt of the transaction, nil if it's pending.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil || t.block == nil {
+		return nil, err
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || t.index >= uint64(len(receipts)) {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) (common.Hash, error) {
+	return t.hash, nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *Transaction) Lane(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Lane()), nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil || t.block == nil {
+		return nil, err
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	var signer types.Signer = types.FrontierSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	return t.account(ctx, from, args)
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.To() == nil {
+		return nil, err
+	}
+	return t.account(ctx, *tx.To(), args)
+}
+
+func (t *Transaction) FeePayer(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.FeePayer() == nil {
+		return nil, err
+	}
+	return t.account(ctx, *tx.FeePayer(), args)
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := hexutil.Uint64(receipt.GasUsed)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || t.tx.To() != nil {
+		return nil, err
+	}
+	return t.account(ctx, receipt.ContractAddress, args)
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	logs := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		logs = append(logs, &Log{backend: t.backend, transaction: t, log: log})
+	}
+	return &logs, nil
+}
+
+// Block is a block of the canonical chain, looked up by number or hash when first
+// needed.
+type Block struct {
+	backend ethapi.Backend
+	number  rpc.BlockNumber // Number the block is looked up by, unless hash is set
+	hash    common.Hash     // Hash the block is looked up by, zero if by number
+
+	block    *types.Block
+	receipts types.Receipts
+	lock     sync.Mutex
+}
+
+// resolve returns the block, looking it up if not known yet. If there's no such
+// block, errBlockNotFound is returned.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.block != nil {
+		return b.block, nil
+	}
+	var (
+		block *types.Block
+		err   error
+	)
+	if b.hash != (common.Hash{}) {
+		block, err = b.backend.GetBlock(ctx, b.hash)
+	} else {
+		block, err = b.backend.BlockByNumber(ctx, b.number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+	b.block = block
+	return block, nil
+}
+
+// resolveReceipts returns the receipts of the transactions in the block, looking
+// them up if not known yet.
+func (b *Block) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.receipts == nil {
+		receipts, err := b.backend.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// stateNumber returns the number of the block accounts and calls are resolved at.
+func (b *Block) stateNumber(ctx context.Context) (rpc.BlockNumber, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rpc.BlockNumber(block.NumberU64()), nil
+}
+
+// transactions returns the resolvers of the transactions in the block.
+func (b *Block) transactions(block *types.Block) []*Transaction {
+	txs := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs = append(txs, &Transaction{
+			backend: b.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+			block:   b,
+			index:   uint64(i),
+		})
+	}
+	return txs
+}
+
+// filterLogs returns the logs of the block matching the filter criteria.
+func (b *Block) filterLogs(ctx context.Context, addresses []common.Address, topics [][]common.Hash) ([]*Log, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		txs  = b.transactions(block)
+		logs []*Log
+	)
+	for i, receipt := range receipts {
+		if i >= len(txs) {
+			break
+		}
+		for _, log := range filterLogs(receipt.Logs, addresses, topics) {
+			logs = append(logs, &Log{backend: b.backend, transaction: txs[i], log: log})
+		}
+	}
+	return logs, nil
+}
+
+func (b *Block) Number(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.NumberU64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.Hash(), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block.NumberU64() == 0 {
+		return nil, err
+	}
+	return &Block{backend: b.backend, hash: block.ParentHash()}, nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Header().Nonce
+	return hexutil.Bytes(nonce[:]), nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.TxHash(), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.Root(), nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.ReceiptHash(), nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     block.Coinbase(),
+		blockNumber: args.number(rpc.BlockNumber(block.NumberU64())),
+	}, nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Extra()), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.GasLimit()), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.GasUsed()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Time()), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Bloom().Bytes()), nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.MixDigest(), nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Difficulty()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	td := b.backend.GetTd(block.Hash())
+	if td == nil {
+		return hexutil.Big{}, errTDNotFound
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(block.Transactions())), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.transactions(block), nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	return &Transaction{
+		backend: b.backend,
+		hash:    txs[args.Index].Hash(),
+		tx:      txs[args.Index],
+		block:   b,
+		index:   uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria are the criteria of the logs of a block returned.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address
+	Topics    *[][]common.Hash
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var (
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	return b.filterLogs(ctx, addresses, topics)
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.stateNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{backend: b.backend, address: args.Address, blockNumber: number}, nil
+}
+
+func (b *Block) Call(ctx context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	number, err := b.stateNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return doCall(ctx, b.backend, args.Data, number)
+}
+
+func (b *Block) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Uint64, error) {
+	number, err := b.stateNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return ethapi.DoEstimateGas(ctx, b.backend, args.Data.callArgs(), number)
+}
+
+// CallData is the input of a call or gas estimation, all fields being optional.
+type CallData struct {
+	From     *common.Address
+	To       *common.Address
+	Gas      *hexutil.Uint64
+	GasPrice *hexutil.Big
+	Value    *hexutil.Big
+	Data     *hexutil.Bytes
+}
+
+// callArgs converts the call data to the arguments of the API calls.
+func (c CallData) callArgs() ethapi.CallArgs {
+	var args ethapi.CallArgs
+	if c.From != nil {
+		args.From = *c.From
+	}
+	args.To = c.To
+	if c.Gas != nil {
+		args.Gas = *c.Gas
+	}
+	if c.GasPrice != nil {
+		args.GasPrice = *c.GasPrice
+	}
+	if c.Value != nil {
+		args.Value = *c.Value
+	}
+	if c.Data != nil {
+		args.Data = *c.Data
+	}
+	return args
+}
+
+// CallResult is the result of a call.
+type CallResult struct {
+	data    hexutil.Bytes
+	gasUsed hexutil.Uint64
+	status  hexutil.Uint64
+}
+
+func (c *CallResult) Data() hexutil.Bytes {
+	return c.data
+}
+
+func (c *CallResult) GasUsed() hexutil.Uint64 {
+	return c.gasUsed
+}
+
+func (c *CallResult) Status() hexutil.Uint64 {
+	return c.status
+}
+
+// doCall executes a call at the state of the given block.
+func doCall(ctx context.Context, backend ethapi.Backend, data CallData, number rpc.BlockNumber) (*CallResult, error) {
+	result, gas, failed, err := ethapi.DoCall(ctx, backend, data.callArgs(), number, vm.Config{}, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(1)
+	if failed {
+		status = 0
+	}
+	return &CallResult{data: result, gasUsed: hexutil.Uint64(gas), status: status}, nil
+}
+
+// Pending is the pending block and the transactions of the pool.
+type Pending struct {
+	backend ethapi.Backend
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context) ([]*Transaction, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{backend: p.backend, hash: tx.Hash(), tx: tx})
+	}
+	return ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{backend: p.backend, address: args.Address, blockNumber: rpc.PendingBlockNumber}
+}
+
+func (p *Pending) Call(ctx context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	return doCall(ctx, p.backend, args.Data, rpc.PendingBlockNumber)
+}
+
+func (p *Pending) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Uint64, error) {
+	return ethapi.DoEstimateGas(ctx, p.backend, args.Data.callArgs(), rpc.PendingBlockNumber)
+}
+
+// Resolver is the root resolver of the queries.
+type Resolver struct {
+	backend ethapi.Backend
+}
+
+// head returns the number of the latest block.
+func (r *Resolver) head(ctx context.Context) (uint64, error) {
+	header, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errBlockNotFound
+	}
+	return header.Number.Uint64(), nil
+}
+
+// blockRange returns the range of blocks between from and to (the latest block
+// if nil), inclusive.
+func (r *Resolver) blockRange(ctx context.Context, from hexutil.Uint64, to *hexutil.Uint64) (uint64, uint64, error) {
+	head, err := r.head(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	last := head
+	if to != nil && uint64(*to) < head {
+		last = uint64(*to)
+	}
+	if uint64(from) > last {
+		if to != nil && *to < from {
+			return 0, 0, errInvalidRange
+		}
+		return uint64(from), uint64(from) - 1, nil // beyond the head, empty range
+	}
+	if last-uint64(from) >= maxQueryRange {
+		return 0, 0, errRangeTooLarge
+	}
+	return uint64(from), last, nil
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	block := &Block{backend: r.backend, number: rpc.LatestBlockNumber}
+	switch {
+	case args.Number != nil && args.Hash != nil:
+		return nil, errNumberAndHash
+	case args.Number != nil:
+		block.number = blockNumber(*args.Number)
+	case args.Hash != nil:
+		block.hash = *args.Hash
+	}
+	switch _, err := block.resolve(ctx); err {
+	case nil:
+		return block, nil
+	case errBlockNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	from, to, err := r.blockRange(ctx, args.From, args.To)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*Block
+	for number := from; number <= to && number >= from; number++ {
+		blocks = append(blocks, &Block{backend: r.backend, number: rpc.BlockNumber(number)})
+	}
+	return blocks, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) (*Pending, error) {
+	return &Pending{backend: r.backend}, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{backend: r.backend, hash: args.Hash}
+	if known, err := tx.resolve(ctx); err != nil || known == nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// FilterCriteria are the criteria of the logs returned.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64
+	ToBlock   *hexutil.Uint64
+	Addresses *[]common.Address
+	Topics    *[][]common.Hash
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	var (
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	// Resolve the range of blocks to search, the latest one by default
+	from := args.Filter.FromBlock
+	if from == nil {
+		head, err := r.head(ctx)
+		if err != nil {
+			return nil, err
+		}
+		latest := hexutil.Uint64(head)
+		from = &latest
+	}
+	first, last, err := r.blockRange(ctx, *from, args.Filter.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	// Collect the logs of the blocks whose bloom filter matches
+	logs := []*Log{}
+	for number := first; number <= last && number >= first; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header, err := r.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil || !bloomFilter(header.Bloom, addresses, topics) {
+			continue
+		}
+		block := &Block{backend: r.backend, hash: header.Hash()}
+		matches, err := block.filterLogs(ctx, addresses, topics)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, matches...)
+	}
+	return logs, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
+
+// filterLogs returns the logs matching the given addresses and topics.
+func filterLogs(logs []*types.Log, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+	var ret []*types.Log
+Logs:
+	for _, log := range logs {
+		if len(addresses) > 0 && !includes(addresses, log.Address) {
+			continue
+		}
+		// If the to filtered topics is greater than the amount of topics in logs, skip.
+		if len(topics) > len(log.Topics) {
+			continue
+		}
+		for i, sub := range topics {
+			match := len(sub) == 0 // empty rule set == wildcard
+			for _, topic := range sub {
+				if log.Topics[i] == topic {
+					match = true
+					break
+				}
+			}
+			if !match {
+				continue Logs
+			}
+		}
+		ret = append(ret, log)
+	}
+	return ret
+}
+
+// bloomFilter reports whether a bloom filter may contain logs matching the given
+// addresses and topics.
+func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var included bool
+		for _, addr := range addresses {
+			if types.BloomLookup(bloom, addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, sub := range topics {
+		included := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
+// includes reports whether addresses contains a.
+func includes(addresses []common.Address, a common.Address) bool {
+	for _, addr := range addresses {
+		if addr == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/relianz2019/relianz/common"
+	"github.com/relianz2019/relianz/core/rawdb"
+	"github.com/relianz2019/relianz/core/state"
+	"github.com/relianz2019/relianz/core/types"
+	"github.com/relianz2019/relianz/crypto"
+	"github.com/relianz2019/relianz/dspdb"
+	ethapi "github.com/relianz2019/relianz/internal/rlzapi"
+	"github.com/relianz2019/relianz/rpc"
+)
+
+var (
+	testKey, _  = crypto.GenerateKey()
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+	testTo      = common.Address{0x01}
+)
+
+// testBackend is a chain of a few blocks sharing a single state, only
+// implementing the methods of the Backend used by the tests.
+type testBackend struct {
+	ethapi.Backend
+
+	db       dspdb.Database
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+	pool     []*types.Transaction
+	state    *state.StateDB
+}
+
+// newTestBackend creates a chain where block i has i transfers from testAddress
+// to testTo, each with a receipt carrying a log, along with a pending transfer.
+func newTestBackend(blocks int) *testBackend {
+	b := &testBackend{
+		db:       dspdb.NewMemDatabase(),
+		receipts: make(map[common.Hash]types.Receipts),
+	}
+	b.state, _ = state.New(common.Hash{}, state.NewDatabase(dspdb.NewMemDatabase()))
+	b.state.SetBalance(testAddress, big.NewInt(1000000000))
+	b.state.SetBalance(testTo, big.NewInt(5000))
+
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	nonce := uint64(0)
+	for number := 0; number < blocks; number++ {
+		var (
+			txs      []*types.Transaction
+			receipts []*types.Receipt
+		)
+		for i := 0; i < number; i++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, testTo, big.NewInt(1000), 21000, big.NewInt(1), nil), signer, testKey)
+			nonce++
+			txs = append(txs, tx)
+
+			receipt := types.NewReceipt(nil, false, uint64(i+1)*21000)
+			receipt.Status = types.ReceiptStatusSuccessful
+			receipt.TxHash = tx.Hash()
+			receipt.GasUsed = 21000
+			receipt.Logs = []*types.Log{{
+				Address:     testTo,
+				Topics:      []common.Hash{{0x02}, {byte(number)}},
+				Data:        []byte{0x03},
+				BlockNumber: uint64(number),
+				TxHash:      tx.Hash(),
+				TxIndex:     uint(i),
+				Index:       uint(i),
+			}}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			receipts = append(receipts, receipt)
+		}
+		header := &types.Header{Number: big.NewInt(int64(number)), Difficulty: big.NewInt(131072), GasLimit: 8000000, Time: big.NewInt(1550000000)}
+		if number > 0 {
+			header.ParentHash = b.blocks[number-1].Hash()
+		}
+		block := types.NewBlock(header, txs, nil, receipts)
+		rawdb.WriteBlock(b.db, block)
+		rawdb.WriteTxLookupEntries(b.db, block)
+
+		b.blocks = append(b.blocks, block)
+		b.receipts[block.Hash()] = receipts
+	}
+	tx, _ := types.SignTx(types.NewTransaction(nonce, testTo, big.NewInt(1000), 21000, big.NewInt(1), nil), signer, testKey)
+	b.pool = append(b.pool, tx)
+	return b
+}
+
+func (b *testBackend) ChainDb() dspdb.Database { return b.db }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	block, err := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return b.blocks[len(b.blocks)-1], nil
+	}
+	if int64(number) < 0 || int64(number) >= int64(len(b.blocks)) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, block := range b.blocks {
+		if block.Hash() == hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int {
+	block, _ := b.GetBlock(context.Background(), hash)
+	if block == nil {
+		return nil
+	}
+	return new(big.Int).Mul(block.Number(), big.NewInt(131072))
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, err := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, err
+	}
+	return b.state, header, nil
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.pool, nil
+}
+
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	for _, tx := range b.pool {
+		if tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+// testResponse is the response of a query.
+type testResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// query POSTs a query to the handler, decoding the data of the response to result.
+func query(t *testing.T, handler http.Handler, query string, variables map[string]interface{}, result interface{}) *testResponse {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", Path, bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("query failed with status %d: %s", rec.Code, rec.Body)
+	}
+	response := new(testResponse)
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	if result != nil && len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, result); err != nil {
+			t.Fatalf("invalid response data %s: %v", response.Data, err)
+		}
+	}
+	return response
+}
+
+func newTestHandler(t *testing.T, backend ethapi.Backend) http.Handler {
+	handler, err := NewHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	return handler
+}
+
+// Tests that a block can be fetched along with all its receipts and the balances
+// of the accounts touched by its transactions in a single query.
+func TestBlockNestedQuery(t *testing.T) {
+	backend := newTestBackend(4)
+	handler := newTestHandler(t, backend)
+
+	var result struct {
+		Block struct {
+			Number           string
+			Hash             common.Hash
+			Parent           struct{ Hash common.Hash }
+			TotalDifficulty  string
+			TransactionCount int
+			Transactions     []struct {
+				Hash              common.Hash
+				Index             int
+				Status            string
+				CumulativeGasUsed string
+				From              struct {
+					Address common.Address
+					Balance string
+				}
+				To   struct{ Balance string }
+				Logs []struct {
+					Index  int
+					Topics []common.Hash
+					Data   string
+				}
+			}
+		}
+	}
+	response := query(t, handler, `query($number: Long) {
+		block(number: $number) {
+			number hash parent { hash } totalDifficulty transactionCount
+			transactions {
+				hash index status cumulativeGasUsed
+				from { address balance }
+				to { balance }
+				logs { index topics data }
+			}
+		}
+	}`, map[string]interface{}{"number": 3}, &result)
+	if len(response.Errors) > 0 {
+		t.Fatalf("query failed: %v", response.Errors)
+	}
+	block := result.Block
+	if block.Number != "0x3" || block.Hash != backend.blocks[3].Hash() || block.Parent.Hash != backend.blocks[2].Hash() {
+		t.Fatalf("block mismatch: have number %s hash %x parent %x", block.Number, block.Hash, block.Parent.Hash)
+	}
+	if block.TotalDifficulty != "0x60000" {
+		t.Errorf("total difficulty mismatch: have %s, want 0x60000", block.TotalDifficulty)
+	}
+	if block.TransactionCount != 3 || len(block.Transactions) != 3 {
+		t.Fatalf("transaction count mismatch: have %d (%d listed), want 3", block.TransactionCount, len(block.Transactions))
+	}
+	for i, tx := range block.Transactions {
+		if tx.Hash != backend.blocks[3].Transactions()[i].Hash() || tx.Index != i {
+			t.Errorf("tx %d: mismatch: have hash %x index %d", i, tx.Hash, tx.Index)
+		}
+		if tx.Status != "0x1" || tx.CumulativeGasUsed != hexUint(uint64(i+1)*21000) {
+			t.Errorf("tx %d: receipt mismatch: have status %s cumulative gas %s", i, tx.Status, tx.CumulativeGasUsed)
+		}
+		if tx.From.Address != testAddress || tx.From.Balance != "0x3b9aca00" || tx.To.Balance != "0x1388" {
+			t.Errorf("tx %d: account mismatch: have from %x (%s), to balance %s", i, tx.From.Address, tx.From.Balance, tx.To.Balance)
+		}
+		if len(tx.Logs) != 1 || tx.Logs[0].Index != i || tx.Logs[0].Topics[1] != (common.Hash{0x03}) || tx.Logs[0].Data != "0x03" {
+			t.Errorf("tx %d: logs mismatch: have %+v", i, tx.Logs)
+		}
+	}
+}
+
+// Tests that transactions are resolved by hash, both mined and pending ones.
+func TestTransactionQuery(t *testing.T) {
+	backend := newTestBackend(3)
+	handler := newTestHandler(t, backend)
+
+	type transaction struct {
+		Nonce   string
+		Index   *int
+		Status  *string
+		Block   *struct{ Number string }
+		GasUsed *string
+	}
+	var result struct{ Transaction *transaction }
+
+	const q = `query($hash: Bytes32!) { transaction(hash: $hash) { nonce index status block { number } gasUsed } }`
+
+	mined := backend.blocks[2].Transactions()[1]
+	query(t, handler, q, map[string]interface{}{"hash": mined.Hash().Hex()}, &result)
+	if tx := result.Transaction; tx == nil || tx.Nonce != "0x2" || tx.Index == nil || *tx.Index != 1 || tx.Block == nil || tx.Block.Number != "0x2" || *tx.GasUsed != "0x5208" {
+		t.Fatalf("mined transaction mismatch: have %+v", result.Transaction)
+	}
+	result.Transaction = nil
+	query(t, handler, q, map[string]interface{}{"hash": backend.pool[0].Hash().Hex()}, &result)
+	if tx := result.Transaction; tx == nil || tx.Nonce != "0x3" || tx.Index != nil || tx.Status != nil || tx.Block != nil {
+		t.Fatalf("pending transaction mismatch: have %+v", result.Transaction)
+	}
+	result.Transaction = &transaction{}
+	query(t, handler, q, map[string]interface{}{"hash": common.Hash{0xff}.Hex()}, &result)
+	if result.Transaction != nil {
+		t.Fatalf("unknown transaction resolved: %+v", result.Transaction)
+	}
+}
+
+// Tests that logs are filtered over block ranges and within blocks.
+func TestLogsQuery(t *testing.T) {
+	backend := newTestBackend(5)
+	handler := newTestHandler(t, backend)
+
+	var result struct {
+		Logs []struct {
+			Transaction struct{ Hash common.Hash }
+		}
+		Block struct {
+			Logs []struct{ Index int }
+		}
+	}
+	response := query(t, handler, `{
+		logs(filter: {fromBlock: 1, topics: [[], ["0x0300000000000000000000000000000000000000000000000000000000000000", "0x0400000000000000000000000000000000000000000000000000000000000000"]]}) {
+			transaction { hash }
+		}
+		block(number: 4) { logs(filter: {addresses: ["0x0100000000000000000000000000000000000000"]}) { index } }
+	}`, nil, &result)
+	if len(response.Errors) > 0 {
+		t.Fatalf("query failed: %v", response.Errors)
+	}
+	if len(result.Logs) != 7 {
+		t.Fatalf("range log count mismatch: have %d, want 7", len(result.Logs))
+	}
+	if result.Logs[0].Transaction.Hash != backend.blocks[3].Transactions()[0].Hash() {
+		t.Errorf("log transaction mismatch: have %x", result.Logs[0].Transaction.Hash)
+	}
+	if len(result.Block.Logs) != 4 {
+		t.Errorf("block log count mismatch: have %d, want 4", len(result.Block.Logs))
+	}
+	// Ensure oversized ranges are rejected
+	response = query(t, handler, `{ logs(filter: {fromBlock: 0, toBlock: 20000}) { index } }`, nil, nil)
+	if len(response.Errors) != 0 {
+		t.Fatalf("range clamped to the head rejected: %v", response.Errors)
+	}
+	response = query(t, handler, `{ blocks(from: 3, to: 1) { number } }`, nil, nil)
+	if len(response.Errors) != 1 || response.Errors[0].Message != errInvalidRange.Error() {
+		t.Fatalf("invalid range error mismatch: have %v", response.Errors)
+	}
+}
+
+// Tests the queries of the pending state and of missing blocks.
+func TestPendingAndMissing(t *testing.T) {
+	backend := newTestBackend(2)
+	handler := newTestHandler(t, backend)
+
+	var result struct {
+		Pending struct {
+			TransactionCount int
+			Account          struct{ Balance string }
+		}
+		Block *struct{ Number string }
+	}
+	response := query(t, handler, `{
+		pending { transactionCount account(address: "0x0100000000000000000000000000000000000000") { balance } }
+		block(number: 10) { number }
+	}`, nil, &result)
+	if len(response.Errors) > 0 {
+		t.Fatalf("query failed: %v", response.Errors)
+	}
+	if result.Pending.TransactionCount != 1 || result.Pending.Account.Balance != "0x1388" {
+		t.Errorf("pending state mismatch: have %+v", result.Pending)
+	}
+	if result.Block != nil {
+		t.Errorf("missing block resolved: %+v", result.Block)
+	}
+}
+
+// Tests that queries can be passed as URL parameters of GET requests, and that
+// other methods are rejected.
+func TestHandlerMethods(t *testing.T) {
+	handler := newTestHandler(t, newTestBackend(2))
+
+	params := url.Values{"query": {"query($n: Long) { block(number: $n) { number } }"}, "variables": {`{"n": 1}`}}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", Path+"?"+params.Encode(), nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"data":{"block":{"number":"0x1"}}}` {
+		t.Fatalf("GET query mismatch: have %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("PUT", Path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PUT status mismatch: have %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func hexUint(n uint64) string {
+	return "0x" + new(big.Int).SetUint64(n).Text(16)
+}
@@ -0,0 +1,272 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+// schema is the GraphQL schema of the chain data served.
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # Address is a 20 byte account identifier, represented as 0x-prefixed
+    # hexadecimal (or with the node's display prefix).
+    scalar Address
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed
+    # hexadecimal. An empty byte string is represented as '0x'.
+    scalar Bytes
+    # BigInt is a large integer, represented as 0x-prefixed hexadecimal. Inputs
+    # may be integers too.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer, represented as 0x-prefixed hexadecimal.
+    # Inputs may be integers too.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # Account is an account at a particular block.
+    type Account {
+        # Address is the address owning the account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # TransactionCount is the number of transactions sent from this account,
+        # or in the case of a contract, the number of contracts created.
+        transactionCount: Long!
+        # Code contains the smart contract code for this account, if the account
+        # is a (non-self-destructed) contract.
+        code: Bytes!
+        # Storage provides access to the storage of a contract account, indexed
+        # by its 32 byte slot identifier.
+        storage(slot: Bytes32!): Bytes32!
+    }
+
+    # Log is a log entry emitted by a contract.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the account which generated this log, at the block including
+        # it unless another one is given.
+        account(block: Long): Account!
+        # Topics is a list of 0-4 indexed topics for the log.
+        topics: [Bytes32!]!
+        # Data is unindexed data for this log.
+        data: Bytes!
+        # Transaction is the transaction that generated this log entry.
+        transaction: Transaction!
+    }
+
+    # Transaction is a transaction, either included in a block or pending in the
+    # transaction pool. The accounts of a transaction are resolved at the block
+    # including it (the pending one if not included yet), unless another one is
+    # given.
+    type Transaction {
+        # Hash is the hash of this transaction.
+        hash: Bytes32!
+        # Nonce is the nonce of the account this transaction was generated with.
+        nonce: Long!
+        # Lane is the nonce lane of the transaction, zero for the default one.
+        lane: Long!
+        # Index is the index of this transaction in the block, null if pending.
+        index: Int
+        # From is the account that sent this transaction.
+        from(block: Long): Account!
+        # To is the account the transaction was sent to, null for contract
+        # creations.
+        to(block: Long): Account
+        # FeePayer is the account paying the fees of a sponsored transaction,
+        # null for the others.
+        feePayer(block: Long): Account
+        # Value is the value, in wei, sent along with this transaction.
+        value: BigInt!
+        # GasPrice is the price offered to miners for gas, in wei per unit.
+        gasPrice: BigInt!
+        # Gas is the maximum amount of gas this transaction can consume.
+        gas: Long!
+        # InputData is the data supplied to the target of the transaction.
+        inputData: Bytes!
+        # Block is the block this transaction was mined in, null if pending.
+        block: Block
+
+        # Status is the return status of the transaction: 1 if it succeeded and
+        # 0 if it failed. Null if pending.
+        status: Long
+        # GasUsed is the amount of gas used by this transaction, null if pending.
+        gasUsed: Long
+        # CumulativeGasUsed is the total gas used in the block up to and including
+        # this transaction, null if pending.
+        cumulativeGasUsed: Long
+        # CreatedContract is the account created by this contract creation, null
+        # if pending or not a contract creation.
+        createdContract(block: Long): Account
+        # Logs is the list of logs emitted by this transaction, null if pending.
+        logs: [Log!]
+    }
+
+    # BlockFilterCriteria encapsulates log filter criteria for searching a single
+    # block.
+    input BlockFilterCriteria {
+        # Addresses is a list of addresses that are of interest. If this list is
+        # empty, results will not be filtered by address.
+        addresses: [Address!]
+        # Topics list restricts matches to particular event topics. Each event
+        # has a list of topics. Topics matches a prefix of that list. An empty
+        # element slice matches any topic. Non-empty elements represent an
+        # alternative that matches any of the contained topics.
+        topics: [[Bytes32!]!]
+    }
+
+    # Block is a block of the canonical chain. Its accounts are resolved at the
+    # block itself, unless another one is given.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block, null for the genesis block.
+        parent: Block
+        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # TransactionsRoot is the keccak256 hash of the root of the trie of
+        # transactions in this block.
+        transactionsRoot: Bytes32!
+        # StateRoot is the keccak256 hash of the state trie after this block was
+        # processed.
+        stateRoot: Bytes32!
+        # ReceiptsRoot is the keccak256 hash of the trie of transaction receipts
+        # in this block.
+        receiptsRoot: Bytes32!
+        # Miner is the account that mined this block.
+        miner(block: Long): Account!
+        # ExtraData is an arbitrary data field supplied by the miner.
+        extraData: Bytes!
+        # GasLimit is the maximum amount of gas that was available to
+        # transactions in this block.
+        gasLimit: Long!
+        # GasUsed is the amount of gas that was used executing transactions in
+        # this block.
+        gasUsed: Long!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # LogsBloom is a bloom filter that can be used to check if a block may
+        # contain log entries matching a filter.
+        logsBloom: Bytes!
+        # MixHash is the hash that was used as an input to the PoW process.
+        mixHash: Bytes32!
+        # Difficulty is a measure of the difficulty of mining this block.
+        difficulty: BigInt!
+        # TotalDifficulty is the sum of all difficulty values up to and including
+        # this block.
+        totalDifficulty: BigInt!
+        # TransactionCount is the number of transactions in this block.
+        transactionCount: Int!
+        # Transactions is the list of transactions in this block, along with
+        # their receipts.
+        transactions: [Transaction!]!
+        # TransactionAt returns the transaction at the specified index, null if
+        # there's none.
+        transactionAt(index: Int!): Transaction
+        # Logs returns the logs of this block matching a filter.
+        logs(filter: BlockFilterCriteria!): [Log!]!
+        # Account fetches an account at the block's state.
+        account(address: Address!): Account!
+        # Call executes a local call operation at the block's state.
+        call(data: CallData!): CallResult
+        # EstimateGas estimates the amount of gas that will be required for
+        # successfully executing a transaction at the block's state.
+        estimateGas(data: CallData!): Long!
+    }
+
+    # CallData represents the data associated with a local contract call. All
+    # fields are optional.
+    input CallData {
+        # From is the address making the call.
+        from: Address
+        # To is the address the call is sent to.
+        to: Address
+        # Gas is the amount of gas sent with the call.
+        gas: Long
+        # GasPrice is the price, in wei, offered for each unit of gas.
+        gasPrice: BigInt
+        # Value is the value, in wei, sent along with the call.
+        value: BigInt
+        # Data is the data sent to the callee.
+        data: Bytes
+    }
+
+    # CallResult is the result of a local call operation.
+    type CallResult {
+        # Data is the return data of the called contract.
+        data: Bytes!
+        # GasUsed is the amount of gas used by the call, after any refunds.
+        gasUsed: Long!
+        # Status is the result of the call: 1 for success and 0 for failure.
+        status: Long!
+    }
+
+    # FilterCriteria encapsulates log filter criteria for searching a range of
+    # blocks.
+    input FilterCriteria {
+        # FromBlock is the block at which to start searching, inclusive. Defaults
+        # to the latest block.
+        fromBlock: Long
+        # ToBlock is the block at which to stop searching, inclusive. Defaults to
+        # the latest block.
+        toBlock: Long
+        # Addresses is a list of addresses that are of interest. If this list is
+        # empty, results will not be filtered by address.
+        addresses: [Address!]
+        # Topics list restricts matches to particular event topics, see the
+        # BlockFilterCriteria.
+        topics: [[Bytes32!]!]
+    }
+
+    # Pending represents the current pending state.
+    type Pending {
+        # TransactionCount is the number of transactions in the pending state.
+        transactionCount: Int!
+        # Transactions is a list of transactions in the pending state.
+        transactions: [Transaction!]!
+        # Account fetches an account at the pending state.
+        account(address: Address!): Account!
+        # Call executes a local call operation at the pending state.
+        call(data: CallData!): CallResult
+        # EstimateGas estimates the amount of gas that will be required for
+        # successfully executing a transaction at the pending state.
+        estimateGas(data: CallData!): Long!
+    }
+
+    type Query {
+        # Block fetches a block by number or by hash, the latest one if neither
+        # is given. Null if there's no such block.
+        block(number: Long, hash: Bytes32): Block
+        # Blocks returns all the blocks between two numbers, inclusive. If to is
+        # not supplied, it defaults to the latest block.
+        blocks(from: Long!, to: Long): [Block!]!
+        # Pending returns the current pending state.
+        pending: Pending!
+        # Transaction returns a transaction specified by its hash, null if it's
+        # unknown.
+        transaction(hash: Bytes32!): Transaction
+        # Logs returns log entries matching the provided filter.
+        logs(filter: FilterCriteria!): [Log!]!
+        # GasPrice returns the node's estimate of a gas price sufficient to
+        # ensure a transaction is mined in a timely fashion.
+        gasPrice: BigInt!
+        # ProtocolVersion returns the current wire protocol version number.
+        protocolVersion: Int!
+    }
+`
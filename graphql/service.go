@@ -0,0 +1,127 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	ethapi "github.com/relianz2019/relianz/internal/rlzapi"
+	"github.com/relianz2019/relianz/log"
+	"github.com/relianz2019/relianz/p2p"
+	"github.com/relianz2019/relianz/rpc"
+)
+
+const (
+	// Path is the path of the node's HTTP endpoint the queries are served at.
+	Path = "/graphql"
+
+	maxRequestContentLength = 1024 * 128
+	maxQueryDepth           = 12 // Maximum nesting of the fields selected by a query
+)
+
+// Service is a node service serving GraphQL queries of the chain data on the
+// node's HTTP endpoint.
+type Service struct {
+	handler http.Handler
+}
+
+// New creates a GraphQL service resolving the queries with the given backend.
+func New(backend ethapi.Backend) (*Service, error) {
+	handler, err := NewHandler(backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{handler: handler}, nil
+}
+
+// Protocols implements node.Service, returning no p2p protocols.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning no RPC APIs.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start implements node.Service, the queries being served by the node.
+func (s *Service) Start(server *p2p.Server) error {
+	log.Info("GraphQL endpoint enabled", "path", Path)
+	return nil
+}
+
+// Stop implements node.Service.
+func (s *Service) Stop() error { return nil }
+
+// HTTPHandlers implements node.HTTPService, serving the queries at Path.
+func (s *Service) HTTPHandlers() map[string]http.Handler {
+	return map[string]http.Handler{Path: s.handler}
+}
+
+// handler serves GraphQL queries over HTTP, either POSTed as JSON or passed as URL
+// parameters of GET requests.
+type handler struct {
+	schema *graphql.Schema
+}
+
+// NewHandler creates an HTTP handler serving GraphQL queries of the chain data,
+// resolved with the given backend.
+func NewHandler(backend ethapi.Backend) (http.Handler, error) {
+	parsed, err := graphql.ParseSchema(schema, &Resolver{backend}, graphql.MaxDepth(maxQueryDepth))
+	if err != nil {
+		return nil, err
+	}
+	return &handler{schema: parsed}, nil
+}
+
+// ServeHTTP implements http.Handler, executing a query.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	switch r.Method {
+	case http.MethodGet:
+		values := r.URL.Query()
+		params.Query = values.Get("query")
+		params.OperationName = values.Get("operationName")
+		if variables := values.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+				http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestContentLength)).Decode(&params); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodOptions:
+		return // CORS preflight, answered by the wrapping handler
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	response := h.schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+	blob, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
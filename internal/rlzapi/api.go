@@ -19,10 +19,10 @@ package ethapi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
 	"github.com/dsplinz2019/dsplinz/accounts"
@@ -42,8 +42,6 @@ import (
 	"github.com/dsplinz2019/dsplinz/rlp"
 	"github.com/dsplinz2019/dsplinz/rpc"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
@@ -132,8 +130,17 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
+// Status returns the number of pending and queued transaction in the pool. If a
+// transaction hash is given, the recorded status transitions of that transaction
+// are returned instead, oldest first.
+func (s *PublicTxPoolAPI) Status(hash *common.Hash) interface{} {
+	if hash != nil {
+		history := s.b.TxPoolHistory(*hash)
+		if history == nil {
+			history = []*core.TxTransition{}
+		}
+		return history
+	}
 	pending, queue := s.b.Stats()
 	return map[string]hexutil.Uint{
 		"pending": hexutil.Uint(pending),
@@ -176,6 +183,52 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// Policy returns the name of the ordering and eviction policy of the transaction
+// pool, along with its counters.
+func (s *PublicTxPoolAPI) Policy() map[string]interface{} {
+	name, stats := s.b.TxPoolPolicy()
+
+	counters := make(map[string]hexutil.Uint64, len(stats))
+	for key, value := range stats {
+		counters[key] = hexutil.Uint64(value)
+	}
+	return map[string]interface{}{
+		"name":  name,
+		"stats": counters,
+	}
+}
+
+// Scheduled returns the transactions of an account that are held back by the
+// pool until the block number or timestamp of their schedule is reached.
+func (s *PublicTxPoolAPI) Scheduled(addr common.Address) []*RPCTransaction {
+	txs := s.b.TxPoolScheduled(addr)
+
+	result := make([]*RPCTransaction, len(txs))
+	for i, tx := range txs {
+		result[i] = newRPCPendingTransaction(tx)
+	}
+	return result
+}
+
+// ExportPending returns a versioned and checksummed snapshot of the executable
+// transactions in the pool, which can be imported into another node.
+func (s *PublicTxPoolAPI) ExportPending() (hexutil.Bytes, error) {
+	return s.b.TxPoolExport()
+}
+
+// ImportPending adds the transactions of an exported snapshot to the pool. Every
+// transaction is revalidated against the current head, the invalid ones dropped.
+func (s *PublicTxPoolAPI) ImportPending(snapshot hexutil.Bytes) (map[string]hexutil.Uint, error) {
+	added, dropped, err := s.b.TxPoolImport(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]hexutil.Uint{
+		"added":   hexutil.Uint(added),
+		"dropped": hexutil.Uint(dropped),
+	}, nil
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -352,11 +405,7 @@ func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args SendTxArgs
 	// Assemble the transaction and sign with the wallet
 	tx := args.toTransaction()
 
-	var chainID *big.Int
-	if config := s.b.ChainConfig(); config.IsEIP155(s.b.CurrentBlock().Number()) {
-		chainID = config.ChainId
-	}
-	return wallet.SignTxWithPassphrase(account, passwd, tx, chainID)
+	return wallet.SignTxWithPassphrase(account, passwd, tx, signingChainID(s.b, tx))
 }
 
 // SendTransaction will create a transaction from the given arguments and
@@ -373,6 +422,11 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if args.FeePayer != nil {
+		if signed, err = signAsFeePayer(s.am, signed); err != nil {
+			return common.Hash{}, err
+		}
+	}
 	return submitTransaction(ctx, s.b, signed)
 }
 
@@ -493,8 +547,9 @@ func (s *PublicBlockChainAPI) BlockNumber() *big.Int {
 }
 
 // GetBalance returns the amount of wei for the given address in the state of the
-// given block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta
-// block numbers are also allowed.
+// given block number. The rpc.LatestBlockNumber, rpc.PendingBlockNumber,
+// rpc.FinalizedBlockNumber and rpc.SafeBlockNumber meta block numbers are also
+// allowed.
 func (s *PublicBlockChainAPI) GetBalance(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*big.Int, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
@@ -605,25 +660,28 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
-	From     common.Address  `json:"from"`
-	To       *common.Address `json:"to"`
-	Gas      hexutil.Uint64  `json:"gas"`
-	GasPrice hexutil.Big     `json:"gasPrice"`
-	Value    hexutil.Big     `json:"value"`
-	Data     hexutil.Bytes   `json:"data"`
-}
-
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+	From     common.Address    `json:"from"`
+	To       *common.Address   `json:"to"`
+	Gas      hexutil.Uint64    `json:"gas"`
+	GasPrice hexutil.Big       `json:"gasPrice"`
+	Value    hexutil.Big       `json:"value"`
+	Data     hexutil.Bytes     `json:"data"`
+	Calls    []types.BatchCall `json:"calls"` // Executes a batch instead of To, Value and Data
+}
+
+// DoCall executes the given call on the state of the given block, returning its
+// output, the gas used and whether it failed. Zero timeout disables it.
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	state, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, 0, false, err
 	}
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -640,6 +698,9 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 	// Create new call message
 	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	if len(args.Calls) > 0 {
+		msg = types.NewBatchMessage(addr, 0, args.Calls, gas, gasPrice, false)
+	}
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -654,7 +715,7 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	defer cancel()
 
 	// Get a new instance of the EVM.
-	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vmCfg)
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -678,13 +739,13 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
+	result, _, _, err := DoCall(ctx, s.b, args, blockNr, vm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
-// EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// DoEstimateGas returns an estimate of the amount of gas needed to execute the
+// given call against the state of the given block.
+func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -694,8 +755,8 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	if uint64(args.Gas) >= params.TxGas {
 		hi = uint64(args.Gas)
 	} else {
-		// Retrieve the current pending block to act as the gas ceiling
-		block, err := s.b.BlockByNumber(ctx, rpc.PendingBlockNumber)
+		// Retrieve the block to act as the gas ceiling
+		block, err := b.BlockByNumber(ctx, blockNr)
 		if err != nil {
 			return 0, err
 		}
@@ -707,7 +768,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := DoCall(ctx, b, args, blockNr, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -731,6 +792,12 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	return hexutil.Uint64(hi), nil
 }
 
+// EstimateGas returns an estimate of the amount of gas needed to execute the
+// given transaction against the current pending block.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+	return DoEstimateGas(ctx, s.b, args, rpc.PendingBlockNumber)
+}
+
 // ExecutionResult groups all structured logs emitted by the EVM
 // while replaying a transaction in debug mode as well as transaction
 // execution status, the amount of gas used and the return value
@@ -866,6 +933,20 @@ type RPCTransaction struct {
 	V                *hexutil.Big    `json:"v"`
 	R                *hexutil.Big    `json:"r"`
 	S                *hexutil.Big    `json:"s"`
+
+	// Fields only present for typed (sponsored, batch and lane) transactions
+	Type     hexutil.Uint64    `json:"type,omitempty"`
+	ChainID  *hexutil.Big      `json:"chainId,omitempty"`
+	FeePayer *common.Address   `json:"feePayer,omitempty"`
+	PayerV   *hexutil.Big      `json:"payerV,omitempty"`
+	PayerR   *hexutil.Big      `json:"payerR,omitempty"`
+	PayerS   *hexutil.Big      `json:"payerS,omitempty"`
+	Calls    []types.BatchCall `json:"calls,omitempty"`
+	Lane     hexutil.Uint64    `json:"lane,omitempty"`
+
+	// Fields only present for scheduled transactions
+	NotBefore     hexutil.Uint64 `json:"notBefore,omitempty"`
+	NotBeforeTime hexutil.Uint64 `json:"notBeforeTime,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -891,6 +972,28 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
 	}
+	if tx.Type() != types.LegacyTxType {
+		result.Type = hexutil.Uint64(tx.Type())
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+	}
+	switch tx.Type() {
+	case types.SponsoredTxType:
+		pv, pr, ps := tx.RawFeePayerSignatureValues()
+
+		result.FeePayer = tx.FeePayer()
+		result.PayerV = (*hexutil.Big)(pv)
+		result.PayerR = (*hexutil.Big)(pr)
+		result.PayerS = (*hexutil.Big)(ps)
+	case types.BatchTxType:
+		result.Calls = tx.Calls()
+	case types.LaneTxType:
+		result.Lane = hexutil.Uint64(tx.Lane())
+	case types.ScheduledTxType:
+		number, time := tx.Schedule()
+
+		result.NotBefore = hexutil.Uint64(number)
+		result.NotBeforeTime = hexutil.Uint64(time)
+	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = blockHash
 		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
@@ -994,13 +1097,19 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByBlockHashAndIndex(ctx cont
 	return nil
 }
 
-// GetTransactionCount returns the number of transactions the given address has sent for the given block number
-func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*hexutil.Uint64, error) {
+// GetTransactionCount returns the number of transactions the given address has sent for the given block number.
+// If a nonce lane is given, only the transactions sent on that lane are counted.
+func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, address common.Address, blockNr rpc.BlockNumber, lane *hexutil.Uint64) (*hexutil.Uint64, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, err
 	}
-	nonce := state.GetNonce(address)
+	var nonce uint64
+	if lane != nil {
+		nonce = state.GetLaneNonce(address, uint64(*lane))
+	} else {
+		nonce = state.GetNonce(address)
+	}
 	return (*hexutil.Uint64)(&nonce), state.Error()
 }
 
@@ -1081,6 +1190,10 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Batch transactions report the results of their individual calls too
+	if len(receipt.Calls) > 0 {
+		fields["calls"] = receipt.Calls
+	}
 	return fields, nil
 }
 
@@ -1094,11 +1207,38 @@ func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transacti
 		return nil, err
 	}
 	// Request the wallet to sign the transaction
-	var chainID *big.Int
-	if config := s.b.ChainConfig(); config.IsEIP155(s.b.CurrentBlock().Number()) {
-		chainID = config.ChainId
+	return wallet.SignTx(account, tx, signingChainID(s.b, tx))
+}
+
+// signingChainID returns the chain id to sign the transaction with, or nil if
+// it should be signed without replay protection. Sponsored transactions are
+// always bound to the chain.
+func signingChainID(b Backend, tx *types.Transaction) *big.Int {
+	if config := b.ChainConfig(); config.IsEIP155(b.CurrentBlock().Number()) || tx.Type() != types.LegacyTxType {
+		return config.ChainId
 	}
-	return wallet.SignTx(account, tx, chainID)
+	return nil
+}
+
+// signAsFeePayer countersigns a sponsored transaction, already signed by its
+// sender, with the key of its fee payer. The fee payer has to be managed by an
+// unlocked wallet of the node.
+func signAsFeePayer(am *accounts.Manager, tx *types.Transaction) (*types.Transaction, error) {
+	payer := tx.FeePayer()
+	if payer == nil {
+		return nil, errors.New("not a sponsored transaction")
+	}
+	account := accounts.Account{Address: *payer}
+
+	wallet, err := am.Find(account)
+	if err != nil {
+		return nil, fmt.Errorf("fee payer %s: %v", payer.Hex(), err)
+	}
+	sig, err := wallet.SignHash(account, types.FeePayerHash(tx).Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("fee payer %s: %v", payer.Hex(), err)
+	}
+	return tx.WithFeePayerSignature(sig)
 }
 
 // SendTxArgs represents the arguments to sumbit a new transaction into the transaction pool.
@@ -1113,13 +1253,96 @@ type SendTxArgs struct {
 	// newer name and should be preferred by clients.
 	Data  *hexutil.Bytes `json:"data"`
 	Input *hexutil.Bytes `json:"input"`
+
+	// FeePayer turns the transaction into a sponsored one, with its gas paid
+	// by the given account. Calls turns it into a batch one, executing all the
+	// calls atomically instead of To, Value and Data. Lane sends it on a non-
+	// default nonce lane of the sender, with the Nonce counted within the lane.
+	// NotBefore and NotBeforeTime schedule it, holding it back until the given
+	// block number and timestamp. Sponsored, batch, lane and scheduled
+	// transactions are bound to ChainID, which defaults to the chain of the node.
+	FeePayer      *common.Address   `json:"feePayer"`
+	Calls         []types.BatchCall `json:"calls"`
+	Lane          *hexutil.Uint64   `json:"lane"`
+	NotBefore     *hexutil.Uint64   `json:"notBefore"`
+	NotBeforeTime *hexutil.Uint64   `json:"notBeforeTime"`
+	ChainID       *hexutil.Big      `json:"chainId"`
+}
+
+// UnmarshalJSON decodes the transaction arguments, rejecting sender and recipient
+// addresses with a bad prefix or checksum.
+func (args *SendTxArgs) UnmarshalJSON(input []byte) error {
+	var addrs struct {
+		From     *string `json:"from"`
+		To       *string `json:"to"`
+		FeePayer *string `json:"feePayer"`
+	}
+	if err := json.Unmarshal(input, &addrs); err != nil {
+		return err
+	}
+	if addrs.From != nil {
+		if err := common.ValidateHexAddress(*addrs.From); err != nil {
+			return fmt.Errorf("invalid from address: %v", err)
+		}
+	}
+	if addrs.To != nil {
+		if err := common.ValidateHexAddress(*addrs.To); err != nil {
+			return fmt.Errorf("invalid to address: %v", err)
+		}
+	}
+	if addrs.FeePayer != nil {
+		if err := common.ValidateHexAddress(*addrs.FeePayer); err != nil {
+			return fmt.Errorf("invalid feePayer address: %v", err)
+		}
+	}
+	type sendTxArgs SendTxArgs
+	return json.Unmarshal(input, (*sendTxArgs)(args))
+}
+
+// scheduled reports whether the arguments request a scheduled transaction, held
+// back until a block number or timestamp.
+func (args *SendTxArgs) scheduled() bool {
+	return (args.NotBefore != nil && *args.NotBefore != 0) || (args.NotBeforeTime != nil && *args.NotBeforeTime != 0)
 }
 
 // setDefaults is a helper function that fills in default values for unspecified tx fields.
 func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
+	if args.Calls != nil {
+		if len(args.Calls) == 0 {
+			return errors.New(`batch transaction without any calls provided`)
+		}
+		if args.To != nil || args.Data != nil || args.Input != nil || (args.Value != nil && args.Value.ToInt().Sign() != 0) {
+			return errors.New(`"calls" can't be combined with "to", "value", "data" or "input"`)
+		}
+		if args.FeePayer != nil {
+			return errors.New(`batch transactions can't be sponsored`)
+		}
+	}
+	if args.Lane != nil {
+		if *args.Lane == 0 {
+			return errors.New(`"lane" must be non-zero, omit it for the default lane`)
+		}
+		if args.To == nil || args.FeePayer != nil || args.Calls != nil {
+			return errors.New(`lane transactions need a "to" and can't be sponsored or batched`)
+		}
+	}
+	if args.scheduled() {
+		if args.FeePayer != nil || args.Calls != nil || args.Lane != nil {
+			return errors.New(`scheduled transactions can't be sponsored, batched or sent on a lane`)
+		}
+	}
 	if args.Gas == nil {
 		args.Gas = new(hexutil.Uint64)
 		*(*uint64)(args.Gas) = 90000
+
+		// Batches may contain any number of calls, there's no sane default
+		if args.Calls != nil {
+			gas, err := NewPublicBlockChainAPI(b).EstimateGas(ctx, CallArgs{From: args.From, Calls: args.Calls})
+			if err != nil {
+				return err
+			}
+			args.Gas = &gas
+		}
 	}
 	if args.GasPrice == nil {
 		price, err := b.SuggestPrice(ctx)
@@ -1132,7 +1355,15 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 		args.Value = new(hexutil.Big)
 	}
 	if args.Nonce == nil {
-		nonce, err := b.GetPoolNonce(ctx, args.From)
+		var (
+			nonce uint64
+			err   error
+		)
+		if args.Lane != nil {
+			nonce, err = b.GetPoolLaneNonce(ctx, args.From, uint64(*args.Lane))
+		} else {
+			nonce, err = b.GetPoolNonce(ctx, args.From)
+		}
 		if err != nil {
 			return err
 		}
@@ -1141,7 +1372,7 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
 		return errors.New(`Both "data" and "input" are set and not equal. Please use "input" to pass transaction call data.`)
 	}
-	if args.To == nil {
+	if args.To == nil && args.Calls == nil {
 		// Contract creation
 		var input []byte
 		if args.Data != nil {
@@ -1153,6 +1384,14 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 			return errors.New(`contract creation without any data provided`)
 		}
 	}
+	if args.FeePayer != nil || args.Calls != nil || args.Lane != nil || args.scheduled() {
+		chainID := b.ChainConfig().ChainId
+		if args.ChainID == nil {
+			args.ChainID = (*hexutil.Big)(chainID)
+		} else if (*big.Int)(args.ChainID).Cmp(chainID) != 0 {
+			return fmt.Errorf("chainId does not match node chain: have %v, want %v", args.ChainID, chainID)
+		}
+	}
 	return nil
 }
 
@@ -1163,6 +1402,25 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Input != nil {
 		input = *args.Input
 	}
+	if args.Calls != nil {
+		return types.NewBatchTransaction((*big.Int)(args.ChainID), uint64(*args.Nonce), args.Calls, uint64(*args.Gas), (*big.Int)(args.GasPrice))
+	}
+	if args.scheduled() {
+		var number, time uint64
+		if args.NotBefore != nil {
+			number = uint64(*args.NotBefore)
+		}
+		if args.NotBeforeTime != nil {
+			time = uint64(*args.NotBeforeTime)
+		}
+		return types.NewScheduledTransaction((*big.Int)(args.ChainID), number, time, uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	}
+	if args.Lane != nil {
+		return types.NewLaneTransaction((*big.Int)(args.ChainID), uint64(*args.Lane), uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	}
+	if args.FeePayer != nil {
+		return types.NewSponsoredTransaction((*big.Int)(args.ChainID), uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, *args.FeePayer)
+	}
 	if args.To == nil {
 		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
 	}
@@ -1214,14 +1472,16 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 	// Assemble the transaction and sign with the wallet
 	tx := args.toTransaction()
 
-	var chainID *big.Int
-	if config := s.b.ChainConfig(); config.IsEIP155(s.b.CurrentBlock().Number()) {
-		chainID = config.ChainId
-	}
-	signed, err := wallet.SignTx(account, tx, chainID)
+	signed, err := wallet.SignTx(account, tx, signingChainID(s.b, tx))
 	if err != nil {
 		return common.Hash{}, err
 	}
+	// Sponsored transactions need the consent of the fee payer too
+	if args.FeePayer != nil {
+		if signed, err = signAsFeePayer(s.b.AccountManager(), signed); err != nil {
+			return common.Hash{}, err
+		}
+	}
 	return submitTransaction(ctx, s.b, signed)
 }
 
@@ -1235,6 +1495,31 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	return submitTransaction(ctx, s.b, tx)
 }
 
+// SendSponsoredTransaction countersigns a sponsored transaction, signed by its
+// sender, with the key of its fee payer and adds it to the transaction pool. The
+// fee payer has to be an unlocked account of this node, so the node operator
+// decides which accounts sponsor transactions. Senders without a node of their
+// own obtain the sender signed transaction with signTransaction.
+func (s *PublicTransactionPoolAPI) SendSponsoredTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if tx.Type() != types.SponsoredTxType {
+		return common.Hash{}, errors.New("not a sponsored transaction")
+	}
+	// Refuse to sponsor anything not properly signed by its sender
+	signer := types.MakeSigner(s.b.ChainConfig(), s.b.CurrentBlock().Number())
+	if _, err := types.Sender(signer, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid sender signature: %v", err)
+	}
+	signed, err := signAsFeePayer(s.b.AccountManager(), tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, signed)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Dsplinz Signed Message:\n" + len(message) + message).
 //
@@ -1315,6 +1600,23 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 	return transactions, nil
 }
 
+// CancelScheduledTransaction removes a transaction of one of the accounts this
+// node manages from the pool, as long as it's still waiting for its schedule.
+func (s *PublicTransactionPoolAPI) CancelScheduledTransaction(from common.Address, hash common.Hash) (*RPCTransaction, error) {
+	if _, err := s.b.AccountManager().Find(accounts.Account{Address: from}); err != nil {
+		return nil, err
+	}
+	for _, tx := range s.b.TxPoolScheduled(from) {
+		if tx.Hash() == hash {
+			if _, err := s.b.CancelScheduledTx(hash); err != nil {
+				return nil, err
+			}
+			return newRPCPendingTransaction(tx), nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %#x not scheduled by %#x", hash, from)
+}
+
 // Resend accepts an existing transaction and a new gas price and limit. It will remove
 // the given transaction from the pool and reinsert it with the new gas price and limit.
 func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxArgs, gasPrice *hexutil.Big, gasLimit *hexutil.Uint64) (common.Hash, error) {
@@ -1414,33 +1716,17 @@ func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
 	return &PrivateDebugAPI{b: b}
 }
 
-// ChaindbProperty returns leveldb properties of the chain database.
+// ChaindbProperty returns internal properties of the chain database. The
+// supported properties depend on the database engine in use.
 func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return "", fmt.Errorf("chaindbProperty does not work for memory databases")
-	}
-	if property == "" {
-		property = "leveldb.stats"
-	} else if !strings.HasPrefix(property, "leveldb.") {
-		property = "leveldb." + property
-	}
-	return ldb.LDB().GetProperty(property)
+	return api.b.ChainDb().Stat(property)
 }
 
 func (api *PrivateDebugAPI) ChaindbCompact() error {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return fmt.Errorf("chaindbCompact does not work for memory databases")
-	}
+	db := api.b.ChainDb()
 	for b := byte(0); b < 255; b++ {
 		log.Info("Compacting chain database", "range", fmt.Sprintf("0x%0.2X-0x%0.2X", b, b+1))
-		err := ldb.LDB().CompactRange(util.Range{Start: []byte{b}, Limit: []byte{b + 1}})
-		if err != nil {
+		if err := db.Compact([]byte{b}, []byte{b + 1}); err != nil {
 			log.Error("Database compaction failed", "err", err)
 			return err
 		}
@@ -63,8 +63,15 @@ type Backend interface {
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
+	GetPoolLaneNonce(ctx context.Context, addr common.Address, lane uint64) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolPolicy() (string, map[string]uint64)
+	TxPoolExport() ([]byte, error)
+	TxPoolImport(snapshot []byte) (int, int, error)
+	TxPoolHistory(hash common.Hash) []*core.TxTransition
+	TxPoolScheduled(addr common.Address) types.Transactions
+	CancelScheduledTx(hash common.Hash) (*types.Transaction, error)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
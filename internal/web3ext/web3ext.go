@@ -135,6 +135,41 @@ web3._extend({
 			call: 'alien_getSnapshotByHeaderTime',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'signerQueue',
+			call: 'alien_signerQueue',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'candidates',
+			call: 'alien_candidates',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'votes',
+			call: 'alien_votes',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'missedSlots',
+			call: 'alien_missedSlots',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'voterStatus',
+			call: 'alien_voterStatus',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getCheckpoint',
+			call: 'alien_getCheckpoint',
+			params: 2
+		}),
 	]
 });
 `
@@ -189,6 +224,11 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setRPCAuth',
+			call: 'admin_setRPCAuth',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -448,6 +488,17 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendSponsoredTransaction',
+			call: 'eth_sendSponsoredTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'cancelScheduledTransaction',
+			call: 'eth_cancelScheduledTransaction',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'eth_getRawTransactionByHash',
@@ -638,7 +689,33 @@ web3._extend({
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods: [
+		new web3._extend.Method({
+			name: 'exportPending',
+			call: 'txpool_exportPending',
+		}),
+		new web3._extend.Method({
+			name: 'importPending',
+			call: 'txpool_importPending',
+			params: 1,
+			outputFormatter: function(result) {
+				result.added = web3._extend.utils.toDecimal(result.added);
+				result.dropped = web3._extend.utils.toDecimal(result.dropped);
+				return result;
+			}
+		}),
+		new web3._extend.Method({
+			name: 'transactionStatus',
+			call: 'txpool_status',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'scheduled',
+			call: 'txpool_scheduled',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -658,6 +735,16 @@ web3._extend({
 				return status;
 			}
 		}),
+		new web3._extend.Property({
+			name: 'policy',
+			getter: 'txpool_policy',
+			outputFormatter: function(policy) {
+				for (var key in policy.stats) {
+					policy.stats[key] = web3._extend.utils.toDecimal(policy.stats[key]);
+				}
+				return policy;
+			}
+		}),
 	]
 });
 `
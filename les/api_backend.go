@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/dsplinz2019/dsplinz/accounts"
@@ -38,6 +39,18 @@ import (
 	"github.com/dsplinz2019/dsplinz/rpc"
 )
 
+// errNoFinality is returned if the finalized or safe block is requested from a
+// light client, which does not collect signer confirmations.
+var errNoFinality = errors.New("finalized and safe blocks are not tracked in light mode")
+
+// errNoPoolSnapshots is returned if a transaction pool snapshot is requested to
+// be exported or imported in light mode.
+var errNoPoolSnapshots = errors.New("transaction pool snapshots are not supported in light mode")
+
+// errNoScheduledTxs is returned if a scheduled transaction is to be cancelled in
+// light mode, where transactions are never held back until their schedule.
+var errNoScheduledTxs = errors.New("scheduled transactions are not supported in light mode")
+
 type LesApiBackend struct {
 	dsp *LightDsplinz
 	gpo *gasprice.Oracle
@@ -60,6 +73,9 @@ func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
 		return b.dsp.blockchain.CurrentHeader(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber || blockNr == rpc.SafeBlockNumber {
+		return nil, errNoFinality
+	}
 
 	return b.dsp.blockchain.GetHeaderByNumberOdr(ctx, uint64(blockNr))
 }
@@ -128,6 +144,10 @@ func (b *LesApiBackend) GetPoolNonce(ctx context.Context, addr common.Address) (
 	return b.dsp.txPool.GetNonce(ctx, addr)
 }
 
+func (b *LesApiBackend) GetPoolLaneNonce(ctx context.Context, addr common.Address, lane uint64) (uint64, error) {
+	return b.dsp.txPool.GetLaneNonce(ctx, addr, lane)
+}
+
 func (b *LesApiBackend) Stats() (pending int, queued int) {
 	return b.dsp.txPool.Stats(), 0
 }
@@ -136,10 +156,41 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.dsp.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolPolicy() (string, map[string]uint64) {
+	return "", nil // light clients don't pack blocks nor evict by policy
+}
+
+func (b *LesApiBackend) TxPoolExport() ([]byte, error) {
+	return nil, errNoPoolSnapshots
+}
+
+func (b *LesApiBackend) TxPoolImport(snapshot []byte) (int, int, error) {
+	return 0, 0, errNoPoolSnapshots
+}
+
+func (b *LesApiBackend) TxPoolHistory(hash common.Hash) []*core.TxTransition {
+	return nil // light clients don't track the transaction lifecycle
+}
+
+func (b *LesApiBackend) TxPoolScheduled(addr common.Address) types.Transactions {
+	return nil // light clients don't hold back scheduled transactions
+}
+
+func (b *LesApiBackend) CancelScheduledTx(hash common.Hash) (*types.Transaction, error) {
+	return nil, errNoScheduledTxs
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.dsp.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeTxStatusEvent(ch chan<- core.TxStatusEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.dsp.blockchain.SubscribeChainEvent(ch)
 }
@@ -96,7 +96,7 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 		return nil, err
 	}
 	if alien, ok := bc.hc.Engine().(*alien.Alien); ok {
-		alien.ApplyGenesis(bc.hc, bc.hc.CurrentHeader().Root)
+		alien.ApplyGenesis(bc.hc)
 	}
 	bc.genesisBlock, _ = bc.GetBlockByNumber(NoOdr, 0)
 	if bc.genesisBlock == nil {
@@ -133,6 +133,18 @@ func (pool *TxPool) GetNonce(ctx context.Context, addr common.Address) (uint64,
 	return nonce, nil
 }
 
+// GetLaneNonce returns the "pending" nonce of a given address and nonce lane. The
+// non-default lanes are not tracked locally, their nonces are always retrieved
+// from the current state.
+func (pool *TxPool) GetLaneNonce(ctx context.Context, addr common.Address, lane uint64) (uint64, error) {
+	if lane == 0 {
+		return pool.GetNonce(ctx, addr)
+	}
+	state := pool.currentState(ctx)
+	nonce := state.GetLaneNonce(addr, lane)
+	return nonce, state.Error()
+}
+
 // txStateChanges stores the recent changes between pending/mined states of
 // transactions. True means mined, false means rolled back, no entry means no change
 type txStateChanges map[common.Hash]bool
@@ -351,7 +363,7 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 	// Last but not least check for nonce errors
 	currentState := pool.currentState(ctx)
-	if n := currentState.GetNonce(from); n > tx.Nonce() {
+	if n := currentState.GetLaneNonce(from, tx.Lane()); n > tx.Nonce() {
 		return core.ErrNonceTooLow
 	}
 
@@ -376,7 +388,12 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Should supply enough intrinsic gas
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	var gas uint64
+	if tx.Type() == types.BatchTxType {
+		gas, err = core.BatchIntrinsicGas(tx.Calls())
+	} else {
+		gas, err = core.IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	}
 	if err != nil {
 		return err
 	}
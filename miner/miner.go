@@ -39,6 +39,7 @@ type Backend interface {
 	AccountManager() *accounts.Manager
 	BlockChain() *core.BlockChain
 	TxPool() *core.TxPool
+	ConfirmationPool() *core.ConfirmationPool
 	ChainDb() ethdb.Database
 }
 
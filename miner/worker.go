@@ -284,7 +284,7 @@ func (self *worker) update() {
 					acc, _ := types.Sender(self.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
+				txset := self.dsp.TxPool().Policy().Ordering(self.current.signer, txs)
 				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
 				self.updateSnapshot()
 				self.currentMu.Unlock()
@@ -474,7 +474,7 @@ func (self *worker) commitNewWork() {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+	txs := self.dsp.TxPool().Policy().Ordering(self.current.signer, pending)
 	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
 
 	// compute uncles for the new block.
@@ -511,52 +511,29 @@ func (self *worker) commitNewWork() {
 	}
 	self.push(work)
 	self.updateSnapshot()
-	// todo: add params into dsp, to decide if or not send this tx
 	if self.config.Alien != nil && self.config.Alien.PBFTEnable {
-		err = self.sendConfirmTx(parent.Number())
-		if err != nil {
-			log.Info("Fail to Sign the transaction by coinbase", "err", err)
-		}
+		self.confirm(parent.Header())
 	}
-
 }
 
-// For PBFT of alien consensus, the follow code may move later
-// After confirm a new block, the miner send a custom transaction to self, which value is 0
-// and data like "ufo:1:event:confirm:123" (ufo is prefix, 1 is version, 123 is block number
-// to let the next signer now this signer already confirm this block
-func (self *worker) sendConfirmTx(blockNumber *big.Int) error {
-	wallets := self.dsp.AccountManager().Wallets()
-	// wallets check
-	if len(wallets) == 0 {
-		return nil
-	}
-	for _, wallet := range wallets {
-		if len(wallet.Accounts()) == 0 {
-			continue
-		} else {
-			for _, account := range wallet.Accounts() {
-				if account.Address == self.coinbase {
-					// coinbase account found
-					// send custom tx
-					nonce := self.snapshotState.GetNonce(account.Address)
-					tmpTx := types.NewTransaction(nonce, account.Address, big.NewInt(0), uint64(100000), big.NewInt(10000), []byte(fmt.Sprintf("ufo:1:event:confirm:%d", blockNumber)))
-					signedTx, err := wallet.SignTx(account, tmpTx, self.dsp.BlockChain().Config().ChainId)
-					if err != nil {
-						return err
-					} else {
-						err = self.dsp.TxPool().AddLocal(signedTx)
-						if err != nil {
-							return err
-						}
-					}
-					return nil
-				}
-			}
-		}
-
+// confirm signs a confirmation of the given block with the local signer and
+// hands it to the confirmation pool for aggregation and propagation.
+func (self *worker) confirm(header *types.Header) {
+	confirmer, ok := self.engine.(consensus.Confirmer)
+	if !ok {
+		return
+	}
+	confirmation, err := confirmer.Confirm(self.chain, header)
+	if err != nil {
+		log.Warn("Failed to confirm block", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	if confirmation == nil {
+		return
+	}
+	if err := self.dsp.ConfirmationPool().Add(confirmation); err != nil && err != core.ErrKnownConfirmation {
+		log.Warn("Failed to add block confirmation", "number", header.Number, "hash", header.Hash(), "err", err)
 	}
-	return nil
 }
 
 func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
@@ -587,7 +564,7 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+func (env *Work) commitTransactions(mux *event.TypeMux, txs core.TxOrdering, bc *core.BlockChain, coinbase common.Address) {
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
 	}
@@ -638,6 +615,11 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
 			txs.Pop()
 
+		case core.ErrTxNotYetValid:
+			// Scheduled transaction released a bit early, skip the rest of its lane
+			log.Trace("Skipping not yet valid scheduled transaction", "sender", from, "nonce", tx.Nonce())
+			txs.Pop()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
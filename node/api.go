@@ -229,6 +229,20 @@ func (api *PrivateAdminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// SetRPCAuth replaces the credentials accepted by the HTTP and WebSocket RPC
+// endpoints, along with the access they grant. The change applies to the already
+// open connections too. Empty credentials disable authentication.
+func (api *PrivateAdminAPI) SetRPCAuth(config rpc.AuthConfig) (bool, error) {
+	api.node.lock.Lock()
+	defer api.node.lock.Unlock()
+
+	if err := api.node.rpcAuth.Update(config); err != nil {
+		return false, err
+	}
+	api.node.config.RPCAuth = &config
+	return true, nil
+}
+
 // PublicAdminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type PublicAdminAPI struct {
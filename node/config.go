@@ -29,10 +29,13 @@ import (
 	"github.com/dsplinz2019/dsplinz/accounts/keystore"
 	"github.com/dsplinz2019/dsplinz/accounts/usbwallet"
 	"github.com/dsplinz2019/dsplinz/common"
+	"github.com/dsplinz2019/dsplinz/core/rawdb"
 	"github.com/dsplinz2019/dsplinz/crypto"
+	"github.com/dsplinz2019/dsplinz/ethdb"
 	"github.com/dsplinz2019/dsplinz/log"
 	"github.com/dsplinz2019/dsplinz/p2p"
 	"github.com/dsplinz2019/dsplinz/p2p/discover"
+	"github.com/dsplinz2019/dsplinz/rpc"
 )
 
 const (
@@ -66,6 +69,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the key-value store backing the databases opened through the
+	// node (e.g. "leveldb" or "bolt"). If empty, existing databases are opened
+	// with the engine they were created with and new ones use LevelDB.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -145,6 +153,40 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// GRPCHost is the host interface on which to start the gRPC server serving the
+	// chain data as protocol buffers. If this field is empty, no gRPC endpoint will
+	// be started. The calls are subject to RPCAuth, RPCLimits and RPCAudit, named
+	// like RPC methods after their service ("dspgrpc.Chain_SendTransaction").
+	GRPCHost string `toml:",omitempty"`
+
+	// GRPCPort is the TCP port number on which to start the gRPC server.
+	GRPCPort int `toml:",omitempty"`
+
+	// GRPCTLSCert and GRPCTLSKey are the PEM encoded certificate and key files the
+	// gRPC server uses for TLS. Without them, calls are served in plain text.
+	GRPCTLSCert string `toml:",omitempty"`
+	GRPCTLSKey  string `toml:",omitempty"`
+
+	// HexPrefix is the prefix the RPC servers of the node return addresses and
+	// hashes with, either "0x" or "t0". If empty, hexutil.HexPrefix is used. RPC
+	// clients may select a different prefix for their own requests with the
+	// X-Hex-Prefix header.
+	HexPrefix string `toml:",omitempty"`
+
+	// RPCAuth restricts the HTTP and WebSocket RPC interfaces to the holders of
+	// the configured JWT secret or API keys, each granted access to a set of
+	// modules and methods. It can be replaced at runtime via admin_setRPCAuth.
+	RPCAuth *rpc.AuthConfig `toml:",omitempty"`
+
+	// RPCLimits throttles the clients of the HTTP and WebSocket RPC interfaces by
+	// the cost of their requests, and caps the size of batches and responses.
+	RPCLimits *rpc.LimitConfig `toml:",omitempty"`
+
+	// RPCAudit records the requests served by the IPC, HTTP and WebSocket RPC
+	// interfaces into an audit log. Relative paths are resolved in the instance
+	// directory.
+	RPCAudit *rpc.AuditConfig `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -224,6 +266,21 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// GRPCEndpoint resolves a gRPC endpoint based on the configured host interface
+// and port parameters.
+func (c *Config) GRPCEndpoint() string {
+	if c.GRPCHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GRPCHost, c.GRPCPort)
+}
+
+// DefaultGRPCEndpoint returns the gRPC endpoint used by default.
+func DefaultGRPCEndpoint() string {
+	config := &Config{GRPCHost: DefaultGRPCHost, GRPCPort: DefaultGRPCPort}
+	return config.GRPCEndpoint()
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
@@ -285,6 +342,31 @@ func (c *Config) resolvePath(path string) string {
 	return filepath.Join(c.instanceDir(), path)
 }
 
+// openDatabaseWithFreezer opens a persistent key-value database in the instance
+// directory with an ancient store attached to it.
+func (c *Config) openDatabaseWithFreezer(name string, cache, handles int, freezer string, depth uint64, namespace string) (ethdb.Database, error) {
+	root := c.resolvePath(name)
+
+	kvdb, err := ethdb.NewDatabase(c.DBEngine, root, cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	if db, ok := kvdb.(interface{ Meter(prefix string) }); ok && namespace != "" {
+		db.Meter(namespace)
+	}
+	if freezer == "" {
+		freezer = filepath.Join(root, "ancient")
+	} else {
+		freezer = c.resolvePath(freezer)
+	}
+	db, err := rawdb.NewDatabaseWithFreezer(kvdb, freezer, namespace, depth)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 func (c *Config) instanceDir() string {
 	if c.DataDir == "" {
 		return ""
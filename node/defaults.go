@@ -31,6 +31,8 @@ const (
 	DefaultHTTPPort = 8545        // Default TCP port for the HTTP RPC server
 	DefaultWSHost   = "localhost" // Default host interface for the websocket RPC server
 	DefaultWSPort   = 8546        // Default TCP port for the websocket RPC server
+	DefaultGRPCHost = "localhost" // Default host interface for the gRPC server
+	DefaultGRPCPort = 8548        // Default TCP port for the gRPC server
 )
 
 // DefaultConfig contains reasonable default settings.
@@ -41,6 +43,7 @@ var DefaultConfig = Config{
 	HTTPVirtualHosts: []string{"localhost"},
 	WSPort:           DefaultWSPort,
 	WSModules:        []string{"net", "web3"},
+	GRPCPort:         DefaultGRPCPort,
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   25,
@@ -0,0 +1,102 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dsplinz2019/dsplinz/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// unaryCallGuard creates an interceptor admitting the unary gRPC calls through
+// guard, recording them along with their request and response.
+func unaryCallGuard(guard *rpc.CallGuard) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		call, err := admitCall(ctx, guard, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		call.End(resp, err)
+		return resp, err
+	}
+}
+
+// streamCallGuard creates an interceptor admitting the streaming gRPC calls
+// through guard. Streams are charged once when opened and recorded once closed,
+// without their messages.
+func streamCallGuard(guard *rpc.CallGuard) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		call, err := admitCall(stream.Context(), guard, info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		err = handler(srv, stream)
+		call.End(nil, err)
+		return err
+	}
+}
+
+// admitCall admits a gRPC call through guard, naming it after the service and
+// method of its full name ("/dspgrpc.Chain/GetBlock" is "dspgrpc.Chain_GetBlock").
+// Rejections are converted into the matching gRPC status errors.
+func admitCall(ctx context.Context, guard *rpc.CallGuard, fullMethod string, req interface{}) (*rpc.Call, error) {
+	service, method := strings.TrimPrefix(fullMethod, "/"), ""
+	if i := strings.LastIndex(service, "/"); i >= 0 {
+		service, method = service[:i], service[i+1:]
+	}
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	call, err := guard.Begin(service, method, grpcCredential(ctx), remote, req)
+	if err == nil {
+		return call, nil
+	}
+	code := codes.Unauthenticated
+	if err, ok := err.(rpc.Error); ok {
+		switch err.ErrorCode() {
+		case -32001:
+			code = codes.PermissionDenied
+		case -32005:
+			code = codes.ResourceExhausted
+		}
+	}
+	return nil, status.Error(code, err.Error())
+}
+
+// grpcCredential extracts the credential of a gRPC call from its metadata, either
+// a bearer token or an API key, like on the HTTP endpoint.
+func grpcCredential(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if keys := md[strings.ToLower(rpc.APIKeyHeader)]; len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	if auth := md["authorization"]; len(auth) > 0 && len(auth[0]) > 7 && strings.EqualFold(auth[0][:7], "Bearer ") {
+		return strings.TrimSpace(auth[0][7:])
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/dsplinz2019/dsplinz/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testServerStream is a server stream only carrying the context of the call.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context { return s.ctx }
+
+// newGRPCContext creates the context of a gRPC call from a remote client sending
+// the given metadata.
+func newGRPCContext(pairs ...string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 30000}})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+}
+
+// Tests that gRPC calls are subject to the credentials and request limits of
+// the RPC endpoints.
+func TestGRPCCallGuard(t *testing.T) {
+	auth, err := rpc.NewAuthenticator(rpc.AuthConfig{APIKeys: map[string][]string{"key": {"test.Chain_GetBlock", "test.Chain_SubscribeNewHeads"}}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	guard := rpc.NewCallGuard(auth, rpc.LimitConfig{}, rpc.NewTokenBucketLimiter(0.001, 2), nil)
+	unary := unaryCallGuard(guard)
+
+	var served int
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		served++
+		return req, nil
+	}
+	getBlock := &grpc.UnaryServerInfo{FullMethod: "/test.Chain/GetBlock"}
+	sendTx := &grpc.UnaryServerInfo{FullMethod: "/test.Chain/SendTransaction"}
+
+	// Ensure calls without valid credentials or access are rejected
+	if _, err := unary(newGRPCContext(), "req", getBlock, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("missing credential error mismatch: have %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err := unary(newGRPCContext("authorization", "Bearer other"), "req", getBlock, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("invalid credential error mismatch: have %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err := unary(newGRPCContext("x-api-key", "key"), "req", sendTx, handler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("denied call error mismatch: have %v, want %v", err, codes.PermissionDenied)
+	}
+	if served != 0 {
+		t.Fatalf("rejected calls served: %d", served)
+	}
+	// Ensure granted calls are served until the client's allowance runs out
+	if resp, err := unary(newGRPCContext("authorization", "Bearer key"), "req", getBlock, handler); err != nil || resp != "req" {
+		t.Fatalf("granted call failed: have %v, %v", resp, err)
+	}
+	stream := &testServerStream{ctx: newGRPCContext("x-api-key", "key")}
+	streamer := func(srv interface{}, stream grpc.ServerStream) error {
+		served++
+		return nil
+	}
+	if err := streamCallGuard(guard)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Chain/SubscribeNewHeads"}, streamer); err != nil {
+		t.Fatalf("granted stream failed: %v", err)
+	}
+	if _, err := unary(newGRPCContext("x-api-key", "key"), "req", getBlock, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("throttled call error mismatch: have %v, want %v", err, codes.ResourceExhausted)
+	}
+	if served != 2 {
+		t.Fatalf("served call count mismatch: have %d, want 2", served)
+	}
+}
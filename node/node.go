@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -27,6 +28,7 @@ import (
 	"sync"
 
 	"github.com/dsplinz2019/dsplinz/accounts"
+	"github.com/dsplinz2019/dsplinz/common/hexutil"
 	"github.com/dsplinz2019/dsplinz/ethdb"
 	"github.com/dsplinz2019/dsplinz/event"
 	"github.com/dsplinz2019/dsplinz/internal/debug"
@@ -34,6 +36,8 @@ import (
 	"github.com/dsplinz2019/dsplinz/p2p"
 	"github.com/dsplinz2019/dsplinz/rpc"
 	"github.com/prometheus/prometheus/util/flock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Node is a container on which services can be registered.
@@ -58,15 +62,24 @@ type Node struct {
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
 
-	httpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
-	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
-	httpListener  net.Listener // HTTP RPC listener socket to server API requests
-	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
+	httpEndpoint  string                  // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
+	httpWhitelist []string                // HTTP RPC modules to allow through this endpoint
+	httpListener  net.Listener            // HTTP RPC listener socket to server API requests
+	httpHandler   *rpc.Server             // HTTP RPC request handler to process the API requests
+	httpPaths     map[string]http.Handler // Handlers of the services serving HTTP paths besides JSON-RPC
 
 	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
+	grpcEndpoint string       // gRPC endpoint (interface + port) to listen at (empty = gRPC disabled)
+	grpcListener net.Listener // gRPC listener socket to serve the chain data
+	grpcServer   *grpc.Server // gRPC server dispatching the calls to the services
+
+	rpcAuth    *rpc.Authenticator // Credential verification of the HTTP, websocket and gRPC endpoints
+	rpcLimiter rpc.Limiter        // Request allowance of the clients, shared by the HTTP, websocket and gRPC endpoints
+	rpcAuditor *rpc.Auditor       // Audit log of the requests served by the IPC, HTTP, websocket and gRPC endpoints
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -97,6 +110,24 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	// The display prefix is applied by this node's RPC servers only, other nodes
+	// in the same process keep their own.
+	if conf.HexPrefix != "" && !hexutil.IsValidHexPrefix(conf.HexPrefix) {
+		return nil, fmt.Errorf("invalid hex prefix %q, want \"0x\" or %q", conf.HexPrefix, hexutil.DefaultHexPrefix)
+	}
+	// Verify the RPC credentials before anything gets exposed.
+	var authConfig rpc.AuthConfig
+	if conf.RPCAuth != nil {
+		authConfig = *conf.RPCAuth
+	}
+	rpcAuth, err := rpc.NewAuthenticator(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	var rpcLimiter rpc.Limiter
+	if conf.RPCLimits != nil && conf.RPCLimits.Rate > 0 {
+		rpcLimiter = rpc.NewTokenBucketLimiter(conf.RPCLimits.Rate, conf.RPCLimits.Burst)
+	}
 	// Ensure that the AccountManager method works before the node has started.
 	// We rely on this in cmd/dsp.
 	am, ephemeralKeystore, err := makeAccountManager(conf)
@@ -116,6 +147,9 @@ func New(conf *Config) (*Node, error) {
 		ipcEndpoint:       conf.IPCEndpoint(),
 		httpEndpoint:      conf.HTTPEndpoint(),
 		wsEndpoint:        conf.WSEndpoint(),
+		grpcEndpoint:      conf.GRPCEndpoint(),
+		rpcAuth:           rpcAuth,
+		rpcLimiter:        rpcLimiter,
 		eventmux:          new(event.TypeMux),
 		log:               conf.Logger,
 	}, nil
@@ -255,23 +289,48 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
+	n.httpPaths = make(map[string]http.Handler)
+	for _, service := range services {
+		if service, ok := service.(HTTPService); ok {
+			for path, handler := range service.HTTPHandlers() {
+				n.httpPaths[path] = handler
+				n.log.Debug("HTTP handler registered", "path", path)
+			}
+		}
+	}
+	// Open the audit log before anything gets served
+	if err := n.startRPCAudit(); err != nil {
+		return err
+	}
 	// Start the various API endpoints, terminating all in case of errors
 	if err := n.startInProc(apis); err != nil {
+		n.stopRPCAudit()
 		return err
 	}
 	if err := n.startIPC(apis); err != nil {
 		n.stopInProc()
+		n.stopRPCAudit()
 		return err
 	}
 	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts); err != nil {
 		n.stopIPC()
 		n.stopInProc()
+		n.stopRPCAudit()
 		return err
 	}
 	if err := n.startWS(n.wsEndpoint, apis, n.config.WSModules, n.config.WSOrigins, n.config.WSExposeAll); err != nil {
 		n.stopHTTP()
 		n.stopIPC()
 		n.stopInProc()
+		n.stopRPCAudit()
+		return err
+	}
+	if err := n.startGRPC(n.grpcEndpoint, services); err != nil {
+		n.stopWS()
+		n.stopHTTP()
+		n.stopIPC()
+		n.stopInProc()
+		n.stopRPCAudit()
 		return err
 	}
 	// All API endpoints started successfully
@@ -283,6 +342,7 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 func (n *Node) startInProc(apis []rpc.API) error {
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	handler.SetHexPrefix(n.config.HexPrefix)
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 			return err
@@ -310,6 +370,8 @@ func (n *Node) startIPC(apis []rpc.API) error {
 	if err != nil {
 		return err
 	}
+	handler.SetAuditor(n.rpcAuditor)
+	handler.SetHexPrefix(n.config.HexPrefix)
 	n.ipcListener = listener
 	n.ipcHandler = handler
 	n.log.Info("IPC endpoint opened", "url", n.ipcEndpoint)
@@ -336,11 +398,13 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, n.rpcAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	n.setRPCLimits(handler)
+	handler.SetHTTPHandlers(n.httpPaths)
+	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "auth", n.rpcAuth.Enabled())
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -369,11 +433,12 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.rpcAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	n.setRPCLimits(handler)
+	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()), "auth", n.rpcAuth.Enabled())
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
@@ -382,6 +447,43 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	return nil
 }
 
+// setRPCLimits configures the request limits, the audit log and the hex prefix
+// of an HTTP or websocket endpoint.
+func (n *Node) setRPCLimits(handler *rpc.Server) {
+	if n.config.RPCLimits != nil {
+		handler.SetLimits(*n.config.RPCLimits, n.rpcLimiter)
+	}
+	handler.SetAuditor(n.rpcAuditor)
+	handler.SetHexPrefix(n.config.HexPrefix)
+}
+
+// startRPCAudit opens the audit log of the RPC endpoints, if configured.
+func (n *Node) startRPCAudit() error {
+	if n.config.RPCAudit == nil || n.config.RPCAudit.Path == "" {
+		return nil
+	}
+	config := *n.config.RPCAudit
+	if path := n.config.resolvePath(config.Path); path != "" {
+		config.Path = path // ephemeral nodes keep relative paths as is
+	}
+
+	auditor, err := rpc.NewAuditor(config)
+	if err != nil {
+		return err
+	}
+	n.rpcAuditor = auditor
+	n.log.Info("RPC audit log opened", "path", config.Path, "payloads", config.Payloads)
+	return nil
+}
+
+// stopRPCAudit closes the audit log of the RPC endpoints.
+func (n *Node) stopRPCAudit() {
+	if n.rpcAuditor != nil {
+		n.rpcAuditor.Close()
+		n.rpcAuditor = nil
+	}
+}
+
 // stopWS terminates the websocket RPC endpoint.
 func (n *Node) stopWS() {
 	if n.wsListener != nil {
@@ -396,6 +498,68 @@ func (n *Node) stopWS() {
 	}
 }
 
+// startGRPC initializes and starts the gRPC endpoint, serving the gRPC services
+// of the node's services. Calls are subject to the same credentials, request
+// limits and audit log as the HTTP and websocket RPC endpoints.
+func (n *Node) startGRPC(endpoint string, services map[reflect.Type]Service) error {
+	// Short circuit if the gRPC endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	var limits rpc.LimitConfig
+	if n.config.RPCLimits != nil {
+		limits = *n.config.RPCLimits
+	}
+	guard := rpc.NewCallGuard(n.rpcAuth, limits, n.rpcLimiter, n.rpcAuditor)
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(unaryCallGuard(guard)),
+		grpc.StreamInterceptor(streamCallGuard(guard)),
+	}
+	if limits.ResponseLimit > 0 {
+		options = append(options, grpc.MaxSendMsgSize(limits.ResponseLimit))
+	}
+	secure := n.config.GRPCTLSCert != "" || n.config.GRPCTLSKey != ""
+	if secure {
+		creds, err := credentials.NewServerTLSFromFile(n.config.GRPCTLSCert, n.config.GRPCTLSKey)
+		if err != nil {
+			return err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	server := grpc.NewServer(options...)
+	for _, service := range services {
+		if service, ok := service.(GRPCService); ok {
+			service.RegisterGRPC(server)
+		}
+	}
+	for name := range server.GetServiceInfo() {
+		n.log.Debug("gRPC registered", "service", name)
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	go server.Serve(listener)
+	n.log.Info("gRPC endpoint opened", "endpoint", listener.Addr(), "tls", secure, "auth", n.rpcAuth.Enabled())
+	// All listeners booted successfully
+	n.grpcEndpoint = endpoint
+	n.grpcListener = listener
+	n.grpcServer = server
+
+	return nil
+}
+
+// stopGRPC terminates the gRPC endpoint.
+func (n *Node) stopGRPC() {
+	if n.grpcServer != nil {
+		n.grpcServer.Stop()
+		n.grpcServer = nil
+		n.grpcListener = nil
+
+		n.log.Info("gRPC endpoint closed", "endpoint", n.grpcEndpoint)
+	}
+}
+
 // Stop terminates a running node along with all it's services. In the node was
 // not started, an error is returned.
 func (n *Node) Stop() error {
@@ -408,9 +572,11 @@ func (n *Node) Stop() error {
 	}
 
 	// Terminate the API, services and the p2p server.
+	n.stopGRPC()
 	n.stopWS()
 	n.stopHTTP()
 	n.stopIPC()
+	n.stopRPCAudit()
 	n.rpcAPIs = nil
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
@@ -570,7 +736,20 @@ func (n *Node) OpenDatabase(name string, cache, handles int) (ethdb.Database, er
 	if n.config.DataDir == "" {
 		return ethdb.NewMemDatabase(), nil
 	}
-	return ethdb.NewLDBDatabase(n.config.resolvePath(name), cache, handles)
+	return ethdb.NewDatabase(n.config.DBEngine, n.config.resolvePath(name), cache, handles)
+}
+
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's instance
+// directory, and attaches an ancient store to it that canonical blocks deeper
+// than depth are moved into. A relative freezer path is resolved within the
+// instance directory, an empty one places the ancient store inside the database
+// directory. If the node is ephemeral, a memory database is returned.
+func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer string, depth uint64, namespace string) (ethdb.Database, error) {
+	if n.config.DataDir == "" {
+		return ethdb.NewMemDatabase(), nil
+	}
+	return n.config.openDatabaseWithFreezer(name, cache, handles, freezer, depth, namespace)
 }
 
 // ResolvePath returns the absolute path of a resource in the instance directory.
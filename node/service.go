@@ -17,6 +17,7 @@
 package node
 
 import (
+	"net/http"
 	"reflect"
 
 	"github.com/dsplinz2019/dsplinz/accounts"
@@ -24,6 +25,7 @@ import (
 	"github.com/dsplinz2019/dsplinz/event"
 	"github.com/dsplinz2019/dsplinz/p2p"
 	"github.com/dsplinz2019/dsplinz/rpc"
+	"google.golang.org/grpc"
 )
 
 // ServiceContext is a collection of service independent options inherited from
@@ -43,11 +45,18 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (et
 	if ctx.config.DataDir == "" {
 		return ethdb.NewMemDatabase(), nil
 	}
-	db, err := ethdb.NewLDBDatabase(ctx.config.resolvePath(name), cache, handles)
-	if err != nil {
-		return nil, err
+	return ethdb.NewDatabase(ctx.config.DBEngine, ctx.config.resolvePath(name), cache, handles)
+}
+
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's data directory,
+// and attaches an ancient store to it that canonical blocks deeper than depth are
+// moved into. If the node is an ephemeral one, a memory database is returned.
+func (ctx *ServiceContext) OpenDatabaseWithFreezer(name string, cache int, handles int, freezer string, depth uint64, namespace string) (ethdb.Database, error) {
+	if ctx.config.DataDir == "" {
+		return ethdb.NewMemDatabase(), nil
 	}
-	return db, nil
+	return ctx.config.openDatabaseWithFreezer(name, cache, handles, freezer, depth, namespace)
 }
 
 // ResolvePath resolves a user path into the data directory if that was relative
@@ -96,3 +105,19 @@ type Service interface {
 	// are all terminated.
 	Stop() error
 }
+
+// GRPCService is an optional interface of the services exposing gRPC services on
+// the node's gRPC endpoint.
+type GRPCService interface {
+	// RegisterGRPC registers the gRPC services provided on the server of the
+	// endpoint, before it starts serving.
+	RegisterGRPC(server *grpc.Server)
+}
+
+// HTTPService is an optional interface of the services serving HTTP paths of the
+// node's HTTP endpoint besides JSON-RPC.
+type HTTPService interface {
+	// HTTPHandlers retrieves the handlers of the HTTP paths served, keyed by the
+	// path (e.g. "/graphql").
+	HTTPHandlers() map[string]http.Handler
+}
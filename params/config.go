@@ -19,7 +19,6 @@ package params
 import (
 	"fmt"
 	"github.com/relianz2019/relianz/common"
-	"github.com/relianz2019/relianz/rpc"
 	"math/big"
 )
 
@@ -145,23 +144,23 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllRlzashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(RlzashConfig), nil, nil}
+	AllRlzashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), new(RlzashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Dsplinz core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
 	// AllAlienProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Dsplinz core developers into the Alien consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllAlienProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &AlienConfig{Period: 3, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
+	AllAlienProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &AlienConfig{Period: 3, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(RlzashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), new(RlzashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -184,6 +183,11 @@ type ChainConfig struct {
 
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
+	PetersburgBlock     *big.Int `json:"petersburgBlock,omitempty"`     // Petersburg switch block, disables SSTORE net gas metering (nil = no fork)
+	SponsoredTxBlock    *big.Int `json:"sponsoredTxBlock,omitempty"`    // Sponsored (fee payer) transactions switch block (nil = no fork, 0 = already activated)
+	BatchTxBlock        *big.Int `json:"batchTxBlock,omitempty"`        // Batch (multi-call) transactions switch block (nil = no fork, 0 = already activated)
+	LaneTxBlock         *big.Int `json:"laneTxBlock,omitempty"`         // Lane (parallel nonce) transactions switch block (nil = no fork, 0 = already activated)
+	ScheduledTxBlock    *big.Int `json:"scheduledTxBlock,omitempty"`    // Scheduled (time-locked) transactions switch block (nil = no fork, 0 = already activated)
 
 	// Various consensus engines
 	Rlzash *RlzashConfig `json:"ethash,omitempty"`
@@ -221,21 +225,28 @@ type AlienLightConfig struct {
 
 // AlienConfig is the consensus engine configs for delegated-proof-of-stake based sealing.
 type AlienConfig struct {
-	Period           uint64                     `json:"period"`           // Number of seconds between blocks to enforce
-	Epoch            uint64                     `json:"epoch"`            // Epoch length to reset votes and checkpoint
-	MaxSignerCount   uint64                     `json:"maxSignersCount"`  // Max count of signers
-	MinVoterBalance  *big.Int                   `json:"minVoterBalance"`  // Min voter balance to valid this vote
-	GenesisTimestamp uint64                     `json:"genesisTimestamp"` // The LoopStartTime of first Block
-	SelfVoteSigners  []common.UnprefixedAddress `json:"signers"`          // Signers vote by themselves to seal the block, make sure the signer accounts are pre-funded
-	SideChain        bool                       `json:"sideChain"`        // If side chain or not
-	MCRPCClient      *rpc.Client                // Main chain rpc client for side chain
-	PBFTEnable       bool                       `json:"pbft"` //
+	Period             uint64                     `json:"period"`                       // Number of seconds between blocks to enforce
+	Epoch              uint64                     `json:"epoch"`                        // Epoch length to reset votes and checkpoint
+	MaxSignerCount     uint64                     `json:"maxSignersCount"`              // Max count of signers
+	MinVoterBalance    *big.Int                   `json:"minVoterBalance"`              // Min voter balance to valid this vote
+	GenesisTimestamp   uint64                     `json:"genesisTimestamp"`             // The LoopStartTime of first Block
+	SelfVoteSigners    []common.UnprefixedAddress `json:"signers"`                      // Signers vote by themselves to seal the block, make sure the signer accounts are pre-funded
+	SideChain          bool                       `json:"sideChain"`                    // If side chain or not
+	CheckpointInterval uint64                     `json:"checkpointInterval,omitempty"` // Side chain blocks between two main chain checkpoints (0 = default)
+	SideChains         []AlienSideChain           `json:"sideChains,omitempty"`         // Side chains anchoring their blocks to this chain
+	PBFTEnable         bool                       `json:"pbft"`                         //
 
 	TrantorBlock  *big.Int          `json:"trantorBlock,omitempty"`  // Trantor switch block (nil = no fork)
 	TerminusBlock *big.Int          `json:"terminusBlock,omitempty"` // Terminus switch block (nil = no fork)
 	LightConfig   *AlienLightConfig `json:"lightConfig,omitempty"`
 }
 
+// AlienSideChain is a side chain anchoring its blocks to an alien main chain.
+type AlienSideChain struct {
+	Genesis common.Hash      `json:"genesis"` // Genesis hash identifying the side chain
+	Signers []common.Address `json:"signers"` // Side chain signers allowed to submit checkpoints
+}
+
 // String implements the stringer interface, returning the consensus engine details.
 func (a *AlienConfig) String() string {
 	return "alien"
@@ -264,7 +275,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v SponsoredTx: %v BatchTx: %v LaneTx: %v ScheduledTx: %v Engine: %v}",
 		c.ChainId,
 		c.HomesteadBlock,
 		c.EIP150Block,
@@ -272,6 +283,11 @@ func (c *ChainConfig) String() string {
 		c.EIP158Block,
 		c.ByzantiumBlock,
 		c.ConstantinopleBlock,
+		c.PetersburgBlock,
+		c.SponsoredTxBlock,
+		c.BatchTxBlock,
+		c.LaneTxBlock,
+		c.ScheduledTxBlock,
 		engine,
 	)
 }
@@ -301,6 +317,35 @@ func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// IsPetersburg returns whether num is either equal to the Petersburg fork block or greater.
+func (c *ChainConfig) IsPetersburg(num *big.Int) bool {
+	return isForked(c.PetersburgBlock, num)
+}
+
+// IsSponsoredTx returns whether num is either equal to the sponsored transaction
+// fork block or greater.
+func (c *ChainConfig) IsSponsoredTx(num *big.Int) bool {
+	return isForked(c.SponsoredTxBlock, num)
+}
+
+// IsBatchTx returns whether num is either equal to the batch transaction fork
+// block or greater.
+func (c *ChainConfig) IsBatchTx(num *big.Int) bool {
+	return isForked(c.BatchTxBlock, num)
+}
+
+// IsLaneTx returns whether num is either equal to the lane transaction fork block
+// or greater.
+func (c *ChainConfig) IsLaneTx(num *big.Int) bool {
+	return isForked(c.LaneTxBlock, num)
+}
+
+// IsScheduledTx returns whether num is either equal to the scheduled transaction
+// fork block or greater.
+func (c *ChainConfig) IsScheduledTx(num *big.Int) bool {
+	return isForked(c.ScheduledTxBlock, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -309,6 +354,8 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 		return GasTableHomestead
 	}
 	switch {
+	case c.IsConstantinople(num):
+		return GasTableConstantinople
 	case c.IsEIP158(num):
 		return GasTableEIP158
 	case c.IsEIP150(num):
@@ -358,6 +405,26 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	if isForkIncompatible(c.PetersburgBlock, newcfg.PetersburgBlock, head) {
+		return newCompatError("Petersburg fork block", c.PetersburgBlock, newcfg.PetersburgBlock)
+	}
+	if isForkIncompatible(c.SponsoredTxBlock, newcfg.SponsoredTxBlock, head) {
+		return newCompatError("SponsoredTx fork block", c.SponsoredTxBlock, newcfg.SponsoredTxBlock)
+	}
+	if isForkIncompatible(c.BatchTxBlock, newcfg.BatchTxBlock, head) {
+		return newCompatError("BatchTx fork block", c.BatchTxBlock, newcfg.BatchTxBlock)
+	}
+	if isForkIncompatible(c.LaneTxBlock, newcfg.LaneTxBlock, head) {
+		return newCompatError("LaneTx fork block", c.LaneTxBlock, newcfg.LaneTxBlock)
+	}
+	if isForkIncompatible(c.ScheduledTxBlock, newcfg.ScheduledTxBlock, head) {
+		return newCompatError("ScheduledTx fork block", c.ScheduledTxBlock, newcfg.ScheduledTxBlock)
+	}
+	if c.Alien != nil || newcfg.Alien != nil {
+		if err := c.EngineForks().checkCompatible(newcfg.EngineForks(), "Alien", head); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -422,9 +489,9 @@ func (err *ConfigCompatError) Error() string {
 // Rules is a one time interface meaning that it shouldn't be used in between transition
 // phases.
 type Rules struct {
-	ChainId                                   *big.Int
-	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
-	IsByzantium                               bool
+	ChainId                                     *big.Int
+	IsHomestead, IsEIP150, IsEIP155, IsEIP158   bool
+	IsByzantium, IsConstantinople, IsPetersburg bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -432,5 +499,5 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num), IsConstantinople: c.IsConstantinople(num), IsPetersburg: c.IsPetersburg(num)}
 }
@@ -70,6 +70,45 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(10)}},
+			new:     &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(20)}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(10)}},
+			new:    &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(20)}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Alien Trantor fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(10), TerminusBlock: big.NewInt(30)}},
+			new:    &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(10)}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "Alien Terminus fork block",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    nil,
+				RewindTo:     29,
+			},
+		},
+		{
+			stored: &ChainConfig{Alien: &AlienConfig{TrantorBlock: big.NewInt(10)}},
+			new:    &ChainConfig{Clique: &CliqueConfig{}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "Alien Trantor fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +118,28 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	tests := []struct {
+		config  *AlienConfig
+		wantErr bool
+	}{
+		{&AlienConfig{}, false},
+		{&AlienConfig{TrantorBlock: big.NewInt(5)}, false},
+		{&AlienConfig{TrantorBlock: big.NewInt(5), TerminusBlock: big.NewInt(5)}, false},
+		{&AlienConfig{TrantorBlock: big.NewInt(5), TerminusBlock: big.NewInt(10)}, false},
+		{&AlienConfig{TrantorBlock: big.NewInt(10), TerminusBlock: big.NewInt(5)}, true},
+		{&AlienConfig{TerminusBlock: big.NewInt(5)}, true},
+	}
+	for i, test := range tests {
+		err := (&ChainConfig{Alien: test.config}).CheckConfigForkOrder()
+		if (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+		if err != nil {
+			if _, ok := err.(*ForkOrderError); !ok {
+				t.Errorf("test %d: unexpected error type %T", i, err)
+			}
+		}
+	}
+}
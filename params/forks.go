@@ -0,0 +1,138 @@
+// Copyright 2019 The go-dsplinz Authors
+// This file is part of the go-dsplinz library.
+//
+// The go-dsplinz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dsplinz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dsplinz library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Fork is a hard fork of a consensus engine, activated at a block number.
+type Fork struct {
+	Name  string   // Human readable name of the fork, e.g. "Trantor"
+	Block *big.Int // Activation block of the fork (nil = not scheduled)
+}
+
+// ForkSchedule is the list of engine specific forks of a chain, in the order
+// they must activate in.
+type ForkSchedule []Fork
+
+// ForkOrderError is returned if a fork of a schedule is set to activate before
+// one of its predecessors.
+type ForkOrderError struct {
+	Fork, Previous           string
+	ForkBlock, PreviousBlock *big.Int
+}
+
+func (err *ForkOrderError) Error() string {
+	if err.PreviousBlock == nil {
+		return fmt.Sprintf("unsupported fork ordering: %s enabled at %d, but %s is not scheduled", err.Fork, err.ForkBlock, err.Previous)
+	}
+	return fmt.Sprintf("unsupported fork ordering: %s enabled at %d, before %s at %d", err.Fork, err.ForkBlock, err.Previous, err.PreviousBlock)
+}
+
+// Lookup returns the activation block of the named fork, or nil if the fork is
+// unknown or not scheduled.
+func (s ForkSchedule) Lookup(name string) *big.Int {
+	for _, fork := range s {
+		if fork.Name == name {
+			return fork.Block
+		}
+	}
+	return nil
+}
+
+// CheckOrder verifies that every scheduled fork activates at or after the forks
+// preceding it, and that no fork is scheduled while one of its predecessors is not.
+func (s ForkSchedule) CheckOrder() error {
+	for i := 1; i < len(s); i++ {
+		prev, cur := s[i-1], s[i]
+		if cur.Block == nil {
+			continue
+		}
+		if prev.Block == nil || prev.Block.Cmp(cur.Block) > 0 {
+			return &ForkOrderError{Fork: cur.Name, Previous: prev.Name, ForkBlock: cur.Block, PreviousBlock: prev.Block}
+		}
+	}
+	return nil
+}
+
+// checkCompatible returns an error if any fork of the schedule would be moved
+// across the given head by switching to the new schedule. Forks are matched by
+// name, a fork missing from the new schedule is treated as unscheduled.
+func (s ForkSchedule) checkCompatible(newsched ForkSchedule, engine string, head *big.Int) *ConfigCompatError {
+	names := make(map[string]bool)
+	for _, sched := range []ForkSchedule{s, newsched} {
+		for _, fork := range sched {
+			if names[fork.Name] {
+				continue
+			}
+			names[fork.Name] = true
+
+			stored, updated := s.Lookup(fork.Name), newsched.Lookup(fork.Name)
+			if isForkIncompatible(stored, updated, head) {
+				return newCompatError(fmt.Sprintf("%s %s fork block", engine, fork.Name), stored, updated)
+			}
+		}
+	}
+	return nil
+}
+
+// Forks returns the hard forks of the alien engine in activation order.
+func (a *AlienConfig) Forks() ForkSchedule {
+	if a == nil {
+		return nil
+	}
+	return ForkSchedule{
+		{Name: "Trantor", Block: a.TrantorBlock},
+		{Name: "Terminus", Block: a.TerminusBlock},
+	}
+}
+
+// EngineForks returns the hard forks of the configured consensus engine in
+// activation order, or nil if the engine schedules no forks of its own.
+func (c *ChainConfig) EngineForks() ForkSchedule {
+	if c.Alien != nil {
+		return c.Alien.Forks()
+	}
+	return nil
+}
+
+// CheckConfigForkOrder checks that the engine specific forks of the chain
+// configuration are scheduled in their required order.
+func (c *ChainConfig) CheckConfigForkOrder() error {
+	return c.EngineForks().CheckOrder()
+}
+
+// ForkSchedule returns the complete fork schedule of the chain, protocol forks
+// first and engine specific forks after, for display purposes.
+func (c *ChainConfig) ForkSchedule() ForkSchedule {
+	sched := ForkSchedule{
+		{Name: "Homestead", Block: c.HomesteadBlock},
+		{Name: "EIP150", Block: c.EIP150Block},
+		{Name: "EIP155", Block: c.EIP155Block},
+		{Name: "EIP158", Block: c.EIP158Block},
+		{Name: "Byzantium", Block: c.ByzantiumBlock},
+		{Name: "Constantinople", Block: c.ConstantinopleBlock},
+		{Name: "Petersburg", Block: c.PetersburgBlock},
+		{Name: "SponsoredTx", Block: c.SponsoredTxBlock},
+		{Name: "BatchTx", Block: c.BatchTxBlock},
+		{Name: "LaneTx", Block: c.LaneTxBlock},
+		{Name: "ScheduledTx", Block: c.ScheduledTxBlock},
+	}
+	return append(sched, c.EngineForks()...)
+}
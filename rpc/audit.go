@@ -0,0 +1,294 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relianz2019/relianz/log"
+)
+
+// AuditConfig configures the audit log of an RPC server, recording the requests
+// it serves as JSON lines of AuditRecords.
+type AuditConfig struct {
+	// Path is the file the records are appended to.
+	Path string `toml:",omitempty"`
+
+	// Methods restricts the log to the listed modules ("eth") and methods
+	// ("eth_sendRawTransaction"), an empty list recording everything. Exclude
+	// lists the modules and methods never recorded.
+	Methods []string `toml:",omitempty"`
+	Exclude []string `toml:",omitempty"`
+
+	// Payloads records the parameters and results of the requests too, needed to
+	// replay them. Otherwise only their hashes are recorded.
+	Payloads bool `toml:",omitempty"`
+
+	// MaxSize is the size in bytes above which the log is rotated, zero meaning
+	// never. MaxFiles is the number of rotated logs kept around, zero dropping
+	// the log when rotating.
+	MaxSize  int64 `toml:",omitempty"`
+	MaxFiles int   `toml:",omitempty"`
+}
+
+// AuditRecord is the audit log entry of a request. Hashes are the SHA256 of the
+// JSON encoding of the parameters and results.
+type AuditRecord struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	ID         json.RawMessage `json:"id,omitempty"`
+	ParamsHash string          `json:"paramsHash"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Caller     string          `json:"caller,omitempty"`     // Remote address of the client, empty for local ones
+	Credential string          `json:"credential,omitempty"` // Fingerprint of the credential the client authenticated with
+	Latency    time.Duration   `json:"latency"`
+	ErrorCode  int             `json:"errorCode,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ResultHash string          `json:"resultHash,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+}
+
+// Auditor writes the audit records of one or more RPC servers to a log file,
+// rotating it once it grows too large.
+type Auditor struct {
+	config  AuditConfig
+	include map[string]bool // Modules and methods recorded, nil for all
+	exclude map[string]bool // Modules and methods never recorded
+
+	file *os.File // Currently open log file
+	size int64    // Size of the current log file
+	lock sync.Mutex
+}
+
+// NewAuditor creates an auditor appending to the configured log file.
+func NewAuditor(config AuditConfig) (*Auditor, error) {
+	if config.Path == "" {
+		return nil, errors.New("missing audit log path")
+	}
+	a := &Auditor{config: config, exclude: make(map[string]bool)}
+	if len(config.Methods) > 0 {
+		a.include = make(map[string]bool)
+		for _, name := range config.Methods {
+			a.include[name] = true
+		}
+	}
+	for _, name := range config.Exclude {
+		a.exclude[name] = true
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// open opens the log file for appending.
+func (a *Auditor) open() error {
+	file, err := os.OpenFile(a.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	a.file, a.size = file, info.Size()
+	return nil
+}
+
+// Close closes the log file, dropping any records written afterwards.
+func (a *Auditor) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// records reports whether requests of the given method are recorded.
+func (a *Auditor) records(method string) bool {
+	module := method
+	if i := strings.Index(method, serviceMethodSeparator); i >= 0 {
+		module = method[:i]
+	}
+	if a.exclude[module] || a.exclude[method] {
+		return false
+	}
+	return a.include == nil || a.include["*"] || a.include[module] || a.include[method]
+}
+
+// write appends a record to the log, rotating it first if it's too large.
+func (a *Auditor) write(record *AuditRecord) error {
+	blob, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	blob = append(blob, '\n')
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		return errors.New("audit log closed")
+	}
+	if a.config.MaxSize > 0 && a.size > 0 && a.size+int64(len(blob)) > a.config.MaxSize {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := a.file.Write(blob)
+	a.size += int64(n)
+	return err
+}
+
+// rotate moves the log file to path.1, shifting the previously rotated ones and
+// dropping the oldest, then starts a new log file.
+func (a *Auditor) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	a.file = nil
+
+	if a.config.MaxFiles > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", a.config.Path, a.config.MaxFiles))
+		for i := a.config.MaxFiles - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", a.config.Path, i), fmt.Sprintf("%s.%d", a.config.Path, i+1))
+		}
+		if err := os.Rename(a.config.Path, a.config.Path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(a.config.Path); err != nil {
+		return err
+	}
+	return a.open()
+}
+
+// SetAuditor configures the auditor recording the requests served, nil to stop
+// recording them.
+func (s *Server) SetAuditor(auditor *Auditor) {
+	s.auditor.Store(&auditorRef{auditor})
+}
+
+// auditorRef wraps the auditor of a server, as atomic.Value can't store nil.
+type auditorRef struct {
+	auditor *Auditor
+}
+
+// currentAuditor returns the auditor set with SetAuditor, nil if none.
+func (s *Server) currentAuditor() *Auditor {
+	ref, _ := s.auditor.Load().(*auditorRef)
+	if ref == nil {
+		return nil
+	}
+	return ref.auditor
+}
+
+// audit records a served request along with its response.
+func (a *Auditor) audit(ctx context.Context, req *serverRequest, start time.Time, response interface{}) {
+	if a == nil || !a.records(req.method) {
+		return
+	}
+	record := &AuditRecord{
+		Time:    start,
+		Method:  req.method,
+		Latency: time.Since(start),
+	}
+	if id, err := json.Marshal(req.id); err == nil {
+		record.ID = id
+	}
+	if remote, ok := ctx.Value("remote").(string); ok {
+		record.Caller = remote
+	}
+	if session, _ := ctx.Value(authSessionKey{}).(*authSession); session != nil {
+		record.Credential = auditFingerprint(session.credential)
+	}
+	params, _ := req.params.(json.RawMessage)
+
+	var result []byte
+	switch res := response.(type) {
+	case *jsonErrResponse:
+		record.ErrorCode, record.Error = res.Error.Code, res.Error.Message
+	case *jsonSuccessResponse:
+		var err error
+		if result, err = json.Marshal(res.Result); err != nil {
+			result = nil // encoding errors are reported by the codec
+		}
+	}
+	a.submit(record, params, result)
+}
+
+// submit fills in the hashes of the parameters and result of a record, and the
+// payloads themselves if configured, then appends it to the log. A nil result is
+// left out, as the calls failing or their encoding failing have none.
+func (a *Auditor) submit(record *AuditRecord, params, result []byte) {
+	record.ParamsHash = AuditHash(params)
+	if a.config.Payloads {
+		record.Params = params
+	}
+	if result != nil {
+		record.ResultHash = AuditHash(result)
+		if a.config.Payloads {
+			record.Result = result
+		}
+	}
+	if err := a.write(record); err != nil {
+		log.Warn("Failed to write RPC audit record", "method", record.Method, "err", err)
+	}
+}
+
+// auditFingerprint returns the fingerprint a credential is recorded with, empty
+// if there's no credential.
+func auditFingerprint(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	return AuditHash([]byte(credential))[:16]
+}
+
+// AuditHash returns the hex encoded SHA256 of a blob, as recorded in the audit
+// records.
+func AuditHash(blob []byte) string {
+	hash := sha256.Sum256(blob)
+	return hex.EncodeToString(hash[:])
+}
+
+// requestMethod returns the full name of the method a request calls, as sent by
+// the client.
+func requestMethod(r rpcRequest) string {
+	switch {
+	case r.isPubSub && r.service == "":
+		return r.method // unsubscribe, the method holds the full name
+	case r.isPubSub:
+		return r.service + subscribeMethodSuffix
+	case r.service == "":
+		return r.method
+	}
+	return r.service + serviceMethodSeparator + r.method
+}
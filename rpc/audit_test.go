@@ -0,0 +1,129 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readAuditLog parses the records of an audit log file.
+func readAuditLog(t *testing.T, path string) []*AuditRecord {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var records []*AuditRecord
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		record := new(AuditRecord)
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			t.Fatalf("invalid audit record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-audit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	auditor, err := NewAuditor(AuditConfig{Path: filepath.Join(dir, "audit.log"), Exclude: []string{"service_rets"}, Payloads: true})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	srv := newTestServer("service", new(Service))
+	defer srv.Stop()
+	srv.SetAuditor(auditor)
+
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	echo := `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["hello",10,{"S":"world"}]}`
+	rets := `{"jsonrpc":"2.0","id":2,"method":"service_rets","params":[]}`
+	fail := `{"jsonrpc":"2.0","id":3,"method":"service_missing","params":[1]}`
+	postLimitedRequest(t, hs.URL, "["+echo+","+rets+","+fail+"]")
+
+	auditor.Close()
+	records := readAuditLog(t, filepath.Join(dir, "audit.log"))
+	if len(records) != 2 {
+		t.Fatalf("record count mismatch: have %d, want 2", len(records))
+	}
+	// Ensure successful calls are recorded with their payloads and caller
+	record := records[0]
+	if record.Method != "service_echo" || string(record.ID) != "1" {
+		t.Errorf("record method mismatch: have %s (id %s), want service_echo (id 1)", record.Method, record.ID)
+	}
+	if params := `["hello",10,{"S":"world"}]`; string(record.Params) != params || record.ParamsHash != AuditHash([]byte(params)) {
+		t.Errorf("record params mismatch: have %s (hash %s)", record.Params, record.ParamsHash)
+	}
+	if record.Result == nil || record.ResultHash != AuditHash(record.Result) || record.Error != "" {
+		t.Errorf("record result mismatch: have %s (hash %s), error %q", record.Result, record.ResultHash, record.Error)
+	}
+	if !strings.HasPrefix(record.Caller, "127.0.0.1:") {
+		t.Errorf("record caller mismatch: have %q", record.Caller)
+	}
+	// Ensure failures are recorded with their error
+	if record = records[1]; record.Method != "service_missing" || record.ErrorCode != -32601 || record.Result != nil {
+		t.Errorf("failure record mismatch: have %s, code %d, result %s", record.Method, record.ErrorCode, record.Result)
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-audit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	auditor, err := NewAuditor(AuditConfig{Path: path, MaxSize: 512, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := auditor.write(&AuditRecord{Method: "service_echo", ParamsHash: AuditHash(nil)}); err != nil {
+			t.Fatalf("record %d: failed to write: %v", i, err)
+		}
+	}
+	auditor.Close()
+
+	// Ensure the logs stay within the size limit, and only the newest ones are kept
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("missing log file %s: %v", name, err)
+		}
+		if info.Size() > 512 {
+			t.Errorf("log file %s too large: %d bytes", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("log file beyond the limit kept: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/relianz2019/relianz/common/hexutil"
+	"github.com/relianz2019/relianz/log"
+)
+
+// APIKeyHeader is the HTTP header (or websocket handshake header) clients can
+// pass a static API key in, as an alternative to the Authorization header.
+const APIKeyHeader = "X-API-Key"
+
+// allowClaim is the JWT claim restricting the modules and methods a bearer token
+// grants access to.
+const allowClaim = "allow"
+
+var (
+	errMissingCredentials = errors.New("missing credentials")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+// AuthConfig configures the credentials accepted by the HTTP and WebSocket RPC
+// endpoints. Without any credentials configured, authentication is disabled.
+//
+// Access is granted per module ("eth") or per method ("eth_sendRawTransaction"),
+// "*" grants access to everything.
+type AuthConfig struct {
+	// JWTSecret is the hex encoded secret HS256 bearer tokens are signed with.
+	// Tokens can restrict their access with an "allow" claim listing modules and
+	// methods, tokens without one are granted access to everything.
+	JWTSecret string `toml:",omitempty"`
+
+	// APIKeys maps the static API keys to the modules and methods they grant
+	// access to. Keys are sent as bearer tokens or in the X-API-Key header.
+	APIKeys map[string][]string `toml:",omitempty"`
+}
+
+// grant is the set of modules and methods a credential gives access to.
+type grant map[string]bool
+
+// newGrant creates a grant out of a list of modules and methods.
+func newGrant(allowed []string) grant {
+	g := make(grant)
+	for _, name := range allowed {
+		g[name] = true
+	}
+	return g
+}
+
+// allows reports whether the grant gives access to the given method. The meta
+// information module is always accessible.
+func (g grant) allows(service, method string) bool {
+	if service == MetadataApi {
+		return true
+	}
+	return g["*"] || g[service] || g[service+serviceMethodSeparator+method]
+}
+
+// Authenticator verifies the credentials of RPC requests and tracks what they
+// grant access to. Its configuration can be replaced while serving requests, the
+// new one applying to the already open connections too.
+type Authenticator struct {
+	secret []byte           // Secret of the HS256 bearer tokens, nil if disabled
+	keys   map[string]grant // Access granted by the static API keys
+	lock   sync.RWMutex
+}
+
+// NewAuthenticator creates an authenticator accepting the given credentials.
+func NewAuthenticator(config AuthConfig) (*Authenticator, error) {
+	auth := new(Authenticator)
+	if err := auth.Update(config); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// Update replaces the accepted credentials.
+func (a *Authenticator) Update(config AuthConfig) error {
+	var secret []byte
+	if config.JWTSecret != "" {
+		blob, err := hexutil.Decode(config.JWTSecret)
+		if err != nil {
+			return fmt.Errorf("invalid JWT secret: %v", err)
+		}
+		if len(blob) < 32 {
+			return fmt.Errorf("JWT secret too short: have %d bytes, want at least 32", len(blob))
+		}
+		secret = blob
+	}
+	keys := make(map[string]grant, len(config.APIKeys))
+	for key, allowed := range config.APIKeys {
+		if key == "" {
+			return errors.New("empty API key")
+		}
+		keys[key] = newGrant(allowed)
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.secret, a.keys = secret, keys
+	return nil
+}
+
+// Enabled reports whether any credentials are configured. If not, all requests
+// are served unauthenticated.
+func (a *Authenticator) Enabled() bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.secret != nil || len(a.keys) > 0
+}
+
+// authorize verifies a credential, returning the access it grants.
+func (a *Authenticator) authorize(credential string) (grant, error) {
+	if credential == "" {
+		return nil, errMissingCredentials
+	}
+	a.lock.RLock()
+	secret, keys := a.secret, a.keys
+	a.lock.RUnlock()
+
+	if g, ok := keys[credential]; ok {
+		return g, nil
+	}
+	if secret == nil {
+		return nil, errInvalidCredentials
+	}
+	token, err := jwt.Parse(credential, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errInvalidCredentials
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	allowed, ok := claims[allowClaim]
+	if !ok {
+		return grant{"*": true}, nil
+	}
+	list, ok := allowed.([]interface{})
+	if !ok {
+		return nil, errInvalidCredentials
+	}
+	g := make(grant, len(list))
+	for _, name := range list {
+		name, ok := name.(string)
+		if !ok {
+			return nil, errInvalidCredentials
+		}
+		g[name] = true
+	}
+	return g, nil
+}
+
+// requestCredential extracts the credential of an HTTP request, either a bearer
+// token or an API key.
+func requestCredential(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// authSessionKey is the context key of the authSession of a request.
+type authSessionKey struct{}
+
+// authSession is the credential a connection was authenticated with. It's
+// reauthorized on every request, so configuration updates apply immediately.
+type authSession struct {
+	auth       *Authenticator
+	credential string
+}
+
+// grant returns the access currently granted to the session, nothing if its
+// credential got revoked in the meantime. A nil session is granted everything.
+func (s *authSession) grant() grant {
+	if s == nil || !s.auth.Enabled() {
+		return nil
+	}
+	g, err := s.auth.authorize(s.credential)
+	if err != nil {
+		return grant{}
+	}
+	return g
+}
+
+// withAuthSession copies the authentication session of an HTTP request over to
+// the context requests are served with.
+func withAuthSession(ctx context.Context, r *http.Request) context.Context {
+	if session, ok := r.Context().Value(authSessionKey{}).(*authSession); ok {
+		return context.WithValue(ctx, authSessionKey{}, session)
+	}
+	return ctx
+}
+
+// authHandler is a handler rejecting the HTTP requests (and websocket handshakes)
+// without valid credentials.
+type authHandler struct {
+	auth *Authenticator
+	next http.Handler
+}
+
+// newAuthHandler wraps next with credential verification, unless auth is nil.
+func newAuthHandler(auth *Authenticator, next http.Handler) http.Handler {
+	if auth == nil {
+		return next
+	}
+	return &authHandler{auth, next}
+}
+
+// ServeHTTP implements http.Handler, authenticating the request.
+func (h *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Health-checks don't need credentials, but websocket handshakes look alike
+	if isHealthCheck(r) && r.Header.Get("Upgrade") == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	credential := requestCredential(r)
+	if h.auth.Enabled() {
+		if _, err := h.auth.authorize(credential); err != nil {
+			log.Debug("Rejected unauthenticated RPC request", "remote", r.RemoteAddr, "err", err)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	// Track the session even if authentication is disabled, so long lived
+	// connections get restricted if it's enabled later on
+	session := &authSession{auth: h.auth, credential: credential}
+	h.next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authSessionKey{}, session)))
+}
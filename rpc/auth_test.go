@@ -0,0 +1,218 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/net/websocket"
+)
+
+var testJWTSecret = bytes.Repeat([]byte{0x42}, 32)
+
+// newTestAuthServer creates an RPC server with two modules, serving the Echo
+// method of the test service.
+func newTestAuthServer(t *testing.T) *Server {
+	srv := newTestServer("service", new(Service))
+	if err := srv.RegisterName("other", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// newTestAuthenticator creates an authenticator with a restricted and a full
+// access API key, also accepting bearer tokens signed with testJWTSecret.
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	auth, err := NewAuthenticator(AuthConfig{
+		JWTSecret: "0x" + strings.Repeat("42", 32),
+		APIKeys: map[string][]string{
+			"reader": {"service_echo"},
+			"admin":  {"*"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return auth
+}
+
+// signTestToken creates an HS256 bearer token with the given claims.
+func signTestToken(t *testing.T, secret []byte, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// postTestRequest calls method on the HTTP server with the given headers set,
+// returning the status code and the JSON-RPC error code (0 if successful).
+func postTestRequest(t *testing.T, url string, method string, headers map[string]string) (int, int) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":["hello",10,{"S":"world"}]}`
+	if method == "rpc_modules" {
+		body = `{"jsonrpc":"2.0","id":1,"method":"rpc_modules","params":[]}`
+	}
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, 0
+	}
+	blob, _ := ioutil.ReadAll(resp.Body)
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(blob, &msg); err != nil {
+		t.Fatalf("invalid response %q: %v", blob, err)
+	}
+	if msg.Error != nil {
+		return resp.StatusCode, msg.Error.Code
+	}
+	return resp.StatusCode, 0
+}
+
+func TestHTTPAuth(t *testing.T) {
+	srv := newTestAuthServer(t)
+	defer srv.Stop()
+
+	auth := newTestAuthenticator(t)
+	hs := httptest.NewServer(newHTTPServer(nil, nil, auth, srv).Handler)
+	defer hs.Close()
+
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+	tests := []struct {
+		method  string
+		headers map[string]string
+		status  int
+		code    int
+	}{
+		// Missing and unknown credentials are rejected
+		{"service_echo", nil, http.StatusUnauthorized, 0},
+		{"service_echo", map[string]string{APIKeyHeader: "unknown"}, http.StatusUnauthorized, 0},
+		{"service_echo", bearer("unknown"), http.StatusUnauthorized, 0},
+
+		// API keys are restricted to their modules and methods
+		{"service_echo", map[string]string{APIKeyHeader: "reader"}, http.StatusOK, 0},
+		{"service_echo", bearer("reader"), http.StatusOK, 0},
+		{"other_echo", map[string]string{APIKeyHeader: "reader"}, http.StatusOK, -32001},
+		{"rpc_modules", map[string]string{APIKeyHeader: "reader"}, http.StatusOK, 0},
+		{"other_echo", map[string]string{APIKeyHeader: "admin"}, http.StatusOK, 0},
+
+		// Bearer tokens are restricted to their allow claim, if any
+		{"other_echo", bearer(signTestToken(t, testJWTSecret, jwt.MapClaims{})), http.StatusOK, 0},
+		{"other_echo", bearer(signTestToken(t, testJWTSecret, jwt.MapClaims{"allow": []string{"other"}})), http.StatusOK, 0},
+		{"service_echo", bearer(signTestToken(t, testJWTSecret, jwt.MapClaims{"allow": []string{"other"}})), http.StatusOK, -32001},
+
+		// Forged and expired bearer tokens are rejected
+		{"service_echo", bearer(signTestToken(t, []byte("forged"), jwt.MapClaims{})), http.StatusUnauthorized, 0},
+		{"service_echo", bearer(signTestToken(t, testJWTSecret, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), http.StatusUnauthorized, 0},
+	}
+	for i, tt := range tests {
+		status, code := postTestRequest(t, hs.URL, tt.method, tt.headers)
+		if status != tt.status || code != tt.code {
+			t.Errorf("test %d: %s: result mismatch: have %d/%d, want %d/%d", i, tt.method, status, code, tt.status, tt.code)
+		}
+	}
+	// Revoke the restricted key and ensure it's rejected from now on
+	if err := auth.Update(AuthConfig{APIKeys: map[string][]string{"admin": {"*"}}}); err != nil {
+		t.Fatalf("failed to update credentials: %v", err)
+	}
+	if status, _ := postTestRequest(t, hs.URL, "service_echo", map[string]string{APIKeyHeader: "reader"}); status != http.StatusUnauthorized {
+		t.Errorf("revoked key status mismatch: have %d, want %d", status, http.StatusUnauthorized)
+	}
+	// Disable authentication altogether and ensure anything goes
+	if err := auth.Update(AuthConfig{}); err != nil {
+		t.Fatalf("failed to disable authentication: %v", err)
+	}
+	if status, code := postTestRequest(t, hs.URL, "other_echo", nil); status != http.StatusOK || code != 0 {
+		t.Errorf("unauthenticated result mismatch: have %d/%d, want %d/%d", status, code, http.StatusOK, 0)
+	}
+}
+
+func TestWebsocketAuth(t *testing.T) {
+	srv := newTestAuthServer(t)
+	defer srv.Stop()
+
+	auth := newTestAuthenticator(t)
+	hs := httptest.NewServer(srv.websocketHandler([]string{"*"}, auth))
+	defer hs.Close()
+
+	dial := func(key string) (*websocket.Conn, error) {
+		config, err := websocket.NewConfig("ws"+strings.TrimPrefix(hs.URL, "http"), "http://localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			config.Header.Set(APIKeyHeader, key)
+		}
+		return websocket.DialConfig(config)
+	}
+	call := func(conn *websocket.Conn, method string) *jsonError {
+		req := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": []interface{}{"hello", 10, &Args{"world"}}}
+		if err := websocket.JSON.Send(conn, req); err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		var msg jsonrpcMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		return msg.Error
+	}
+	// Handshakes without valid credentials are rejected
+	if _, err := dial(""); err == nil {
+		t.Fatalf("unauthenticated handshake accepted")
+	}
+	if _, err := dial("unknown"); err == nil {
+		t.Fatalf("handshake with unknown key accepted")
+	}
+	// Authenticated connections are restricted to their methods
+	conn, err := dial("reader")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if err := call(conn, "service_echo"); err != nil {
+		t.Fatalf("allowed method failed: %v", err.Message)
+	}
+	if err := call(conn, "other_echo"); err == nil || err.Code != -32001 {
+		t.Fatalf("denied method error mismatch: have %v, want code %d", err, -32001)
+	}
+	// Revoke the key and ensure the open connection loses access
+	if err := auth.Update(AuthConfig{APIKeys: map[string][]string{"admin": {"*"}}}); err != nil {
+		t.Fatalf("failed to update credentials: %v", err)
+	}
+	if err := call(conn, "service_echo"); err == nil || err.Code != -32001 {
+		t.Fatalf("revoked key error mismatch: have %v, want code %d", err, -32001)
+	}
+}
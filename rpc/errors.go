@@ -64,3 +64,19 @@ type shutdownError struct{}
 func (e *shutdownError) ErrorCode() int { return -32000 }
 
 func (e *shutdownError) Error() string { return "server is shutting down" }
+
+// request is not permitted by the credentials of the connection
+type unauthorizedError struct{ service, method string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string {
+	return fmt.Sprintf("access to the method %s%s%s is denied", e.service, serviceMethodSeparator, e.method)
+}
+
+// request exceeds the rate, batch or response size limits of the server
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
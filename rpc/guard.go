@@ -0,0 +1,112 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CallGuard applies the credential checks, request limits and audit log of the
+// RPC endpoints to the calls served over other transports, like gRPC. Calls are
+// named like RPC methods, service and method joined by an underscore, both when
+// granting access to them and when weighing and recording them.
+type CallGuard struct {
+	auth    *Authenticator
+	limits  *limits
+	auditor *Auditor
+}
+
+// NewCallGuard creates a guard verifying credentials with auth, throttling the
+// clients with limiter and recording the calls with auditor. Any of them may be
+// nil to skip the respective checks.
+func NewCallGuard(auth *Authenticator, config LimitConfig, limiter Limiter, auditor *Auditor) *CallGuard {
+	return &CallGuard{
+		auth:    auth,
+		limits:  &limits{config: config, limiter: limiter},
+		auditor: auditor,
+	}
+}
+
+// Call is a call admitted by a CallGuard, recorded in the audit log once done.
+type Call struct {
+	auditor    *Auditor
+	method     string
+	params     interface{}
+	caller     string
+	credential string
+	start      time.Time
+}
+
+// Begin admits a call of method by the client at the remote address, presenting
+// credential (if any). The call is rejected with a plain error if the credential
+// is missing or invalid, with an Error of code -32001 if it isn't granted access
+// to the method and with one of code -32005 if the client exceeded its allowance.
+// Rejected calls are recorded right away, admitted ones once End is called.
+func (g *CallGuard) Begin(service, method, credential, remote string, params interface{}) (*Call, error) {
+	call := &Call{
+		auditor:    g.auditor,
+		method:     service + serviceMethodSeparator + method,
+		params:     params,
+		caller:     remote,
+		credential: credential,
+		start:      time.Now(),
+	}
+	if g.auth != nil && g.auth.Enabled() {
+		access, err := g.auth.authorize(credential)
+		if err != nil {
+			return nil, err
+		}
+		if !access.allows(service, method) {
+			err := &unauthorizedError{service, method}
+			call.End(nil, err)
+			return nil, err
+		}
+	}
+	if err := g.limits.charge(clientID(credential, remote), call.method); err != nil {
+		call.End(nil, err)
+		return nil, err
+	}
+	return call, nil
+}
+
+// End records the outcome of the call in the audit log, its result if err is nil.
+// Errors not carrying an RPC error code are recorded like failing RPC methods.
+func (c *Call) End(result interface{}, err error) {
+	if c.auditor == nil || !c.auditor.records(c.method) {
+		return
+	}
+	record := &AuditRecord{
+		Time:       c.start,
+		Method:     c.method,
+		Caller:     c.caller,
+		Credential: auditFingerprint(c.credential),
+		Latency:    time.Since(c.start),
+	}
+	params, _ := json.Marshal(c.params)
+
+	var blob []byte
+	if err != nil {
+		record.ErrorCode, record.Error = (&callbackError{}).ErrorCode(), err.Error()
+		if err, ok := err.(Error); ok {
+			record.ErrorCode = err.ErrorCode()
+		}
+	} else if blob, err = json.Marshal(result); err != nil {
+		blob = nil
+	}
+	c.auditor.submit(record, params, blob)
+}
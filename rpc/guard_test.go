@@ -0,0 +1,88 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCallGuard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-guard-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	auth, err := NewAuthenticator(AuthConfig{APIKeys: map[string][]string{"key": {"chain_GetBlock"}}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	auditor, err := NewAuditor(AuditConfig{Path: filepath.Join(dir, "audit.log"), Payloads: true})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	guard := NewCallGuard(auth, LimitConfig{Costs: map[string]uint64{"chain_GetBlock": 2}}, NewTokenBucketLimiter(0.001, 4), auditor)
+
+	// Ensure calls without valid credentials are rejected before anything else
+	if _, err := guard.Begin("chain", "GetBlock", "", "127.0.0.1:1000", nil); err != errMissingCredentials {
+		t.Fatalf("missing credential error mismatch: have %v, want %v", err, errMissingCredentials)
+	}
+	if _, err := guard.Begin("chain", "GetBlock", "other", "127.0.0.1:1000", nil); err != errInvalidCredentials {
+		t.Fatalf("invalid credential error mismatch: have %v, want %v", err, errInvalidCredentials)
+	}
+	// Ensure the methods not granted are denied, and the granted ones charged
+	if _, err := guard.Begin("chain", "SendTransaction", "key", "127.0.0.1:1000", nil); err == nil || err.(Error).ErrorCode() != -32001 {
+		t.Fatalf("denied call error mismatch: have %v, want code -32001", err)
+	}
+	for i := 0; i < 2; i++ {
+		call, err := guard.Begin("chain", "GetBlock", "key", "127.0.0.1:1000", []uint64{uint64(i)})
+		if err != nil {
+			t.Fatalf("call %d: rejected: %v", i, err)
+		}
+		if i == 0 {
+			call.End("block", nil)
+		} else {
+			call.End(nil, errors.New("not found"))
+		}
+	}
+	if _, err := guard.Begin("chain", "GetBlock", "key", "127.0.0.2:1000", nil); err == nil || err.(Error).ErrorCode() != -32005 {
+		t.Fatalf("throttled call error mismatch: have %v, want code -32005", err)
+	}
+	auditor.Close()
+
+	// Ensure the rejected and the served calls got recorded
+	records := readAuditLog(t, filepath.Join(dir, "audit.log"))
+	if len(records) != 4 {
+		t.Fatalf("record count mismatch: have %d, want 4", len(records))
+	}
+	if record := records[0]; record.Method != "chain_SendTransaction" || record.ErrorCode != -32001 {
+		t.Errorf("denied record mismatch: have %s, code %d", record.Method, record.ErrorCode)
+	}
+	if record := records[1]; record.Method != "chain_GetBlock" || string(record.Params) != "[0]" || string(record.Result) != `"block"` || record.Credential != AuditHash([]byte("key"))[:16] {
+		t.Errorf("served record mismatch: have %s, params %s, result %s, credential %s", record.Method, record.Params, record.Result, record.Credential)
+	}
+	if record := records[2]; record.Error != "not found" || record.ErrorCode != -32000 || record.Result != nil {
+		t.Errorf("failed record mismatch: have error %q, code %d, result %s", record.Error, record.ErrorCode, record.Result)
+	}
+	if record := records[3]; record.ErrorCode != -32005 || record.Caller != "127.0.0.2:1000" {
+		t.Errorf("throttled record mismatch: have code %d, caller %s", record.ErrorCode, record.Caller)
+	}
+}
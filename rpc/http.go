@@ -162,16 +162,37 @@ func (t *httpReadWriteNopCloser) Close() error {
 //
 // Deprecated: Server implements http.Handler
 func NewHTTPServer(cors []string, vhosts []string, srv *Server) *http.Server {
-	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	return newHTTPServer(cors, vhosts, nil, srv)
+}
+
+// newHTTPServer creates a new HTTP RPC server around an API provider, only serving
+// the requests authenticated by auth (if set).
+func newHTTPServer(cors []string, vhosts []string, auth *Authenticator, srv *Server) *http.Server {
+	// Wrap the auth-handler within a CORS-handler within a host-handler
+	handler := newCorsHandler(newAuthHandler(auth, srv), cors)
 	handler = newVHostHandler(vhosts, handler)
 	return &http.Server{Handler: handler}
 }
 
+// SetHTTPHandlers configures the handlers serving the requests of the given HTTP
+// paths (e.g. "/graphql") instead of JSON-RPC. They're authenticated the same way,
+// a credential only granting access to a path if it covers the module named like
+// it ("graphql"), and are subject to the same request limits and audit log.
+func (srv *Server) SetHTTPHandlers(handlers map[string]http.Handler) {
+	srv.handlers.Store(handlers)
+}
+
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Hand the requests of the configured paths over to their handlers
+	if handlers, _ := srv.handlers.Load().(map[string]http.Handler); handlers != nil {
+		if handler, ok := handlers[r.URL.Path]; ok {
+			srv.serveHandler(w, r, handler)
+			return
+		}
+	}
 	// Permit dumb empty requests for remote health-checks (AWS)
-	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+	if isHealthCheck(r) {
 		return
 	}
 	if code, err := validateRequest(r); err != nil {
@@ -181,7 +202,7 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// All checks passed, create a codec that reads direct from the request body
 	// untilEOF and writes the response to w and order the server to process a
 	// single request.
-	ctx := context.Background()
+	ctx := withAuthSession(context.Background(), r)
 	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
 	ctx = context.WithValue(ctx, "scheme", r.Proto)
 	ctx = context.WithValue(ctx, "local", r.Host)
@@ -190,10 +211,102 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	codec := NewJSONCodec(&httpReadWriteNopCloser{body, w})
 	defer codec.Close()
 
+	if err := withHexPrefix(codec, r.Header.Get(HexPrefixHeader)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	w.Header().Set("content-type", contentType)
 	srv.ServeSingleRequest(ctx, codec, OptionMethodInvocation)
 }
 
+// serveHandler serves a request of one of the configured HTTP paths. It's subject
+// to the request limits and audit log like a call of the module named like the
+// path ("graphql"), its query recorded as parameters and its response as result.
+func (srv *Server) serveHandler(w http.ResponseWriter, r *http.Request, handler http.Handler) {
+	module := strings.Trim(r.URL.Path, "/")
+
+	session, _ := r.Context().Value(authSessionKey{}).(*authSession)
+	if access := session.grant(); access != nil && !access.allows(module, "") {
+		http.Error(w, "credential not authorized for "+r.URL.Path, http.StatusForbidden)
+		return
+	}
+	// Buffer the request to record it, GET requests carry it in the URL instead
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestContentLength))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	query := body
+	if len(query) == 0 {
+		query = []byte(r.URL.RawQuery)
+	}
+	call := &Call{
+		auditor: srv.currentAuditor(),
+		method:  module,
+		params:  auditPayload(query),
+		caller:  r.RemoteAddr,
+		start:   time.Now(),
+	}
+	if session != nil {
+		call.credential = session.credential
+	}
+	limits := srv.currentLimits()
+	if err := limits.charge(clientID(call.credential, call.caller), module); err != nil {
+		call.End(nil, err)
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	// Buffer the response too, to enforce the response size limit on it
+	res := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	handler.ServeHTTP(res, r)
+
+	if err := limits.checkSize(res.body.Len()); err != nil {
+		call.End(nil, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if res.status >= http.StatusBadRequest {
+		call.End(nil, errors.New(http.StatusText(res.status)))
+	} else {
+		call.End(auditPayload(res.body.Bytes()), nil)
+	}
+	for key, values := range res.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(res.status)
+	w.Write(res.body.Bytes())
+}
+
+// bufferedResponse is an http.ResponseWriter holding the response in memory.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header         { return r.header }
+func (r *bufferedResponse) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *bufferedResponse) WriteHeader(status int)      { r.status = status }
+
+// auditPayload returns a blob as recorded in the audit log, as is if it's JSON and
+// as a JSON string otherwise.
+func auditPayload(blob []byte) json.RawMessage {
+	if json.Valid(blob) {
+		return blob
+	}
+	payload, _ := json.Marshal(string(blob))
+	return payload
+}
+
+// isHealthCheck reports whether the request is a dumb empty one, as sent by remote
+// health-checks.
+func isHealthCheck(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == ""
+}
+
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func validateRequest(r *http.Request) (int, error) {
@@ -212,7 +325,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
@@ -17,8 +17,11 @@
 package rpc
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -52,3 +55,116 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPHandlers(t *testing.T) {
+	srv := newTestAuthServer(t)
+	defer srv.Stop()
+
+	srv.SetHTTPHandlers(map[string]http.Handler{
+		"/graphql": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("graphql"))
+		}),
+	})
+	hs := httptest.NewServer(newHTTPServer(nil, nil, newTestAuthenticator(t), srv).Handler)
+	defer hs.Close()
+
+	tests := []struct {
+		key    string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"reader", http.StatusForbidden},
+		{"admin", http.StatusOK},
+	}
+	for i, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, hs.URL+"/graphql?query={}", nil)
+		if tt.key != "" {
+			req.Header.Set(APIKeyHeader, tt.key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("test %d: request failed: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, resp.StatusCode, tt.status)
+		}
+		if tt.status == http.StatusOK && string(body) != "graphql" {
+			t.Errorf("test %d: body mismatch: have %q, want %q", i, body, "graphql")
+		}
+	}
+	// Ensure JSON-RPC is still served on the other paths
+	if status, code := postTestRequest(t, hs.URL, "service_echo", map[string]string{APIKeyHeader: "reader"}); status != http.StatusOK || code != 0 {
+		t.Errorf("JSON-RPC result mismatch: have %d/%d, want %d/%d", status, code, http.StatusOK, 0)
+	}
+}
+
+func TestHTTPHandlerLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-handler-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	auditor, err := NewAuditor(AuditConfig{Path: filepath.Join(dir, "audit.log"), Payloads: true})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	srv := newTestServer("service", new(Service))
+	defer srv.Stop()
+
+	srv.SetLimits(LimitConfig{Costs: map[string]uint64{"graphql": 2}, ResponseLimit: 32}, NewTokenBucketLimiter(0.001, 3))
+	srv.SetAuditor(auditor)
+	srv.SetHTTPHandlers(map[string]http.Handler{
+		"/graphql": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("content-type", "application/json")
+			w.Write([]byte(`{"data":{"block":null}}`))
+		}),
+		"/large": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(make([]byte, 64))
+		}),
+	})
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/graphql", http.StatusOK},
+		{"/graphql", http.StatusTooManyRequests},
+		{"/large", http.StatusInternalServerError},
+	}
+	for i, tt := range tests {
+		resp, err := http.Post(hs.URL+tt.path, "application/json", strings.NewReader(`{"query":"{block{number}}"}`))
+		if err != nil {
+			t.Fatalf("test %d: request failed: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, resp.StatusCode, tt.status)
+		}
+		if tt.status == http.StatusOK && (string(body) != `{"data":{"block":null}}` || resp.Header.Get("content-type") != "application/json") {
+			t.Errorf("test %d: response mismatch: have %q (%s)", i, body, resp.Header.Get("content-type"))
+		}
+	}
+	auditor.Close()
+
+	// Ensure the served and the rejected requests got recorded
+	records := readAuditLog(t, filepath.Join(dir, "audit.log"))
+	if len(records) != 3 {
+		t.Fatalf("record count mismatch: have %d, want 3", len(records))
+	}
+	if record := records[0]; record.Method != "graphql" || string(record.Params) != `{"query":"{block{number}}"}` || string(record.Result) != `{"data":{"block":null}}` {
+		t.Errorf("served record mismatch: have %s, params %s, result %s", record.Method, record.Params, record.Result)
+	}
+	for i, method := range []string{"graphql", "large"} {
+		if record := records[i+1]; record.Method != method || record.ErrorCode != -32005 || record.Result != nil {
+			t.Errorf("rejected record %d mismatch: have %s, code %d, result %s", i, record.Method, record.ErrorCode, record.Result)
+		}
+	}
+}
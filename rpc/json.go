@@ -26,6 +26,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/relianz2019/relianz/common/hexutil"
 	"github.com/relianz2019/relianz/log"
 )
 
@@ -83,6 +84,7 @@ type jsonCodec struct {
 	encMu  sync.Mutex                // guards the encoder
 	encode func(v interface{}) error // encoder to allow multiple transports
 	rw     io.ReadWriteCloser        // connection
+	prefix string                    // prefix of addresses and hashes in responses, "" for the node default
 }
 
 func (err *jsonError) Error() string {
@@ -354,9 +356,30 @@ func (c *jsonCodec) Write(res interface{}) error {
 	c.encMu.Lock()
 	defer c.encMu.Unlock()
 
+	if c.prefix != "" && c.prefix != hexutil.HexPrefix() {
+		blob, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		return c.encode(json.RawMessage(swapHexPrefix(blob, hexutil.HexPrefix(), c.prefix)))
+	}
 	return c.encode(res)
 }
 
+func (c *jsonCodec) hexPrefix() string {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+
+	return c.prefix
+}
+
+func (c *jsonCodec) setHexPrefix(prefix string) {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+
+	c.prefix = prefix
+}
+
 // Close the underlying connection
 func (c *jsonCodec) Close() {
 	c.closer.Do(func() {
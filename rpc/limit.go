@@ -0,0 +1,260 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/relianz2019/relianz/metrics"
+)
+
+var (
+	throttledRequestMeter  = metrics.NewRegisteredMeter("rpc/throttled/requests", nil)
+	throttledBatchMeter    = metrics.NewRegisteredMeter("rpc/throttled/batches", nil)
+	throttledResponseMeter = metrics.NewRegisteredMeter("rpc/throttled/responses", nil)
+	requestCostMeter       = metrics.NewRegisteredMeter("rpc/cost", nil)
+)
+
+// limiterCleanupInterval is the minimum time between two sweeps of the idle
+// client buckets of a TokenBucketLimiter.
+const limiterCleanupInterval = time.Minute
+
+// LimitConfig configures the request limits of an RPC server.
+type LimitConfig struct {
+	// Rate is the request cost a client is granted per second, Burst the cost
+	// it may spend at once. A zero Rate disables rate limiting.
+	Rate  float64 `toml:",omitempty"`
+	Burst uint64  `toml:",omitempty"`
+
+	// Costs weighs the methods ("eth_getLogs") against each other, unlisted ones
+	// costing 1. Subscriptions are weighed by their name ("eth_logs"), requests
+	// of the HTTP paths served besides JSON-RPC by the path ("graphql").
+	Costs map[string]uint64 `toml:",omitempty"`
+
+	// BatchLimit is the maximum number of requests in a batch and ResponseLimit
+	// the maximum size of a response in bytes, zero meaning no limit.
+	BatchLimit    int `toml:",omitempty"`
+	ResponseLimit int `toml:",omitempty"`
+}
+
+// Limiter throttles the clients of an RPC server by the cost of their requests.
+type Limiter interface {
+	// Allow reports whether the client may issue a request of the given cost,
+	// charging the client for it if so.
+	Allow(client string, cost uint64) bool
+}
+
+// limits is the limiter and configuration enforced by a server.
+type limits struct {
+	config  LimitConfig
+	limiter Limiter
+}
+
+// SetLimits configures the request limits enforced by the server, throttling its
+// clients with limiter (if set). Clients are identified by the credential they
+// authenticated with, or by their remote IP otherwise; local (IPC and in-process)
+// clients are never throttled.
+func (s *Server) SetLimits(config LimitConfig, limiter Limiter) {
+	s.limits.Store(&limits{config: config, limiter: limiter})
+}
+
+// currentLimits returns the limits set with SetLimits, nil if none.
+func (s *Server) currentLimits() *limits {
+	l, _ := s.limits.Load().(*limits)
+	return l
+}
+
+// clientKey is the context key of the identity of the client a request is served to.
+type clientKey struct{}
+
+// withClient tags the context with the identity of the client, used to track its
+// request allowance.
+func withClient(ctx context.Context) context.Context {
+	var credential string
+	if session, _ := ctx.Value(authSessionKey{}).(*authSession); session != nil {
+		credential = session.credential
+	}
+	remote, _ := ctx.Value("remote").(string)
+	if client := clientID(credential, remote); client != "" {
+		return context.WithValue(ctx, clientKey{}, client)
+	}
+	return ctx
+}
+
+// clientID identifies a client by its credential, or by its remote IP if it has
+// none. Local clients have neither and aren't identified.
+func clientID(credential, remote string) string {
+	if credential != "" {
+		return "auth:" + credential
+	}
+	if remote != "" {
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+		return "ip:" + remote
+	}
+	return ""
+}
+
+// batchTooLarge reports whether a batch of requests exceeds the batch limit.
+func (l *limits) batchTooLarge(size int) bool {
+	if l == nil || l.config.BatchLimit <= 0 || size <= l.config.BatchLimit {
+		return false
+	}
+	throttledBatchMeter.Mark(1)
+	return true
+}
+
+// throttle charges the client of the context for the request, returning an error
+// if its allowance is exceeded.
+func (l *limits) throttle(ctx context.Context, req *serverRequest) Error {
+	if req.callb == nil {
+		return nil
+	}
+	client, ok := ctx.Value(clientKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return l.charge(client, req.svcname+serviceMethodSeparator+formatName(req.callb.method.Name))
+}
+
+// charge charges a client for a call of method, returning an error if its
+// allowance is exceeded.
+func (l *limits) charge(client, method string) Error {
+	if l == nil || l.limiter == nil || client == "" {
+		return nil
+	}
+	cost, ok := l.config.Costs[method]
+	if !ok {
+		cost = 1
+	}
+	if !l.limiter.Allow(client, cost) {
+		throttledRequestMeter.Mark(1)
+		return &limitExceededError{fmt.Sprintf("rate limit exceeded for %s", method)}
+	}
+	requestCostMeter.Mark(int64(cost))
+	return nil
+}
+
+// checkResponse returns an error if the result of a call exceeds the response
+// size limit once encoded.
+func (l *limits) checkResponse(result interface{}) Error {
+	if l == nil || l.config.ResponseLimit <= 0 {
+		return nil
+	}
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return nil // encoding errors are reported by the codec
+	}
+	return l.checkSize(len(blob))
+}
+
+// checkSize returns an error if a response of the given size in bytes exceeds
+// the response size limit.
+func (l *limits) checkSize(size int) Error {
+	if l == nil || l.config.ResponseLimit <= 0 || size <= l.config.ResponseLimit {
+		return nil
+	}
+	throttledResponseMeter.Mark(1)
+	return &limitExceededError{fmt.Sprintf("response too large (%d>%d)", size, l.config.ResponseLimit)}
+}
+
+// TokenBucketLimiter is a Limiter granting each client a bucket of tokens that
+// refills at a constant rate, each request spending its cost worth of tokens.
+type TokenBucketLimiter struct {
+	rate    float64                 // Tokens added to the buckets per second
+	burst   float64                 // Capacity of the buckets
+	buckets map[string]*tokenBucket // Buckets of the recently seen clients
+	swept   time.Time               // Time of the last sweep of the idle buckets
+	now     func() time.Time        // Clock of the limiter, replaced in tests
+	lock    sync.Mutex
+}
+
+// tokenBucket is the allowance of a single client.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewTokenBucketLimiter creates a limiter granting each client rate tokens per
+// second, up to burst tokens at once.
+func NewTokenBucketLimiter(rate float64, burst uint64) *TokenBucketLimiter {
+	if burst == 0 {
+		burst = 1
+	}
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow implements Limiter, spending cost tokens of the client's bucket if it has
+// enough of them. Requests costing more than the burst need a full bucket.
+func (l *TokenBucketLimiter) Allow(client string, cost uint64) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if now.Sub(l.swept) > limiterCleanupInterval {
+		l.sweep(now)
+	}
+	bucket := l.buckets[client]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.refill(now, l.rate, l.burst)
+
+	need := float64(cost)
+	if need > l.burst {
+		need = l.burst
+	}
+	if bucket.tokens < need {
+		return false
+	}
+	bucket.tokens -= need
+	return true
+}
+
+// sweep drops the buckets that have refilled completely, their clients being no
+// different from unseen ones.
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.refill(now, l.rate, l.burst); bucket.tokens >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.swept = now
+}
+
+// refill adds the tokens accumulated since the last update to the bucket.
+func (b *tokenBucket) refill(now time.Time, rate float64, burst float64) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.updated = now
+}
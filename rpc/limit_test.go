@@ -0,0 +1,141 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Unix(1550000000, 0)
+
+	limiter := NewTokenBucketLimiter(1, 3)
+	limiter.now = func() time.Time { return now }
+
+	// Drain the bucket of a client and ensure others are unaffected
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("a", 1) {
+			t.Fatalf("request %d: throttled within burst", i)
+		}
+	}
+	if limiter.Allow("a", 1) {
+		t.Fatalf("drained client not throttled")
+	}
+	if !limiter.Allow("b", 3) {
+		t.Fatalf("fresh client throttled")
+	}
+	// Let the bucket refill partially and ensure only cheap requests get through
+	now = now.Add(2 * time.Second)
+	if limiter.Allow("a", 3) {
+		t.Fatalf("request costlier than the refilled tokens allowed")
+	}
+	if !limiter.Allow("a", 2) {
+		t.Fatalf("request within the refilled tokens throttled")
+	}
+	// Requests above the burst need a full bucket
+	now = now.Add(time.Hour)
+	if !limiter.Allow("a", 10) {
+		t.Fatalf("request above burst throttled with a full bucket")
+	}
+	if limiter.Allow("a", 1) {
+		t.Fatalf("request above burst didn't drain the bucket")
+	}
+	// Idle clients with full buckets are swept
+	now = now.Add(time.Hour)
+	limiter.Allow("c", 1)
+	if _, ok := limiter.buckets["b"]; ok {
+		t.Fatalf("idle client not swept")
+	}
+}
+
+// postLimitedRequest sends a raw request to the HTTP server, returning the error
+// codes of the responses, 0 for the successful ones.
+func postLimitedRequest(t *testing.T, url string, body string) []int {
+	resp, err := http.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	blob, _ := ioutil.ReadAll(resp.Body)
+	var msgs []*jsonrpcMessage
+	if err := json.Unmarshal(blob, &msgs); err != nil {
+		msg := new(jsonrpcMessage)
+		if err := json.Unmarshal(blob, msg); err != nil {
+			t.Fatalf("invalid response %q: %v", blob, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	codes := make([]int, len(msgs))
+	for i, msg := range msgs {
+		if msg.Error != nil {
+			codes[i] = msg.Error.Code
+		}
+	}
+	return codes
+}
+
+func TestServerLimits(t *testing.T) {
+	srv := newTestServer("service", new(Service))
+	defer srv.Stop()
+
+	srv.SetLimits(LimitConfig{Costs: map[string]uint64{"service_echo": 2}, BatchLimit: 2}, NewTokenBucketLimiter(0.001, 5))
+
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	echo := `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["hello",10,{"S":"world"}]}`
+	rets := `{"jsonrpc":"2.0","id":2,"method":"service_rets","params":[]}`
+
+	// Batches above the limit are rejected as a whole, without being charged for
+	if codes := postLimitedRequest(t, hs.URL, "["+rets+","+rets+","+rets+"]"); len(codes) != 1 || codes[0] != -32005 {
+		t.Fatalf("oversized batch result mismatch: have %v, want [-32005]", codes)
+	}
+	// Requests are charged by their cost, throttled ones failing individually
+	if codes := postLimitedRequest(t, hs.URL, "["+echo+","+echo+"]"); len(codes) != 2 || codes[0] != 0 || codes[1] != 0 {
+		t.Fatalf("batch within allowance result mismatch: have %v, want [0 0]", codes)
+	}
+	if codes := postLimitedRequest(t, hs.URL, "["+echo+","+rets+"]"); len(codes) != 2 || codes[0] != -32005 || codes[1] != 0 {
+		t.Fatalf("batch above allowance result mismatch: have %v, want [-32005 0]", codes)
+	}
+	if codes := postLimitedRequest(t, hs.URL, rets); len(codes) != 1 || codes[0] != -32005 {
+		t.Fatalf("drained client result mismatch: have %v, want [-32005]", codes)
+	}
+}
+
+func TestServerResponseLimit(t *testing.T) {
+	srv := newTestServer("service", new(Service))
+	defer srv.Stop()
+
+	srv.SetLimits(LimitConfig{ResponseLimit: 16}, nil)
+
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	if codes := postLimitedRequest(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["hello",10,{"S":"world"}]}`); len(codes) != 1 || codes[0] != -32005 {
+		t.Fatalf("oversized response result mismatch: have %v, want [-32005]", codes)
+	}
+	if codes := postLimitedRequest(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_rets","params":[]}`); len(codes) != 1 || codes[0] != 0 {
+		t.Fatalf("small response result mismatch: have %v, want [0]", codes)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/relianz2019/relianz/common/hexutil"
+)
+
+// Lengths of the hex encoded addresses and hashes, without prefix.
+const (
+	addressHexLength = 2 * 20
+	hashHexLength    = 2 * 32
+)
+
+// HexPrefixHeader is the HTTP header (or websocket handshake header) clients can
+// set to select the prefix addresses and hashes are returned with, e.g. "0x".
+const HexPrefixHeader = "X-Hex-Prefix"
+
+// hexPrefixCodec is implemented by codecs that can change the prefix of the
+// addresses and hashes they send.
+type hexPrefixCodec interface {
+	hexPrefix() string
+	setHexPrefix(prefix string)
+}
+
+// SetHexPrefix sets the prefix addresses and hashes are returned with on all
+// connections served afterwards, unless the connection selected its own. An
+// empty prefix keeps the one addresses and hashes are marshalled with, see
+// hexutil.HexPrefix.
+func (s *Server) SetHexPrefix(prefix string) error {
+	if prefix != "" && !hexutil.IsValidHexPrefix(prefix) {
+		return fmt.Errorf("invalid hex prefix %q", prefix)
+	}
+	s.prefix.Store(prefix)
+	return nil
+}
+
+// HexPrefix returns the prefix set with SetHexPrefix.
+func (s *Server) HexPrefix() string {
+	if prefix, ok := s.prefix.Load().(string); ok {
+		return prefix
+	}
+	return ""
+}
+
+// applyHexPrefix sets the server's prefix on codec unless it already has one.
+func (s *Server) applyHexPrefix(codec ServerCodec) {
+	c, ok := codec.(hexPrefixCodec)
+	if !ok || c.hexPrefix() != "" {
+		return
+	}
+	if prefix := s.HexPrefix(); prefix != "" {
+		c.setHexPrefix(prefix)
+	}
+}
+
+// withHexPrefix sets the prefix requested by a client on codec. It returns an
+// error if the prefix is not supported.
+func withHexPrefix(codec ServerCodec, prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if !hexutil.IsValidHexPrefix(prefix) {
+		return fmt.Errorf("invalid %s %q", HexPrefixHeader, prefix)
+	}
+	if c, ok := codec.(hexPrefixCodec); ok {
+		c.setHexPrefix(prefix)
+	}
+	return nil
+}
+
+// swapHexPrefix replaces the prefix of the addresses and hashes in the encoded
+// message blob, marshalled with from, by to. With the default display prefix of
+// hexutil, addresses and hashes are the only values marshalled with it, all other
+// hex data carrying "0x". If they are marshalled with "0x" too, they can only be
+// told apart by their length, so any 20 or 32 byte hex value gets swapped.
+func swapHexPrefix(blob []byte, from, to string) []byte {
+	if from == to {
+		return blob
+	}
+	var (
+		out  = make([]byte, 0, len(blob))
+		mark = []byte(`"` + from)
+	)
+	for {
+		i := bytes.Index(blob, mark)
+		if i < 0 {
+			return append(out, blob...)
+		}
+		out = append(out, blob[:i+1]...)
+		blob = blob[i+1:]
+
+		// Escaped quotes are part of a string, not the start of one
+		if !isEscaped(out[:len(out)-1]) && isHexValue(blob[len(from):]) {
+			out = append(out, to...)
+			blob = blob[len(from):]
+		}
+	}
+}
+
+// isEscaped reports whether a character following b is escaped by an odd
+// number of backslashes.
+func isEscaped(b []byte) bool {
+	n := 0
+	for i := len(b) - 1; i >= 0 && b[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// isHexValue reports whether b starts with an address or hash sized hex string
+// followed by the closing quote.
+func isHexValue(b []byte) bool {
+	n := 0
+	for n < len(b) && isHexChar(b[n]) {
+		n++
+	}
+	return (n == addressHexLength || n == hashHexLength) && n < len(b) && b[n] == '"'
+}
+
+func isHexChar(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 The go-relianz Authors
+// This file is part of the go-relianz library.
+//
+// The go-relianz library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-relianz library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-relianz library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relianz2019/relianz/common/hexutil"
+)
+
+type nopRWC struct {
+	bytes.Buffer
+}
+
+func (rwc *nopRWC) Close() error { return nil }
+
+// testPrefixed is marshalled with the display prefix, like addresses and hashes.
+type testPrefixed []byte
+
+func (b testPrefixed) MarshalText() ([]byte, error) {
+	return []byte(hexutil.HexToCP(hexutil.Encode(b))), nil
+}
+
+func TestCodecHexPrefix(t *testing.T) {
+	addr := "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	hash := "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+
+	tests := []struct {
+		display, prefix string // Display prefix of hexutil and prefix of the codec
+		result          string // Prefix of 32 byte hex data in the response
+	}{
+		{"t0", "0x", "0x"},
+		{"0x", "t0", "t0"},
+		{"t0", "t0", "0x"},
+	}
+	defer hexutil.SetHexPrefix(hexutil.HexPrefix())
+	for i, tt := range tests {
+		hexutil.SetHexPrefix(tt.display)
+		reply := map[string]interface{}{
+			"address": testPrefixed(hexutil.MustDecode("0x" + addr)),
+			"hashes":  []testPrefixed{hexutil.MustDecode("0x" + hash)},
+			"result":  hexutil.Bytes(hexutil.MustDecode("0x" + hash)),
+			"number":  hexutil.Uint64(16),
+			"quoted":  `\"` + tt.display + addr + `\"`,
+		}
+		rwc := new(nopRWC)
+		codec := NewJSONCodec(rwc)
+		if err := withHexPrefix(codec, tt.prefix); err != nil {
+			t.Fatalf("test %d: failed to set prefix: %v", i, err)
+		}
+		if err := codec.Write(codec.CreateResponse(1, reply)); err != nil {
+			t.Fatalf("test %d: failed to write response: %v", i, err)
+		}
+		have := rwc.String()
+		for _, want := range []string{
+			`"address":"` + tt.prefix + addr + `"`,
+			`"hashes":["` + tt.prefix + hash + `"]`,
+			`"result":"` + tt.result + hash + `"`,
+			`"number":"0x10"`,
+			`"quoted":"\\\"` + tt.display + addr + `\\\""`,
+		} {
+			if !strings.Contains(have, want) {
+				t.Errorf("test %d: response %s misses %s", i, have, want)
+			}
+		}
+	}
+}
+
+func TestSwapHexPrefix(t *testing.T) {
+	addr := "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	tests := []struct {
+		from, to, in, want string
+	}{
+		{"t0", "0x", `["t0` + addr + `"]`, `["0x` + addr + `"]`},
+		{"t0", "0x", `["t0` + addr + `00"]`, `["t0` + addr + `00"]`},
+		{"t0", "0x", `["t0` + addr[:38] + `"]`, `["t0` + addr[:38] + `"]`},
+		{"t0", "0x", `["\\\"t0` + addr + `\""]`, `["\\\"t0` + addr + `\""]`},
+		{"t0", "0x", `["\\","t0` + addr + `"]`, `["\\","0x` + addr + `"]`},
+		{"0x", "t0", `["0x` + addr + `","0x10","0x` + addr[:38] + `"]`, `["t0` + addr + `","0x10","0x` + addr[:38] + `"]`},
+		{"0x", "0x", `["0x` + addr + `"]`, `["0x` + addr + `"]`},
+	}
+	for i, test := range tests {
+		if have := string(swapHexPrefix([]byte(test.in), test.from, test.to)); have != test.want {
+			t.Errorf("test %d: have %s, want %s", i, have, test.want)
+		}
+	}
+}
+
+func TestServerHexPrefix(t *testing.T) {
+	server := NewServer()
+	if err := server.SetHexPrefix("t1"); err == nil {
+		t.Fatal("expected error for invalid prefix")
+	}
+	if err := server.SetHexPrefix("0x"); err != nil {
+		t.Fatalf("failed to set prefix: %v", err)
+	}
+	codec := NewJSONCodec(new(nopRWC))
+	server.applyHexPrefix(codec)
+	if prefix := codec.(hexPrefixCodec).hexPrefix(); prefix != "0x" {
+		t.Errorf("codec prefix mismatch: have %q, want %q", prefix, "0x")
+	}
+	// Connections selecting their own prefix keep it
+	codec = NewJSONCodec(new(nopRWC))
+	withHexPrefix(codec, "t0")
+	server.applyHexPrefix(codec)
+	if prefix := codec.(hexPrefixCodec).hexPrefix(); prefix != "t0" {
+		t.Errorf("codec prefix mismatch: have %q, want %q", prefix, "t0")
+	}
+}
+
+func TestHTTPInvalidHexPrefix(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`))
+	request.Header.Set("content-type", contentType)
+	request.Header.Set(HexPrefixHeader, "t5")
+
+	recorder := httptest.NewRecorder()
+	NewServer().ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("response code should be %d not %d", http.StatusBadRequest, recorder.Code)
+	}
+}
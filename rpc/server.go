@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/relianz2019/relianz/log"
 	"gopkg.in/fatih/set.v0"
@@ -150,6 +151,13 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 	if options&OptionSubscriptions == OptionSubscriptions {
 		ctx = context.WithValue(ctx, notifierKey{}, newNotifier(codec))
 	}
+	s.applyHexPrefix(codec)
+
+	// if the connection was authenticated, restrict it to the methods its
+	// credential grants access to
+	session, _ := ctx.Value(authSessionKey{}).(*authSession)
+	ctx = withClient(ctx)
+
 	s.codecsMu.Lock()
 	if atomic.LoadInt32(&s.run) != 1 { // server stopped
 		s.codecsMu.Unlock()
@@ -160,7 +168,7 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 
 	// test if the server is ordered to stop
 	for atomic.LoadInt32(&s.run) == 1 {
-		reqs, batch, err := s.readRequest(codec)
+		reqs, batch, err := s.readRequest(codec, session)
 		if err != nil {
 			// If a parsing error occurred, send an error
 			if err.Error() != "EOF" {
@@ -187,6 +195,15 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 			}
 			return nil
 		}
+		// reject batches above the batch limit as a whole
+		if batch && s.currentLimits().batchTooLarge(len(reqs)) {
+			err := &limitExceededError{fmt.Sprintf("batch too large (%d>%d)", len(reqs), s.currentLimits().config.BatchLimit)}
+			codec.Write(codec.CreateErrorResponse(nil, err))
+			if singleShot {
+				return nil
+			}
+			continue
+		}
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -321,18 +338,26 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 			return res, nil
 		}
 	}
-	return codec.CreateResponse(req.id, reply[0].Interface()), nil
+	result := reply[0].Interface()
+	if err := s.currentLimits().checkResponse(result); err != nil {
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	return codec.CreateResponse(req.id, result), nil
 }
 
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
 	var response interface{}
 	var callback func()
+	start := time.Now()
 	if req.err != nil {
 		response = codec.CreateErrorResponse(&req.id, req.err)
+	} else if err := s.currentLimits().throttle(ctx, req); err != nil {
+		response = codec.CreateErrorResponse(&req.id, err)
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	s.currentAuditor().audit(ctx, req, start, response)
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -350,15 +375,20 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
+	limits, auditor := s.currentLimits(), s.currentAuditor()
 	for i, req := range requests {
+		start := time.Now()
 		if req.err != nil {
 			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
+		} else if err := limits.throttle(ctx, req); err != nil {
+			responses[i] = codec.CreateErrorResponse(&req.id, err)
 		} else {
 			var callback func()
 			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
 				callbacks = append(callbacks, callback)
 			}
 		}
+		auditor.audit(ctx, req, start, responses[i])
 	}
 
 	if err := codec.Write(responses); err != nil {
@@ -374,12 +404,14 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 
 // readRequest requests the next (batch) request from the codec. It will return the collection
 // of requests, an indication if the request was a batch, the invalid request identifier and an
-// error when the request could not be read/parsed.
-func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error) {
+// error when the request could not be read/parsed. Requests for methods the session isn't
+// granted access to are rejected, a nil session is granted access to all of them.
+func (s *Server) readRequest(codec ServerCodec, session *authSession) ([]*serverRequest, bool, Error) {
 	reqs, batch, err := codec.ReadRequestHeaders()
 	if err != nil {
 		return nil, batch, err
 	}
+	access := session.grant()
 
 	requests := make([]*serverRequest, len(reqs))
 
@@ -404,6 +436,11 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
+		if access != nil && !access.allows(r.service, r.method) { // credential doesn't cover method
+			requests[i] = &serverRequest{id: r.id, err: &unauthorizedError{r.service, r.method}}
+			continue
+		}
+
 		if svc, ok = s.services[r.service]; !ok { // rpc method isn't available
 			requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method}}
 			continue
@@ -441,6 +478,8 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method}}
 	}
-
+	for i, r := range reqs {
+		requests[i].method, requests[i].params = requestMethod(r), r.params
+	}
 	return requests, batch, nil
 }
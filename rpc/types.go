@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/relianz2019/relianz/common/hexutil"
 	"gopkg.in/fatih/set.v0"
@@ -61,6 +62,9 @@ type serverRequest struct {
 	args          []reflect.Value
 	isUnsubscribe bool
 	err           Error
+
+	method string      // full method name as requested, for the audit log
+	params interface{} // raw parameters as requested, for the audit log
 }
 
 type serviceRegistry map[string]*service // collection of services
@@ -74,6 +78,10 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   *set.Set
+	prefix   atomic.Value // default prefix of addresses and hashes, see SetHexPrefix
+	limits   atomic.Value // request limits of the clients, see SetLimits
+	auditor  atomic.Value // audit log of the requests served, see SetAuditor
+	handlers atomic.Value // handlers of the non JSON-RPC HTTP paths, see SetHTTPHandlers
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -119,13 +127,15 @@ type ServerCodec interface {
 type BlockNumber int64
 
 const (
-	PendingBlockNumber  = BlockNumber(-2)
-	LatestBlockNumber   = BlockNumber(-1)
-	EarliestBlockNumber = BlockNumber(0)
+	SafeBlockNumber      = BlockNumber(-4)
+	FinalizedBlockNumber = BlockNumber(-3)
+	PendingBlockNumber   = BlockNumber(-2)
+	LatestBlockNumber    = BlockNumber(-1)
+	EarliestBlockNumber  = BlockNumber(0)
 )
 
 // UnmarshalJSON parses the given JSON fragment into a BlockNumber. It supports:
-// - "latest", "earliest" or "pending" as string arguments
+// - "latest", "earliest", "pending", "finalized" or "safe" as string arguments
 // - the block number
 // Returned errors:
 // - an invalid block number error when the given argument isn't a known strings
@@ -146,6 +156,12 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 	case "pending":
 		*bn = PendingBlockNumber
 		return nil
+	case "finalized":
+		*bn = FinalizedBlockNumber
+		return nil
+	case "safe":
+		*bn = SafeBlockNumber
+		return nil
 	}
 
 	blckNum, err := hexutil.DecodeUint64(input)
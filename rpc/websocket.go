@@ -56,7 +56,13 @@ var websocketJSONCodec = websocket.Codec{
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
-	return websocket.Server{
+	return srv.websocketHandler(allowedOrigins, nil)
+}
+
+// websocketHandler returns a handler that serves JSON-RPC to WebSocket connections,
+// only accepting the handshakes authenticated by auth (if set).
+func (srv *Server) websocketHandler(allowedOrigins []string, auth *Authenticator) http.Handler {
+	return newAuthHandler(auth, websocket.Server{
 		Handshake: wsHandshakeValidator(allowedOrigins),
 		Handler: func(conn *websocket.Conn) {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
@@ -68,9 +74,18 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			codec := NewCodec(conn, encoder, decoder)
+			if err := withHexPrefix(codec, conn.Request().Header.Get(HexPrefixHeader)); err != nil {
+				log.Debug("Rejected websocket connection", "err", err)
+				codec.Close()
+				return
+			}
+			defer codec.Close()
+			ctx := withAuthSession(context.Background(), conn.Request())
+			ctx = context.WithValue(ctx, "remote", conn.Request().RemoteAddr)
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
-	}
+	})
 }
 
 // NewWSServer creates a new websocket RPC server around an API provider.
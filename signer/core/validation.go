@@ -109,6 +109,12 @@ func (v *Validator) validate(msgs *ValidationMessages, txargs *SendTxArgs, metho
 		// This is a showstopper
 		return errors.New(`Ambiguous request: both "data" and "input" are set and are not identical`)
 	}
+	// Reject mistyped addresses, they can't be told apart from intended ones
+	if original := txargs.From.Original(); original != "" {
+		if err := common.ValidateHexAddress(original); err != nil {
+			return fmt.Errorf("Invalid from-address: %v", err)
+		}
+	}
 	var (
 		data []byte
 	)
@@ -141,8 +147,11 @@ func (v *Validator) validate(msgs *ValidationMessages, txargs *SendTxArgs, metho
 		}
 
 	} else {
+		if err := common.ValidateHexAddress(txargs.To.Original()); err != nil {
+			return fmt.Errorf("Invalid to-address: %v", err)
+		}
 		if !txargs.To.ValidChecksum() {
-			msgs.warn("Invalid checksum on to-address")
+			msgs.warn("Missing checksum on to-address")
 		}
 		// Normal transaction
 		if bytes.Equal(txargs.To.Address().Bytes(), common.Address{}.Bytes()) {
@@ -81,9 +81,15 @@ func TestValidator(t *testing.T) {
 		v     = NewValidator(db)
 	)
 	testcases := []txtestcase{
-		// Invalid to checksum
+		// Missing to checksum
 		{from: "000000000000000000000000000000000000dead", to: "000000000000000000000000000000000000dead",
 			n: "0x01", g: "0x20", gp: "0x40", value: "0x01", numMessages: 1},
+		// Invalid to checksum
+		{from: "000000000000000000000000000000000000dead", to: "0x000000000000000000000000000000000000DeAd",
+			n: "0x01", g: "0x20", gp: "0x40", value: "0x01", expectErr: true},
+		// Invalid from checksum
+		{from: "0x000000000000000000000000000000000000DEad", to: "0x000000000000000000000000000000000000dEaD",
+			n: "0x01", g: "0x20", gp: "0x40", value: "0x01", expectErr: true},
 		// valid 0x000000000000000000000000000000000000dEaD
 		{from: "000000000000000000000000000000000000dead", to: "0x000000000000000000000000000000000000dEaD",
 			n: "0x01", g: "0x20", gp: "0x40", value: "0x01", numMessages: 0},